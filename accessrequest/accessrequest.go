@@ -0,0 +1,64 @@
+// Package accessrequest provides storage for requests to be invited to
+// channels, and for whether they have been approved.
+package accessrequest
+
+import "time"
+
+const (
+	// StatusPending is the status of a Request no one has decided on yet.
+	StatusPending = "pending"
+
+	// StatusApproved is the status of a Request which was approved, and
+	// whose requester was invited to the channel.
+	StatusApproved = "approved"
+
+	// StatusDenied is the status of a Request which was denied.
+	StatusDenied = "denied"
+)
+
+// Request records someone asking to be invited to a channel, and what became
+// of it.
+type Request struct {
+	ID            int       `json:"id"`
+	RequesterID   string    `json:"requester_id"`
+	RequesterName string    `json:"requester_name"`
+	ChannelID     string    `json:"channel_id"`
+	ChannelName   string    `json:"channel_name"`
+	RequestedAt   time.Time `json:"requested_at"`
+
+	// MessageTimestamp identifies the message asking the channel to approve
+	// the Request, so that it can be updated once it has been decided.
+	MessageTimestamp string `json:"message_ts,omitempty"`
+
+	Status      string    `json:"status"`
+	DeciderID   string    `json:"decider_id,omitempty"`
+	DeciderName string    `json:"decider_name,omitempty"`
+	DecidedAt   time.Time `json:"decided_at,omitempty"`
+}
+
+// Pending returns true if the Request has not yet been approved or denied.
+func (r Request) Pending() bool {
+	return r.Status == StatusPending
+}
+
+//go:generate counterfeiter . Store
+
+// Store is somewhere Requests are kept, each under its own ID.
+type Store interface {
+	// Add keeps a new Request, returning it with the ID it was given.
+	Add(Request) (Request, error)
+
+	// Get returns the Request with the given ID, and whether there is one.
+	Get(id int) (Request, bool, error)
+
+	// Put replaces the Request with the same ID.
+	Put(Request) error
+
+	// PutIf replaces the Request with the same ID provided that it still has
+	// the given status, returning whether it did, so that only one of those
+	// deciding on a Request at once can.
+	PutIf(request Request, status string) (bool, error)
+
+	// All returns every Request, oldest first.
+	All() ([]Request, error)
+}
@@ -0,0 +1,13 @@
+package accessrequest_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestAccessRequest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "AccessRequest Suite")
+}
@@ -0,0 +1,215 @@
+// This file was generated by counterfeiter
+package accessrequestfakes
+
+import (
+	"sync"
+
+	"github.com/pivotalservices/goulash/accessrequest"
+)
+
+type FakeStore struct {
+	AddStub        func(accessrequest.Request) (accessrequest.Request, error)
+	addMutex       sync.RWMutex
+	addArgsForCall []struct {
+		arg1 accessrequest.Request
+	}
+	addReturns struct {
+		result1 accessrequest.Request
+		result2 error
+	}
+	GetStub        func(id int) (accessrequest.Request, bool, error)
+	getMutex       sync.RWMutex
+	getArgsForCall []struct {
+		id int
+	}
+	getReturns struct {
+		result1 accessrequest.Request
+		result2 bool
+		result3 error
+	}
+	PutStub        func(accessrequest.Request) error
+	putMutex       sync.RWMutex
+	putArgsForCall []struct {
+		arg1 accessrequest.Request
+	}
+	putReturns struct {
+		result1 error
+	}
+	PutIfStub        func(request accessrequest.Request, status string) (bool, error)
+	putIfMutex       sync.RWMutex
+	putIfArgsForCall []struct {
+		request accessrequest.Request
+		status  string
+	}
+	putIfReturns struct {
+		result1 bool
+		result2 error
+	}
+	AllStub        func() ([]accessrequest.Request, error)
+	allMutex       sync.RWMutex
+	allArgsForCall []struct{}
+	allReturns     struct {
+		result1 []accessrequest.Request
+		result2 error
+	}
+}
+
+func (fake *FakeStore) Add(arg1 accessrequest.Request) (accessrequest.Request, error) {
+	fake.addMutex.Lock()
+	fake.addArgsForCall = append(fake.addArgsForCall, struct {
+		arg1 accessrequest.Request
+	}{arg1})
+	fake.addMutex.Unlock()
+	if fake.AddStub != nil {
+		return fake.AddStub(arg1)
+	} else {
+		return fake.addReturns.result1, fake.addReturns.result2
+	}
+}
+
+func (fake *FakeStore) AddCallCount() int {
+	fake.addMutex.RLock()
+	defer fake.addMutex.RUnlock()
+	return len(fake.addArgsForCall)
+}
+
+func (fake *FakeStore) AddArgsForCall(i int) accessrequest.Request {
+	fake.addMutex.RLock()
+	defer fake.addMutex.RUnlock()
+	return fake.addArgsForCall[i].arg1
+}
+
+func (fake *FakeStore) AddReturns(result1 accessrequest.Request, result2 error) {
+	fake.AddStub = nil
+	fake.addReturns = struct {
+		result1 accessrequest.Request
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStore) Get(id int) (accessrequest.Request, bool, error) {
+	fake.getMutex.Lock()
+	fake.getArgsForCall = append(fake.getArgsForCall, struct {
+		id int
+	}{id})
+	fake.getMutex.Unlock()
+	if fake.GetStub != nil {
+		return fake.GetStub(id)
+	} else {
+		return fake.getReturns.result1, fake.getReturns.result2, fake.getReturns.result3
+	}
+}
+
+func (fake *FakeStore) GetCallCount() int {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return len(fake.getArgsForCall)
+}
+
+func (fake *FakeStore) GetArgsForCall(i int) int {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return fake.getArgsForCall[i].id
+}
+
+func (fake *FakeStore) GetReturns(result1 accessrequest.Request, result2 bool, result3 error) {
+	fake.GetStub = nil
+	fake.getReturns = struct {
+		result1 accessrequest.Request
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeStore) Put(arg1 accessrequest.Request) error {
+	fake.putMutex.Lock()
+	fake.putArgsForCall = append(fake.putArgsForCall, struct {
+		arg1 accessrequest.Request
+	}{arg1})
+	fake.putMutex.Unlock()
+	if fake.PutStub != nil {
+		return fake.PutStub(arg1)
+	} else {
+		return fake.putReturns.result1
+	}
+}
+
+func (fake *FakeStore) PutCallCount() int {
+	fake.putMutex.RLock()
+	defer fake.putMutex.RUnlock()
+	return len(fake.putArgsForCall)
+}
+
+func (fake *FakeStore) PutArgsForCall(i int) accessrequest.Request {
+	fake.putMutex.RLock()
+	defer fake.putMutex.RUnlock()
+	return fake.putArgsForCall[i].arg1
+}
+
+func (fake *FakeStore) PutReturns(result1 error) {
+	fake.PutStub = nil
+	fake.putReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStore) PutIf(request accessrequest.Request, status string) (bool, error) {
+	fake.putIfMutex.Lock()
+	fake.putIfArgsForCall = append(fake.putIfArgsForCall, struct {
+		request accessrequest.Request
+		status  string
+	}{request, status})
+	fake.putIfMutex.Unlock()
+	if fake.PutIfStub != nil {
+		return fake.PutIfStub(request, status)
+	} else {
+		return fake.putIfReturns.result1, fake.putIfReturns.result2
+	}
+}
+
+func (fake *FakeStore) PutIfCallCount() int {
+	fake.putIfMutex.RLock()
+	defer fake.putIfMutex.RUnlock()
+	return len(fake.putIfArgsForCall)
+}
+
+func (fake *FakeStore) PutIfArgsForCall(i int) (accessrequest.Request, string) {
+	fake.putIfMutex.RLock()
+	defer fake.putIfMutex.RUnlock()
+	return fake.putIfArgsForCall[i].request, fake.putIfArgsForCall[i].status
+}
+
+func (fake *FakeStore) PutIfReturns(result1 bool, result2 error) {
+	fake.PutIfStub = nil
+	fake.putIfReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStore) All() ([]accessrequest.Request, error) {
+	fake.allMutex.Lock()
+	fake.allArgsForCall = append(fake.allArgsForCall, struct{}{})
+	fake.allMutex.Unlock()
+	if fake.AllStub != nil {
+		return fake.AllStub()
+	} else {
+		return fake.allReturns.result1, fake.allReturns.result2
+	}
+}
+
+func (fake *FakeStore) AllCallCount() int {
+	fake.allMutex.RLock()
+	defer fake.allMutex.RUnlock()
+	return len(fake.allArgsForCall)
+}
+
+func (fake *FakeStore) AllReturns(result1 []accessrequest.Request, result2 error) {
+	fake.AllStub = nil
+	fake.allReturns = struct {
+		result1 []accessrequest.Request
+		result2 error
+	}{result1, result2}
+}
+
+var _ accessrequest.Store = new(FakeStore)
@@ -0,0 +1,154 @@
+package accessrequest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type fileStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileStore returns a Store which keeps every Request in the file at the
+// given path as a JSON array, creating the file if it does not exist. The file
+// is replaced as a whole, so it is never left partly written.
+func NewFileStore(path string) Store {
+	return &fileStore{path: path}
+}
+
+func (s *fileStore) Add(request Request) (Request, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	requests, err := s.read()
+	if err != nil {
+		return Request{}, err
+	}
+
+	request.ID = nextID(requests)
+	requests[request.ID] = request
+
+	if err = s.write(requests); err != nil {
+		return Request{}, err
+	}
+
+	return request, nil
+}
+
+func (s *fileStore) Get(id int) (Request, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	requests, err := s.read()
+	if err != nil {
+		return Request{}, false, err
+	}
+
+	request, ok := requests[id]
+	return request, ok, nil
+}
+
+func (s *fileStore) Put(request Request) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	requests, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := requests[request.ID]; !ok {
+		return fmt.Errorf("no access request with ID %d", request.ID)
+	}
+
+	requests[request.ID] = request
+
+	return s.write(requests)
+}
+
+func (s *fileStore) PutIf(request Request, status string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	requests, err := s.read()
+	if err != nil {
+		return false, err
+	}
+
+	existing, ok := requests[request.ID]
+	if !ok {
+		return false, fmt.Errorf("no access request with ID %d", request.ID)
+	}
+
+	if existing.Status != status {
+		return false, nil
+	}
+
+	requests[request.ID] = request
+
+	return true, s.write(requests)
+}
+
+func (s *fileStore) All() ([]Request, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	requests, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return sorted(requests), nil
+}
+
+func (s *fileStore) read() (map[int]Request, error) {
+	requests := map[int]Request{}
+
+	contents, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return requests, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Request
+	if err = json.Unmarshal(contents, &list); err != nil {
+		return nil, err
+	}
+
+	for _, request := range list {
+		requests[request.ID] = request
+	}
+
+	return requests, nil
+}
+
+func (s *fileStore) write(requests map[int]Request) error {
+	contents, err := json.MarshalIndent(sorted(requests), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = file.Write(contents); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), s.path)
+}
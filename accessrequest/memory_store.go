@@ -0,0 +1,103 @@
+package accessrequest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+type memoryStore struct {
+	requests map[int]Request
+	mutex    sync.Mutex
+}
+
+// NewMemoryStore returns a Store which keeps Requests only for as long as the
+// process runs.
+func NewMemoryStore() Store {
+	return &memoryStore{requests: map[int]Request{}}
+}
+
+func (s *memoryStore) Add(request Request) (Request, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	request.ID = nextID(s.requests)
+	s.requests[request.ID] = request
+
+	return request, nil
+}
+
+func (s *memoryStore) Get(id int) (Request, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	request, ok := s.requests[id]
+	return request, ok, nil
+}
+
+func (s *memoryStore) Put(request Request) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.requests[request.ID]; !ok {
+		return fmt.Errorf("no access request with ID %d", request.ID)
+	}
+
+	s.requests[request.ID] = request
+
+	return nil
+}
+
+func (s *memoryStore) PutIf(request Request, status string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	existing, ok := s.requests[request.ID]
+	if !ok {
+		return false, fmt.Errorf("no access request with ID %d", request.ID)
+	}
+
+	if existing.Status != status {
+		return false, nil
+	}
+
+	s.requests[request.ID] = request
+
+	return true, nil
+}
+
+func (s *memoryStore) All() ([]Request, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return sorted(s.requests), nil
+}
+
+// nextID returns the ID following the highest of the given Requests.
+func nextID(requests map[int]Request) int {
+	id := 1
+	for existing := range requests {
+		if existing >= id {
+			id = existing + 1
+		}
+	}
+
+	return id
+}
+
+func sorted(requests map[int]Request) []Request {
+	list := []Request{}
+	for _, request := range requests {
+		list = append(list, request)
+	}
+
+	sort.Sort(byID(list))
+
+	return list
+}
+
+type byID []Request
+
+func (r byID) Len() int           { return len(r) }
+func (r byID) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r byID) Less(i, j int) bool { return r[i].ID < r[j].ID }
@@ -0,0 +1,177 @@
+package accessrequest_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pivotalservices/goulash/accessrequest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func behavesLikeAStore(newStore func() accessrequest.Store) {
+	var (
+		store accessrequest.Store
+		now   time.Time
+	)
+
+	BeforeEach(func() {
+		store = newStore()
+		now = time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC)
+	})
+
+	request := func(requesterName string) accessrequest.Request {
+		return accessrequest.Request{
+			RequesterID:   "id-" + requesterName,
+			RequesterName: requesterName,
+			ChannelID:     "channel-id",
+			ChannelName:   "channel-name",
+			RequestedAt:   now,
+			Status:        accessrequest.StatusPending,
+		}
+	}
+
+	It("has no requests to begin with", func() {
+		requests, err := store.All()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(requests).Should(BeEmpty())
+	})
+
+	It("gives each request added its own ID", func() {
+		first, err := store.Add(request("tsmith"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(first.ID).Should(Equal(1))
+
+		second, err := store.Add(request("jdoe"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(second.ID).Should(Equal(2))
+	})
+
+	It("gets a request by its ID", func() {
+		added, err := store.Add(request("tsmith"))
+		Ω(err).ShouldNot(HaveOccurred())
+
+		found, ok, err := store.Get(added.ID)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ok).Should(BeTrue())
+		Ω(found).Should(Equal(added))
+	})
+
+	It("does not find a request which was never added", func() {
+		_, ok, err := store.Get(42)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ok).Should(BeFalse())
+	})
+
+	It("replaces a request", func() {
+		added, err := store.Add(request("tsmith"))
+		Ω(err).ShouldNot(HaveOccurred())
+
+		added.Status = accessrequest.StatusApproved
+		added.DeciderName = "approver"
+		added.DecidedAt = now.Add(time.Hour)
+		Ω(store.Put(added)).Should(Succeed())
+
+		found, _, err := store.Get(added.ID)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(found).Should(Equal(added))
+	})
+
+	It("returns an error when replacing a request which was never added", func() {
+		missing := request("tsmith")
+		missing.ID = 42
+
+		Ω(store.Put(missing)).Should(MatchError("no access request with ID 42"))
+	})
+
+	It("replaces a request only while it has the given status", func() {
+		added, err := store.Add(request("tsmith"))
+		Ω(err).ShouldNot(HaveOccurred())
+
+		approved := added
+		approved.Status = accessrequest.StatusApproved
+		approved.DeciderName = "approver"
+
+		denied := added
+		denied.Status = accessrequest.StatusDenied
+		denied.DeciderName = "denier"
+
+		replaced, err := store.PutIf(approved, accessrequest.StatusPending)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(replaced).Should(BeTrue())
+
+		replaced, err = store.PutIf(denied, accessrequest.StatusPending)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(replaced).Should(BeFalse())
+
+		found, _, err := store.Get(added.ID)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(found).Should(Equal(approved))
+
+		missing := request("jdoe")
+		missing.ID = 42
+
+		_, err = store.PutIf(missing, accessrequest.StatusPending)
+		Ω(err).Should(MatchError("no access request with ID 42"))
+	})
+
+	It("returns every request, oldest first", func() {
+		first, _ := store.Add(request("tsmith"))
+		second, _ := store.Add(request("jdoe"))
+		third, _ := store.Add(request("mjones"))
+
+		requests, err := store.All()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(requests).Should(Equal([]accessrequest.Request{first, second, third}))
+	})
+}
+
+var _ = Describe("MemoryStore", func() {
+	behavesLikeAStore(accessrequest.NewMemoryStore)
+})
+
+var _ = Describe("FileStore", func() {
+	var (
+		dir  string
+		path string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "goulash-access-requests")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		path = filepath.Join(dir, "access-requests.json")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	behavesLikeAStore(func() accessrequest.Store {
+		return accessrequest.NewFileStore(path)
+	})
+
+	It("keeps requests for another store using the same file", func() {
+		added, err := accessrequest.NewFileStore(path).Add(accessrequest.Request{
+			RequesterName: "tsmith",
+			Status:        accessrequest.StatusPending,
+		})
+		Ω(err).ShouldNot(HaveOccurred())
+
+		found, ok, err := accessrequest.NewFileStore(path).Get(added.ID)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(ok).Should(BeTrue())
+		Ω(found).Should(Equal(added))
+	})
+
+	It("returns an error when the file cannot be parsed", func() {
+		Ω(ioutil.WriteFile(path, []byte("not json"), 0600)).Should(Succeed())
+
+		_, err := accessrequest.NewFileStore(path).All()
+		Ω(err).Should(HaveOccurred())
+	})
+})
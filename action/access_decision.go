@@ -0,0 +1,309 @@
+package action
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/accessrequest"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/slack"
+)
+
+type accessDecision struct {
+	params        []string
+	approve       bool
+	store         accessrequest.Store
+	commanderName string
+	commanderID   string
+
+	// request is the Request decided on, once it has been found.
+	request *accessrequest.Request
+}
+
+// NewAccessDecision returns a new access decision action, used to approve or
+// deny the request in the given Store with the number given in params. An
+// approved request's requester is invited to the channel they asked for.
+func NewAccessDecision(
+	params []string,
+	approve bool,
+	store accessrequest.Store,
+	commanderName string,
+	commanderID string,
+) Action {
+	accessDecisionParams := []string{""}
+	copy(accessDecisionParams, params)
+
+	return &accessDecision{
+		params:        accessDecisionParams,
+		approve:       approve,
+		store:         store,
+		commanderName: commanderName,
+		commanderID:   commanderID,
+	}
+}
+
+func (a *accessDecision) Do(
+	config config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) (slackapi.Message, error) {
+	logger = logger.Session("do")
+
+	id, err := strconv.Atoi(a.params[0])
+	if err != nil || id <= 0 {
+		command, _ := lookUpCommand(a.command())
+		err = NewUsageErr(fmt.Sprintf(invalidAccessRequestProblemFmt, a.params[0]), command.usage(), config.SlackSlashCommand())
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(err.Error()), err
+	}
+
+	pending, err := a.check(id, config, api, logger)
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(a.failureMessage(id, err)), err
+	}
+
+	request := pending
+	request.Status = accessrequest.StatusDenied
+	if a.approve {
+		request.Status = accessrequest.StatusApproved
+	}
+	request.DeciderID = a.commanderID
+	request.DeciderName = a.commanderName
+	request.DecidedAt = clock.Now().UTC()
+
+	// The decision is recorded before the requester is invited, and only if
+	// the request is still pending, so that it is never decided twice.
+	if err = a.decide(request); err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(a.failureMessage(id, err)), err
+	}
+	a.request = &request
+
+	if a.approve {
+		if err = inviteToChannel(request.ChannelID, request.RequesterID, api); err != nil {
+			logger.Error("failed", err)
+			if putErr := a.store.Put(pending); putErr != nil {
+				logger.Error("failed-to-restore-pending-request", putErr)
+			}
+			a.request = &pending
+			return slackapi.NewErrorMessage(a.failureMessage(id, err)), err
+		}
+	}
+
+	if request.MessageTimestamp != "" {
+		err = api.UpdateMessage(request.ChannelID, request.MessageTimestamp, decidedAccessRequestMessage(request))
+		if err != nil {
+			logger.Error("failed-to-update-request-message", err)
+		}
+	}
+
+	if err = a.notifyRequester(request, api); err != nil {
+		logger.Error("failed-to-notify-requester", err)
+	}
+
+	logger.Info("succeeded")
+
+	if a.approve {
+		return slackapi.NewTextMessage(fmt.Sprintf(
+			"Approved @%s's request for access to #%s; they have been invited.",
+			request.RequesterName,
+			request.ChannelName,
+		)), nil
+	}
+
+	return slackapi.NewTextMessage(fmt.Sprintf(
+		"Denied @%s's request for access to #%s; they have been told.",
+		request.RequesterName,
+		request.ChannelName,
+	)), nil
+}
+
+// check returns the pending request with the given ID, provided that the
+// commander may decide on it: they must not be its requester, and must be a
+// member of the channel it is for unless the Policy has a rule for the
+// command which permits them.
+func (a *accessDecision) check(
+	id int,
+	config config.Config,
+	api slackapi.SlackAPI,
+	logger lager.Logger,
+) (accessrequest.Request, error) {
+	logger = logger.Session("check")
+
+	user, err := api.GetUserInfo(a.commanderID)
+	if err != nil {
+		logger.Error("failed", err)
+		return accessrequest.Request{}, err
+	}
+
+	if user.IsRestricted || user.IsUltraRestricted {
+		logger.Error("failed", errUnauthorized)
+		return accessrequest.Request{}, errUnauthorized
+	}
+
+	request, found, err := a.store.Get(id)
+	if err != nil {
+		logger.Error("failed", err)
+		return accessrequest.Request{}, err
+	}
+
+	if !found {
+		err = NewAccessRequestNotFoundErr(id)
+		logger.Error("failed", err)
+		return accessrequest.Request{}, err
+	}
+
+	a.request = &request
+
+	if !request.Pending() {
+		err = NewAccessRequestDecidedErr(id, request.Status, request.DeciderName)
+		logger.Error("failed", err)
+		return accessrequest.Request{}, err
+	}
+
+	if request.RequesterID == a.commanderID {
+		logger.Error("failed", errOwnAccessRequest)
+		return accessrequest.Request{}, errOwnAccessRequest
+	}
+
+	permitted, err := a.mayDecide(request, config, api)
+	if err != nil {
+		logger.Error("failed", err)
+		return accessrequest.Request{}, err
+	}
+
+	if !permitted {
+		err = NewNotAccessRequestDeciderErr(request.ChannelName)
+		logger.Error("failed", err)
+		return accessrequest.Request{}, err
+	}
+
+	logger.Info("passed")
+
+	return request, nil
+}
+
+// mayDecide returns true if the commander is permitted by the Policy's rule
+// for the command, or else is a member of the channel the request is for.
+func (a *accessDecision) mayDecide(
+	request accessrequest.Request,
+	config config.Config,
+	api slackapi.SlackAPI,
+) (bool, error) {
+	if rule, ok := config.Policy()[a.command()]; ok {
+		permitted, err := permits(rule, a.commanderID, api)
+		if err != nil || permitted {
+			return permitted, err
+		}
+	}
+
+	members, err := api.GetConversationMembers(request.ChannelID)
+	if err != nil {
+		return false, err
+	}
+
+	return matches(a.commanderID, members...), nil
+}
+
+// decide records the decision on the request, provided that no one else has
+// decided on it since it was found to be pending.
+func (a *accessDecision) decide(request accessrequest.Request) error {
+	decided, err := a.store.PutIf(request, accessrequest.StatusPending)
+	if err != nil || decided {
+		return err
+	}
+
+	current, _, err := a.store.Get(request.ID)
+	if err != nil {
+		return err
+	}
+
+	return NewAccessRequestDecidedErr(request.ID, current.Status, current.DeciderName)
+}
+
+func (a *accessDecision) notifyRequester(request accessrequest.Request, api slackapi.SlackAPI) error {
+	_, _, dmID, err := api.OpenIMChannel(request.RequesterID)
+	if err != nil {
+		return err
+	}
+
+	postMessageParams := slack.NewPostMessageParameters()
+	postMessageParams.AsUser = true
+
+	_, _, err = api.PostMessage(dmID, fmt.Sprintf(
+		"Your request for access to #%s was %s by @%s.",
+		request.ChannelName,
+		request.Status,
+		a.commanderName,
+	), postMessageParams)
+
+	return err
+}
+
+func (a *accessDecision) command() string {
+	if a.approve {
+		return approveAccessRequestCommand
+	}
+
+	return denyAccessRequestCommand
+}
+
+func (a *accessDecision) verb() string {
+	if a.approve {
+		return "approve"
+	}
+
+	return "deny"
+}
+
+func (a *accessDecision) failureMessage(id int, err error) string {
+	return fmt.Sprintf(
+		"Failed to %s access request #%d: %s",
+		a.verb(),
+		id,
+		NewSlackErr(err).Error(),
+	)
+}
+
+func (a *accessDecision) AuditMessage(api slackapi.SlackAPI) string {
+	verb := "approved"
+	if !a.approve {
+		verb = "denied"
+	}
+
+	if a.request == nil {
+		return fmt.Sprintf("@%s %s access request #%s", a.commanderName, verb, a.params[0])
+	}
+
+	return fmt.Sprintf(
+		"@%s %s @%s's request for access to #%s",
+		a.commanderName,
+		verb,
+		a.request.RequesterName,
+		a.request.ChannelName,
+	)
+}
+
+func (a *accessDecision) AuditTarget() string {
+	if a.request == nil {
+		return ""
+	}
+
+	return a.request.RequesterID
+}
+
+// inviteToChannel invites the user to the public or private channel,
+// treating them already being in it as success.
+func inviteToChannel(channelID string, userID string, api slackapi.SlackAPI) error {
+	err := api.InviteToConversation(channelID, userID)
+	if slackapi.ErrorCode(err) == slackapi.ErrorAlreadyInChannel {
+		return nil
+	}
+
+	return err
+}
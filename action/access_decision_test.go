@@ -0,0 +1,319 @@
+package action_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/accessrequest"
+	"github.com/pivotalservices/goulash/accessrequest/accessrequestfakes"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AccessDecision", func() {
+	var (
+		c            config.Config
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeStore    *accessrequestfakes.FakeStore
+		fakeClock    *fakeclock.FakeClock
+		logger       lager.Logger
+		request      accessrequest.Request
+	)
+
+	BeforeEach(func() {
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackAuthToken:    "slack-auth-token",
+			SlackSlashCommand: "/slack-slash-command",
+			SlackTeamName:     "slack-team-name",
+			SlackUserID:       "slack-user-id",
+			AuditLogChannelID: "audit-log-channel-id",
+		})
+
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeSlackAPI.GetUserInfoReturns(&slack.User{}, nil)
+		fakeSlackAPI.OpenIMChannelReturns(false, false, "dm-id", nil)
+		fakeSlackAPI.GetConversationMembersReturns([]string{"requester-id", "commander-id"}, nil)
+
+		request = accessrequest.Request{
+			ID:               42,
+			RequesterID:      "requester-id",
+			RequesterName:    "requester-name",
+			ChannelID:        "channel-id",
+			ChannelName:      "channel-name",
+			RequestedAt:      time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC),
+			MessageTimestamp: "message-ts",
+			Status:           accessrequest.StatusPending,
+		}
+
+		fakeStore = &accessrequestfakes.FakeStore{}
+		fakeStore.GetReturns(request, true, nil)
+		fakeStore.PutIfReturns(true, nil)
+
+		fakeClock = fakeclock.NewFakeClock(time.Date(2016, 5, 2, 12, 0, 0, 0, time.UTC))
+		logger = lager.NewLogger("testlogger")
+	})
+
+	Describe("Do", func() {
+		Context("when approving", func() {
+			var a action.Action
+
+			BeforeEach(func() {
+				a = action.NewAccessDecision([]string{"42"}, true, fakeStore, "commander-name", "commander-id")
+			})
+
+			It("invites the requester to the channel", func() {
+				result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(result.String()).Should(Equal("Approved @requester-name's request for access to #channel-name; they have been invited."))
+
+				Ω(fakeStore.GetArgsForCall(0)).Should(Equal(42))
+
+				Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(1))
+				channelID, userID := fakeSlackAPI.InviteToConversationArgsForCall(0)
+				Ω(channelID).Should(Equal("channel-id"))
+				Ω(userID).Should(Equal("requester-id"))
+			})
+
+			It("treats the requester already being in the channel as success", func() {
+				fakeSlackAPI.InviteToConversationReturns(slackapi.Error{Code: slackapi.ErrorAlreadyInChannel})
+
+				_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+
+			It("records the decision", func() {
+				_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				approved := request
+				approved.Status = accessrequest.StatusApproved
+				approved.DeciderID = "commander-id"
+				approved.DeciderName = "commander-name"
+				approved.DecidedAt = time.Date(2016, 5, 2, 12, 0, 0, 0, time.UTC)
+
+				Ω(fakeStore.PutIfCallCount()).Should(Equal(1))
+				decided, status := fakeStore.PutIfArgsForCall(0)
+				Ω(decided).Should(Equal(approved))
+				Ω(status).Should(Equal(accessrequest.StatusPending))
+			})
+
+			It("replaces the buttons in the channel with the decision", func() {
+				_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(fakeSlackAPI.UpdateMessageCallCount()).Should(Equal(1))
+
+				channelID, timestamp, message := fakeSlackAPI.UpdateMessageArgsForCall(0)
+				Ω(channelID).Should(Equal("channel-id"))
+				Ω(timestamp).Should(Equal("message-ts"))
+				Ω(message.Text).Should(Equal("<@requester-id> asked to be invited to this channel. :white_check_mark: Approved by <@commander-id>."))
+				Ω(message.Blocks).Should(Equal([]slackapi.Block{
+					slackapi.NewSectionBlock("<@requester-id> asked to be invited to this channel."),
+					slackapi.NewContextBlock(":white_check_mark: Approved by <@commander-id>"),
+				}))
+			})
+
+			It("tells the requester", func() {
+				_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(fakeSlackAPI.OpenIMChannelArgsForCall(0)).Should(Equal("requester-id"))
+
+				Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(1))
+				channelID, text, params := fakeSlackAPI.PostMessageArgsForCall(0)
+				Ω(channelID).Should(Equal("dm-id"))
+				Ω(text).Should(Equal("Your request for access to #channel-name was approved by @commander-name."))
+				Ω(params.AsUser).Should(BeTrue())
+			})
+
+			It("succeeds even if the requester cannot be told", func() {
+				fakeSlackAPI.PostMessageReturns("", "", errors.New("post-message-err"))
+
+				_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+			})
+
+			It("returns an error and leaves the request pending when the invitation fails", func() {
+				fakeSlackAPI.InviteToConversationReturns(errors.New("invite-err"))
+
+				result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).Should(MatchError("invite-err"))
+				Ω(result.String()).Should(Equal("Failed to approve access request #42: invite-err"))
+
+				Ω(fakeStore.PutCallCount()).Should(Equal(1))
+				Ω(fakeStore.PutArgsForCall(0)).Should(Equal(request))
+				Ω(fakeSlackAPI.UpdateMessageCallCount()).Should(Equal(0))
+			})
+
+			It("does not invite the requester when someone else decides on the request first", func() {
+				denied := request
+				denied.Status = accessrequest.StatusDenied
+				denied.DeciderName = "decider-name"
+
+				fakeStore.PutIfReturns(false, nil)
+				fakeStore.GetStub = func(id int) (accessrequest.Request, bool, error) {
+					if fakeStore.GetCallCount() == 1 {
+						return request, true, nil
+					}
+
+					return denied, true, nil
+				}
+
+				_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).Should(Equal(action.NewAccessRequestDecidedErr(42, "denied", "decider-name")))
+
+				Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(0))
+				Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(0))
+			})
+		})
+
+		Context("when denying", func() {
+			var a action.Action
+
+			BeforeEach(func() {
+				a = action.NewAccessDecision([]string{"42"}, false, fakeStore, "commander-name", "commander-id")
+			})
+
+			It("does not invite the requester", func() {
+				result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(result.String()).Should(Equal("Denied @requester-name's request for access to #channel-name; they have been told."))
+
+				Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(0))
+			})
+
+			It("records the decision and tells the requester", func() {
+				_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				decided, _ := fakeStore.PutIfArgsForCall(0)
+				Ω(decided.Status).Should(Equal(accessrequest.StatusDenied))
+
+				_, _, message := fakeSlackAPI.UpdateMessageArgsForCall(0)
+				Ω(message.Blocks[1]).Should(Equal(slackapi.NewContextBlock(":x: Denied by <@commander-id>")))
+
+				_, text, _ := fakeSlackAPI.PostMessageArgsForCall(0)
+				Ω(text).Should(Equal("Your request for access to #channel-name was denied by @commander-name."))
+			})
+		})
+
+		It("returns a usage error when the number is not a number", func() {
+			a := action.NewAccessDecision([]string{"abc"}, true, fakeStore, "commander-name", "commander-id")
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("Expected an access request number such as `42`, but got 'abc'."))
+
+			Ω(fakeStore.GetCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error when the commander is a guest", func() {
+			fakeSlackAPI.GetUserInfoReturns(&slack.User{IsRestricted: true}, nil)
+
+			a := action.NewAccessDecision([]string{"42"}, true, fakeStore, "commander-name", "commander-id")
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("Sorry, you don't have access to that function."))
+
+			Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error when there is no such request", func() {
+			fakeStore.GetReturns(accessrequest.Request{}, false, nil)
+
+			a := action.NewAccessDecision([]string{"42"}, true, fakeStore, "commander-name", "commander-id")
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(Equal(action.NewAccessRequestNotFoundErr(42)))
+			Ω(result.String()).Should(Equal("Failed to approve access request #42: Access request #42 not found."))
+		})
+
+		It("returns an error when the request has already been decided", func() {
+			request.Status = accessrequest.StatusDenied
+			request.DeciderName = "decider-name"
+			fakeStore.GetReturns(request, true, nil)
+
+			a := action.NewAccessDecision([]string{"42"}, true, fakeStore, "commander-name", "commander-id")
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(Equal(action.NewAccessRequestDecidedErr(42, "denied", "decider-name")))
+
+			Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(0))
+			Ω(fakeStore.PutIfCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error when the commander is the requester", func() {
+			a := action.NewAccessDecision([]string{"42"}, true, fakeStore, "requester-name", "requester-id")
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("You cannot decide on your own access request."))
+
+			Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(0))
+			Ω(fakeStore.PutIfCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error when the commander is not a member of the channel", func() {
+			fakeSlackAPI.GetConversationMembersReturns([]string{"requester-id", "someone-else"}, nil)
+
+			a := action.NewAccessDecision([]string{"42"}, true, fakeStore, "commander-name", "commander-id")
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(Equal(action.NewNotAccessRequestDeciderErr("channel-name")))
+			Ω(err.Error()).Should(Equal("Only members of #channel-name can decide on requests for access to it."))
+
+			Ω(fakeSlackAPI.GetConversationMembersArgsForCall(0)).Should(Equal("channel-id"))
+			Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(0))
+			Ω(fakeStore.PutIfCallCount()).Should(Equal(0))
+		})
+
+		It("lets those the policy permits decide without being members of the channel", func() {
+			fakeSlackAPI.GetConversationMembersReturns([]string{"requester-id"}, nil)
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:    "slack-auth-token",
+				SlackSlashCommand: "/slack-slash-command",
+				SlackTeamName:     "slack-team-name",
+				SlackUserID:       "slack-user-id",
+				AuditLogChannelID: "audit-log-channel-id",
+				Policy:            config.Policy{"approve-access-request": {UserIDs: []string{"commander-id"}}},
+			})
+
+			a := action.NewAccessDecision([]string{"42"}, true, fakeStore, "commander-name", "commander-id")
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(1))
+		})
+	})
+
+	Describe("AuditMessage", func() {
+		It("names the requester and channel once the request is found", func() {
+			a := action.NewAccessDecision([]string{"42"}, true, fakeStore, "commander-name", "commander-id")
+			a.Do(c, fakeSlackAPI, fakeClock, logger)
+
+			ta, ok := a.(action.TargetedAction)
+			Ω(ok).Should(BeTrue())
+			Ω(ta.AuditMessage(fakeSlackAPI)).Should(Equal("@commander-name approved @requester-name's request for access to #channel-name"))
+			Ω(ta.AuditTarget()).Should(Equal("requester-id"))
+		})
+
+		It("names the request's number otherwise", func() {
+			fakeStore.GetReturns(accessrequest.Request{}, false, nil)
+
+			a := action.NewAccessDecision([]string{"42"}, false, fakeStore, "commander-name", "commander-id")
+			a.Do(c, fakeSlackAPI, fakeClock, logger)
+
+			ta := a.(action.TargetedAction)
+			Ω(ta.AuditMessage(fakeSlackAPI)).Should(Equal("@commander-name denied access request #42"))
+			Ω(ta.AuditTarget()).Should(BeEmpty())
+		})
+	})
+})
@@ -2,36 +2,96 @@ package action
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/pivotal-golang/clock"
 	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/accessrequest"
 	"github.com/pivotalservices/goulash/config"
 	"github.com/pivotalservices/goulash/slackapi"
 	"github.com/pivotalservices/slack"
 )
 
+const (
+	approveAccessRequestCommand = "approve-access-request"
+	denyAccessRequestCommand    = "deny-access-request"
+)
+
+func init() {
+	Register(Command{
+		Name:        "request-access",
+		Params:      []Param{{Name: "#channel"}},
+		Description: "Request an invitation to a channel",
+		New: func(r Request) Action {
+			return NewAccessRequest(r.Params, r.CommanderName, r.CommanderID, accessRequests)
+		},
+	})
+}
+
+// accessRequests is where request-access keeps the requests it makes, or nil
+// if they are not tracked.
+var accessRequests accessrequest.Store
+
+// RegisterAccessRequests has request-access ask for approval with buttons,
+// keeping its requests in the given Store, and registers the commands which
+// approve, deny, and list them.
+func RegisterAccessRequests(store accessrequest.Store) {
+	accessRequests = store
+
+	Register(Command{
+		Name:        approveAccessRequestCommand,
+		Params:      []Param{{Name: "number"}},
+		Description: "Approve a request for access to a channel, inviting the requester to it",
+		New: func(r Request) Action {
+			return NewAccessDecision(r.Params, true, store, r.CommanderName, r.CommanderID)
+		},
+	})
+
+	Register(Command{
+		Name:        denyAccessRequestCommand,
+		Params:      []Param{{Name: "number"}},
+		Description: "Deny a request for access to a channel",
+		New: func(r Request) Action {
+			return NewAccessDecision(r.Params, false, store, r.CommanderName, r.CommanderID)
+		},
+	})
+
+	Register(Command{
+		Name:        accessRequestsCommand,
+		Options:     []Option{{Name: "all"}},
+		Description: "List the requests for access to channels awaiting a decision, or all of them with `--all`",
+		New: func(r Request) Action {
+			return NewAccessRequestList(r.Options["all"] != "", store)
+		},
+	})
+}
+
 type accessRequest struct {
 	params        []string
 	commanderName string
 	commanderID   string
+	store         accessrequest.Store
 }
 
 // NewAccessRequest returns a new AccessRequest action, used to request an
-// invitation to a channel.
+// invitation to a channel. When a Store is given, the request is kept in it
+// and the channel is asked to approve or deny it; otherwise the channel is
+// only told of it.
 func NewAccessRequest(
 	params []string,
 	commanderName string,
 	commanderID string,
+	store accessrequest.Store,
 ) Action {
 	accessRequestParams := []string{"", "", ""}
-	for i := range params {
-		accessRequestParams[i] = params[i]
-	}
+	copy(accessRequestParams, params)
 
 	return &accessRequest{
 		params:        accessRequestParams,
 		commanderName: commanderName,
 		commanderID:   commanderID,
+		store:         store,
 	}
 }
 
@@ -48,22 +108,48 @@ func (a accessRequest) channelName() string {
 func (a accessRequest) Do(
 	config config.Config,
 	api slackapi.SlackAPI,
+	clock clock.Clock,
 	logger lager.Logger,
-) (string, error) {
+) (slackapi.Message, error) {
 	logger = logger.Session("do")
 
 	err := a.check(api, logger)
 	if err != nil {
 		logger.Error("failed", err)
-		return a.failureMessage(err), err
+		return slackapi.NewErrorMessage(a.failureMessage(err)), err
 	}
 
 	channel, err := findChannel(a.channelName(), api)
 	if err != nil {
 		logger.Error("failed", err)
-		return a.failureMessage(err), err
+		return slackapi.NewErrorMessage(a.failureMessage(err)), err
+	}
+
+	if a.store == nil {
+		err = a.announce(channel, api)
+	} else {
+		err = a.askForApproval(channel, config, api, clock, logger)
+	}
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(a.failureMessage(err)), err
+	}
+
+	logger.Info("succeeded")
+
+	successMessage := fmt.Sprintf(
+		"Successfully requested access to <#%s>.",
+		a.channelName(),
+	)
+	if a.store != nil {
+		successMessage += " You will be sent a message once it is approved or denied."
 	}
 
+	return slackapi.NewTextMessage(successMessage), nil
+}
+
+// announce tells the channel that the commander would like to be invited.
+func (a accessRequest) announce(channel slackapi.Conversation, api slackapi.SlackAPI) error {
 	message := fmt.Sprintf(
 		"@%s would like to be invited to this channel. To invite them, use `/invite @%s`",
 		a.commanderName,
@@ -74,20 +160,43 @@ func (a accessRequest) Do(
 	postMessageParams.AsUser = true
 	postMessageParams.Parse = "full"
 
-	_, _, err = api.PostMessage(channel.ID, message, postMessageParams)
+	_, _, err := api.PostMessage(channel.ID, message, postMessageParams)
+	return err
+}
+
+// askForApproval keeps the request, and asks the channel to approve or deny it.
+func (a accessRequest) askForApproval(
+	channel slackapi.Conversation,
+	config config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) error {
+	request, err := a.store.Add(accessrequest.Request{
+		RequesterID:   a.commanderID,
+		RequesterName: a.commanderName,
+		ChannelID:     channel.ID,
+		ChannelName:   channel.Name,
+		RequestedAt:   clock.Now().UTC(),
+		Status:        accessrequest.StatusPending,
+	})
 	if err != nil {
-		logger.Error("failed", err)
-		return a.failureMessage(err), err
+		return err
 	}
 
-	logger.Info("succeeded")
+	timestamp, err := api.SendMessage(channel.ID, pendingAccessRequestMessage(request, config.SlackSlashCommand()))
+	if err != nil {
+		return err
+	}
 
-	successMessage := fmt.Sprintf(
-		"Successfully requested access to <#%s>.",
-		a.channelName(),
-	)
+	// The request has been made even if the message cannot be updated once
+	// it is decided, so failing to record where it is is not an error.
+	request.MessageTimestamp = timestamp
+	if err = a.store.Put(request); err != nil {
+		logger.Error("failed-to-record-message-timestamp", err)
+	}
 
-	return successMessage, nil
+	return nil
 }
 
 func (a accessRequest) check(
@@ -116,7 +225,7 @@ func (a accessRequest) failureMessage(err error) string {
 	return fmt.Sprintf(
 		"Failed to request access to #%s: %s",
 		a.channelName(),
-		err.Error(),
+		NewSlackErr(err).Error(),
 	)
 }
 
@@ -129,3 +238,47 @@ func (a accessRequest) AuditMessage(
 		a.channelName(),
 	)
 }
+
+func (a accessRequest) AuditTarget() string {
+	return "#" + a.channelName()
+}
+
+func pendingAccessRequestMessage(request accessrequest.Request, slackSlashCommand string) slackapi.Message {
+	text := fmt.Sprintf("<@%s> would like to be invited to this channel.", request.RequesterID)
+	id := strconv.Itoa(request.ID)
+
+	return slackapi.Message{
+		Text: text,
+		Blocks: []slackapi.Block{
+			slackapi.NewSectionBlock(text),
+			slackapi.NewActionsBlock(
+				"access-request-"+id,
+				slackapi.NewButton("Approve", approveAccessRequestCommand, id, slackapi.ButtonStylePrimary),
+				slackapi.NewButton("Deny", denyAccessRequestCommand, id, slackapi.ButtonStyleDanger),
+			),
+			slackapi.NewContextBlock(fmt.Sprintf(
+				"Request #%s. You can also use `%s %s %s` or `%s %s %s`.",
+				id,
+				slackSlashCommand, approveAccessRequestCommand, id,
+				slackSlashCommand, denyAccessRequestCommand, id,
+			)),
+		},
+	}
+}
+
+func decidedAccessRequestMessage(request accessrequest.Request) slackapi.Message {
+	text := fmt.Sprintf("<@%s> asked to be invited to this channel.", request.RequesterID)
+
+	decision := fmt.Sprintf(":x: Denied by <@%s>", request.DeciderID)
+	if request.Status == accessrequest.StatusApproved {
+		decision = fmt.Sprintf(":white_check_mark: Approved by <@%s>", request.DeciderID)
+	}
+
+	return slackapi.Message{
+		Text: fmt.Sprintf("%s %s.", text, decision),
+		Blocks: []slackapi.Block{
+			slackapi.NewSectionBlock(text),
+			slackapi.NewContextBlock(decision),
+		},
+	}
+}
@@ -0,0 +1,105 @@
+package action
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/accessrequest"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+)
+
+const (
+	accessRequestsCommand = "access-requests"
+
+	// maxAccessRequests is how many of the most recent matching requests are
+	// shown.
+	maxAccessRequests = 50
+)
+
+type accessRequestList struct {
+	all   bool
+	store accessrequest.Store
+}
+
+// NewAccessRequestList returns a new access request list action, used to list
+// the pending requests in the given Store, or all of them.
+func NewAccessRequestList(all bool, store accessrequest.Store) Action {
+	return &accessRequestList{
+		all:   all,
+		store: store,
+	}
+}
+
+func (l accessRequestList) Do(
+	config config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) (slackapi.Message, error) {
+	logger = logger.Session("do")
+
+	all, err := l.store.All()
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(fmt.Sprintf("Failed to list access requests: %s", err.Error())), err
+	}
+
+	var requests []accessrequest.Request
+	for _, request := range all {
+		if l.all || request.Pending() {
+			requests = append(requests, request)
+		}
+	}
+
+	logger.Info("succeeded", lager.Data{"requests": len(requests)})
+
+	kind := "pending access requests"
+	if l.all {
+		kind = "access requests"
+	}
+
+	if len(requests) == 0 {
+		return slackapi.NewTextMessage(fmt.Sprintf("No %s found.", kind)), nil
+	}
+
+	summary := fmt.Sprintf("Found %d %s.", len(requests), kind)
+	if len(requests) > maxAccessRequests {
+		summary = fmt.Sprintf("Found %d %s; showing the most recent %d.", len(requests), kind, maxAccessRequests)
+		requests = requests[len(requests)-maxAccessRequests:]
+	}
+
+	var lines []string
+	for _, request := range requests {
+		lines = append(lines, accessRequestLine(request))
+	}
+
+	message := slackapi.Message{
+		Text:   strings.Join(append([]string{summary}, lines...), "\n"),
+		Blocks: []slackapi.Block{slackapi.NewSectionBlock(summary)},
+	}
+	message.Blocks = append(message.Blocks, slackapi.NewSectionBlocks(lines)...)
+
+	return message.Fit(), nil
+}
+
+func accessRequestLine(request accessrequest.Request) string {
+	line := fmt.Sprintf(
+		"`#%d` `%s` @%s asked for #%s",
+		request.ID,
+		request.RequestedAt.UTC().Format(auditTimeFormat),
+		request.RequesterName,
+		request.ChannelName,
+	)
+
+	switch request.Status {
+	case accessrequest.StatusApproved:
+		return fmt.Sprintf(":white_check_mark: %s: approved by @%s", line, request.DeciderName)
+	case accessrequest.StatusDenied:
+		return fmt.Sprintf(":x: %s: denied by @%s", line, request.DeciderName)
+	}
+
+	return fmt.Sprintf(":hourglass: %s: pending", line)
+}
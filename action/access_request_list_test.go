@@ -0,0 +1,87 @@
+package action_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/accessrequest"
+	"github.com/pivotalservices/goulash/accessrequest/accessrequestfakes"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AccessRequestList", func() {
+	var (
+		c            config.Config
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeStore    *accessrequestfakes.FakeStore
+		fakeClock    *fakeclock.FakeClock
+		logger       lager.Logger
+	)
+
+	BeforeEach(func() {
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackSlashCommand: "/slack-slash-command",
+		})
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		logger = lager.NewLogger("testlogger")
+
+		requestedAt := time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC)
+
+		fakeStore = &accessrequestfakes.FakeStore{}
+		fakeStore.AllReturns([]accessrequest.Request{
+			{ID: 1, RequesterName: "tsmith", ChannelName: "general", RequestedAt: requestedAt, Status: accessrequest.StatusApproved, DeciderName: "admin"},
+			{ID: 2, RequesterName: "jdoe", ChannelName: "random", RequestedAt: requestedAt, Status: accessrequest.StatusDenied, DeciderName: "admin"},
+			{ID: 3, RequesterName: "mjones", ChannelName: "general", RequestedAt: requestedAt, Status: accessrequest.StatusPending},
+		}, nil)
+	})
+
+	Describe("Do", func() {
+		It("lists the pending requests", func() {
+			a := action.NewAccessRequestList(false, fakeStore)
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(Equal("Found 1 pending access requests.\n" +
+				":hourglass: `#3` `2016-05-01 12:00 UTC` @mjones asked for #general: pending"))
+		})
+
+		It("lists every request with --all", func() {
+			a := action.NewAccessRequestList(true, fakeStore)
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(Equal("Found 3 access requests.\n" +
+				":white_check_mark: `#1` `2016-05-01 12:00 UTC` @tsmith asked for #general: approved by @admin\n" +
+				":x: `#2` `2016-05-01 12:00 UTC` @jdoe asked for #random: denied by @admin\n" +
+				":hourglass: `#3` `2016-05-01 12:00 UTC` @mjones asked for #general: pending"))
+		})
+
+		It("says when there are none", func() {
+			fakeStore.AllReturns(nil, nil)
+
+			a := action.NewAccessRequestList(false, fakeStore)
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(Equal("No pending access requests found."))
+		})
+
+		It("returns an error when the requests cannot be listed", func() {
+			fakeStore.AllReturns(nil, errors.New("all-err"))
+
+			a := action.NewAccessRequestList(false, fakeStore)
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("all-err"))
+			Ω(result.String()).Should(Equal("Failed to list access requests: all-err"))
+		})
+	})
+})
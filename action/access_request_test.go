@@ -2,8 +2,12 @@ package action_test
 
 import (
 	"errors"
+	"time"
 
+	"github.com/pivotal-golang/clock/fakeclock"
 	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/accessrequest"
+	"github.com/pivotalservices/goulash/accessrequest/accessrequestfakes"
 	"github.com/pivotalservices/goulash/action"
 	"github.com/pivotalservices/goulash/config"
 	"github.com/pivotalservices/goulash/slackapi"
@@ -18,25 +22,27 @@ var _ = Describe("AccessRequest", func() {
 	var (
 		c            config.Config
 		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeClock    *fakeclock.FakeClock
 		logger       lager.Logger
 	)
 
 	BeforeEach(func() {
 		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
 	})
 
 	Describe("Do", func() {
 		BeforeEach(func() {
 			logger = lager.NewLogger("testlogger")
-			c = config.NewLocalConfig(
-				"slack-auth-token",
-				"/slack-slash-command",
-				"slack-team-name",
-				"slack-user-id",
-				"audit-log-channel-id",
-				"uninvitable-domain.com",
-				"uninvitable-domain-message",
-			)
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:     "slack-auth-token",
+				SlackSlashCommand:  "/slack-slash-command",
+				SlackTeamName:      "slack-team-name",
+				SlackUserID:        "slack-user-id",
+				AuditLogChannelID:  "audit-log-channel-id",
+				UninvitableDomain:  "uninvitable-domain.com",
+				UninvitableMessage: "uninvitable-domain-message",
+			})
 		})
 
 		It("returns an error if the commanding user is a single-channel guest", func() {
@@ -53,10 +59,10 @@ var _ = Describe("AccessRequest", func() {
 				"request-access #channel-name",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("Sorry, you don't have access to that function."))
-			Ω(result).Should(Equal("Failed to request access to #channel-name: Sorry, you don't have access to that function."))
+			Ω(result.String()).Should(Equal("Failed to request access to #channel-name: Sorry, you don't have access to that function."))
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(0))
 		})
@@ -75,10 +81,10 @@ var _ = Describe("AccessRequest", func() {
 				"request-access #channel-name",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("Sorry, you don't have access to that function."))
-			Ω(result).Should(Equal("Failed to request access to #channel-name: Sorry, you don't have access to that function."))
+			Ω(result.String()).Should(Equal("Failed to request access to #channel-name: Sorry, you don't have access to that function."))
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(0))
 		})
@@ -93,17 +99,17 @@ var _ = Describe("AccessRequest", func() {
 				"request-access #channel-name",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("get-user-info-err"))
-			Ω(result).Should(Equal("Failed to request access to #channel-name: get-user-info-err"))
+			Ω(result.String()).Should(Equal("Failed to request access to #channel-name: get-user-info-err"))
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(0))
 		})
 
 		It("returns an error when getting channels returns an error", func() {
 			fakeSlackAPI.GetUserInfoReturns(&slack.User{}, nil)
-			fakeSlackAPI.GetChannelsReturns([]slack.Channel{}, errors.New("get-channels-err"))
+			fakeSlackAPI.GetConversationsReturns([]slackapi.Conversation{}, errors.New("get-channels-err"))
 
 			a := action.New(
 				slackapi.NewChannel("channel-name", "channel-id"),
@@ -112,17 +118,17 @@ var _ = Describe("AccessRequest", func() {
 				"request-access #channel-name",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("get-channels-err"))
-			Ω(result).Should(Equal("Failed to request access to #channel-name: get-channels-err"))
+			Ω(result.String()).Should(Equal("Failed to request access to #channel-name: get-channels-err"))
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(0))
 		})
 
 		It("returns an error when the channel can't be found", func() {
 			fakeSlackAPI.GetUserInfoReturns(&slack.User{}, nil)
-			fakeSlackAPI.GetChannelsReturns([]slack.Channel{}, nil)
+			fakeSlackAPI.GetConversationsReturns([]slackapi.Conversation{}, nil)
 
 			a := action.New(
 				slackapi.NewChannel("channel-name", "channel-id"),
@@ -131,20 +137,20 @@ var _ = Describe("AccessRequest", func() {
 				"request-access #channel-name",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("Channel '#channel-name' not found."))
-			Ω(result).Should(Equal("Failed to request access to #channel-name: Channel '#channel-name' not found."))
+			Ω(result.String()).Should(Equal("Failed to request access to #channel-name: Channel '#channel-name' not found."))
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(0))
 		})
 
 		It("attempts to send a message to the channel for which access was requested when the channel can be found", func() {
 			fakeSlackAPI.GetUserInfoReturns(&slack.User{}, nil)
-			expectedChannel := slack.Channel{}
+			expectedChannel := slackapi.Conversation{}
 			expectedChannel.Name = "channel-name"
 			expectedChannel.ID = "channel-id"
-			fakeSlackAPI.GetChannelsReturns([]slack.Channel{expectedChannel}, nil)
+			fakeSlackAPI.GetConversationsReturns([]slackapi.Conversation{expectedChannel}, nil)
 
 			a := action.New(
 				slackapi.NewChannel("channel-name", "channel-id"),
@@ -153,7 +159,7 @@ var _ = Describe("AccessRequest", func() {
 				"request-access #channel-name",
 			)
 
-			_, err := a.Do(c, fakeSlackAPI, logger)
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(1))
@@ -167,10 +173,10 @@ var _ = Describe("AccessRequest", func() {
 
 		It("returns a positive result and nil on success", func() {
 			fakeSlackAPI.GetUserInfoReturns(&slack.User{}, nil)
-			expectedChannel := slack.Channel{}
+			expectedChannel := slackapi.Conversation{}
 			expectedChannel.Name = "channel-name"
 			expectedChannel.ID = "channel-id"
-			fakeSlackAPI.GetChannelsReturns([]slack.Channel{expectedChannel}, nil)
+			fakeSlackAPI.GetConversationsReturns([]slackapi.Conversation{expectedChannel}, nil)
 
 			a := action.New(
 				slackapi.NewChannel("channel-name", "channel-id"),
@@ -179,17 +185,17 @@ var _ = Describe("AccessRequest", func() {
 				"request-access #channel-name",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
-			Ω(result).Should(Equal("Successfully requested access to <#channel-name>."))
+			Ω(result.String()).Should(Equal("Successfully requested access to <#channel-name>."))
 		})
 
 		It("returns an error if the PostMessage call fails", func() {
 			fakeSlackAPI.GetUserInfoReturns(&slack.User{}, nil)
-			expectedChannel := slack.Channel{}
+			expectedChannel := slackapi.Conversation{}
 			expectedChannel.Name = "channel-name"
 			expectedChannel.ID = "channel-id"
-			fakeSlackAPI.GetChannelsReturns([]slack.Channel{expectedChannel}, nil)
+			fakeSlackAPI.GetConversationsReturns([]slackapi.Conversation{expectedChannel}, nil)
 
 			fakeSlackAPI.PostMessageReturns("channel", "timestamp", errors.New("post-message-err"))
 
@@ -200,10 +206,104 @@ var _ = Describe("AccessRequest", func() {
 				"request-access #channel-name",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("post-message-err"))
-			Ω(result).Should(Equal("Failed to request access to #channel-name: post-message-err"))
+			Ω(result.String()).Should(Equal("Failed to request access to #channel-name: post-message-err"))
+		})
+
+		Context("when requests are kept in a Store", func() {
+			var fakeStore *accessrequestfakes.FakeStore
+
+			BeforeEach(func() {
+				fakeClock = fakeclock.NewFakeClock(time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC))
+
+				fakeStore = &accessrequestfakes.FakeStore{}
+				fakeStore.AddStub = func(request accessrequest.Request) (accessrequest.Request, error) {
+					request.ID = 42
+					return request, nil
+				}
+
+				fakeSlackAPI.GetUserInfoReturns(&slack.User{}, nil)
+				expectedChannel := slackapi.Conversation{}
+				expectedChannel.Name = "channel-name"
+				expectedChannel.ID = "channel-id"
+				fakeSlackAPI.GetConversationsReturns([]slackapi.Conversation{expectedChannel}, nil)
+				fakeSlackAPI.SendMessageReturns("message-ts", nil)
+			})
+
+			It("keeps the request", func() {
+				a := action.NewAccessRequest([]string{"#channel-name"}, "commander-name", "commander-id", fakeStore)
+
+				result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(result.String()).Should(Equal("Successfully requested access to <#channel-name>. You will be sent a message once it is approved or denied."))
+
+				Ω(fakeStore.AddCallCount()).Should(Equal(1))
+				Ω(fakeStore.AddArgsForCall(0)).Should(Equal(accessrequest.Request{
+					RequesterID:   "commander-id",
+					RequesterName: "commander-name",
+					ChannelID:     "channel-id",
+					ChannelName:   "channel-name",
+					RequestedAt:   time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC),
+					Status:        accessrequest.StatusPending,
+				}))
+			})
+
+			It("asks the channel to approve or deny it", func() {
+				a := action.NewAccessRequest([]string{"#channel-name"}, "commander-name", "commander-id", fakeStore)
+
+				_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(0))
+				Ω(fakeSlackAPI.SendMessageCallCount()).Should(Equal(1))
+
+				channelID, message := fakeSlackAPI.SendMessageArgsForCall(0)
+				Ω(channelID).Should(Equal("channel-id"))
+				Ω(message.Text).Should(Equal("<@commander-id> would like to be invited to this channel."))
+				Ω(message.Blocks).Should(ContainElement(slackapi.NewActionsBlock(
+					"access-request-42",
+					slackapi.NewButton("Approve", "approve-access-request", "42", slackapi.ButtonStylePrimary),
+					slackapi.NewButton("Deny", "deny-access-request", "42", slackapi.ButtonStyleDanger),
+				)))
+				Ω(message.Blocks).Should(ContainElement(slackapi.NewContextBlock(
+					"Request #42. You can also use `/slack-slash-command approve-access-request 42` or `/slack-slash-command deny-access-request 42`.",
+				)))
+			})
+
+			It("records which message asked for approval", func() {
+				a := action.NewAccessRequest([]string{"#channel-name"}, "commander-name", "commander-id", fakeStore)
+
+				_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(fakeStore.PutCallCount()).Should(Equal(1))
+				Ω(fakeStore.PutArgsForCall(0).ID).Should(Equal(42))
+				Ω(fakeStore.PutArgsForCall(0).MessageTimestamp).Should(Equal("message-ts"))
+			})
+
+			It("returns an error if the request cannot be kept", func() {
+				fakeStore.AddStub = nil
+				fakeStore.AddReturns(accessrequest.Request{}, errors.New("add-err"))
+
+				a := action.NewAccessRequest([]string{"#channel-name"}, "commander-name", "commander-id", fakeStore)
+
+				result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).Should(MatchError("add-err"))
+				Ω(result.String()).Should(Equal("Failed to request access to #channel-name: add-err"))
+				Ω(fakeSlackAPI.SendMessageCallCount()).Should(Equal(0))
+			})
+
+			It("returns an error if the message cannot be sent", func() {
+				fakeSlackAPI.SendMessageReturns("", errors.New("send-message-err"))
+
+				a := action.NewAccessRequest([]string{"#channel-name"}, "commander-name", "commander-id", fakeStore)
+
+				result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).Should(MatchError("send-message-err"))
+				Ω(result.String()).Should(Equal("Failed to request access to #channel-name: send-message-err"))
+			})
 		})
 	})
 
@@ -1,18 +1,18 @@
 package action
 
 import (
-	"fmt"
 	"strings"
+	"unicode"
 
+	"github.com/pivotal-golang/clock"
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotalservices/goulash/config"
 	"github.com/pivotalservices/goulash/slackapi"
-	"github.com/pivotalservices/slack"
 )
 
 // Action represents an action that is able to be performed by the server.
 type Action interface {
-	Do(config.Config, slackapi.SlackAPI, lager.Logger) (string, error)
+	Do(config.Config, slackapi.SlackAPI, clock.Clock, lager.Logger) (slackapi.Message, error)
 }
 
 // AuditableAction is an Action that should have an audit log entry created.
@@ -20,78 +20,136 @@ type AuditableAction interface {
 	AuditMessage(slackapi.SlackAPI) string
 }
 
-// New creates a new Action based on the command provided.
+// MultiAuditableAction is an AuditableAction that, once performed, should have
+// an audit log entry created for each of the things it did instead.
+type MultiAuditableAction interface {
+	AuditableAction
+	AuditEntries(slackapi.SlackAPI) []AuditEntry
+}
+
+// TargetedAction is an AuditableAction that acts on a particular user or
+// channel, which its audit log entry names as its target.
+type TargetedAction interface {
+	AuditableAction
+	AuditTarget() string
+}
+
+// AuditEntry describes one thing done by a MultiAuditableAction, the error
+// that prevented it if it failed, and whether it was only a dry run.
+type AuditEntry struct {
+	Message string
+	Command string
+	Target  string
+	Err     error
+	DryRun  bool
+
+	// TargetRole and TargetChannelID are recorded as in audit.Event.
+	TargetRole      string
+	TargetChannelID string
+}
+
+// New creates a new Action for the registered Command named, or aliased, by
+// the first word of text. Parameters may be quoted to include whitespace. When
+// they do not match what the command expects, the returned Action explains how
+// the command should be used. Text not naming a Command gets help. Destructive
+// commands ask to be confirmed before doing anything.
 func New(
 	channel slackapi.Channel,
 	commanderName string,
 	commanderID string,
 	text string,
 ) Action {
-	commandAndParams := strings.Fields(text)
-	command := commandAndParams[0]
-	params := commandAndParams[1:]
+	return newAction(channel, commanderName, commanderID, text, false)
+}
 
-	switch command {
-	case "info":
-		return NewInfo(params, commanderName)
+// NewConfirmed is like New, but returns destructive commands ready to be
+// performed, as if they had already been confirmed.
+func NewConfirmed(
+	channel slackapi.Channel,
+	commanderName string,
+	commanderID string,
+	text string,
+) Action {
+	return newAction(channel, commanderName, commanderID, text, true)
+}
 
-	case "invite-guest", "invite-restricted":
-		return NewInvite(params, command, channel, commanderName)
+func newAction(
+	channel slackapi.Channel,
+	commanderName string,
+	commanderID string,
+	text string,
+	confirmed bool,
+) Action {
+	lines := strings.Split(text, "\n")
 
-	case "disable-user":
-		return NewDisableUser(params, commanderName)
+	command, ok := lookUpCommand(commandName(text))
+	if !ok {
+		return help{}
+	}
 
-	case "guestify":
-		return NewGuestify(params, channel, commanderName)
+	commandLine := text
+	if command.Lines {
+		commandLine = lines[0]
+	}
 
-	case "restrictify":
-		return NewRestrictify(params, channel, commanderName)
+	args, options, err := command.parseCommandLine(commandLine)
+	if err != nil {
+		return invalidUsage{problem: err.Error(), usage: command.usage()}
+	}
+	params := args[1:]
 
-	case "groups":
-		return NewGroups(commanderName, commanderID)
+	if problem := command.check(params, options); problem != "" {
+		return invalidUsage{problem: problem, usage: command.usage()}
+	}
 
-	case "request-access":
-		return NewAccessRequest(params, commanderName, commanderID)
+	request := Request{
+		Command:       command.Name,
+		Params:        params,
+		Options:       options,
+		Lines:         lines[1:],
+		Channel:       channel,
+		CommanderName: commanderName,
+		CommanderID:   commanderID,
+	}
+	_, request.DryRun = options[dryRunOption]
 
-	default:
-		return help{}
+	if request.DryRun {
+		return NewDryRun(command.New(request), command.Name)
 	}
-}
 
-func uninvitableEmail(emailAddress string, uninvitableDomain string) bool {
-	return len(uninvitableDomain) > 0 && strings.HasSuffix(emailAddress, uninvitableDomain)
+	// The command is checked before being confirmed by performing it as a
+	// dry run, so it is created as one, to describe rather than make any
+	// changes outside Slack.
+	if command.Destructive && !confirmed {
+		checkRequest := request
+		checkRequest.DryRun = true
+		return NewConfirmation(command.New(checkRequest), request, text)
+	}
+
+	return command.New(request)
 }
 
-func findUser(searchVal string, api slackapi.SlackAPI) (slack.User, error) {
-	users, err := api.GetUsers()
-	if err != nil {
-		return slack.User{}, err
-	}
+func findChannel(searchVal string, api slackapi.SlackAPI) (slackapi.Conversation, error) {
+	if directory, ok := api.(slackapi.Directory); ok {
+		channel, found, err := directory.ChannelByName(searchVal)
+		if err != nil {
+			return slackapi.Conversation{}, err
+		}
 
-	var foundUser slack.User
-	for _, user := range users {
-		if matches(searchVal, user.Profile.Email, fmt.Sprintf("@%s", user.Name)) {
-			foundUser = user
-			break
+		if !found {
+			return slackapi.Conversation{}, NewChannelNotFoundErr(searchVal)
 		}
-	}
 
-	if (foundUser == slack.User{}) {
-		err = NewUserNotFoundErr(searchVal)
-		return slack.User{}, err
+		return channel, nil
 	}
 
-	return foundUser, nil
-}
-
-func findChannel(searchVal string, api slackapi.SlackAPI) (slack.Channel, error) {
 	excludeArchived := true
-	channels, err := api.GetChannels(excludeArchived)
+	channels, err := api.GetConversations([]string{slackapi.PublicChannel}, excludeArchived)
 	if err != nil {
-		return slack.Channel{}, err
+		return slackapi.Conversation{}, err
 	}
 
-	var foundChannel slack.Channel
+	var foundChannel slackapi.Conversation
 	var found bool
 	for _, channel := range channels {
 		if matches(searchVal, channel.Name) {
@@ -102,12 +160,53 @@ func findChannel(searchVal string, api slackapi.SlackAPI) (slack.Channel, error)
 	}
 
 	if !found {
-		return slack.Channel{}, NewChannelNotFoundErr(searchVal)
+		return slackapi.Conversation{}, NewChannelNotFoundErr(searchVal)
 	}
 
 	return foundChannel, nil
 }
 
+// findChannelOrGroup returns the public channel with the given name, or
+// failing that the private channel with that name which the configured user
+// is in.
+func findChannelOrGroup(name string, api slackapi.SlackAPI) (slackapi.Channel, error) {
+	channel, err := findChannel(name, api)
+	if err == nil {
+		return slackapi.NewChannel(channel.Name, channel.ID), nil
+	}
+
+	if _, notFound := err.(channelNotFoundErr); !notFound {
+		return nil, err
+	}
+
+	excludeArchived := true
+	groups, err := api.GetConversations([]string{slackapi.PrivateChannel}, excludeArchived)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		if matches(name, group.Name) {
+			return slackapi.NewChannel(group.Name, group.ID), nil
+		}
+	}
+
+	return nil, NewChannelNotFoundErr(name)
+}
+
+// channelNames splits a list of channel names, such as "#eng,#design", into
+// the names without their leading #.
+func channelNames(list string) []string {
+	var names []string
+	for _, name := range strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}) {
+		names = append(names, strings.TrimPrefix(name, "#"))
+	}
+
+	return names
+}
+
 func matches(searchVal string, candidates ...string) bool {
 	var match bool
 	for _, candidate := range candidates {
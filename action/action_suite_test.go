@@ -1,6 +1,10 @@
 package action_test
 
 import (
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -11,3 +15,73 @@ func TestAction(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Action Suite")
 }
+
+// stubConversations makes fakeSlackAPI return the given public and private
+// channels for whichever of those types are asked for.
+func stubConversations(
+	fakeSlackAPI *slackapifakes.FakeSlackAPI,
+	public []slackapi.Conversation,
+	private []slackapi.Conversation,
+) {
+	fakeSlackAPI.GetConversationsStub = func(types []string, excludeArchived bool) ([]slackapi.Conversation, error) {
+		var conversations []slackapi.Conversation
+		for _, t := range types {
+			switch t {
+			case slackapi.PublicChannel:
+				conversations = append(conversations, public...)
+			case slackapi.PrivateChannel:
+				conversations = append(conversations, private...)
+			}
+		}
+
+		return conversations, nil
+	}
+}
+
+// stubMembers makes fakeSlackAPI return the members of each conversation, by
+// its ID, and the conversations stubbed with stubConversations that each user
+// is a member of.
+func stubMembers(fakeSlackAPI *slackapifakes.FakeSlackAPI, members map[string][]string) {
+	fakeSlackAPI.GetConversationMembersStub = func(conversationID string) ([]string, error) {
+		return members[conversationID], nil
+	}
+
+	fakeSlackAPI.GetUserConversationsStub = func(userID string, types []string, excludeArchived bool) ([]slackapi.Conversation, error) {
+		var conversations []slackapi.Conversation
+		if fakeSlackAPI.GetConversationsStub == nil {
+			return conversations, nil
+		}
+
+		all, err := fakeSlackAPI.GetConversationsStub(types, excludeArchived)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, conversation := range all {
+			for _, member := range members[conversation.ID] {
+				if member == userID {
+					conversations = append(conversations, conversation)
+				}
+			}
+		}
+
+		return conversations, nil
+	}
+}
+
+// stubUsers makes fakeSlackAPI return the given users as a single page, and
+// look each of them up by ID as Slack would. Anyone else is looked up as a
+// full member.
+func stubUsers(fakeSlackAPI *slackapifakes.FakeSlackAPI, users []slack.User) {
+	fakeSlackAPI.GetUsersPageReturns(users, "", nil)
+
+	fakeSlackAPI.GetUserInfoStub = func(userID string) (*slack.User, error) {
+		for _, user := range users {
+			if user.ID == userID {
+				return &user, nil
+			}
+		}
+
+		return &slack.User{ID: userID}, nil
+	}
+}
@@ -1,8 +1,14 @@
 package action_test
 
 import (
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
 	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/config"
 	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -37,6 +43,10 @@ var _ = Describe("Action", func() {
 				"invite-guest",
 				expectedChannel,
 				"commander-name",
+				"",
+				registeredExpiryStore,
+				false,
+				nil,
 			)))
 		})
 
@@ -54,11 +64,56 @@ var _ = Describe("Action", func() {
 				"invite-restricted",
 				expectedChannel,
 				"commander-name",
+				"",
+				registeredExpiryStore,
+				false,
+				nil,
 			)))
 		})
 
-		It("supports creating a disable user action", func() {
+		It("supports creating an invite-bulk action", func() {
+			expectedChannel := slackapi.NewChannel("channel-name", "channel-id")
 			a = action.New(
+				expectedChannel,
+				"commander-name",
+				"commander-id",
+				"invite-bulk guest\nuser@example.com,Tom,Smith",
+			)
+
+			Ω(a).Should(Equal(action.NewBulkInvite(
+				"guest",
+				[]string{"user@example.com,Tom,Smith"},
+				expectedChannel,
+				"commander-name",
+			)))
+		})
+
+		It("asks for destructive actions to be confirmed", func() {
+			channel := slackapi.NewChannel("channel-name", "channel-id")
+			a = action.New(
+				channel,
+				"commander-name",
+				"commander-id",
+				"disable-user user@example.com",
+			)
+
+			Ω(a).Should(Equal(action.NewConfirmation(
+				action.NewDisableUser([]string{"user@example.com"}, "commander-name"),
+				action.Request{
+					Command:       "disable-user",
+					Params:        []string{"user@example.com"},
+					Options:       map[string]string{},
+					Lines:         []string{},
+					Channel:       channel,
+					CommanderName: "commander-name",
+					CommanderID:   "commander-id",
+				},
+				"disable-user user@example.com",
+			)))
+		})
+
+		It("supports creating a disable user action", func() {
+			a = action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
@@ -70,7 +125,7 @@ var _ = Describe("Action", func() {
 
 		It("supports creating a guestify action", func() {
 			channel := slackapi.NewChannel("channel-name", "channel-id")
-			a = action.New(
+			a = action.NewConfirmed(
 				channel,
 				"commander-name",
 				"commander-id",
@@ -113,7 +168,109 @@ var _ = Describe("Action", func() {
 				"request-access #channel-name",
 			)
 
-			Ω(a).Should(Equal(action.NewAccessRequest([]string{"#channel-name"}, "commander-name", "commander-id")))
+			Ω(a).Should(Equal(action.NewAccessRequest([]string{"#channel-name"}, "commander-name", "commander-id", nil)))
+		})
+	})
+
+	Describe("parameters", func() {
+		var (
+			channel      slackapi.Channel
+			c            config.Config
+			fakeSlackAPI *slackapifakes.FakeSlackAPI
+			fakeClock    *fakeclock.FakeClock
+			logger       lager.Logger
+		)
+
+		BeforeEach(func() {
+			channel = slackapi.NewChannel("channel-name", "channel-id")
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackSlashCommand: "/slack-slash-command",
+			})
+			fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+			fakeClock = fakeclock.NewFakeClock(time.Now())
+			logger = lager.NewLogger("testlogger")
+		})
+
+		newInvite := func(params ...string) action.Action {
+			return action.NewInvite(params, "invite-guest", channel, "commander-name", "", registeredExpiryStore, false, nil)
+		}
+
+		usageErr := func(text string) error {
+			a := action.New(channel, "commander-name", "commander-id", text)
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(result.String()).Should(Equal(err.Error()))
+
+			return err
+		}
+
+		It("keeps quoted words together", func() {
+			a := action.New(channel, "commander-name", "commander-id", `invite-guest a@example.com "Mary Ann" 'Van Der Berg'`)
+
+			Ω(a).Should(Equal(newInvite("a@example.com", "Mary Ann", "Van Der Berg")))
+		})
+
+		It("treats curly double quotes as quotes", func() {
+			a := action.New(channel, "commander-name", "commander-id", "invite-guest a@example.com “Mary Ann” Smith")
+
+			Ω(a).Should(Equal(newInvite("a@example.com", "Mary Ann", "Smith")))
+		})
+
+		It("includes characters escaped with a backslash", func() {
+			a := action.New(channel, "commander-name", "commander-id", `invite-guest a@example.com Mary\ Ann "Van \"Der\" Berg"`)
+
+			Ω(a).Should(Equal(newInvite("a@example.com", "Mary Ann", `Van "Der" Berg`)))
+		})
+
+		It("keeps quotes within a word", func() {
+			a := action.New(channel, "commander-name", "commander-id", "invite-guest a@example.com Sean O'Brien")
+
+			Ω(a).Should(Equal(newInvite("a@example.com", "Sean", "O'Brien")))
+		})
+
+		It("returns a usage error for unexpected parameters", func() {
+			err := usageErr(`invite-guest a@example.com Mary Ann "Van Der Berg"`)
+
+			Ω(err).Should(MatchError("Unexpected parameter 'Van Der Berg'. Usage: `/slack-slash-command invite-guest [email] [firstname] [lastname] [--expires duration]`"))
+			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
+		})
+
+		It("returns a usage error for missing parameters", func() {
+			err := usageErr("disable-user")
+
+			Ω(err).Should(MatchError("Missing required email|@username parameter. Usage: `/slack-slash-command disable-user [email|@username]`"))
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+		})
+
+		It("returns a usage error for unknown options", func() {
+			err := usageErr("groups --all")
+
+			Ω(err).Should(MatchError("Unknown option '--all'. Usage: `/slack-slash-command groups`"))
+		})
+
+		It("returns a usage error for an unterminated quote", func() {
+			err := usageErr(`invite-guest a@example.com "Mary Ann`)
+
+			Ω(err).Should(MatchError("Unterminated quote. Usage: `/slack-slash-command invite-guest [email] [firstname] [lastname] [--expires duration]`"))
+		})
+
+		It("treats words after -- as parameters", func() {
+			a := action.New(channel, "commander-name", "commander-id", "invite-guest a@example.com -- --Tom Smith")
+
+			Ω(a).Should(Equal(newInvite("a@example.com", "--Tom", "Smith")))
+		})
+
+		It("does not panic when constructors are given too many parameters", func() {
+			Ω(func() { newInvite("a@example.com", "Mary", "Ann", "Smith") }).ShouldNot(Panic())
+			Ω(func() { action.NewInfo([]string{"a@example.com", "b@example.com"}, "commander-name") }).ShouldNot(Panic())
+		})
+
+		It("returns help for an empty command", func() {
+			a := action.New(channel, "commander-name", "commander-id", "   ")
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.Text).Should(ContainSubstring("*USAGE*"))
 		})
 	})
 })
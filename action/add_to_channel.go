@@ -0,0 +1,227 @@
+package action
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/slack"
+)
+
+func init() {
+	Register(Command{
+		Name:        "add-to-channel",
+		Params:      []Param{{Name: "email|@username"}, {Name: "#channel,..."}},
+		Description: "Add an existing Restricted Account to one or more channels/groups",
+		Mutating:    true,
+		Permission:  &config.PolicyRule{Admins: true},
+		New: func(r Request) Action {
+			return NewAddToChannel(r.Params, r.CommanderName)
+		},
+	})
+}
+
+type addToChannel struct {
+	params     []string
+	addingUser string
+
+	// channels are the channels the user is added to, once they have been
+	// found.
+	channels []slackapi.Channel
+
+	// added is how many of the channels the user was added to before adding
+	// them to the next one failed, or all of them.
+	added int
+
+	// userID is the ID of the user, once they have been found.
+	userID string
+}
+
+func (a addToChannel) searchVal() string {
+	return a.params[0]
+}
+
+// NewAddToChannel returns a new add to channel action, used to add an
+// existing Restricted Account to the channels or groups named in params.
+func NewAddToChannel(params []string, addingUser string) Action {
+	addToChannelParams := []string{"", ""}
+	copy(addToChannelParams, params)
+
+	return &addToChannel{
+		params:     addToChannelParams,
+		addingUser: addingUser,
+	}
+}
+
+func (a *addToChannel) Do(
+	config config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) (slackapi.Message, error) {
+	logger = logger.Session("do")
+
+	user, err := a.check(config, api, logger)
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(a.failureMessage(a.channelList(api), err)), err
+	}
+
+	a.userID = user.ID
+
+	for _, channel := range a.channels {
+		if err = inviteToChannel(channel.ID(), user.ID, api); err != nil {
+			logger.Error("failed", err, lager.Data{"channelID": channel.ID(), "added": a.added})
+			message := a.failureMessage(fmt.Sprintf("'%s'", channel.Name(api)), err)
+			if a.added > 0 {
+				message = fmt.Sprintf("%s. They were added to %s before that.", strings.TrimSuffix(message, "."), a.addedList(api))
+			}
+			return slackapi.NewErrorMessage(message), err
+		}
+		a.added++
+	}
+
+	logger.Info("succeeded")
+
+	return slackapi.NewTextMessage(fmt.Sprintf(
+		"Successfully added user '%s' to %s",
+		a.searchVal(),
+		a.channelList(api),
+	)), nil
+}
+
+// AuditMessage names each channel the user was added to and, when adding
+// them to one failed after others succeeded, the channels they were not.
+func (a *addToChannel) AuditMessage(api slackapi.SlackAPI) string {
+	if len(a.channels) == 0 {
+		return fmt.Sprintf("@%s added user %s to %s", a.addingUser, a.searchVal(), a.params[1])
+	}
+
+	if a.added == 0 || a.added == len(a.channels) {
+		return fmt.Sprintf(
+			"@%s added user %s to %s",
+			a.addingUser,
+			a.searchVal(),
+			destinations(a.channels, api),
+		)
+	}
+
+	return fmt.Sprintf(
+		"@%s added user %s to %s, but not to %s",
+		a.addingUser,
+		a.searchVal(),
+		destinations(a.channels[:a.added], api),
+		destinations(a.channels[a.added:], api),
+	)
+}
+
+func (a *addToChannel) AuditTarget() string {
+	return userTarget(a.userID, a.searchVal())
+}
+
+// channelList returns the names of the channels the user is added to, or as
+// they were given if they have not been found.
+func (a *addToChannel) channelList(api slackapi.SlackAPI) string {
+	if len(a.channels) == 0 {
+		return fmt.Sprintf("'%s'", a.params[1])
+	}
+
+	var names []string
+	for _, channel := range a.channels {
+		names = append(names, channel.Name(api))
+	}
+
+	return quotedList(names)
+}
+
+// addedList returns the names of the channels the user has been added to.
+func (a *addToChannel) addedList(api slackapi.SlackAPI) string {
+	var names []string
+	for _, channel := range a.channels[:a.added] {
+		names = append(names, channel.Name(api))
+	}
+
+	return quotedList(names)
+}
+
+// destinations returns the names and IDs of the channels, for audit
+// messages.
+func destinations(channels []slackapi.Channel, api slackapi.SlackAPI) string {
+	var destinations []string
+	for _, channel := range channels {
+		destinations = append(destinations, fmt.Sprintf("'%s' (%s)", channel.Name(api), channel.ID()))
+	}
+
+	return strings.Join(destinations, ", ")
+}
+
+func (a *addToChannel) failureMessage(channels string, err error) string {
+	return fmt.Sprintf(
+		"Failed to add user '%s' to %s: %s",
+		a.searchVal(),
+		channels,
+		NewSlackErr(err).Error(),
+	)
+}
+
+// check returns the user to add, provided that they are a Restricted
+// Account and that the channels they are to be added to are all visible.
+func (a *addToChannel) check(
+	config config.Config,
+	api slackapi.SlackAPI,
+	logger lager.Logger,
+) (slack.User, error) {
+	logger = logger.Session("check")
+
+	names := channelNames(a.params[1])
+	if len(names) == 0 {
+		command, _ := lookUpCommand("add-to-channel")
+		err := NewUsageErr(fmt.Sprintf(missingParameterProblemFmt, "#channel"), command.usage(), config.SlackSlashCommand())
+		logger.Error("failed", err)
+		return slack.User{}, err
+	}
+
+	user, err := findUserToChange(a.searchVal(), "", api)
+	if err != nil {
+		logger.Error("failed", err)
+		return slack.User{}, err
+	}
+
+	if user.IsUltraRestricted {
+		err = NewGuestCannotBeErr("added to more channels")
+		logger.Error("failed", err)
+		return slack.User{}, err
+	}
+
+	if !user.IsRestricted {
+		err = NewFullUserCannotBeErr("added to channels")
+		logger.Error("failed", err)
+		return slack.User{}, err
+	}
+
+	var channels []slackapi.Channel
+	for _, name := range names {
+		channel, err := findChannelOrGroup(name, api)
+		if err != nil {
+			logger.Error("failed", err)
+			return slack.User{}, err
+		}
+
+		if !channel.Visible(api) {
+			err = NewChannelNotVisibleErr(config.SlackUserID())
+			logger.Error("failed", err, lager.Data{"channelID": channel.ID()})
+			return slack.User{}, err
+		}
+
+		channels = append(channels, channel)
+	}
+
+	a.channels = channels
+
+	logger.Info("passed")
+
+	return user, nil
+}
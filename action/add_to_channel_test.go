@@ -0,0 +1,220 @@
+package action_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AddToChannel", func() {
+	var (
+		c            config.Config
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeClock    *fakeclock.FakeClock
+		logger       lager.Logger
+	)
+
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackAuthToken:    "slack-auth-token",
+			SlackSlashCommand: "/slack-slash-command",
+			SlackTeamName:     "slack-team-name",
+			SlackUserID:       "slack-user-id",
+			AuditLogChannelID: "audit-log-channel-id",
+		})
+
+		logger = lager.NewLogger("testlogger")
+
+		stubUsers(fakeSlackAPI, []slack.User{
+			{
+				ID:           "U1234",
+				Name:         "tsmith",
+				IsRestricted: true,
+			},
+		})
+
+		stubConversations(
+			fakeSlackAPI,
+			[]slackapi.Conversation{{ID: "C1", Name: "eng"}},
+			[]slackapi.Conversation{{ID: "G1", Name: "secret", IsPrivate: true}},
+		)
+		fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{IsMember: true}, nil)
+	})
+
+	newAddToChannel := func(text string) action.Action {
+		return action.New(
+			slackapi.NewChannel("channel-name", "channel-id"),
+			"commander-name",
+			"commander-id",
+			text,
+		)
+	}
+
+	Describe("Do", func() {
+		It("adds the user to a public channel", func() {
+			result, err := newAddToChannel("add-to-channel @tsmith #eng").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(Equal("Successfully added user '@tsmith' to 'eng'"))
+
+			Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(1))
+			channelID, userID := fakeSlackAPI.InviteToConversationArgsForCall(0)
+			Ω(channelID).Should(Equal("C1"))
+			Ω(userID).Should(Equal("U1234"))
+		})
+
+		It("adds the user to each of several channels and groups", func() {
+			result, err := newAddToChannel("add-to-channel @tsmith #eng,#secret").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(Equal("Successfully added user '@tsmith' to 'eng', 'secret'"))
+
+			Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(2))
+
+			channelID, userID := fakeSlackAPI.InviteToConversationArgsForCall(0)
+			Ω(channelID).Should(Equal("C1"))
+			Ω(userID).Should(Equal("U1234"))
+
+			groupID, userID := fakeSlackAPI.InviteToConversationArgsForCall(1)
+			Ω(groupID).Should(Equal("G1"))
+			Ω(userID).Should(Equal("U1234"))
+		})
+
+		It("treats the user already being in the channel as success", func() {
+			fakeSlackAPI.InviteToConversationReturns(slackapi.Error{Code: slackapi.ErrorAlreadyInChannel})
+
+			_, err := newAddToChannel("add-to-channel @tsmith #eng").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("returns an error naming the channel the user could not be added to", func() {
+			fakeSlackAPI.InviteToConversationStub = func(channelID string, userID string) error {
+				if channelID == "G1" {
+					return errors.New("cant_invite")
+				}
+				return nil
+			}
+
+			result, err := newAddToChannel("add-to-channel @tsmith #eng,#secret").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("cant_invite"))
+			Ω(result.String()).Should(Equal("Failed to add user '@tsmith' to 'secret': cant_invite. They were added to 'eng' before that."))
+		})
+
+		It("returns an error naming only the channel which failed when it is the first", func() {
+			fakeSlackAPI.InviteToConversationReturns(errors.New("cant_invite"))
+
+			result, err := newAddToChannel("add-to-channel @tsmith #eng,#secret").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("cant_invite"))
+			Ω(result.String()).Should(Equal("Failed to add user '@tsmith' to 'eng': cant_invite"))
+			Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(1))
+		})
+
+		It("returns an error without adding the user anywhere when a channel cannot be found", func() {
+			result, err := newAddToChannel("add-to-channel @tsmith #eng,#nope").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(Equal(action.NewChannelNotFoundErr("nope")))
+			Ω(result.String()).Should(Equal("Failed to add user '@tsmith' to '#eng,#nope': Channel '#nope' not found."))
+
+			Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error without adding the user anywhere when the configured user is not in a channel", func() {
+			fakeSlackAPI.GetConversationInfoStub = func(channelID string) (slackapi.Conversation, error) {
+				return slackapi.Conversation{ID: channelID, IsMember: channelID == "C1"}, nil
+			}
+
+			_, err := newAddToChannel("add-to-channel @tsmith #eng,#secret").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(Equal(action.NewChannelNotVisibleErr("slack-user-id")))
+
+			Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error if the user cannot be found", func() {
+			_, err := newAddToChannel("add-to-channel @jdoe #eng").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("Unable to find user matching '@jdoe'."))
+		})
+
+		It("refuses to guess when several users match", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{ID: "U1234", Name: "tsmith", RealName: "Tom Smith", IsRestricted: true},
+				{ID: "U5678", Name: "tsmith2", RealName: "Tom Smith", IsRestricted: true},
+			})
+
+			_, err := newAddToChannel(`add-to-channel "Tom Smith" #eng`).Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(Equal(action.NewAmbiguousUserErr("Tom Smith", []string{"@tsmith", "@tsmith2"})))
+
+			Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error if the user is a single-channel guest", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{
+					ID:                "U1234",
+					Name:              "tsmith",
+					IsUltraRestricted: true,
+				},
+			})
+
+			_, err := newAddToChannel("add-to-channel @tsmith #eng").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("Single-channel guests cannot be added to more channels."))
+
+			Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error if the user is a full user", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{
+					ID:   "U1234",
+					Name: "tsmith",
+				},
+			})
+
+			_, err := newAddToChannel("add-to-channel @tsmith #eng").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("Full users cannot be added to channels."))
+
+			Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(0))
+		})
+
+		It("returns a usage error when the channel is missing", func() {
+			_, err := newAddToChannel("add-to-channel @tsmith").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("Missing required #channel,... parameter."))
+		})
+	})
+
+	Describe("AuditMessage", func() {
+		It("names each channel the user was added to", func() {
+			a := newAddToChannel("add-to-channel @tsmith #eng,#secret")
+			a.Do(c, fakeSlackAPI, fakeClock, logger)
+
+			ta, ok := a.(action.TargetedAction)
+			Ω(ok).Should(BeTrue())
+			Ω(ta.AuditMessage(fakeSlackAPI)).Should(Equal("@commander-name added user @tsmith to 'eng' (C1), 'secret' (G1)"))
+			Ω(ta.AuditTarget()).Should(Equal("U1234"))
+		})
+
+		It("names the channels the user was and was not added to when one fails", func() {
+			fakeSlackAPI.InviteToConversationStub = func(channelID string, userID string) error {
+				if channelID == "G1" {
+					return errors.New("cant_invite")
+				}
+				return nil
+			}
+
+			a := newAddToChannel("add-to-channel @tsmith #eng,#secret")
+			a.Do(c, fakeSlackAPI, fakeClock, logger)
+
+			aa := a.(action.AuditableAction)
+			Ω(aa.AuditMessage(fakeSlackAPI)).Should(Equal("@commander-name added user @tsmith to 'eng' (C1), but not to 'secret' (G1)"))
+		})
+	})
+})
@@ -0,0 +1,187 @@
+package action
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/audit"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+)
+
+const (
+	auditCommand = "audit"
+
+	// maxAuditEvents is how many of the most recent matching events are
+	// shown.
+	maxAuditEvents = 50
+
+	auditTimeFormat = "2006-01-02 15:04 MST"
+	sinceDateFormat = "2006-01-02"
+)
+
+// auditEvents is the Store the audit command queries, or nil if there is
+// none. Other commands look up who invited whom in it.
+var auditEvents audit.Store
+
+// RegisterAudit registers the audit command, which queries the given Store.
+// By default only admins and owners may run it.
+func RegisterAudit(store audit.Store) {
+	auditEvents = store
+
+	Register(Command{
+		Name:        auditCommand,
+		Params:      []Param{{Name: "email|@username", Optional: true}},
+		Options:     []Option{{Name: "since", Value: "time"}},
+		Description: "List who invited, disabled, or changed whom, optionally only since a time ago such as `72h` or `7d`, or a date such as `2016-05-01`",
+		Permission:  &config.PolicyRule{Admins: true},
+		New: func(r Request) Action {
+			return NewAuditQuery(r.Params, r.Options["since"], store, r.CommanderName)
+		},
+	})
+}
+
+type auditQuery struct {
+	params         []string
+	since          string
+	store          audit.Store
+	requestingUser string
+}
+
+// NewAuditQuery returns a new audit query action, used to list the events in
+// the given Store for the user given in params, or for everyone, since the
+// given duration ago or date.
+func NewAuditQuery(
+	params []string,
+	since string,
+	store audit.Store,
+	requestingUser string,
+) Action {
+	auditParams := []string{""}
+	copy(auditParams, params)
+
+	return &auditQuery{
+		params:         auditParams,
+		since:          since,
+		store:          store,
+		requestingUser: requestingUser,
+	}
+}
+
+func (a auditQuery) user() string {
+	return a.params[0]
+}
+
+func (a auditQuery) Do(
+	config config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) (slackapi.Message, error) {
+	logger = logger.Session("do")
+
+	since, ok := parseSince(a.since, clock.Now())
+	if !ok {
+		command, _ := lookUpCommand(auditCommand)
+		err := NewUsageErr(fmt.Sprintf(invalidSinceProblemFmt, a.since), command.usage(), config.SlackSlashCommand())
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(err.Error()), err
+	}
+
+	events, err := a.store.Query(a.query(since, api, logger))
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(fmt.Sprintf("Failed to query the audit log: %s", err.Error())), err
+	}
+
+	logger.Info("succeeded", lager.Data{"events": len(events)})
+
+	if len(events) == 0 {
+		return slackapi.NewTextMessage("No audit log entries found."), nil
+	}
+
+	summary := fmt.Sprintf("Found %d audit log entries.", len(events))
+	if len(events) > maxAuditEvents {
+		summary = fmt.Sprintf("Found %d audit log entries; showing the most recent %d.", len(events), maxAuditEvents)
+		events = events[len(events)-maxAuditEvents:]
+	}
+
+	var lines []string
+	for _, event := range events {
+		lines = append(lines, auditEventLine(event))
+	}
+
+	message := slackapi.Message{
+		Text:   strings.Join(append([]string{summary}, lines...), "\n"),
+		Blocks: []slackapi.Block{slackapi.NewSectionBlock(summary)},
+	}
+	message.Blocks = append(message.Blocks, slackapi.NewSectionBlocks(lines)...)
+
+	return message.Fit(), nil
+}
+
+// query returns the Query for the events since the given time for the user,
+// if one was given. Actions record the users they target by ID, or by email
+// address when they are invited, so the user is looked up to match those
+// however they were given. Events are still matched as the user was given if
+// they cannot be found, such as when they have been invited but not joined.
+func (a auditQuery) query(since time.Time, api slackapi.SlackAPI, logger lager.Logger) audit.Query {
+	query := audit.Query{User: a.user(), Since: since}
+	if query.User == "" {
+		return query
+	}
+
+	user, err := FindUser(query.User, api, true)
+	if err != nil {
+		logger.Info("user-not-found", lager.Data{"user": query.User, "error": err.Error()})
+		return query
+	}
+
+	query.UserID = user.ID
+	query.Email = user.Profile.Email
+
+	return query
+}
+
+func (a auditQuery) AuditMessage(api slackapi.SlackAPI) string {
+	if a.user() == "" {
+		return fmt.Sprintf("@%s queried the audit log", a.requestingUser)
+	}
+
+	return fmt.Sprintf("@%s queried the audit log for '%s'", a.requestingUser, a.user())
+}
+
+func (a auditQuery) AuditTarget() string {
+	return a.user()
+}
+
+func auditEventLine(event audit.Event) string {
+	line := fmt.Sprintf("`%s` %s", event.Time.UTC().Format(auditTimeFormat), event.Message)
+
+	if event.Outcome == audit.OutcomeFailed {
+		return fmt.Sprintf(":x: %s: %s", line, event.Error)
+	}
+
+	return fmt.Sprintf(":white_check_mark: %s", line)
+}
+
+// parseSince returns the time given as a duration before now, such as 72h or
+// 7d, or as a date. An empty value is the zero time.
+func parseSince(value string, now time.Time) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, true
+	}
+
+	if duration, ok := parseDuration(value); ok {
+		return now.Add(-duration), true
+	}
+
+	if date, err := time.Parse(sinceDateFormat, value); err == nil {
+		return date, true
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,183 @@
+package action_test
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/audit"
+	"github.com/pivotalservices/goulash/audit/auditfakes"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var registeredAuditStore = &auditfakes.FakeStore{}
+
+func init() {
+	action.RegisterAudit(registeredAuditStore)
+}
+
+var _ = Describe("AuditQuery", func() {
+	var (
+		c            config.Config
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeStore    *auditfakes.FakeStore
+		fakeClock    *fakeclock.FakeClock
+		logger       lager.Logger
+		now          time.Time
+	)
+
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeStore = &auditfakes.FakeStore{}
+		now = time.Date(2016, 5, 10, 12, 0, 0, 0, time.UTC)
+		fakeClock = fakeclock.NewFakeClock(now)
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackAuthToken:     "slack-auth-token",
+			SlackSlashCommand:  "/slack-slash-command",
+			SlackTeamName:      "slack-team-name",
+			SlackUserID:        "slack-user-id",
+			AuditLogChannelID:  "audit-log-channel-id",
+			UninvitableDomain:  "uninvitable-domain.com",
+			UninvitableMessage: "uninvitable-domain-message",
+		})
+
+		logger = lager.NewLogger("testlogger")
+	})
+
+	Describe("New", func() {
+		It("creates an audit query of the registered store", func() {
+			a := action.New(
+				slackapi.NewChannel("channel-name", "channel-id"),
+				"commander-name",
+				"commander-id",
+				"audit user@example.com --since 7d",
+			)
+
+			Ω(a).Should(Equal(action.NewAuditQuery([]string{"user@example.com"}, "7d", registeredAuditStore, "commander-name")))
+		})
+
+		It("may only be run by admins by default", func() {
+			fakeSlackAPI.GetUserInfoReturns(&slack.User{}, nil)
+
+			err := action.Authorize("audit", "U1234", c, fakeSlackAPI, logger)
+			Ω(err).Should(Equal(action.NewNotPermittedErr("audit", "/slack-slash-command")))
+		})
+	})
+
+	Describe("Do", func() {
+		It("lists the events for the user since the given time ago", func() {
+			fakeStore.QueryReturns([]audit.Event{
+				{
+					Time:    now.Add(-48 * time.Hour),
+					Message: "@alice invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name' (channel-id)",
+					Outcome: audit.OutcomeSucceeded,
+				},
+				{
+					Time:    now.Add(-time.Hour),
+					Message: "@bob disabled user user@example.com",
+					Outcome: audit.OutcomeFailed,
+					Error:   "user_not_found",
+				},
+			}, nil)
+
+			a := action.NewAuditQuery([]string{"user@example.com"}, "7d", fakeStore, "commander-name")
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.Text).Should(Equal(
+				"Found 2 audit log entries.\n" +
+					":white_check_mark: `2016-05-08 12:00 UTC` @alice invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name' (channel-id)\n" +
+					":x: `2016-05-10 11:00 UTC` @bob disabled user user@example.com: user_not_found",
+			))
+
+			Ω(fakeStore.QueryCallCount()).Should(Equal(1))
+			Ω(fakeStore.QueryArgsForCall(0)).Should(Equal(audit.Query{
+				User:  "user@example.com",
+				Since: now.AddDate(0, 0, -7),
+			}))
+		})
+
+		It("looks the user up to match the events recorded against their ID or email address", func() {
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{
+				{ID: "U1234", Name: "tsmith", Profile: slack.UserProfile{Email: "user@example.com"}},
+			}, "", nil)
+
+			_, err := action.NewAuditQuery([]string{"<@U1234>"}, "", fakeStore, "commander-name").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(fakeStore.QueryArgsForCall(0)).Should(Equal(audit.Query{
+				User:   "<@U1234>",
+				UserID: "U1234",
+				Email:  "user@example.com",
+			}))
+		})
+
+		It("accepts a duration or a date as the time to list events since", func() {
+			_, err := action.NewAuditQuery(nil, "90m", fakeStore, "commander-name").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeStore.QueryArgsForCall(0).Since).Should(Equal(now.Add(-90 * time.Minute)))
+
+			_, err = action.NewAuditQuery(nil, "2016-05-01", fakeStore, "commander-name").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeStore.QueryArgsForCall(1).Since).Should(Equal(time.Date(2016, 5, 1, 0, 0, 0, 0, time.UTC)))
+		})
+
+		It("shows only the most recent events when there are many", func() {
+			var events []audit.Event
+			for i := 0; i < 60; i++ {
+				events = append(events, audit.Event{
+					Time:    now.Add(time.Duration(i) * time.Minute),
+					Message: fmt.Sprintf("event %d", i),
+					Outcome: audit.OutcomeSucceeded,
+				})
+			}
+			fakeStore.QueryReturns(events, nil)
+
+			result, err := action.NewAuditQuery(nil, "", fakeStore, "commander-name").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.Text).Should(HavePrefix("Found 60 audit log entries; showing the most recent 50."))
+			Ω(result.Text).ShouldNot(ContainSubstring("event 9\n"))
+			Ω(result.Text).Should(HaveSuffix("event 59"))
+		})
+
+		It("says when there are no events", func() {
+			result, err := action.NewAuditQuery([]string{"@tsmith"}, "", fakeStore, "commander-name").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.Text).Should(Equal("No audit log entries found."))
+		})
+
+		It("returns a usage error when the time to list events since is not understood", func() {
+			_, err := action.NewAuditQuery(nil, "last-week", fakeStore, "commander-name").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("Expected --since to be a time ago such as `72h` or `7d`, or a date such as `2016-05-01`, but got 'last-week'. Usage: `/slack-slash-command audit [email|@username] [--since time]`"))
+			Ω(fakeStore.QueryCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error when the store cannot be queried", func() {
+			fakeStore.QueryReturns(nil, errors.New("query-failed"))
+
+			result, err := action.NewAuditQuery(nil, "", fakeStore, "commander-name").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("query-failed"))
+			Ω(result.String()).Should(Equal("Failed to query the audit log: query-failed"))
+		})
+	})
+
+	Describe("AuditMessage", func() {
+		It("describes the query", func() {
+			a := action.NewAuditQuery([]string{"user@example.com"}, "", fakeStore, "commander-name")
+
+			aa, ok := a.(action.TargetedAction)
+			Ω(ok).Should(BeTrue())
+			Ω(aa.AuditMessage(fakeSlackAPI)).Should(Equal("@commander-name queried the audit log for 'user@example.com'"))
+			Ω(aa.AuditTarget()).Should(Equal("user@example.com"))
+		})
+	})
+})
@@ -0,0 +1,95 @@
+package action
+
+import (
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+)
+
+const helpCommand = "help"
+
+// Authorize returns an error if the commander may not run the command given
+// in text. The configured Policy's rule for the command applies, falling back
+// to the Command's own Permission and then to the Policy's default rule. The
+// help command, and text which names no registered Command, may always be run.
+func Authorize(
+	text string,
+	commanderID string,
+	c config.Config,
+	api slackapi.SlackAPI,
+	logger lager.Logger,
+) error {
+	logger = logger.Session("authorize")
+
+	command, ok := lookUpCommand(commandName(text))
+	if !ok {
+		logger.Info("passed")
+		return nil
+	}
+
+	rule, ok := c.Policy()[command.Name]
+	if !ok && command.Permission != nil {
+		rule, ok = *command.Permission, true
+	}
+	if !ok {
+		rule, ok = c.Policy().Rule(command.Name)
+	}
+	if !ok {
+		logger.Info("passed")
+		return nil
+	}
+
+	permitted, err := permits(rule, commanderID, api)
+	if err != nil {
+		logger.Error("failed", err)
+		return err
+	}
+
+	if !permitted {
+		err = NewNotPermittedErr(command.Name, c.SlackSlashCommand())
+		logger.Error("failed", err, lager.Data{
+			"command":     command.Name,
+			"commanderID": commanderID,
+		})
+		return err
+	}
+
+	logger.Info("passed")
+
+	return nil
+}
+
+func permits(
+	rule config.PolicyRule,
+	commanderID string,
+	api slackapi.SlackAPI,
+) (bool, error) {
+	if matches(commanderID, rule.UserIDs...) {
+		return true, nil
+	}
+
+	if rule.Admins || rule.Owners {
+		user, err := api.GetUserInfo(commanderID)
+		if err != nil {
+			return false, err
+		}
+
+		owner := user.IsOwner || user.IsPrimaryOwner
+		if (rule.Owners && owner) || (rule.Admins && (owner || user.IsAdmin)) {
+			return true, nil
+		}
+	}
+
+	for _, userGroupID := range rule.UserGroupIDs {
+		members, err := api.GetUserGroupMembers(userGroupID)
+		if err != nil {
+			return false, err
+		}
+
+		if matches(commanderID, members...) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
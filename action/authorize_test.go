@@ -0,0 +1,201 @@
+package action_test
+
+import (
+	"errors"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Authorize", func() {
+	var (
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		logger       lager.Logger
+	)
+
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		logger = lager.NewLogger("testlogger")
+	})
+
+	configWithPolicy := func(policy config.Policy) config.Config {
+		return config.NewLocalConfig(config.LocalConfigOptions{
+			SlackAuthToken:     "slack-auth-token",
+			SlackSlashCommand:  "/slack-slash-command",
+			SlackTeamName:      "slack-team-name",
+			SlackUserID:        "slack-user-id",
+			AuditLogChannelID:  "audit-log-channel-id",
+			UninvitableDomain:  "uninvitable-domain.com",
+			UninvitableMessage: "uninvitable-domain-message",
+			Policy:             policy,
+		})
+	}
+
+	It("permits anyone when there is no policy", func() {
+		err := action.Authorize("invite-guest user@example.com", "U1234", configWithPolicy(nil), fakeSlackAPI, logger)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(fakeSlackAPI.GetUserInfoCallCount()).Should(Equal(0))
+	})
+
+	It("permits only admins to change existing accounts when there is no policy", func() {
+		fakeSlackAPI.GetUserInfoReturns(&slack.User{ID: "U1234"}, nil)
+
+		for _, command := range []string{"disable-user", "enable-user", "guestify", "restrictify", "move-guest", "add-to-channel"} {
+			err := action.Authorize(command+" @tsmith", "U1234", configWithPolicy(nil), fakeSlackAPI, logger)
+			Ω(err).Should(Equal(action.NewNotPermittedErr(command, "/slack-slash-command")))
+		}
+
+		fakeSlackAPI.GetUserInfoReturns(&slack.User{ID: "U1234", IsAdmin: true}, nil)
+
+		err := action.Authorize("disable-user @tsmith", "U1234", configWithPolicy(nil), fakeSlackAPI, logger)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("permits anyone to run a command without a rule when there is no default rule", func() {
+		c := configWithPolicy(config.Policy{"disable-user": config.PolicyRule{}})
+
+		err := action.Authorize("info user@example.com", "U1234", c, fakeSlackAPI, logger)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("always permits help", func() {
+		c := configWithPolicy(config.Policy{"*": config.PolicyRule{}})
+
+		err := action.Authorize("help", "U1234", c, fakeSlackAPI, logger)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("applies the rule of a quoted command", func() {
+		c := configWithPolicy(config.Policy{"disable-user": config.PolicyRule{}})
+
+		err := action.Authorize(`"disable-user" @tsmith`, "U1234", c, fakeSlackAPI, logger)
+		Ω(err).Should(Equal(action.NewNotPermittedErr("disable-user", "/slack-slash-command")))
+	})
+
+	It("permits users listed in the rule", func() {
+		c := configWithPolicy(config.Policy{"disable-user": config.PolicyRule{
+			UserIDs: []string{"U9999", "U1234"},
+		}})
+
+		err := action.Authorize("disable-user @tsmith", "U1234", c, fakeSlackAPI, logger)
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("returns an error for users not matching the rule", func() {
+		c := configWithPolicy(config.Policy{"disable-user": config.PolicyRule{
+			UserIDs: []string{"U9999"},
+		}})
+
+		err := action.Authorize("disable-user @tsmith", "U1234", c, fakeSlackAPI, logger)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(Equal("You are not permitted to use `/slack-slash-command disable-user`."))
+	})
+
+	It("applies the default rule to commands without a rule", func() {
+		c := configWithPolicy(config.Policy{"*": config.PolicyRule{
+			UserIDs: []string{"U9999"},
+		}})
+
+		err := action.Authorize("invite-guest user@example.com", "U1234", c, fakeSlackAPI, logger)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(Equal("You are not permitted to use `/slack-slash-command invite-guest`."))
+	})
+
+	Describe("when the rule permits admins", func() {
+		var c config.Config
+
+		BeforeEach(func() {
+			c = configWithPolicy(config.Policy{"disable-user": config.PolicyRule{
+				Admins: true,
+			}})
+		})
+
+		It("permits admins", func() {
+			fakeSlackAPI.GetUserInfoReturns(&slack.User{ID: "U1234", IsAdmin: true}, nil)
+
+			err := action.Authorize("disable-user @tsmith", "U1234", c, fakeSlackAPI, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(fakeSlackAPI.GetUserInfoCallCount()).Should(Equal(1))
+			Ω(fakeSlackAPI.GetUserInfoArgsForCall(0)).Should(Equal("U1234"))
+		})
+
+		It("permits owners", func() {
+			fakeSlackAPI.GetUserInfoReturns(&slack.User{ID: "U1234", IsOwner: true}, nil)
+
+			err := action.Authorize("disable-user @tsmith", "U1234", c, fakeSlackAPI, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("returns an error for full members", func() {
+			fakeSlackAPI.GetUserInfoReturns(&slack.User{ID: "U1234"}, nil)
+
+			err := action.Authorize("disable-user @tsmith", "U1234", c, fakeSlackAPI, logger)
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("returns an error when the user cannot be looked up", func() {
+			fakeSlackAPI.GetUserInfoReturns(nil, errors.New("network error"))
+
+			err := action.Authorize("disable-user @tsmith", "U1234", c, fakeSlackAPI, logger)
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(Equal("network error"))
+		})
+	})
+
+	Describe("when the rule permits owners", func() {
+		It("returns an error for admins", func() {
+			c := configWithPolicy(config.Policy{"disable-user": config.PolicyRule{
+				Owners: true,
+			}})
+			fakeSlackAPI.GetUserInfoReturns(&slack.User{ID: "U1234", IsAdmin: true}, nil)
+
+			err := action.Authorize("disable-user @tsmith", "U1234", c, fakeSlackAPI, logger)
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("when the rule permits user groups", func() {
+		var c config.Config
+
+		BeforeEach(func() {
+			c = configWithPolicy(config.Policy{"disable-user": config.PolicyRule{
+				UserGroupIDs: []string{"S1111", "S2222"},
+			}})
+		})
+
+		It("permits members of any of the user groups", func() {
+			fakeSlackAPI.GetUserGroupMembersStub = func(userGroupID string) ([]string, error) {
+				if userGroupID == "S2222" {
+					return []string{"U1234"}, nil
+				}
+				return []string{"U9999"}, nil
+			}
+
+			err := action.Authorize("disable-user @tsmith", "U1234", c, fakeSlackAPI, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeSlackAPI.GetUserGroupMembersCallCount()).Should(Equal(2))
+		})
+
+		It("returns an error for users in none of the user groups", func() {
+			fakeSlackAPI.GetUserGroupMembersReturns([]string{"U9999"}, nil)
+
+			err := action.Authorize("disable-user @tsmith", "U1234", c, fakeSlackAPI, logger)
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("returns an error when the user group cannot be looked up", func() {
+			fakeSlackAPI.GetUserGroupMembersReturns(nil, errors.New("missing_scope"))
+
+			err := action.Authorize("disable-user @tsmith", "U1234", c, fakeSlackAPI, logger)
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(Equal("missing_scope"))
+		})
+	})
+})
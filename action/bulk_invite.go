@@ -0,0 +1,243 @@
+package action
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+)
+
+var bulkInviteCommands = map[string]string{
+	"guest":      "invite-guest",
+	"restricted": "invite-restricted",
+}
+
+const bulkInviteCommand = "invite-bulk"
+
+func init() {
+	Register(Command{
+		Name:        bulkInviteCommand,
+		Params:      []Param{{Name: "guest|restricted"}},
+		Lines:       true,
+		Mutating:    true,
+		Description: "Invite several people to the current channel/group, given one `email,firstname,lastname` line each after the command",
+		New: func(r Request) Action {
+			return NewBulkInvite(r.Params[0], r.Lines, r.Channel, r.CommanderName)
+		},
+	})
+}
+
+type bulkInvite struct {
+	inviteeType  string
+	lines        []string
+	channel      slackapi.Channel
+	invitingUser string
+
+	outcomes []bulkInviteOutcome
+}
+
+type bulkInviteOutcome struct {
+	line    string
+	invite  invite
+	invalid bool
+	err     error
+}
+
+// NewBulkInvite returns a new bulk invite action, used to invite each person
+// described by a line of the form email,firstname,lastname as the given type
+// of user.
+func NewBulkInvite(
+	inviteeType string,
+	lines []string,
+	channel slackapi.Channel,
+	invitingUser string,
+) Action {
+	var inviteeLines []string
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			inviteeLines = append(inviteeLines, line)
+		}
+	}
+
+	return &bulkInvite{
+		inviteeType:  inviteeType,
+		lines:        inviteeLines,
+		channel:      channel,
+		invitingUser: invitingUser,
+	}
+}
+
+func (b *bulkInvite) Do(
+	config config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) (slackapi.Message, error) {
+	logger = logger.Session("do")
+
+	b.outcomes = nil
+
+	command, ok := bulkInviteCommands[b.inviteeType]
+
+	var problem string
+	switch {
+	case !ok:
+		problem = fmt.Sprintf(unknownInviteeTypeProblemFmt, b.inviteeType)
+	case len(b.lines) == 0:
+		problem = noInviteesProblem
+	}
+
+	if problem != "" {
+		err := NewUsageErr(problem, bulkInviteUsage(), config.SlackSlashCommand())
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(err.Error()), err
+	}
+
+	var failed int
+	for _, line := range b.lines {
+		outcome := b.invitee(line, command)
+		if outcome.err == nil {
+			outcome.err = outcome.invite.check(config, api, logger)
+		}
+
+		if outcome.err == nil {
+			outcome.err = outcome.invite.invite(config, api)
+		}
+
+		if outcome.err != nil {
+			failed++
+			logger.Error("failed-to-invite", outcome.err, lager.Data{"line": line})
+		}
+
+		b.outcomes = append(b.outcomes, outcome)
+	}
+
+	message := b.summary(api, failed)
+
+	if failed > 0 {
+		err := NewBulkInviteFailedErr(failed, len(b.outcomes))
+		logger.Error("failed", err)
+		return message, err
+	}
+
+	logger.Info("succeeded")
+
+	return message, nil
+}
+
+func (b *bulkInvite) AuditMessage(api slackapi.SlackAPI) string {
+	return fmt.Sprintf(
+		"@%s invited %d people as %ss to '%s' (%s)",
+		b.invitingUser,
+		len(b.lines),
+		b.inviteeTypeName(),
+		b.channel.Name(api),
+		b.channel.ID(),
+	)
+}
+
+// AuditEntries returns an entry for each person the last Do attempted to
+// invite.
+func (b *bulkInvite) AuditEntries(api slackapi.SlackAPI) []AuditEntry {
+	var entries []AuditEntry
+	for _, outcome := range b.outcomes {
+		if outcome.invalid {
+			continue
+		}
+
+		entries = append(entries, AuditEntry{
+			Message: outcome.invite.AuditMessage(api),
+			Command: outcome.invite.command,
+			Target:  outcome.invite.AuditTarget(),
+			Err:     outcome.err,
+		})
+	}
+
+	return entries
+}
+
+func (b *bulkInvite) invitee(line string, command string) bulkInviteOutcome {
+	reader := csv.NewReader(strings.NewReader(line))
+	reader.TrimLeadingSpace = true
+
+	fields, err := reader.Read()
+	if err != nil || len(fields) != 3 {
+		return bulkInviteOutcome{
+			line:    line,
+			invalid: true,
+			err:     NewMalformedInviteeErr(line),
+		}
+	}
+
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+
+	return bulkInviteOutcome{
+		line: line,
+		invite: invite{
+			params:       fields,
+			command:      command,
+			channel:      b.channel,
+			invitingUser: b.invitingUser,
+		},
+	}
+}
+
+func (b *bulkInvite) summary(api slackapi.SlackAPI, failed int) slackapi.Message {
+	summary := fmt.Sprintf(
+		"Invited %d of %d people as %ss to '%s'.",
+		len(b.outcomes)-failed,
+		len(b.outcomes),
+		b.inviteeTypeName(),
+		b.channel.Name(api),
+	)
+
+	var lines []string
+	for _, outcome := range b.outcomes {
+		lines = append(lines, outcome.String())
+	}
+
+	message := slackapi.Message{
+		Text:   strings.Join(append([]string{summary}, lines...), "\n"),
+		Blocks: []slackapi.Block{slackapi.NewSectionBlock(summary)},
+	}
+	message.Blocks = append(message.Blocks, slackapi.NewSectionBlocks(lines)...)
+
+	return message.Fit()
+}
+
+func bulkInviteUsage() string {
+	command, _ := lookUpCommand(bulkInviteCommand)
+	return command.usage()
+}
+
+func (b *bulkInvite) inviteeTypeName() string {
+	return invite{command: bulkInviteCommands[b.inviteeType]}.inviteeType()
+}
+
+func (o bulkInviteOutcome) String() string {
+	switch {
+	case o.invalid:
+		return fmt.Sprintf(":x: %s", o.err.Error())
+	case o.err != nil:
+		return fmt.Sprintf(
+			":x: Failed to invite %s %s (%s): %s",
+			o.invite.firstName(),
+			o.invite.lastName(),
+			o.invite.emailAddress(),
+			NewSlackErr(o.err).Error(),
+		)
+	default:
+		return fmt.Sprintf(
+			":white_check_mark: Invited %s %s (%s)",
+			o.invite.firstName(),
+			o.invite.lastName(),
+			o.invite.emailAddress(),
+		)
+	}
+}
@@ -0,0 +1,178 @@
+package action_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BulkInvite", func() {
+	var (
+		c            config.Config
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeClock    *fakeclock.FakeClock
+		logger       lager.Logger
+	)
+
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackAuthToken:     "slack-auth-token",
+			SlackSlashCommand:  "/slack-slash-command",
+			SlackTeamName:      "slack-team-name",
+			SlackUserID:        "slack-user-id",
+			AuditLogChannelID:  "audit-log-channel-id",
+			UninvitableDomain:  "uninvitable-domain.com",
+			UninvitableMessage: "uninvitable-domain-message",
+		})
+		fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{ID: "channel-id", IsMember: true}, nil)
+
+		logger = lager.NewLogger("testlogger")
+	})
+
+	newBulkInvite := func(text string) action.Action {
+		return action.New(
+			slackapi.NewChannel("channel-name", "channel-id"),
+			"commander-name",
+			"commander-id",
+			text,
+		)
+	}
+
+	Describe("Do", func() {
+		It("invites each person as a guest", func() {
+			a := newBulkInvite("invite-bulk guest\nuser1@example.com,Tom,Smith\n\n\"user2@example.com\", Jane , Doe\n")
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.Text).Should(Equal(
+				"Invited 2 of 2 people as single-channel guests to 'channel-name'.\n" +
+					":white_check_mark: Invited Tom Smith (user1@example.com)\n" +
+					":white_check_mark: Invited Jane Doe (user2@example.com)",
+			))
+
+			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(2))
+
+			teamName, channelID, firstName, lastName, emailAddress := fakeSlackAPI.InviteGuestArgsForCall(1)
+			Ω(teamName).Should(Equal("slack-team-name"))
+			Ω(channelID).Should(Equal("channel-id"))
+			Ω(firstName).Should(Equal("Jane"))
+			Ω(lastName).Should(Equal("Doe"))
+			Ω(emailAddress).Should(Equal("user2@example.com"))
+		})
+
+		It("invites restricted accounts", func() {
+			a := newBulkInvite("invite-bulk restricted\nuser1@example.com,Tom,Smith")
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
+			Ω(fakeSlackAPI.InviteRestrictedCallCount()).Should(Equal(1))
+		})
+
+		It("reports the people who could not be invited and carries on", func() {
+			fakeSlackAPI.InviteGuestReturns(errors.New("failed to invite user"))
+
+			a := newBulkInvite("invite-bulk guest\nuser1@uninvitable-domain.com,Tom,Smith\nuser2@example.com,Jane\nuser3@example.com,Sam,Jones")
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(Equal(action.NewBulkInviteFailedErr(3, 3)))
+			Ω(result.Text).Should(Equal(
+				"Invited 0 of 3 people as single-channel guests to 'channel-name'.\n" +
+					":x: Failed to invite Tom Smith (user1@uninvitable-domain.com): " + action.NewUninvitableDomainErr("uninvitable-domain.com", "uninvitable-domain-message", "/slack-slash-command").Error() + "\n" +
+					":x: Expected `email,firstname,lastname` but got `user2@example.com,Jane`.\n" +
+					":x: Failed to invite Sam Jones (user3@example.com): failed to invite user",
+			))
+
+			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(1))
+		})
+
+		It("treats people who have already been invited as invited", func() {
+			fakeSlackAPI.InviteGuestReturns(slackapi.Error{Code: slackapi.ErrorAlreadyInvited})
+
+			a := newBulkInvite("invite-bulk guest\nuser1@example.com,Tom,Smith")
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("returns a usage error when the type of user is not given", func() {
+			expectedErr := action.NewUsageErr("Missing required guest|restricted parameter.", "invite-bulk [guest|restricted]", "/slack-slash-command")
+
+			a := newBulkInvite("invite-bulk\nuser1@example.com,Tom,Smith")
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(Equal(expectedErr))
+			Ω(result.String()).Should(Equal(expectedErr.Error()))
+		})
+
+		It("returns a usage error when the type of user is unknown", func() {
+			a := newBulkInvite("invite-bulk admin\nuser1@example.com,Tom,Smith")
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("Unknown type of user 'admin'; expected 'guest' or 'restricted'. Usage: `/slack-slash-command invite-bulk [guest|restricted]`"))
+			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
+		})
+
+		It("returns a usage error when no one is given", func() {
+			a := newBulkInvite("invite-bulk guest")
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("No one to invite. Give one `email,firstname,lastname` line per person after the command. Usage: `/slack-slash-command invite-bulk [guest|restricted]`"))
+		})
+	})
+
+	Describe("AuditEntries", func() {
+		It("returns an entry for each person", func() {
+			fakeSlackAPI.InviteGuestStub = func(_, _, _, _, emailAddress string) error {
+				if emailAddress == "user2@example.com" {
+					return errors.New("failed to invite user")
+				}
+				return nil
+			}
+
+			a := newBulkInvite("invite-bulk guest\nuser1@example.com,Tom,Smith\nnot-an-invitee\nuser2@example.com,Jane,Doe")
+
+			a.Do(c, fakeSlackAPI, fakeClock, logger)
+
+			maa, ok := a.(action.MultiAuditableAction)
+			Ω(ok).Should(BeTrue())
+
+			Ω(maa.AuditEntries(fakeSlackAPI)).Should(Equal([]action.AuditEntry{
+				{
+					Message: "@commander-name invited Tom Smith (user1@example.com) as a single-channel guest to 'channel-name' (channel-id)",
+					Command: "invite-guest",
+					Target:  "user1@example.com",
+				},
+				{
+					Message: "@commander-name invited Jane Doe (user2@example.com) as a single-channel guest to 'channel-name' (channel-id)",
+					Command: "invite-guest",
+					Target:  "user2@example.com",
+					Err:     errors.New("failed to invite user"),
+				},
+			}))
+		})
+	})
+
+	Describe("AuditMessage", func() {
+		It("describes the whole invitation", func() {
+			a := newBulkInvite("invite-bulk restricted\nuser1@example.com,Tom,Smith\nuser2@example.com,Jane,Doe")
+
+			aa, ok := a.(action.AuditableAction)
+			Ω(ok).Should(BeTrue())
+
+			Ω(aa.AuditMessage(fakeSlackAPI)).Should(Equal("@commander-name invited 2 people as restricted accounts to 'channel-name' (channel-id)"))
+		})
+	})
+})
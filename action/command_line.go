@@ -0,0 +1,246 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+)
+
+var errUnterminatedQuote = errors.New(unterminatedQuoteProblem)
+
+// usage returns the command followed by its parameters and options, as shown
+// to the user.
+func (c Command) usage() string {
+	words := []string{c.Name}
+	for _, p := range c.Params {
+		words = append(words, fmt.Sprintf("[%s]", p.Name))
+	}
+	for _, option := range c.Options {
+		if option.Value == "" {
+			words = append(words, fmt.Sprintf("[--%s]", option.Name))
+		} else {
+			words = append(words, fmt.Sprintf("[--%s %s]", option.Name, option.Value))
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// check returns a description of the first way in which the given arguments
+// and options do not match those the command accepts, or an empty string if
+// they match.
+func (c Command) check(args []string, options map[string]string) string {
+	for i, p := range c.Params {
+		if i >= len(args) && !p.Optional {
+			return fmt.Sprintf(missingParameterProblemFmt, p.Name)
+		}
+	}
+
+	if len(args) > len(c.Params) {
+		return fmt.Sprintf(unexpectedParameterProblemFmt, args[len(c.Params)])
+	}
+
+	for name, value := range options {
+		option, ok := c.option(name)
+		if !ok {
+			return fmt.Sprintf(unknownOptionProblemFmt, name)
+		}
+
+		if option.Value != "" && value == "" {
+			return fmt.Sprintf(missingOptionValueProblemFmt, option.Value, name)
+		}
+	}
+
+	return ""
+}
+
+// options returns the options the command accepts, including --dry-run for
+// those which change Slack, which is left out of their usage as every such
+// command takes it.
+func (c Command) options() []Option {
+	if !c.Mutating {
+		return c.Options
+	}
+
+	return append(append([]Option(nil), c.Options...), Option{Name: dryRunOption})
+}
+
+func (c Command) option(name string) (Option, bool) {
+	for _, option := range c.options() {
+		if option.Name == name {
+			return option, true
+		}
+	}
+
+	return Option{}, false
+}
+
+// commandName returns the first word of the first line of text, which names
+// the command, or an empty string if there is none.
+func commandName(text string) string {
+	words, _ := tokenize(strings.SplitN(text, "\n", 2)[0])
+	if len(words) == 0 {
+		return ""
+	}
+
+	return words[0]
+}
+
+// CommandName returns the name of the registered Command named, or aliased,
+// by the first word of text, or an empty string if there is none.
+func CommandName(text string) string {
+	command, ok := lookUpCommand(commandName(text))
+	if !ok {
+		return ""
+	}
+
+	return command.Name
+}
+
+// parseCommandLine splits text into words, separating the positional arguments
+// from the options given as --name=value or --name. An option of the command
+// which takes a value may also be given as --name value. Options may not
+// follow a bare -- word.
+func (c Command) parseCommandLine(text string) ([]string, map[string]string, error) {
+	words, err := tokenize(text)
+	if err != nil {
+		return words, nil, err
+	}
+
+	var args []string
+	options := map[string]string{}
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if word == "--" {
+			args = append(args, words[i+1:]...)
+			break
+		}
+
+		if !strings.HasPrefix(word, "--") {
+			args = append(args, word)
+			continue
+		}
+
+		name := strings.TrimPrefix(word, "--")
+		if equals := strings.Index(name, "="); equals >= 0 {
+			options[name[:equals]] = name[equals+1:]
+			continue
+		}
+
+		option, ok := c.option(name)
+		if !ok || option.Value == "" {
+			options[name] = "true"
+			continue
+		}
+
+		options[name] = ""
+		if i+1 < len(words) && !strings.HasPrefix(words[i+1], "--") {
+			i++
+			options[name] = words[i]
+		}
+	}
+
+	return args, options, nil
+}
+
+// tokenize splits text into words separated by whitespace. A word beginning
+// with a single or double quote continues until the matching quote, so may
+// contain whitespace; within double quotes, and outside quotes, a backslash
+// includes the following character literally. The curly double quotes some
+// keyboards substitute are treated as straight ones. Quotes within a word,
+// such as the apostrophe in O'Brien, are kept as they are.
+func tokenize(text string) ([]string, error) {
+	var words []string
+	var word []rune
+	inWord := false
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, string(word))
+				word, inWord = nil, false
+			}
+
+		case r == '\\' && i+1 < len(runes):
+			i++
+			word, inWord = append(word, runes[i]), true
+
+		case !inWord && isQuote(r):
+			closing := closingQuote(r)
+			inWord = true
+
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return words, errUnterminatedQuote
+				}
+
+				if runes[i] == closing || (closing == '"' && runes[i] == '”') {
+					break
+				}
+
+				if runes[i] == '\\' && closing != '\'' && i+1 < len(runes) {
+					i++
+				}
+
+				word = append(word, runes[i])
+			}
+
+		default:
+			word, inWord = append(word, r), true
+		}
+	}
+
+	if inWord {
+		words = append(words, string(word))
+	}
+
+	return words, nil
+}
+
+// parseDuration parses a duration such as 7d, 72h or 90m, returning false if
+// it is not a duration or is negative.
+func parseDuration(value string) (time.Duration, bool) {
+	return config.ParseDuration(value)
+}
+
+func isQuote(r rune) bool {
+	return r == '"' || r == '\'' || r == '“'
+}
+
+func closingQuote(r rune) rune {
+	if r == '\'' {
+		return '\''
+	}
+
+	return '"'
+}
+
+// invalidUsage is the Action for a command given arguments which do not match
+// those it accepts. It does nothing but explain how the command should be used.
+type invalidUsage struct {
+	problem string
+	usage   string
+}
+
+func (u invalidUsage) Do(
+	config config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) (slackapi.Message, error) {
+	err := NewUsageErr(u.problem, u.usage, config.SlackSlashCommand())
+	logger.Session("do").Error("invalid-usage", err)
+
+	return slackapi.NewErrorMessage(err.Error()), err
+}
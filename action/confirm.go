@@ -0,0 +1,393 @@
+package action
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+)
+
+const (
+	confirmCommand = "confirm"
+	cancelCommand  = "cancel"
+
+	// confirmationTimeout is how long a destructive command waits to be
+	// confirmed before it is forgotten.
+	confirmationTimeout = 5 * time.Minute
+)
+
+func init() {
+	Register(Command{
+		Name:        confirmCommand,
+		Params:      []Param{{Name: "token"}},
+		Description: "Carry out a command which is awaiting your confirmation",
+		New: func(r Request) Action {
+			return NewConfirm(r.Params, r.CommanderName, r.CommanderID)
+		},
+	})
+
+	Register(Command{
+		Name:        cancelCommand,
+		Params:      []Param{{Name: "token"}},
+		Description: "Cancel a command which is awaiting your confirmation",
+		New: func(r Request) Action {
+			return NewCancel(r.Params, r.CommanderID)
+		},
+	})
+}
+
+// pendingCommand is a destructive command awaiting confirmation by whoever
+// ran it, with the ID of the user it was shown to act on.
+type pendingCommand struct {
+	text          string
+	userID        string
+	channel       slackapi.Channel
+	commanderName string
+	commanderID   string
+	expiresAt     time.Time
+}
+
+// pendingCommands holds the commands awaiting confirmation by their tokens.
+// They are kept only in memory, so each instance of Goulash has its own, and
+// they are lost when it restarts: a command must be confirmed through the
+// same instance it was run on.
+var pendingCommands = struct {
+	sync.Mutex
+	commands map[string]pendingCommand
+}{
+	commands: map[string]pendingCommand{},
+}
+
+// holdCommand keeps the command until it is taken or expires, returning the
+// token with which to take it. Expired commands are forgotten.
+func holdCommand(command pendingCommand, now time.Time) (string, error) {
+	bytes := make([]byte, 4)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(bytes)
+
+	pendingCommands.Lock()
+	defer pendingCommands.Unlock()
+
+	for t, c := range pendingCommands.commands {
+		if !now.Before(c.expiresAt) {
+			delete(pendingCommands.commands, t)
+		}
+	}
+
+	pendingCommands.commands[token] = command
+
+	return token, nil
+}
+
+// takeCommand returns and forgets the command held with the given token, as
+// long as it has not expired and was run by the given commander.
+func takeCommand(token string, commanderID string, now time.Time) (pendingCommand, error) {
+	pendingCommands.Lock()
+	defer pendingCommands.Unlock()
+
+	command, ok := pendingCommands.commands[token]
+	if !ok || command.commanderID != commanderID {
+		return pendingCommand{}, NewConfirmationNotFoundErr(token)
+	}
+
+	delete(pendingCommands.commands, token)
+
+	if !now.Before(command.expiresAt) {
+		return pendingCommand{}, NewConfirmationNotFoundErr(token)
+	}
+
+	return command, nil
+}
+
+type confirmation struct {
+	action  Action
+	request Request
+	text    string
+
+	err error
+}
+
+// NewConfirmation returns an Action which, rather than performing the given
+// one, checks that it could be performed and asks the commander to confirm
+// it. The Action must have been created as a dry run from the request, whose
+// first parameter names the user it acts on, and text is the command as
+// given.
+func NewConfirmation(a Action, request Request, text string) Action {
+	return &confirmation{
+		action:  a,
+		request: request,
+		text:    text,
+	}
+}
+
+func (c *confirmation) Do(
+	config config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) (slackapi.Message, error) {
+	logger = logger.Session("confirmation")
+
+	dryRunAPI := slackapi.NewDryRun(api)
+
+	var result slackapi.Message
+	result, c.err = c.action.Do(config, dryRunAPI, clock, logger)
+	if c.err != nil {
+		return result, c.err
+	}
+
+	user, err := FindUser(c.request.Params[0], api, true)
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(err.Error()), err
+	}
+
+	token, err := holdCommand(pendingCommand{
+		text:          c.text,
+		userID:        user.ID,
+		channel:       c.request.Channel,
+		commanderName: c.request.CommanderName,
+		commanderID:   c.request.CommanderID,
+		expiresAt:     clock.Now().Add(confirmationTimeout),
+	}, clock.Now())
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(fmt.Sprintf("Failed to ask for confirmation: %s", err.Error())), err
+	}
+
+	logger.Info("succeeded", lager.Data{"token": token})
+
+	lines := []string{fmt.Sprintf("Are you sure? `%s` will:", c.request.Command)}
+	for _, change := range dryRunAPI.Changes() {
+		lines = append(lines, "• "+change)
+	}
+	summary := strings.Join(lines, "\n")
+
+	instructions := fmt.Sprintf(
+		"Confirm within %d minutes, or type `%s %s %s`.",
+		int(confirmationTimeout.Minutes()),
+		config.SlackSlashCommand(), confirmCommand, token,
+	)
+
+	return slackapi.Message{
+		Text: strings.Join([]string{summary, userInfoText(user), instructions}, "\n"),
+		Blocks: []slackapi.Block{
+			slackapi.NewSectionBlock(summary),
+			userInfoBlock(user),
+			slackapi.NewActionsBlock(
+				"confirmation-"+token,
+				slackapi.NewButton("Confirm", confirmCommand, token, slackapi.ButtonStyleDanger),
+				slackapi.NewButton("Cancel", cancelCommand, token, ""),
+			),
+			slackapi.NewContextBlock(instructions),
+		},
+	}, nil
+}
+
+func (c *confirmation) AuditMessage(api slackapi.SlackAPI) string {
+	if auditableAction, ok := c.action.(AuditableAction); ok {
+		return auditableAction.AuditMessage(api)
+	}
+
+	return fmt.Sprintf("@%s ran %s", c.request.CommanderName, c.request.Command)
+}
+
+func (c *confirmation) AuditTarget() string {
+	if targetedAction, ok := c.action.(TargetedAction); ok {
+		return targetedAction.AuditTarget()
+	}
+
+	return ""
+}
+
+// AuditEntries returns nothing when the commander has been asked to confirm,
+// as nothing has been done yet, but records the command failing its checks.
+func (c *confirmation) AuditEntries(api slackapi.SlackAPI) []AuditEntry {
+	if c.err == nil {
+		return nil
+	}
+
+	return auditEntries(c.action, c.request.Command, c.err, api)
+}
+
+// userPinningAction is an Action for a destructive command which can be made
+// to act only on the user shown when the command was confirmed, refusing if
+// its parameter now names someone else.
+type userPinningAction interface {
+	Action
+	pinUser(userID string)
+}
+
+type confirm struct {
+	params        []string
+	commanderName string
+	commanderID   string
+
+	action  Action
+	command string
+	err     error
+}
+
+// NewConfirm returns a new confirm action, which performs the command awaiting
+// confirmation by the commander with the token given as its first parameter,
+// on the user it was shown to act on.
+func NewConfirm(
+	params []string,
+	commanderName string,
+	commanderID string,
+) Action {
+	confirmParams := []string{""}
+	copy(confirmParams, params)
+
+	return &confirm{
+		params:        confirmParams,
+		commanderName: commanderName,
+		commanderID:   commanderID,
+	}
+}
+
+func (c *confirm) token() string {
+	return c.params[0]
+}
+
+func (c *confirm) Do(
+	config config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) (slackapi.Message, error) {
+	logger = logger.Session("do")
+
+	command, err := takeCommand(c.token(), c.commanderID, clock.Now())
+	if err != nil {
+		c.err = err
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(err.Error()), err
+	}
+
+	c.command = CommandName(command.text)
+
+	// The commander may no longer be permitted to run the command they are
+	// confirming.
+	if err = Authorize(command.text, command.commanderID, config, api, logger); err != nil {
+		c.err = err
+		return slackapi.NewErrorMessage(err.Error()), err
+	}
+
+	c.action = NewConfirmed(command.channel, command.commanderName, command.commanderID, command.text)
+	if pinning, ok := c.action.(userPinningAction); ok {
+		pinning.pinUser(command.userID)
+	}
+
+	var result slackapi.Message
+	result, c.err = c.action.Do(config, api, clock, logger)
+
+	return result, c.err
+}
+
+func (c *confirm) AuditMessage(api slackapi.SlackAPI) string {
+	if auditableAction, ok := c.action.(AuditableAction); ok {
+		return auditableAction.AuditMessage(api)
+	}
+
+	return fmt.Sprintf("@%s confirmed '%s'", c.commanderName, c.token())
+}
+
+func (c *confirm) AuditTarget() string {
+	if targetedAction, ok := c.action.(TargetedAction); ok {
+		return targetedAction.AuditTarget()
+	}
+
+	return ""
+}
+
+// AuditEntries returns the audit entries of the confirmed command, as if it
+// had been run without needing confirmation.
+func (c *confirm) AuditEntries(api slackapi.SlackAPI) []AuditEntry {
+	if c.action == nil {
+		command := c.command
+		if command == "" {
+			command = confirmCommand
+		}
+
+		return []AuditEntry{{
+			Message: c.AuditMessage(api),
+			Command: command,
+			Err:     c.err,
+		}}
+	}
+
+	return auditEntries(c.action, c.command, c.err, api)
+}
+
+type cancel struct {
+	params      []string
+	commanderID string
+}
+
+// NewCancel returns a new cancel action, which forgets the command awaiting
+// confirmation by the commander with the token given as its first parameter.
+func NewCancel(
+	params []string,
+	commanderID string,
+) Action {
+	cancelParams := []string{""}
+	copy(cancelParams, params)
+
+	return &cancel{
+		params:      cancelParams,
+		commanderID: commanderID,
+	}
+}
+
+func (c *cancel) Do(
+	config config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) (slackapi.Message, error) {
+	logger = logger.Session("do")
+
+	command, err := takeCommand(c.params[0], c.commanderID, clock.Now())
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(err.Error()), err
+	}
+
+	logger.Info("succeeded")
+
+	return slackapi.NewTextMessage(fmt.Sprintf("Cancelled `%s`. Nothing was changed.", command.text)), nil
+}
+
+// auditEntries returns the audit entries of the given Action for the named
+// command, which failed with err unless it is nil.
+func auditEntries(a Action, command string, err error, api slackapi.SlackAPI) []AuditEntry {
+	if multiAuditableAction, ok := a.(MultiAuditableAction); ok {
+		return multiAuditableAction.AuditEntries(api)
+	}
+
+	auditableAction, ok := a.(AuditableAction)
+	if !ok {
+		return nil
+	}
+
+	entry := AuditEntry{
+		Message: auditableAction.AuditMessage(api),
+		Command: command,
+		Err:     err,
+	}
+	if targetedAction, ok := a.(TargetedAction); ok {
+		entry.Target = targetedAction.AuditTarget()
+	}
+
+	return []AuditEntry{entry}
+}
@@ -0,0 +1,245 @@
+package action_test
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/audit"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// checkedRequests are the Requests test-destructive commands were created
+// from.
+var checkedRequests []action.Request
+
+func init() {
+	action.Register(action.Command{
+		Name:        "test-destructive",
+		Params:      []action.Param{{Name: "email|@username"}},
+		Description: "Do nothing destructive",
+		Destructive: true,
+		New: func(r action.Request) action.Action {
+			checkedRequests = append(checkedRequests, r)
+			return echo{request: r}
+		},
+	})
+}
+
+var _ = Describe("Confirmation", func() {
+	var (
+		c            config.Config
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeClock    *fakeclock.FakeClock
+		logger       lager.Logger
+	)
+
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeClock = fakeclock.NewFakeClock(time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC))
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackSlashCommand: "/slack-slash-command",
+			SlackTeamName:     "slack-team-name",
+			SlackUserID:       "slack-user-id",
+		})
+		logger = lager.NewLogger("testlogger")
+
+		user := slack.User{ID: "U1234", Name: "tsmith", IsRestricted: true}
+		user.Profile.FirstName = "Tom"
+		user.Profile.LastName = "Smith"
+		user.Profile.Email = "tsmith@example.com"
+		stubUsers(fakeSlackAPI, []slack.User{
+			user,
+			{ID: "U5678", Name: "admin"},
+			{ID: "commander-id", Name: "commander-name", IsAdmin: true},
+		})
+	})
+
+	newAction := func(commanderID string, text string) action.Action {
+		return action.New(
+			slackapi.NewChannel("channel-name", "C1234"),
+			"commander-name",
+			commanderID,
+			text,
+		)
+	}
+
+	askToDisable := func() string {
+		result, err := newAction("commander-id", "disable-user @tsmith").Do(c, fakeSlackAPI, fakeClock, logger)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		token := regexp.MustCompile("`/slack-slash-command confirm ([0-9a-f]+)`").FindStringSubmatch(result.String())
+		Ω(token).Should(HaveLen(2))
+
+		return token[1]
+	}
+
+	It("asks for confirmation, showing who would be affected, without changing anything", func() {
+		result, err := newAction("commander-id", "disable-user @tsmith").Do(c, fakeSlackAPI, fakeClock, logger)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(result.String()).Should(MatchRegexp(
+			"^Are you sure\\? `disable-user` will:\n" +
+				"• disable <@U1234>\n" +
+				"Tom Smith \\(tsmith@example.com\\) is a Slack restricted account, with the username <@tsmith>.\n" +
+				"Confirm within 5 minutes, or type `/slack-slash-command confirm [0-9a-f]+`.$",
+		))
+		Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+	})
+
+	It("offers buttons to confirm or cancel", func() {
+		result, err := newAction("commander-id", "disable-user @tsmith").Do(c, fakeSlackAPI, fakeClock, logger)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(result.Blocks).Should(HaveLen(4))
+		Ω(result.Blocks[2].Type).Should(Equal("actions"))
+		Ω(result.Blocks[2].Elements).Should(HaveLen(2))
+
+		confirmButton := result.Blocks[2].Elements[0].(slackapi.Button)
+		Ω(confirmButton.ActionID).Should(Equal("confirm"))
+		Ω(confirmButton.Style).Should(Equal(slackapi.ButtonStyleDanger))
+
+		cancelButton := result.Blocks[2].Elements[1].(slackapi.Button)
+		Ω(cancelButton.ActionID).Should(Equal("cancel"))
+		Ω(cancelButton.Value).Should(Equal(confirmButton.Value))
+	})
+
+	It("fails as usual when the command's checks fail", func() {
+		a := newAction("commander-id", "disable-user @admin")
+
+		result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+		Ω(err).Should(MatchError("Full users cannot be disabled."))
+		Ω(result.String()).Should(Equal("Failed to disable user '@admin': Full users cannot be disabled."))
+
+		entries := a.(action.MultiAuditableAction).AuditEntries(fakeSlackAPI)
+		Ω(entries).Should(HaveLen(1))
+		Ω(entries[0].Command).Should(Equal("disable-user"))
+		Ω(entries[0].Err).Should(MatchError("Full users cannot be disabled."))
+	})
+
+	It("records nothing in the audit log until confirmed", func() {
+		a := newAction("commander-id", "disable-user @tsmith")
+		a.Do(c, fakeSlackAPI, fakeClock, logger)
+
+		Ω(a.(action.MultiAuditableAction).AuditEntries(fakeSlackAPI)).Should(BeEmpty())
+	})
+
+	It("checks the command as a dry run, so that it changes nothing outside Slack either", func() {
+		checkedRequests = nil
+
+		_, err := newAction("commander-id", "test-destructive @tsmith").Do(c, fakeSlackAPI, fakeClock, logger)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		Ω(checkedRequests).Should(HaveLen(1))
+		Ω(checkedRequests[0].DryRun).Should(BeTrue())
+	})
+
+	It("is not needed for a dry run", func() {
+		Ω(action.IsDryRun(newAction("commander-id", "disable-user @tsmith --dry-run"))).Should(BeTrue())
+	})
+
+	Describe("confirm", func() {
+		It("performs the command", func() {
+			token := askToDisable()
+
+			a := newAction("commander-id", "confirm "+token)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(Equal("Successfully disabled user '@tsmith'"))
+
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(1))
+			_, userID := fakeSlackAPI.DisableUserArgsForCall(0)
+			Ω(userID).Should(Equal("U1234"))
+
+			Ω(a.(action.MultiAuditableAction).AuditEntries(fakeSlackAPI)).Should(Equal([]action.AuditEntry{{
+				Message:    "@commander-name disabled user @tsmith",
+				Command:    "disable-user",
+				Target:     "U1234",
+				TargetRole: audit.RoleRestricted,
+			}}))
+		})
+
+		It("refuses when the user no longer names the one shown", func() {
+			token := askToDisable()
+
+			stubUsers(fakeSlackAPI, []slack.User{
+				{ID: "U9999", Name: "tsmith", IsRestricted: true},
+				{ID: "commander-id", Name: "commander-name", IsAdmin: true},
+			})
+
+			result, err := newAction("commander-id", "confirm "+token).Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(Equal(action.NewUserChangedErr("@tsmith")))
+			Ω(result.String()).Should(ContainSubstring("'@tsmith' no longer names the user you were asked to confirm"))
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+		})
+
+		It("performs the command only once", func() {
+			token := askToDisable()
+
+			_, err := newAction("commander-id", "confirm "+token).Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			_, err = newAction("commander-id", "confirm "+token).Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(HaveOccurred())
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(1))
+		})
+
+		It("cannot be used by someone other than the commander", func() {
+			token := askToDisable()
+
+			_, err := newAction("someone-else", "confirm "+token).Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError(action.NewConfirmationNotFoundErr(token)))
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+
+			_, err = newAction("commander-id", "confirm "+token).Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("fails once the confirmation has expired", func() {
+			token := askToDisable()
+			fakeClock.Increment(5 * time.Minute)
+
+			result, err := newAction("commander-id", "confirm "+token).Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError(action.NewConfirmationNotFoundErr(token)))
+			Ω(result.String()).Should(ContainSubstring("It may have expired"))
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+		})
+
+		It("fails when the commander is no longer permitted to run the command", func() {
+			token := askToDisable()
+
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackSlashCommand: "/slack-slash-command",
+				SlackTeamName:     "slack-team-name",
+				SlackUserID:       "slack-user-id",
+				Policy:            config.Policy{"disable-user": config.PolicyRule{}},
+			})
+
+			_, err := newAction("commander-id", "confirm "+token).Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("You are not permitted to use `/slack-slash-command disable-user`."))
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+		})
+	})
+
+	Describe("cancel", func() {
+		It("forgets the command", func() {
+			token := askToDisable()
+
+			result, err := newAction("commander-id", "cancel "+token).Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(Equal("Cancelled `disable-user @tsmith`. Nothing was changed."))
+
+			_, err = newAction("commander-id", "confirm "+token).Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError(action.NewConfirmationNotFoundErr(token)))
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+		})
+	})
+})
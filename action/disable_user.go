@@ -3,15 +3,46 @@ package action
 import (
 	"fmt"
 
+	"github.com/pivotal-golang/clock"
 	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/audit"
 	"github.com/pivotalservices/goulash/config"
 	"github.com/pivotalservices/goulash/slackapi"
 	"github.com/pivotalservices/slack"
 )
 
+const disableUserCommand = "disable-user"
+
+func init() {
+	Register(Command{
+		Name:        disableUserCommand,
+		Params:      []Param{{Name: "email|@username"}},
+		Description: "Disable a Slack user",
+		Permission:  &config.PolicyRule{Admins: true},
+		Mutating:    true,
+		Destructive: true,
+		New: func(r Request) Action {
+			return NewDisableUser(r.Params, r.CommanderName)
+		},
+	})
+}
+
 type disableUser struct {
 	params        []string
 	disablingUser string
+
+	// userID is the ID of the user, once they have been found.
+	userID string
+
+	// pinnedUserID, when set, is the only user the command may act on.
+	pinnedUserID string
+
+	// role and channel are the role the user had and, for a Single-Channel
+	// Guest, the channel they were in, once they have been found.
+	role    string
+	channel slackapi.Channel
+
+	err error
 }
 
 func (du disableUser) searchVal() string {
@@ -21,9 +52,7 @@ func (du disableUser) searchVal() string {
 // NewDisableUser returns a new disable user action
 func NewDisableUser(params []string, disablingUser string) Action {
 	disableUserParams := []string{""}
-	for i := range params {
-		disableUserParams[i] = params[i]
-	}
+	copy(disableUserParams, params)
 
 	return &disableUser{
 		params:        disableUserParams,
@@ -31,28 +60,38 @@ func NewDisableUser(params []string, disablingUser string) Action {
 	}
 }
 
-func (du disableUser) Do(
+func (du *disableUser) pinUser(userID string) {
+	du.pinnedUserID = userID
+}
+
+func (du *disableUser) Do(
 	config config.Config,
 	api slackapi.SlackAPI,
+	clock clock.Clock,
 	logger lager.Logger,
-) (string, error) {
+) (slackapi.Message, error) {
 	logger = logger.Session("do")
 
 	user, err := du.check(du.searchVal(), api, logger)
 	if err != nil {
 		logger.Error("failed", err)
-		return du.failureMessage(err), err
+		du.err = err
+		return slackapi.NewErrorMessage(du.failureMessage(err)), err
 	}
 
+	du.userID = user.ID
+	du.findRole(user, api, logger)
+
 	err = api.DisableUser(config.SlackTeamName(), user.ID)
 	if err != nil {
 		logger.Error("failed", err)
-		return du.failureMessage(err), err
+		du.err = err
+		return slackapi.NewErrorMessage(du.failureMessage(err)), err
 	}
 
 	logger.Info("succeeded")
 
-	return fmt.Sprintf("Successfully disabled user '%s'", du.searchVal()), nil
+	return slackapi.NewTextMessage(fmt.Sprintf("Successfully disabled user '%s'", du.searchVal())), nil
 }
 
 func (du disableUser) AuditMessage(api slackapi.SlackAPI) string {
@@ -63,11 +102,52 @@ func (du disableUser) AuditMessage(api slackapi.SlackAPI) string {
 	)
 }
 
+func (du *disableUser) AuditTarget() string {
+	return userTarget(du.userID, du.searchVal())
+}
+
+// AuditEntries returns the entry for disabling the user, which records the
+// role they had so that enable-user can restore it.
+func (du *disableUser) AuditEntries(api slackapi.SlackAPI) []AuditEntry {
+	entry := AuditEntry{
+		Message:    du.AuditMessage(api),
+		Command:    disableUserCommand,
+		Target:     du.AuditTarget(),
+		Err:        du.err,
+		TargetRole: du.role,
+	}
+	if du.channel != nil {
+		entry.TargetChannelID = du.channel.ID()
+	}
+
+	return []AuditEntry{entry}
+}
+
+// findRole notes the role the user has and, for a Single-Channel Guest, the
+// channel they are in. The channel is left unknown if it cannot be found, as
+// that is no reason not to disable them.
+func (du *disableUser) findRole(user slack.User, api slackapi.SlackAPI, logger lager.Logger) {
+	if !user.IsUltraRestricted {
+		du.role = audit.RoleRestricted
+		return
+	}
+
+	du.role = audit.RoleGuest
+
+	channel, err := memberChannel(user.ID, api)
+	if err != nil {
+		logger.Error("failed-to-find-channel", err)
+		return
+	}
+
+	du.channel = channel
+}
+
 func (du disableUser) failureMessage(err error) string {
 	return fmt.Sprintf(
 		"Failed to disable user '%s': %s",
 		du.searchVal(),
-		err.Error(),
+		NewSlackErr(err).Error(),
 	)
 }
 
@@ -78,7 +158,7 @@ func (du disableUser) check(
 ) (slack.User, error) {
 	logger = logger.Session("check")
 
-	user, err := findUser(searchVal, api)
+	user, err := findUserToChange(searchVal, du.pinnedUserID, api)
 	if err != nil {
 		logger.Error("failed", err)
 		return slack.User{}, err
@@ -2,9 +2,12 @@ package action_test
 
 import (
 	"errors"
+	"time"
 
+	"github.com/pivotal-golang/clock/fakeclock"
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/audit"
 	"github.com/pivotalservices/goulash/config"
 	"github.com/pivotalservices/goulash/slackapi"
 	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
@@ -19,45 +22,47 @@ var _ = Describe("DisableUser", func() {
 		a            action.Action
 		c            config.Config
 		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeClock    *fakeclock.FakeClock
 		logger       lager.Logger
 	)
 
 	BeforeEach(func() {
 		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
-		c = config.NewLocalConfig(
-			"slack-auth-token",
-			"/slack-slash-command",
-			"slack-team-name",
-			"slack-user-id",
-			"audit-log-channel-id",
-			"uninvitable-domain.com",
-			"uninvitable-domain-message",
-		)
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackAuthToken:     "slack-auth-token",
+			SlackSlashCommand:  "/slack-slash-command",
+			SlackTeamName:      "slack-team-name",
+			SlackUserID:        "slack-user-id",
+			AuditLogChannelID:  "audit-log-channel-id",
+			UninvitableDomain:  "uninvitable-domain.com",
+			UninvitableMessage: "uninvitable-domain-message",
+		})
 
 		logger = lager.NewLogger("testlogger")
 	})
 
 	Describe("Do", func() {
 		It("attempts to disable the user if they can be found by name", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:           "U1234",
 					Name:         "tsmith",
 					IsRestricted: true,
 				},
-			}, nil)
+			})
 
-			a = action.New(
+			a = action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
 				"disable-user @tsmith",
 			)
 
-			_, err := a.Do(c, fakeSlackAPI, logger)
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
 
-			Ω(fakeSlackAPI.GetUsersCallCount()).Should(Equal(1))
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(1))
 			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(1))
 
 			actualSlackTeamName, actualID := fakeSlackAPI.DisableUserArgsForCall(0)
@@ -65,8 +70,56 @@ var _ = Describe("DisableUser", func() {
 			Ω(actualID).Should(Equal("U1234"))
 		})
 
+		It("looks the user up in the directory when one is available", func() {
+			user := slack.User{
+				ID:           "U1234",
+				Name:         "tsmith",
+				IsRestricted: true,
+			}
+			stubUsers(fakeSlackAPI, []slack.User{user})
+
+			cache := slackapi.NewCache(fakeSlackAPI, time.Minute, fakeClock, logger)
+
+			for i := 0; i < 2; i++ {
+				a = action.NewConfirmed(
+					slackapi.NewChannel("channel-name", "channel-id"),
+					"commander-name",
+					"commander-id",
+					"disable-user @tsmith",
+				)
+
+				_, err := a.Do(c, cache, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+			}
+
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(1))
+			Ω(fakeSlackAPI.GetUserInfoCallCount()).Should(Equal(3))
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(2))
+		})
+
+		It("checks the user as they are now rather than as the directory remembers them", func() {
+			stubUsers(fakeSlackAPI, []slack.User{{ID: "U1234", Name: "tsmith", IsRestricted: true}})
+			cache := slackapi.NewCache(fakeSlackAPI, time.Minute, fakeClock, logger)
+			_, err := slackapi.AllUsers(cache)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			fakeSlackAPI.GetUserInfoStub = nil
+			fakeSlackAPI.GetUserInfoReturns(&slack.User{ID: "U1234", Name: "tsmith"}, nil)
+
+			a = action.NewConfirmed(
+				slackapi.NewChannel("channel-name", "channel-id"),
+				"commander-name",
+				"commander-id",
+				"disable-user @tsmith",
+			)
+
+			_, err = a.Do(c, cache, fakeClock, logger)
+			Ω(err).Should(MatchError("Full users cannot be disabled."))
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+		})
+
 		It("attempts to disable the user if they can be found by email", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:           "U1234",
 					IsRestricted: true,
@@ -74,19 +127,19 @@ var _ = Describe("DisableUser", func() {
 						Email: "user@example.com",
 					},
 				},
-			}, nil)
+			})
 
-			a = action.New(
+			a = action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
 				"disable-user user@example.com",
 			)
 
-			_, err := a.Do(c, fakeSlackAPI, logger)
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
 
-			Ω(fakeSlackAPI.GetUsersCallCount()).Should(Equal(1))
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(1))
 			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(1))
 
 			actualSlackTeamName, actualID := fakeSlackAPI.DisableUserArgsForCall(0)
@@ -94,40 +147,58 @@ var _ = Describe("DisableUser", func() {
 			Ω(actualID).Should(Equal("U1234"))
 		})
 
-		It("returns an error if the GetUsers call fails", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{}, errors.New("error"))
+		It("returns an error if the GetUsersPage call fails", func() {
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{}, "", errors.New("error"))
 
-			a = action.New(
+			a = action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
 				"disable-user user@example.com",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("error"))
-			Ω(result).Should(Equal("Failed to disable user 'user@example.com': error"))
+			Ω(result.String()).Should(Equal("Failed to disable user 'user@example.com': error"))
 		})
 
 		It("returns an error if the user cannot be found", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{}, nil)
+			stubUsers(fakeSlackAPI, []slack.User{})
 
-			a = action.New(
+			a = action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
 				"disable-user user@example.com",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("Unable to find user matching 'user@example.com'."))
-			Ω(result).Should(Equal("Failed to disable user 'user@example.com': Unable to find user matching 'user@example.com'."))
+			Ω(result.String()).Should(Equal("Failed to disable user 'user@example.com': Unable to find user matching 'user@example.com'."))
+		})
+
+		It("returns an error without disabling anyone if several users match", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{ID: "U1234", Name: "tsmith", RealName: "Tom Smith", IsRestricted: true},
+				{ID: "U5678", Name: "tsmith2", RealName: "Tom Smith", IsRestricted: true},
+			})
+
+			a = action.NewConfirmed(
+				slackapi.NewChannel("channel-name", "channel-id"),
+				"commander-name",
+				"commander-id",
+				`disable-user "tom smith"`,
+			)
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("Several users match 'tom smith': @tsmith, @tsmith2. Try again with an email address or @username."))
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
 		})
 
 		It("returns an error when disabling the user fails", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:           "U1234",
 					IsRestricted: true,
@@ -135,25 +206,25 @@ var _ = Describe("DisableUser", func() {
 						Email: "user@example.com",
 					},
 				},
-			}, nil)
+			})
 
 			fakeSlackAPI.DisableUserReturns(errors.New("failed"))
 
-			a = action.New(
+			a = action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
 				"disable-user user@example.com",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
-			Ω(result).Should(Equal("Failed to disable user 'user@example.com': failed"))
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(result.String()).Should(Equal("Failed to disable user 'user@example.com': failed"))
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("failed"))
 		})
 
 		It("returns nil on success", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:           "U1234",
 					IsRestricted: true,
@@ -161,22 +232,69 @@ var _ = Describe("DisableUser", func() {
 						Email: "user@example.com",
 					},
 				},
-			}, nil)
+			})
 
-			a = action.New(
+			a = action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
 				"disable-user user@example.com",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
-			Ω(result).Should(Equal("Successfully disabled user 'user@example.com'"))
+			Ω(result.String()).Should(Equal("Successfully disabled user 'user@example.com'"))
+		})
+
+		It("explains errors from Slack which can be done something about", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{
+					ID:           "U1234",
+					IsRestricted: true,
+					Profile: slack.UserProfile{
+						Email: "user@example.com",
+					},
+				},
+			})
+			fakeSlackAPI.DisableUserReturns(slackapi.Error{Code: slackapi.ErrorRateLimited})
+
+			a = action.NewConfirmed(
+				slackapi.NewChannel("channel-name", "channel-id"),
+				"commander-name",
+				"commander-id",
+				"disable-user user@example.com",
+			)
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("ratelimited"))
+			Ω(result.String()).Should(Equal("Failed to disable user 'user@example.com': Slack is limiting how quickly Goulash can make requests. Wait a minute, then try again."))
+		})
+
+		It("shows other errors from Slack as they are", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{
+					ID:           "U1234",
+					IsRestricted: true,
+					Profile: slack.UserProfile{
+						Email: "user@example.com",
+					},
+				},
+			})
+			fakeSlackAPI.DisableUserReturns(slackapi.Error{Code: "fatal_error"})
+
+			a = action.NewConfirmed(
+				slackapi.NewChannel("channel-name", "channel-id"),
+				"commander-name",
+				"commander-id",
+				"disable-user user@example.com",
+			)
+
+			result, _ := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(result.String()).Should(Equal("Failed to disable user 'user@example.com': fatal_error"))
 		})
 
 		It("returns an error if the user is a full user", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:                "U9999",
 					IsRestricted:      false,
@@ -193,22 +311,22 @@ var _ = Describe("DisableUser", func() {
 						Email: "user@example.com",
 					},
 				},
-			}, nil)
+			})
 
-			a = action.New(
+			a = action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
 				"disable-user user@example.com",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
-			Ω(result).Should(Equal("Failed to disable user 'user@example.com': Full users cannot be disabled."))
+			Ω(result.String()).Should(Equal("Failed to disable user 'user@example.com': Full users cannot be disabled."))
 		})
 
 		It("does not return an error if another user is a full user", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:                "U9999",
 					IsRestricted:      false,
@@ -225,23 +343,23 @@ var _ = Describe("DisableUser", func() {
 						Email: "user@example.com",
 					},
 				},
-			}, nil)
+			})
 
-			a = action.New(
+			a = action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
 				"disable-user user@example.com",
 			)
 
-			_, err := a.Do(c, fakeSlackAPI, logger)
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
 		})
 	})
 
 	Describe("AuditMessage", func() {
 		It("exists", func() {
-			a = action.New(
+			a = action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
@@ -254,4 +372,58 @@ var _ = Describe("DisableUser", func() {
 			Ω(aa.AuditMessage(fakeSlackAPI)).Should(Equal("@commander-name disabled user user@example.com"))
 		})
 	})
+
+	Describe("AuditEntries", func() {
+		newDisableUser := func() action.Action {
+			return action.NewConfirmed(
+				slackapi.NewChannel("channel-name", "channel-id"),
+				"commander-name",
+				"commander-id",
+				"disable-user @tsmith",
+			)
+		}
+
+		It("records the channel a single-channel guest was in", func() {
+			stubUsers(fakeSlackAPI, []slack.User{{ID: "U1234", Name: "tsmith", IsUltraRestricted: true}})
+			stubConversations(fakeSlackAPI, []slackapi.Conversation{{ID: "C1", Name: "eng"}}, []slackapi.Conversation{{ID: "G1", Name: "secret"}})
+			stubMembers(fakeSlackAPI, map[string][]string{"C1": {"U5678"}, "G1": {"U1234"}})
+
+			a = newDisableUser()
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(a.(action.MultiAuditableAction).AuditEntries(fakeSlackAPI)).Should(Equal([]action.AuditEntry{{
+				Message:         "@commander-name disabled user @tsmith",
+				Command:         "disable-user",
+				Target:          "U1234",
+				TargetRole:      audit.RoleGuest,
+				TargetChannelID: "G1",
+			}}))
+		})
+
+		It("records that a restricted account was one", func() {
+			stubUsers(fakeSlackAPI, []slack.User{{ID: "U1234", Name: "tsmith", IsRestricted: true}})
+
+			a = newDisableUser()
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			entries := a.(action.MultiAuditableAction).AuditEntries(fakeSlackAPI)
+			Ω(entries).Should(HaveLen(1))
+			Ω(entries[0].TargetRole).Should(Equal(audit.RoleRestricted))
+			Ω(entries[0].TargetChannelID).Should(BeEmpty())
+			Ω(fakeSlackAPI.GetUserConversationsCallCount()).Should(Equal(0))
+		})
+
+		It("records the error when disabling fails", func() {
+			stubUsers(fakeSlackAPI, []slack.User{{ID: "U1234", Name: "tsmith", IsRestricted: true}})
+			fakeSlackAPI.DisableUserReturns(errors.New("failed"))
+
+			a = newDisableUser()
+			a.Do(c, fakeSlackAPI, fakeClock, logger)
+
+			entries := a.(action.MultiAuditableAction).AuditEntries(fakeSlackAPI)
+			Ω(entries[0].Err).Should(MatchError("failed"))
+		})
+	})
 })
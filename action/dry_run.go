@@ -0,0 +1,121 @@
+package action
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+)
+
+const (
+	dryRunOption = "dry-run"
+
+	dryRunAuditSuffix = " (dry run)"
+)
+
+type dryRun struct {
+	action  Action
+	command string
+
+	err error
+}
+
+// NewDryRun returns an Action which performs the given one, an Action for the
+// named command, through a SlackAPI which changes nothing. It replies with
+// what would have been changed, and its audit entries are marked as being
+// for a dry run.
+func NewDryRun(a Action, command string) Action {
+	return &dryRun{
+		action:  a,
+		command: command,
+	}
+}
+
+// describe adds the change to those the SlackAPI describes, if it is one
+// returned by slackapi.NewDryRun.
+func describe(api slackapi.SlackAPI, change string) {
+	if dryRun, ok := api.(slackapi.DryRun); ok {
+		dryRun.Describe(change)
+	}
+}
+
+// IsDryRun returns true if the Action was returned by NewDryRun.
+func IsDryRun(a Action) bool {
+	_, ok := a.(*dryRun)
+	return ok
+}
+
+func (d *dryRun) Do(
+	c config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) (slackapi.Message, error) {
+	logger = logger.Session("dry-run")
+
+	dryRunAPI := slackapi.NewDryRun(api)
+
+	var result slackapi.Message
+	result, d.err = d.action.Do(c, dryRunAPI, clock, logger)
+	if d.err != nil {
+		return slackapi.NewErrorMessage(fmt.Sprintf("Dry run: %s", result.String())), d.err
+	}
+
+	changes := dryRunAPI.Changes()
+	if len(changes) == 0 {
+		return slackapi.NewTextMessage(fmt.Sprintf("Dry run: `%s` would not have changed anything.", d.command)), nil
+	}
+
+	lines := []string{fmt.Sprintf("Dry run: nothing was changed. `%s` would have:", d.command)}
+	for _, change := range changes {
+		lines = append(lines, "• "+change)
+	}
+
+	message := slackapi.Message{
+		Text:   strings.Join(lines, "\n"),
+		Blocks: slackapi.NewSectionBlocks(lines),
+	}
+
+	return message.Fit(), nil
+}
+
+func (d *dryRun) AuditMessage(api slackapi.SlackAPI) string {
+	auditableAction, ok := d.action.(AuditableAction)
+	if !ok {
+		return fmt.Sprintf("dry run of %s", d.command)
+	}
+
+	return auditableAction.AuditMessage(api) + dryRunAuditSuffix
+}
+
+func (d *dryRun) AuditTarget() string {
+	if targetedAction, ok := d.action.(TargetedAction); ok {
+		return targetedAction.AuditTarget()
+	}
+
+	return ""
+}
+
+func (d *dryRun) AuditEntries(api slackapi.SlackAPI) []AuditEntry {
+	multiAuditableAction, ok := d.action.(MultiAuditableAction)
+	if !ok {
+		return []AuditEntry{{
+			Message: d.AuditMessage(api),
+			Command: d.command,
+			Target:  d.AuditTarget(),
+			Err:     d.err,
+			DryRun:  true,
+		}}
+	}
+
+	entries := multiAuditableAction.AuditEntries(api)
+	for i := range entries {
+		entries[i].Message += dryRunAuditSuffix
+		entries[i].DryRun = true
+	}
+
+	return entries
+}
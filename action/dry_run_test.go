@@ -0,0 +1,130 @@
+package action_test
+
+import (
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/audit"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DryRun", func() {
+	var (
+		c            config.Config
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeClock    *fakeclock.FakeClock
+		logger       lager.Logger
+	)
+
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{IsMember: true}, nil)
+		fakeClock = fakeclock.NewFakeClock(time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC))
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackSlashCommand: "/slack-slash-command",
+			SlackTeamName:     "slack-team-name",
+			SlackUserID:       "slack-user-id",
+		})
+		logger = lager.NewLogger("testlogger")
+
+		stubUsers(fakeSlackAPI, []slack.User{
+			{ID: "U1234", Name: "tsmith", IsRestricted: true},
+			{ID: "U5678", Name: "admin"},
+		})
+
+		channel := slackapi.Conversation{}
+		channel.ID = "C1234"
+		channel.Name = "channel-name"
+		fakeSlackAPI.GetConversationsReturns([]slackapi.Conversation{channel}, nil)
+	})
+
+	newAction := func(text string) action.Action {
+		return action.New(
+			slackapi.NewChannel("channel-name", "C1234"),
+			"commander-name",
+			"commander-id",
+			text,
+		)
+	}
+
+	It("describes what disable-user would change without changing it", func() {
+		a := newAction("disable-user @tsmith --dry-run")
+		Ω(action.IsDryRun(a)).Should(BeTrue())
+
+		result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(result.String()).Should(Equal("Dry run: nothing was changed. `disable-user` would have:\n• disable <@U1234>"))
+
+		Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+	})
+
+	It("describes what guestify would change without changing it", func() {
+		result, err := newAction("guestify @tsmith --dry-run").Do(c, fakeSlackAPI, fakeClock, logger)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(result.String()).Should(Equal("Dry run: nothing was changed. `guestify` would have:\n• make <@U1234> a single-channel guest in <#C1234>"))
+
+		Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(0))
+	})
+
+	It("runs the command's checks", func() {
+		result, err := newAction("disable-user @admin --dry-run").Do(c, fakeSlackAPI, fakeClock, logger)
+		Ω(err).Should(MatchError("Full users cannot be disabled."))
+		Ω(result.String()).Should(Equal("Dry run: Failed to disable user '@admin': Full users cannot be disabled."))
+	})
+
+	It("does not record when an invited account expires", func() {
+		puts := registeredExpiryStore.PutCallCount()
+
+		result, err := newAction("invite-guest user@example.com Tom Smith --expires=30d --dry-run").Do(c, fakeSlackAPI, fakeClock, logger)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(result.String()).Should(Equal("Dry run: nothing was changed. `invite-guest` would have:\n" +
+			"• invite Tom Smith (user@example.com) as a single-channel guest in <#C1234>\n" +
+			"• record that the account of user@example.com expires on Tuesday, May 31, 2016"))
+
+		Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
+		Ω(registeredExpiryStore.PutCallCount()).Should(Equal(puts))
+	})
+
+	It("is only accepted by commands which change Slack", func() {
+		_, err := newAction("guests --dry-run").Do(c, fakeSlackAPI, fakeClock, logger)
+		Ω(err).Should(HaveOccurred())
+		Ω(err.Error()).Should(ContainSubstring("Unknown option '--dry-run'."))
+	})
+
+	Describe("AuditEntries", func() {
+		It("marks the entry as for a dry run", func() {
+			a := newAction("disable-user @tsmith --dry-run")
+			a.Do(c, fakeSlackAPI, fakeClock, logger)
+
+			entries := a.(action.MultiAuditableAction).AuditEntries(fakeSlackAPI)
+			Ω(entries).Should(Equal([]action.AuditEntry{{
+				Message:    "@commander-name disabled user @tsmith (dry run)",
+				Command:    "disable-user",
+				Target:     "U1234",
+				DryRun:     true,
+				TargetRole: audit.RoleRestricted,
+			}}))
+		})
+
+		It("marks each entry of a command with several as for a dry run", func() {
+			a := newAction("invite-bulk guest --dry-run\nuser@example.com,Tom,Smith\njdoe@example.com,Jane,Doe")
+			a.Do(c, fakeSlackAPI, fakeClock, logger)
+
+			entries := a.(action.MultiAuditableAction).AuditEntries(fakeSlackAPI)
+			Ω(entries).Should(HaveLen(2))
+			for _, entry := range entries {
+				Ω(entry.Message).Should(HaveSuffix(" (dry run)"))
+				Ω(entry.DryRun).Should(BeTrue())
+			}
+			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,229 @@
+package action
+
+import (
+	"fmt"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/audit"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/slack"
+)
+
+func init() {
+	Register(Command{
+		Name:        "enable-user",
+		Params:      []Param{{Name: "email|@username"}},
+		Options:     []Option{{Name: "restricted"}},
+		Description: "Re-enable a disabled Slack guest as whatever they were when disabled, in the same channel/group for a Single-Channel Guest, or with `--restricted` as a Restricted Account",
+		Permission:  &config.PolicyRule{Admins: true},
+		Mutating:    true,
+		New: func(r Request) Action {
+			return NewEnableUser(r.Params, r.Options["restricted"] != "", auditEvents, r.CommanderName)
+		},
+	})
+}
+
+type enableUser struct {
+	params       []string
+	asRestricted bool
+	store        audit.Store
+	enablingUser string
+
+	// asGuest is true once the user has been found to have been a
+	// Single-Channel Guest who is being restored as one, in channel.
+	asGuest bool
+	channel slackapi.Channel
+
+	// userID is the ID of the user, once they have been found.
+	userID string
+}
+
+func (eu enableUser) searchVal() string {
+	return eu.params[0]
+}
+
+// NewEnableUser returns a new enable user action. A user who was a
+// Single-Channel Guest when they were disabled is restored as one in the
+// channel they were in, as recorded in the given Store, unless asRestricted is
+// true, when they are enabled as a Restricted Account instead.
+func NewEnableUser(
+	params []string,
+	asRestricted bool,
+	store audit.Store,
+	enablingUser string,
+) Action {
+	enableUserParams := []string{""}
+	copy(enableUserParams, params)
+
+	return &enableUser{
+		params:       enableUserParams,
+		asRestricted: asRestricted,
+		store:        store,
+		enablingUser: enablingUser,
+	}
+}
+
+func (eu *enableUser) Do(
+	config config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) (slackapi.Message, error) {
+	logger = logger.Session("do")
+
+	user, err := eu.check(eu.searchVal(), config, api, logger)
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(eu.failureMessage(err)), err
+	}
+
+	eu.userID = user.ID
+
+	if eu.asGuest {
+		err = api.SetUltraRestricted(config.SlackTeamName(), user.ID, eu.channel.ID())
+	} else {
+		err = api.EnableUser(config.SlackTeamName(), user.ID)
+	}
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(eu.failureMessage(err)), err
+	}
+
+	logger.Info("succeeded", lager.Data{"asGuest": eu.asGuest})
+
+	return slackapi.NewTextMessage(fmt.Sprintf(
+		"Successfully enabled user '%s' as a %s",
+		eu.searchVal(),
+		eu.role(api),
+	)), nil
+}
+
+func (eu *enableUser) AuditMessage(api slackapi.SlackAPI) string {
+	return fmt.Sprintf(
+		"@%s enabled user %s as a %s",
+		eu.enablingUser,
+		eu.searchVal(),
+		eu.role(api),
+	)
+}
+
+func (eu *enableUser) AuditTarget() string {
+	return userTarget(eu.userID, eu.searchVal())
+}
+
+func (eu *enableUser) role(api slackapi.SlackAPI) string {
+	if eu.asGuest {
+		return fmt.Sprintf("single-channel guest in '%s'", eu.channel.Name(api))
+	}
+
+	return "restricted account"
+}
+
+func (eu *enableUser) failureMessage(err error) string {
+	return fmt.Sprintf(
+		"Failed to enable user '%s': %s",
+		eu.searchVal(),
+		NewSlackErr(err).Error(),
+	)
+}
+
+// check returns the user to enable, provided that they are a disabled guest
+// and, unless they are to be enabled as a Restricted Account, that a
+// Single-Channel Guest's channel is known and visible.
+func (eu *enableUser) check(
+	searchVal string,
+	config config.Config,
+	api slackapi.SlackAPI,
+	logger lager.Logger,
+) (slack.User, error) {
+	logger = logger.Session("check")
+
+	user, err := findUserToChange(searchVal, "", api)
+	if err != nil {
+		logger.Error("failed", err)
+		return slack.User{}, err
+	}
+
+	if !(user.IsRestricted || user.IsUltraRestricted) {
+		err = NewFullUserCannotBeErr("enabled")
+		logger.Error("failed", err)
+		return slack.User{}, err
+	}
+
+	if !user.Deleted {
+		err = NewUserNotDisabledErr(searchVal)
+		logger.Error("failed", err)
+		return slack.User{}, err
+	}
+
+	if eu.asRestricted {
+		logger.Info("passed")
+		return user, nil
+	}
+
+	disabled, found, err := eu.lastDisabled(user)
+	if err != nil {
+		logger.Error("failed", err)
+		return slack.User{}, err
+	}
+
+	// Without a record of them being disabled, Slack still says whether they
+	// were a Single-Channel Guest, but not which channel they were in.
+	if found {
+		eu.asGuest = disabled.TargetRole == audit.RoleGuest
+	} else {
+		eu.asGuest = user.IsUltraRestricted
+	}
+
+	if !eu.asGuest {
+		logger.Info("passed")
+		return user, nil
+	}
+
+	if disabled.TargetChannelID == "" {
+		err = NewRoleNotRecordedErr(searchVal)
+		logger.Error("failed", err)
+		return slack.User{}, err
+	}
+
+	eu.channel = slackapi.NewChannel("", disabled.TargetChannelID)
+	if !eu.channel.Visible(api) {
+		err = NewChannelNotVisibleErr(config.SlackUserID())
+		logger.Error("failed", err)
+		return slack.User{}, err
+	}
+
+	logger.Info("passed")
+
+	return user, nil
+}
+
+// lastDisabled returns the most recent event in the Store recording the role
+// the user had when they were disabled, and whether there is one.
+func (eu *enableUser) lastDisabled(user slack.User) (audit.Event, bool, error) {
+	if eu.store == nil {
+		return audit.Event{}, false, nil
+	}
+
+	events, err := eu.store.Query(audit.Query{
+		User:   user.ID,
+		UserID: user.ID,
+		Email:  user.Profile.Email,
+	})
+	if err != nil {
+		return audit.Event{}, false, err
+	}
+
+	// The Query also selects events the user performed, such as disabling
+	// someone else, which say nothing about the user's own role.
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		if event.Target == user.ID && event.TargetRole != "" && event.Outcome == audit.OutcomeSucceeded && !event.DryRun {
+			return event, true, nil
+		}
+	}
+
+	return audit.Event{}, false, nil
+}
@@ -0,0 +1,329 @@
+package action_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/audit"
+	"github.com/pivotalservices/goulash/audit/auditfakes"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EnableUser", func() {
+	var (
+		c            config.Config
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeClock    *fakeclock.FakeClock
+		logger       lager.Logger
+	)
+
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{IsMember: true}, nil)
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackAuthToken:    "slack-auth-token",
+			SlackSlashCommand: "/slack-slash-command",
+			SlackTeamName:     "slack-team-name",
+			SlackUserID:       "slack-user-id",
+			AuditLogChannelID: "audit-log-channel-id",
+		})
+
+		logger = lager.NewLogger("testlogger")
+	})
+
+	newEnableUser := func(text string) action.Action {
+		return action.New(
+			slackapi.NewChannel("channel-name", "channel-id"),
+			"commander-name",
+			"commander-id",
+			text,
+		)
+	}
+
+	Describe("Do", func() {
+		It("enables a disabled restricted account found by email", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{
+					ID:           "U1234",
+					Deleted:      true,
+					IsRestricted: true,
+					Profile: slack.UserProfile{
+						Email: "user@example.com",
+					},
+				},
+			})
+
+			result, err := newEnableUser("enable-user user@example.com").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(Equal("Successfully enabled user 'user@example.com' as a restricted account"))
+
+			Ω(fakeSlackAPI.EnableUserCallCount()).Should(Equal(1))
+
+			actualSlackTeamName, actualID := fakeSlackAPI.EnableUserArgsForCall(0)
+			Ω(actualSlackTeamName).Should(Equal("slack-team-name"))
+			Ω(actualID).Should(Equal("U1234"))
+		})
+
+		It("enables a disabled single-channel guest as a restricted account with --restricted", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{
+					ID:                "U1234",
+					Name:              "tsmith",
+					Deleted:           true,
+					IsUltraRestricted: true,
+				},
+			})
+
+			_, err := newEnableUser("enable-user @tsmith --restricted").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(fakeSlackAPI.EnableUserCallCount()).Should(Equal(1))
+			Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(0))
+		})
+
+		It("refuses to enable a single-channel guest as anything else when their channel is not known", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{
+					ID:                "U1234",
+					Name:              "tsmith",
+					Deleted:           true,
+					IsUltraRestricted: true,
+				},
+			})
+
+			_, err := newEnableUser("enable-user @tsmith").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(Equal(action.NewRoleNotRecordedErr("@tsmith")))
+
+			Ω(fakeSlackAPI.EnableUserCallCount()).Should(Equal(0))
+			Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(0))
+		})
+
+		Context("with a record of what they were when disabled", func() {
+			var fakeStore *auditfakes.FakeStore
+
+			BeforeEach(func() {
+				fakeStore = &auditfakes.FakeStore{}
+				fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{ID: "G1", Name: "design", IsMember: true}, nil)
+				stubUsers(fakeSlackAPI, []slack.User{
+					{
+						ID:                "U1234",
+						Name:              "tsmith",
+						Deleted:           true,
+						IsUltraRestricted: true,
+						Profile:           slack.UserProfile{Email: "user@example.com"},
+					},
+				})
+			})
+
+			newRestore := func() action.Action {
+				return action.NewEnableUser([]string{"@tsmith"}, false, fakeStore, "commander-name")
+			}
+
+			disabled := func(role string, channelID string) audit.Event {
+				return audit.Event{
+					Action:          "disable-user",
+					Target:          "U1234",
+					Outcome:         audit.OutcomeSucceeded,
+					TargetRole:      role,
+					TargetChannelID: channelID,
+				}
+			}
+
+			It("restores a single-channel guest to the channel they were in when disabled", func() {
+				fakeStore.QueryReturns([]audit.Event{
+					disabled(audit.RoleGuest, "G0"),
+					disabled(audit.RoleGuest, "G1"),
+					{Action: "disable-user", Target: "U1234", Outcome: audit.OutcomeSucceeded, TargetRole: audit.RoleGuest, TargetChannelID: "G2", DryRun: true},
+					{Action: "disable-user", Target: "U1234", Outcome: audit.OutcomeFailed, TargetRole: audit.RoleGuest, TargetChannelID: "G3"},
+				}, nil)
+
+				result, err := newRestore().Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(result.String()).Should(Equal("Successfully enabled user '@tsmith' as a single-channel guest in 'design'"))
+
+				Ω(fakeStore.QueryArgsForCall(0)).Should(Equal(audit.Query{
+					User:   "U1234",
+					UserID: "U1234",
+					Email:  "user@example.com",
+				}))
+
+				Ω(fakeSlackAPI.EnableUserCallCount()).Should(Equal(0))
+				Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(1))
+
+				actualTeamName, actualUserID, actualChannel := fakeSlackAPI.SetUltraRestrictedArgsForCall(0)
+				Ω(actualTeamName).Should(Equal("slack-team-name"))
+				Ω(actualUserID).Should(Equal("U1234"))
+				Ω(actualChannel).Should(Equal("G1"))
+			})
+
+			It("restores a restricted account as one", func() {
+				fakeStore.QueryReturns([]audit.Event{disabled(audit.RoleRestricted, "")}, nil)
+
+				_, err := newRestore().Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(fakeSlackAPI.EnableUserCallCount()).Should(Equal(1))
+				Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(0))
+			})
+
+			It("ignores events in which the user disabled someone else", func() {
+				fakeStore.QueryReturns([]audit.Event{
+					disabled(audit.RoleGuest, "G1"),
+					{Action: "disable-user", ActorID: "U1234", Target: "U5678", Outcome: audit.OutcomeSucceeded, TargetRole: audit.RoleRestricted},
+				}, nil)
+
+				_, err := newRestore().Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(fakeSlackAPI.EnableUserCallCount()).Should(Equal(0))
+				Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(1))
+			})
+
+			It("returns an error when a single-channel guest's disabling is not recorded", func() {
+				result, err := newRestore().Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).Should(Equal(action.NewRoleNotRecordedErr("@tsmith")))
+				Ω(result.String()).Should(Equal("Failed to enable user '@tsmith': User '@tsmith' was a single-channel guest, but no record was kept of the channel they were in when they were disabled. Enable them with `--restricted`, then `guestify` them from their channel."))
+
+				Ω(fakeSlackAPI.EnableUserCallCount()).Should(Equal(0))
+				Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(0))
+			})
+
+			It("returns an error when the channel a single-channel guest was in is not recorded", func() {
+				fakeStore.QueryReturns([]audit.Event{disabled(audit.RoleGuest, "")}, nil)
+
+				_, err := newRestore().Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).Should(Equal(action.NewRoleNotRecordedErr("@tsmith")))
+			})
+
+			It("returns an error when there is no audit store", func() {
+				_, err := action.NewEnableUser([]string{"@tsmith"}, false, nil, "commander-name").Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).Should(Equal(action.NewRoleNotRecordedErr("@tsmith")))
+			})
+
+			It("returns an error when the guest's channel is not visible", func() {
+				fakeStore.QueryReturns([]audit.Event{disabled(audit.RoleGuest, "G1")}, nil)
+				fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{ID: "G1", Name: "design"}, nil)
+
+				_, err := newRestore().Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).Should(Equal(action.NewChannelNotVisibleErr("slack-user-id")))
+
+				Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(0))
+			})
+		})
+
+		It("returns an error if the user cannot be found", func() {
+			stubUsers(fakeSlackAPI, []slack.User{})
+
+			result, err := newEnableUser("enable-user user@example.com").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(HaveOccurred())
+			Ω(result.String()).Should(Equal("Failed to enable user 'user@example.com': Unable to find user matching 'user@example.com'."))
+		})
+
+		It("refuses to guess when several users match", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{ID: "U1234", Name: "tsmith", RealName: "Tom Smith", IsRestricted: true, Deleted: true},
+				{ID: "U5678", Name: "tsmith2", RealName: "Tom Smith", IsRestricted: true, Deleted: true},
+			})
+
+			_, err := newEnableUser(`enable-user "Tom Smith"`).Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(Equal(action.NewAmbiguousUserErr("Tom Smith", []string{"@tsmith", "@tsmith2"})))
+
+			Ω(fakeSlackAPI.EnableUserCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error if the user is a full user", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{
+					ID:      "U1234",
+					Deleted: true,
+					Profile: slack.UserProfile{
+						Email: "user@example.com",
+					},
+				},
+			})
+
+			result, err := newEnableUser("enable-user user@example.com").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(HaveOccurred())
+			Ω(result.String()).Should(Equal("Failed to enable user 'user@example.com': Full users cannot be enabled."))
+
+			Ω(fakeSlackAPI.EnableUserCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error if the user is not disabled", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{
+					ID:           "U1234",
+					IsRestricted: true,
+					Profile: slack.UserProfile{
+						Email: "user@example.com",
+					},
+				},
+			})
+
+			result, err := newEnableUser("enable-user user@example.com").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(Equal(action.NewUserNotDisabledErr("user@example.com")))
+			Ω(result.String()).Should(Equal("Failed to enable user 'user@example.com': User 'user@example.com' is not disabled."))
+
+			Ω(fakeSlackAPI.EnableUserCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error when enabling the user fails", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{
+					ID:           "U1234",
+					Deleted:      true,
+					IsRestricted: true,
+					Profile: slack.UserProfile{
+						Email: "user@example.com",
+					},
+				},
+			})
+
+			fakeSlackAPI.EnableUserReturns(errors.New("failed"))
+
+			result, err := newEnableUser("enable-user user@example.com").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("failed"))
+			Ω(result.String()).Should(Equal("Failed to enable user 'user@example.com': failed"))
+		})
+	})
+
+	Describe("AuditMessage", func() {
+		It("names the role the user was given", func() {
+			fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{ID: "G1", Name: "design", IsMember: true}, nil)
+			stubUsers(fakeSlackAPI, []slack.User{
+				{
+					ID:                "U1234",
+					Name:              "tsmith",
+					Deleted:           true,
+					IsUltraRestricted: true,
+				},
+			})
+
+			fakeStore := &auditfakes.FakeStore{}
+			fakeStore.QueryReturns([]audit.Event{{
+				Target:          "U1234",
+				Outcome:         audit.OutcomeSucceeded,
+				TargetRole:      audit.RoleGuest,
+				TargetChannelID: "G1",
+			}}, nil)
+
+			a := action.NewEnableUser([]string{"@tsmith"}, false, fakeStore, "commander-name")
+			a.Do(c, fakeSlackAPI, fakeClock, logger)
+
+			ta, ok := a.(action.TargetedAction)
+			Ω(ok).Should(BeTrue())
+			Ω(ta.AuditMessage(fakeSlackAPI)).Should(Equal("@commander-name enabled user @tsmith as a single-channel guest in 'design'"))
+			Ω(ta.AuditTarget()).Should(Equal("U1234"))
+		})
+	})
+})
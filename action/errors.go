@@ -3,6 +3,9 @@ package action
 import (
 	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/pivotalservices/goulash/slackapi"
 )
 
 const (
@@ -10,14 +13,63 @@ const (
 	missingParameterErrFmt        = "Missing required %s parameter. See `%s help` for more information."
 	uninvitableDomainErrFmt       = "Users for the '%s' domain are unable to be invited through %s. %s"
 	userNotFoundErrFmt            = "Unable to find user matching '%s'."
+	userSuggestionsErrFmt         = " Did you mean %s?"
+	ambiguousUserErrFmt           = "Several users match '%s': %s. Try again with an email address or @username."
 	fullUserCannotBeErrFmt        = "Full users cannot be %s."
+	guestCannotBeErrFmt           = "Single-channel guests cannot be %s."
 	userIsAlreadyErrFmt           = "User is already a %s."
+	userIsNotErrFmt               = "User is not a %s."
+	userNotDisabledErrFmt         = "User '%s' is not disabled."
+	roleNotRecordedErrFmt         = "User '%s' was a single-channel guest, but no record was kept of the channel they were in when they were disabled. Enable them with `--restricted`, then `guestify` them from their channel."
 	cannotFromDirectMessageErrFmt = "Cannot %s from a direct message. Try again from a channel or group."
 	channelNotFoundErrFmt         = "Channel '#%s' not found."
+	notPermittedErrFmt            = "You are not permitted to use `%s %s`."
+	malformedInviteeErrFmt        = "Expected `email,firstname,lastname` but got `%s`."
+	bulkInviteFailedErrFmt        = "%d of %d invitations failed."
+	usageErrFmt                   = "%s Usage: `%s %s`"
+	guestExpiryDisabledErrFmt     = "Accounts invited through `%s` cannot be given an expiry, as no expiry store is configured."
+	accessRequestNotFoundErrFmt   = "Access request #%d not found."
+	accessRequestDecidedErrFmt    = "Access request #%d has already been %s by @%s."
+	notAccessRequestDeciderErrFmt = "Only members of #%s can decide on requests for access to it."
+	confirmationNotFoundErrFmt    = "Nothing of yours is awaiting confirmation as '%s'. It may have expired, been confirmed, or been cancelled; run the command again if you still want to."
+	userChangedErrFmt             = "'%s' no longer names the user you were asked to confirm, so nothing was changed. Run the command again to see who it names now."
+
+	missingParameterProblemFmt     = "Missing required %s parameter."
+	unexpectedParameterProblemFmt  = "Unexpected parameter '%s'."
+	unknownOptionProblemFmt        = "Unknown option '--%s'."
+	missingOptionValueProblemFmt   = "Missing %s for option '--%s'."
+	unterminatedQuoteProblem       = "Unterminated quote."
+	unknownInviteeTypeProblemFmt   = "Unknown type of user '%s'; expected 'guest' or 'restricted'."
+	noInviteesProblem              = "No one to invite. Give one `email,firstname,lastname` line per person after the command."
+	invalidExpiresProblemFmt       = "Expected --expires to be a time such as `30d` or `12h`, but got '%s'."
+	invalidAccessRequestProblemFmt = "Expected an access request number such as `42`, but got '%s'."
+	invalidSinceProblemFmt         = "Expected --since to be a time ago such as `72h` or `7d`, or a date such as `2016-05-01`, but got '%s'."
+	invalidInactiveProblemFmt      = "Expected --inactive to be a time such as `60d` or `12h`, but got '%s'."
 )
 
 var errUnauthorized = errors.New("Sorry, you don't have access to that function.")
 
+var errOwnAccessRequest = errors.New("You cannot decide on your own access request.")
+
+// slackErrMessages explain the errors returned by Slack's Web API which there
+// is something to be done about, by their codes.
+var slackErrMessages = map[string]string{
+	slackapi.ErrorAlreadyInvited:      "They have already been invited to Slack.",
+	slackapi.ErrorAlreadyInTeam:       "They already have an account on this Slack team.",
+	slackapi.ErrorUserDisabled:        "Their account is disabled. Re-enable it with `enable-user` first.",
+	slackapi.ErrorRateLimited:         "Slack is limiting how quickly Goulash can make requests. Wait a minute, then try again.",
+	slackapi.ErrorNotAuthed:           "Goulash could not sign in to Slack. Ask whoever runs Goulash to check its Slack token.",
+	slackapi.ErrorInvalidAuth:         "Goulash could not sign in to Slack. Ask whoever runs Goulash to check its Slack token.",
+	slackapi.ErrorAccountInactive:     "Goulash could not sign in to Slack, as its Slack token belongs to a disabled account. Ask whoever runs Goulash to replace it.",
+	slackapi.ErrorTokenRevoked:        "Goulash could not sign in to Slack, as its Slack token has been revoked. Ask whoever runs Goulash to replace it.",
+	slackapi.ErrorMissingScope:        "Goulash's Slack token is not allowed to do that. Ask whoever runs Goulash to grant it the missing permission.",
+	slackapi.ErrorNotAllowedTokenType: "Goulash's Slack token is not allowed to do that. Ask whoever runs Goulash to use an admin's user token.",
+	slackapi.ErrorInvalidEmail:        "Slack does not accept that email address.",
+	slackapi.ErrorRestrictedAction:    "This Slack team's settings do not allow that. Ask an owner of the team to do it instead.",
+	slackapi.ErrorIsArchived:          "The channel has been archived.",
+	slackapi.ErrorNotInChannel:        "Goulash is not a member of the channel. Invite it to the channel, then try again.",
+}
+
 type channelNotVisibleErr struct {
 	slackUserID string
 }
@@ -73,17 +125,48 @@ func (e missingEmailParameterErr) Error() string {
 
 type userNotFoundErr struct {
 	searchParam string
+	suggestions string
 }
 
-// NewUserNotFoundErr returns an error
-func NewUserNotFoundErr(searchParam string) error {
+// NewUserNotFoundErr returns an error, suggesting the given users instead
+func NewUserNotFoundErr(searchParam string, suggestions ...string) error {
 	return userNotFoundErr{
 		searchParam: searchParam,
+		suggestions: strings.Join(suggestions, ", "),
 	}
 }
 
 func (e userNotFoundErr) Error() string {
-	return fmt.Sprintf(userNotFoundErrFmt, e.searchParam)
+	message := fmt.Sprintf(userNotFoundErrFmt, e.searchParam)
+	if e.suggestions != "" {
+		message += fmt.Sprintf(userSuggestionsErrFmt, e.suggestions)
+	}
+
+	return message
+}
+
+// IsUserNotFoundErr returns true if the error is one returned by
+// NewUserNotFoundErr, whatever it suggests
+func IsUserNotFoundErr(err error) bool {
+	_, ok := err.(userNotFoundErr)
+	return ok
+}
+
+type ambiguousUserErr struct {
+	searchParam string
+	matches     string
+}
+
+// NewAmbiguousUserErr returns an error
+func NewAmbiguousUserErr(searchParam string, matches []string) error {
+	return ambiguousUserErr{
+		searchParam: searchParam,
+		matches:     strings.Join(matches, ", "),
+	}
+}
+
+func (e ambiguousUserErr) Error() string {
+	return fmt.Sprintf(ambiguousUserErrFmt, e.searchParam, e.matches)
 }
 
 type fullUserCannotBeErr struct {
@@ -101,6 +184,21 @@ func (e fullUserCannotBeErr) Error() string {
 	return fmt.Sprintf(fullUserCannotBeErrFmt, e.verb)
 }
 
+type guestCannotBeErr struct {
+	verb string
+}
+
+// NewGuestCannotBeErr returns an error
+func NewGuestCannotBeErr(verb string) error {
+	return guestCannotBeErr{
+		verb: verb,
+	}
+}
+
+func (e guestCannotBeErr) Error() string {
+	return fmt.Sprintf(guestCannotBeErrFmt, e.verb)
+}
+
 type userIsAlreadyErr struct {
 	noun string
 }
@@ -116,6 +214,51 @@ func (e userIsAlreadyErr) Error() string {
 	return fmt.Sprintf(userIsAlreadyErrFmt, e.noun)
 }
 
+type userIsNotErr struct {
+	noun string
+}
+
+// NewUserIsNotErr returns an error
+func NewUserIsNotErr(noun string) error {
+	return userIsNotErr{
+		noun: noun,
+	}
+}
+
+func (e userIsNotErr) Error() string {
+	return fmt.Sprintf(userIsNotErrFmt, e.noun)
+}
+
+type roleNotRecordedErr struct {
+	searchParam string
+}
+
+// NewRoleNotRecordedErr returns an error
+func NewRoleNotRecordedErr(searchParam string) error {
+	return roleNotRecordedErr{
+		searchParam: searchParam,
+	}
+}
+
+func (e roleNotRecordedErr) Error() string {
+	return fmt.Sprintf(roleNotRecordedErrFmt, e.searchParam)
+}
+
+type userNotDisabledErr struct {
+	searchParam string
+}
+
+// NewUserNotDisabledErr returns an error
+func NewUserNotDisabledErr(searchParam string) error {
+	return userNotDisabledErr{
+		searchParam: searchParam,
+	}
+}
+
+func (e userNotDisabledErr) Error() string {
+	return fmt.Sprintf(userNotDisabledErrFmt, e.searchParam)
+}
+
 type cannotFromDirectMessageErr struct {
 	verb string
 }
@@ -145,3 +288,186 @@ func NewChannelNotFoundErr(channelName string) error {
 func (e channelNotFoundErr) Error() string {
 	return fmt.Sprintf(channelNotFoundErrFmt, e.channelName)
 }
+
+type notPermittedErr struct {
+	command           string
+	slackSlashCommand string
+}
+
+// NewNotPermittedErr returns an error
+func NewNotPermittedErr(command string, slackSlashCommand string) error {
+	return notPermittedErr{
+		command:           command,
+		slackSlashCommand: slackSlashCommand,
+	}
+}
+
+func (e notPermittedErr) Error() string {
+	return fmt.Sprintf(notPermittedErrFmt, e.slackSlashCommand, e.command)
+}
+
+type malformedInviteeErr struct {
+	line string
+}
+
+// NewMalformedInviteeErr returns an error
+func NewMalformedInviteeErr(line string) error {
+	return malformedInviteeErr{
+		line: line,
+	}
+}
+
+func (e malformedInviteeErr) Error() string {
+	return fmt.Sprintf(malformedInviteeErrFmt, e.line)
+}
+
+type bulkInviteFailedErr struct {
+	failed int
+	total  int
+}
+
+// NewBulkInviteFailedErr returns an error
+func NewBulkInviteFailedErr(failed int, total int) error {
+	return bulkInviteFailedErr{
+		failed: failed,
+		total:  total,
+	}
+}
+
+func (e bulkInviteFailedErr) Error() string {
+	return fmt.Sprintf(bulkInviteFailedErrFmt, e.failed, e.total)
+}
+
+type usageErr struct {
+	problem           string
+	usage             string
+	slackSlashCommand string
+}
+
+// NewUsageErr returns an error
+func NewUsageErr(problem string, usage string, slackSlashCommand string) error {
+	return usageErr{
+		problem:           problem,
+		usage:             usage,
+		slackSlashCommand: slackSlashCommand,
+	}
+}
+
+func (e usageErr) Error() string {
+	return fmt.Sprintf(usageErrFmt, e.problem, e.slackSlashCommand, e.usage)
+}
+
+type guestExpiryDisabledErr struct {
+	slackSlashCommand string
+}
+
+// NewGuestExpiryDisabledErr returns an error
+func NewGuestExpiryDisabledErr(slackSlashCommand string) error {
+	return guestExpiryDisabledErr{
+		slackSlashCommand: slackSlashCommand,
+	}
+}
+
+func (e guestExpiryDisabledErr) Error() string {
+	return fmt.Sprintf(guestExpiryDisabledErrFmt, e.slackSlashCommand)
+}
+
+type accessRequestNotFoundErr struct {
+	id int
+}
+
+// NewAccessRequestNotFoundErr returns an error
+func NewAccessRequestNotFoundErr(id int) error {
+	return accessRequestNotFoundErr{
+		id: id,
+	}
+}
+
+func (e accessRequestNotFoundErr) Error() string {
+	return fmt.Sprintf(accessRequestNotFoundErrFmt, e.id)
+}
+
+type accessRequestDecidedErr struct {
+	id          int
+	status      string
+	deciderName string
+}
+
+// NewAccessRequestDecidedErr returns an error
+func NewAccessRequestDecidedErr(id int, status string, deciderName string) error {
+	return accessRequestDecidedErr{
+		id:          id,
+		status:      status,
+		deciderName: deciderName,
+	}
+}
+
+func (e accessRequestDecidedErr) Error() string {
+	return fmt.Sprintf(accessRequestDecidedErrFmt, e.id, e.status, e.deciderName)
+}
+
+type notAccessRequestDeciderErr struct {
+	channelName string
+}
+
+// NewNotAccessRequestDeciderErr returns an error
+func NewNotAccessRequestDeciderErr(channelName string) error {
+	return notAccessRequestDeciderErr{
+		channelName: channelName,
+	}
+}
+
+func (e notAccessRequestDeciderErr) Error() string {
+	return fmt.Sprintf(notAccessRequestDeciderErrFmt, e.channelName)
+}
+
+type confirmationNotFoundErr struct {
+	token string
+}
+
+// NewConfirmationNotFoundErr returns an error
+func NewConfirmationNotFoundErr(token string) error {
+	return confirmationNotFoundErr{
+		token: token,
+	}
+}
+
+func (e confirmationNotFoundErr) Error() string {
+	return fmt.Sprintf(confirmationNotFoundErrFmt, e.token)
+}
+
+type userChangedErr struct {
+	searchVal string
+}
+
+// NewUserChangedErr returns an error
+func NewUserChangedErr(searchVal string) error {
+	return userChangedErr{
+		searchVal: searchVal,
+	}
+}
+
+func (e userChangedErr) Error() string {
+	return fmt.Sprintf(userChangedErrFmt, e.searchVal)
+}
+
+type slackErr struct {
+	code string
+}
+
+// NewSlackErr returns an error explaining the given error from Slack's Web
+// API, or the error itself when there is nothing more to say about it
+func NewSlackErr(err error) error {
+	code := slackapi.ErrorCode(err)
+	if _, ok := slackErrMessages[code]; !ok {
+		return err
+	}
+
+	return slackErr{
+		code: code,
+	}
+}
+
+func (e slackErr) Error() string {
+	return slackErrMessages[e.code]
+}
@@ -0,0 +1,246 @@
+package action
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/slack"
+)
+
+// maxUserSuggestions is how many of the closest users are suggested when no
+// user matches.
+const maxUserSuggestions = 3
+
+// minPartialUserMatch is how long a search must be before users containing it
+// are suggested.
+const minPartialUserMatch = 3
+
+// FindUser returns the Slack user with the given email address, @username,
+// user ID, <@mention>, real name or display name, which may also be given as
+// @displayname, ignoring case. If no user matches, the
+// error suggests the closest ones. If several do, such as two people with the
+// same real name, the first is returned, unless refuseAmbiguous is true, as it
+// should be for every command which changes a user, when it refuses to guess.
+// Users are fetched a page at a time, stopping once the user is found unless
+// refuseAmbiguous is true, when every page must be checked for another match.
+func FindUser(searchVal string, api slackapi.SlackAPI, refuseAmbiguous bool) (slack.User, error) {
+	if directory, ok := api.(slackapi.Directory); ok {
+		lookup := directory.UserByEmail
+		if strings.HasPrefix(searchVal, "@") {
+			lookup = directory.UserByName
+		}
+
+		user, found, err := lookup(searchVal)
+		if err != nil {
+			return slack.User{}, err
+		}
+
+		if found {
+			return user, nil
+		}
+	}
+
+	var users, matched []slack.User
+	err := slackapi.EachUser(api, func(user slack.User) bool {
+		users = append(users, user)
+		if userMatches(searchVal, user) {
+			matched = append(matched, user)
+		}
+
+		return len(matched) == 0 || refuseAmbiguous
+	})
+	if err != nil {
+		return slack.User{}, err
+	}
+
+	switch {
+	case len(matched) == 1, len(matched) > 1 && !refuseAmbiguous:
+		return matched[0], nil
+	case len(matched) > 1:
+		return slack.User{}, NewAmbiguousUserErr(searchVal, userNames(matched))
+	}
+
+	return slack.User{}, NewUserNotFoundErr(searchVal, userNames(closestUsers(searchVal, users))...)
+}
+
+// findUserToChange is FindUser refusing ambiguous matches, for commands which
+// change the user found. As FindUser may use a Directory's cached users, the
+// user is then fetched from Slack again, so that the checks made before
+// changing them see who they are now. When pinnedUserID is set, it returns an
+// error unless searchVal still names the user with that ID.
+func findUserToChange(searchVal string, pinnedUserID string, api slackapi.SlackAPI) (slack.User, error) {
+	found, err := FindUser(searchVal, api, true)
+	if err != nil {
+		return slack.User{}, err
+	}
+
+	if pinnedUserID != "" && found.ID != pinnedUserID {
+		return slack.User{}, NewUserChangedErr(searchVal)
+	}
+
+	user, err := api.GetUserInfo(found.ID)
+	if err != nil {
+		return slack.User{}, err
+	}
+
+	return *user, nil
+}
+
+// userTarget returns the audit target of an action on a user: their ID once
+// they have been found, so that the audit command finds it however the user
+// is given, or else the user as they were given.
+func userTarget(userID string, searchVal string) string {
+	if userID == "" {
+		return searchVal
+	}
+
+	return userID
+}
+
+// userMatches returns true if the user has an email address, username, ID,
+// real name or display name which is the same as searchVal, ignoring case.
+func userMatches(searchVal string, user slack.User) bool {
+	query := strings.ToLower(mentionedUserID(searchVal))
+
+	for _, key := range userKeys(user) {
+		if key == query {
+			return true
+		}
+	}
+
+	return false
+}
+
+// closestUsers returns up to maxUserSuggestions users with an email address,
+// username, real name or display name containing searchVal, or differing from
+// it by a few characters, closest first.
+func closestUsers(searchVal string, users []slack.User) []slack.User {
+	query := strings.ToLower(strings.TrimPrefix(mentionedUserID(searchVal), "@"))
+	if query == "" {
+		return nil
+	}
+
+	maxDistance := len(query) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	var candidates []userCandidate
+	for _, user := range users {
+		distance := -1
+		for _, key := range userKeys(user) {
+			keyDistance := editDistance(query, strings.TrimPrefix(key, "@"))
+			if len(query) >= minPartialUserMatch && strings.Contains(key, query) {
+				keyDistance = 0
+			}
+
+			if distance < 0 || keyDistance < distance {
+				distance = keyDistance
+			}
+		}
+
+		if distance >= 0 && distance <= maxDistance {
+			candidates = append(candidates, userCandidate{user: user, distance: distance})
+		}
+	}
+
+	sort.Stable(byDistance(candidates))
+
+	var closest []slack.User
+	for i := 0; i < len(candidates) && i < maxUserSuggestions; i++ {
+		closest = append(closest, candidates[i].user)
+	}
+
+	return closest
+}
+
+// userKeys returns the lower-cased values a user may be found by.
+func userKeys(user slack.User) []string {
+	var keys []string
+	for _, key := range []string{
+		user.ID,
+		user.Profile.Email,
+		"@" + user.Name,
+		user.Name,
+		user.RealName,
+		user.Profile.RealName,
+		"@" + user.Profile.DisplayName,
+		user.Profile.DisplayName,
+	} {
+		if key != "" && key != "@" {
+			keys = append(keys, strings.ToLower(key))
+		}
+	}
+
+	return keys
+}
+
+// mentionedUserID returns the ID in a mention such as <@U1234> or
+// <@U1234|tsmith>, which Slack sends in place of @tsmith when it parses a
+// command's text, or searchVal itself if it is not a mention.
+func mentionedUserID(searchVal string) string {
+	if !strings.HasPrefix(searchVal, "<@") || !strings.HasSuffix(searchVal, ">") {
+		return searchVal
+	}
+
+	id := strings.TrimSuffix(strings.TrimPrefix(searchVal, "<@"), ">")
+	if bar := strings.Index(id, "|"); bar >= 0 {
+		id = id[:bar]
+	}
+
+	return id
+}
+
+func userNames(users []slack.User) []string {
+	var names []string
+	for _, user := range users {
+		names = append(names, "@"+user.Name)
+	}
+
+	return names
+}
+
+// editDistance returns how many characters must be inserted, deleted or
+// replaced to turn a into b.
+func editDistance(a string, b string) int {
+	ar, br := []rune(a), []rune(b)
+
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(br)]
+}
+
+type userCandidate struct {
+	user     slack.User
+	distance int
+}
+
+type byDistance []userCandidate
+
+func (c byDistance) Len() int           { return len(c) }
+func (c byDistance) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c byDistance) Less(i, j int) bool { return c[i].distance < c[j].distance }
@@ -0,0 +1,145 @@
+package action_test
+
+import (
+	"errors"
+
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FindUser", func() {
+	var fakeSlackAPI *slackapifakes.FakeSlackAPI
+
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeSlackAPI.GetUsersPageReturns([]slack.User{
+			{ID: "U1", Name: "tsmith", RealName: "Tom Smith", Profile: slack.UserProfile{Email: "tsmith@example.com"}},
+			{ID: "U2", Name: "tsmith2", RealName: "Tom Smith", Profile: slack.UserProfile{Email: "tom.smith@partner.com"}},
+			{ID: "U3", Name: "jdoe", RealName: "Jane Doe", Profile: slack.UserProfile{Email: "jdoe@example.com", DisplayName: "Janie"}},
+		}, "", nil)
+	})
+
+	It("finds a user by email address, ignoring case", func() {
+		user, err := action.FindUser("JDoe@Example.com", fakeSlackAPI, true)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(user.ID).Should(Equal("U3"))
+	})
+
+	It("finds a user by username, with or without the @", func() {
+		user, err := action.FindUser("@JDOE", fakeSlackAPI, true)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(user.ID).Should(Equal("U3"))
+
+		user, err = action.FindUser("jdoe", fakeSlackAPI, true)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(user.ID).Should(Equal("U3"))
+	})
+
+	It("finds a user by ID or mention", func() {
+		for _, searchVal := range []string{"U3", "<@U3>", "<@U3|jdoe>"} {
+			user, err := action.FindUser(searchVal, fakeSlackAPI, true)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(user.ID).Should(Equal("U3"))
+		}
+	})
+
+	It("finds a user by real name", func() {
+		user, err := action.FindUser("jane doe", fakeSlackAPI, true)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(user.ID).Should(Equal("U3"))
+	})
+
+	It("finds a user by display name, with or without the @", func() {
+		user, err := action.FindUser("@janie", fakeSlackAPI, true)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(user.ID).Should(Equal("U3"))
+
+		user, err = action.FindUser("Janie", fakeSlackAPI, true)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(user.ID).Should(Equal("U3"))
+	})
+
+	Context("when several users match", func() {
+		It("returns the first when not refusing ambiguous matches", func() {
+			user, err := action.FindUser("Tom Smith", fakeSlackAPI, false)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(user.ID).Should(Equal("U1"))
+		})
+
+		It("refuses to guess when refusing ambiguous matches", func() {
+			_, err := action.FindUser("Tom Smith", fakeSlackAPI, true)
+			Ω(err).Should(Equal(action.NewAmbiguousUserErr("Tom Smith", []string{"@tsmith", "@tsmith2"})))
+			Ω(err).Should(MatchError("Several users match 'Tom Smith': @tsmith, @tsmith2. Try again with an email address or @username."))
+		})
+	})
+
+	Context("when no user matches", func() {
+		It("suggests the closest users", func() {
+			_, err := action.FindUser("@tsmtih", fakeSlackAPI, false)
+			Ω(action.IsUserNotFoundErr(err)).Should(BeTrue())
+			Ω(err).Should(MatchError("Unable to find user matching '@tsmtih'. Did you mean @tsmith?"))
+		})
+
+		It("suggests users containing the search", func() {
+			_, err := action.FindUser("doe", fakeSlackAPI, false)
+			Ω(err).Should(MatchError("Unable to find user matching 'doe'. Did you mean @jdoe?"))
+		})
+
+		It("does not return a user who only nearly matches", func() {
+			_, err := action.FindUser("jdoe@example.org", fakeSlackAPI, false)
+			Ω(err).Should(MatchError("Unable to find user matching 'jdoe@example.org'. Did you mean @jdoe?"))
+		})
+
+		It("suggests no one when no user is close", func() {
+			_, err := action.FindUser("@someone", fakeSlackAPI, false)
+			Ω(err).Should(Equal(action.NewUserNotFoundErr("@someone")))
+			Ω(err).Should(MatchError("Unable to find user matching '@someone'."))
+		})
+	})
+
+	Context("when Slack returns the users a page at a time", func() {
+		BeforeEach(func() {
+			fakeSlackAPI.GetUsersPageStub = func(cursor string) ([]slack.User, string, error) {
+				switch cursor {
+				case "":
+					return []slack.User{{ID: "U1", Name: "tsmith", RealName: "Tom Smith"}}, "page-2", nil
+				case "page-2":
+					return []slack.User{{ID: "U2", Name: "jdoe", RealName: "Jane Doe"}}, "page-3", nil
+				default:
+					return []slack.User{{ID: "U3", Name: "tsmith2", RealName: "Tom Smith"}}, "", nil
+				}
+			}
+		})
+
+		It("finds a user past the first page without fetching the rest", func() {
+			user, err := action.FindUser("@jdoe", fakeSlackAPI, false)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(user.ID).Should(Equal("U2"))
+
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(2))
+		})
+
+		It("checks every page when refusing ambiguous matches", func() {
+			_, err := action.FindUser("Tom Smith", fakeSlackAPI, true)
+			Ω(err).Should(Equal(action.NewAmbiguousUserErr("Tom Smith", []string{"@tsmith", "@tsmith2"})))
+
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(3))
+		})
+
+		It("suggests users from every page", func() {
+			_, err := action.FindUser("@tsmith3", fakeSlackAPI, false)
+			Ω(err).Should(MatchError("Unable to find user matching '@tsmith3'. Did you mean @tsmith, @tsmith2?"))
+		})
+	})
+
+	It("returns an error if the users cannot be listed", func() {
+		fakeSlackAPI.GetUsersPageReturns(nil, "", errors.New("get-users-err"))
+
+		_, err := action.FindUser("@jdoe", fakeSlackAPI, false)
+		Ω(err).Should(MatchError("get-users-err"))
+	})
+})
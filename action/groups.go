@@ -4,12 +4,25 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/pivotal-golang/clock"
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotalservices/goulash/config"
 	"github.com/pivotalservices/goulash/slackapi"
 	"github.com/pivotalservices/slack"
 )
 
+func init() {
+	Register(Command{
+		Name: "groups",
+		Describe: func(c config.Config) string {
+			return fmt.Sprintf("List the groups that @%s is in", c.SlackUserID())
+		},
+		New: func(r Request) Action {
+			return NewGroups(r.CommanderName, r.CommanderID)
+		},
+	})
+}
+
 type groups struct {
 	commanderName string
 	commanderID   string
@@ -27,21 +40,22 @@ func NewGroups(commanderName string, commanderID string) Action {
 func (g groups) Do(
 	c config.Config,
 	api slackapi.SlackAPI,
+	clock clock.Clock,
 	logger lager.Logger,
-) (string, error) {
+) (slackapi.Message, error) {
 	logger = logger.Session("do")
 
 	err := g.check(api, logger)
 	if err != nil {
 		logger.Error("failed", err)
-		return g.failureMessage(err, c), err
+		return slackapi.NewErrorMessage(g.failureMessage(err, c)), err
 	}
 
 	excludeArchived := true
-	groups, err := api.GetGroups(excludeArchived)
+	groups, err := api.GetConversations([]string{slackapi.PrivateChannel}, excludeArchived)
 	if err != nil {
 		logger.Error("failed", err)
-		return g.failureMessage(err, c), err
+		return slackapi.NewErrorMessage(g.failureMessage(err, c)), err
 	}
 
 	var groupNames []string
@@ -62,13 +76,13 @@ func (g groups) Do(
 	_, _, dmID, err := api.OpenIMChannel(g.commanderID)
 	if err != nil {
 		logger.Error("failed", err)
-		return g.failureMessage(err, c), err
+		return slackapi.NewErrorMessage(g.failureMessage(err, c)), err
 	}
 
 	_, _, err = api.PostMessage(dmID, messageText, postMessageParams)
 	if err != nil {
 		logger.Error("failed", err)
-		return g.failureMessage(err, c), err
+		return slackapi.NewErrorMessage(g.failureMessage(err, c)), err
 	}
 
 	logger.Info("succeeded")
@@ -78,7 +92,7 @@ func (g groups) Do(
 		c.SlackUserID(),
 	)
 
-	return result, nil
+	return slackapi.NewTextMessage(result), nil
 }
 
 func (g groups) AuditMessage(
@@ -94,7 +108,7 @@ func (g groups) failureMessage(
 	return fmt.Sprintf(
 		"Failed to list the groups %s is in: %s",
 		config.SlackUserID(),
-		err.Error(),
+		NewSlackErr(err).Error(),
 	)
 }
 
@@ -2,7 +2,9 @@ package action_test
 
 import (
 	"errors"
+	"time"
 
+	"github.com/pivotal-golang/clock/fakeclock"
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotalservices/goulash/action"
 	"github.com/pivotalservices/goulash/config"
@@ -18,20 +20,22 @@ var _ = Describe("Groups", func() {
 	var (
 		c            config.Config
 		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeClock    *fakeclock.FakeClock
 		logger       lager.Logger
 	)
 
 	BeforeEach(func() {
 		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
-		c = config.NewLocalConfig(
-			"slack-auth-token",
-			"/slack-slash-command",
-			"slack-team-name",
-			"slack-user-id",
-			"audit-log-channel-id",
-			"uninvitable-domain.com",
-			"uninvitable-domain-message",
-		)
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackAuthToken:     "slack-auth-token",
+			SlackSlashCommand:  "/slack-slash-command",
+			SlackTeamName:      "slack-team-name",
+			SlackUserID:        "slack-user-id",
+			AuditLogChannelID:  "audit-log-channel-id",
+			UninvitableDomain:  "uninvitable-domain.com",
+			UninvitableMessage: "uninvitable-domain-message",
+		})
 
 		logger = lager.NewLogger("testlogger")
 	})
@@ -51,10 +55,10 @@ var _ = Describe("Groups", func() {
 				"groups",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("Sorry, you don't have access to that function."))
-			Ω(result).Should(Equal("Failed to list the groups slack-user-id is in: Sorry, you don't have access to that function."))
+			Ω(result.String()).Should(Equal("Failed to list the groups slack-user-id is in: Sorry, you don't have access to that function."))
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(0))
 		})
@@ -73,10 +77,10 @@ var _ = Describe("Groups", func() {
 				"groups",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("Sorry, you don't have access to that function."))
-			Ω(result).Should(Equal("Failed to list the groups slack-user-id is in: Sorry, you don't have access to that function."))
+			Ω(result.String()).Should(Equal("Failed to list the groups slack-user-id is in: Sorry, you don't have access to that function."))
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(0))
 		})
@@ -91,10 +95,10 @@ var _ = Describe("Groups", func() {
 				"groups",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("get-user-info-err"))
-			Ω(result).Should(Equal("Failed to list the groups slack-user-id is in: get-user-info-err"))
+			Ω(result.String()).Should(Equal("Failed to list the groups slack-user-id is in: get-user-info-err"))
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(0))
 		})
@@ -110,10 +114,10 @@ var _ = Describe("Groups", func() {
 				"groups",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("open-im-channel-err"))
-			Ω(result).Should(Equal("Failed to list the groups slack-user-id is in: open-im-channel-err"))
+			Ω(result.String()).Should(Equal("Failed to list the groups slack-user-id is in: open-im-channel-err"))
 		})
 
 		It("attempts to get groups", func() {
@@ -126,11 +130,12 @@ var _ = Describe("Groups", func() {
 				"groups",
 			)
 
-			_, err := a.Do(c, fakeSlackAPI, logger)
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
 
-			Ω(fakeSlackAPI.GetGroupsCallCount()).Should(Equal(1))
-			actualExcludeArchived := fakeSlackAPI.GetGroupsArgsForCall(0)
+			Ω(fakeSlackAPI.GetConversationsCallCount()).Should(Equal(1))
+			actualTypes, actualExcludeArchived := fakeSlackAPI.GetConversationsArgsForCall(0)
+			Ω(actualTypes).Should(Equal([]string{slackapi.PrivateChannel}))
 			Ω(actualExcludeArchived).Should(BeTrue())
 		})
 
@@ -144,7 +149,7 @@ var _ = Describe("Groups", func() {
 				"groups",
 			)
 
-			_, err := a.Do(c, fakeSlackAPI, logger)
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
 
 			Ω(fakeSlackAPI.OpenIMChannelCallCount()).Should(Equal(1))
@@ -154,10 +159,10 @@ var _ = Describe("Groups", func() {
 
 		It("attempts to send a direct message with a sorted list of the returned groups", func() {
 			fakeSlackAPI.GetUserInfoReturns(&slack.User{}, nil)
-			fakeSlackAPI.GetGroupsReturns([]slack.Group{
-				newGroup("group-2", "slack-user-id"),
-				newGroup("group-1", "slack-user-id"),
-				newGroup("group-3", "slack-user-id"),
+			fakeSlackAPI.GetConversationsReturns([]slackapi.Conversation{
+				newGroup("group-2"),
+				newGroup("group-1"),
+				newGroup("group-3"),
 			}, nil)
 			fakeSlackAPI.OpenIMChannelReturns(false, false, "dm-id", nil)
 
@@ -168,7 +173,7 @@ var _ = Describe("Groups", func() {
 				"groups",
 			)
 
-			_, err := a.Do(c, fakeSlackAPI, logger)
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(1))
@@ -181,8 +186,8 @@ var _ = Describe("Groups", func() {
 
 		It("returns a positive result and nil on success", func() {
 			fakeSlackAPI.GetUserInfoReturns(&slack.User{}, nil)
-			fakeSlackAPI.GetGroupsReturns([]slack.Group{
-				newGroup("group-1", "slack-user-id"),
+			fakeSlackAPI.GetConversationsReturns([]slackapi.Conversation{
+				newGroup("group-1"),
 			}, nil)
 
 			a := action.New(
@@ -192,17 +197,17 @@ var _ = Describe("Groups", func() {
 				"groups",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
-			Ω(result).Should(Equal("Successfully sent a list of the groups @slack-user-id is in as a direct message."))
+			Ω(result.String()).Should(Equal("Successfully sent a list of the groups @slack-user-id is in as a direct message."))
 		})
 
 		It("returns an error if the PostMessage call fails", func() {
 			fakeSlackAPI.GetUserInfoReturns(&slack.User{}, nil)
-			fakeSlackAPI.GetGroupsReturns([]slack.Group{
-				newGroup("group-2", "slack-user-id"),
-				newGroup("group-1", "slack-user-id"),
-				newGroup("group-3", "slack-user-id"),
+			fakeSlackAPI.GetConversationsReturns([]slackapi.Conversation{
+				newGroup("group-2"),
+				newGroup("group-1"),
+				newGroup("group-3"),
 			}, nil)
 
 			fakeSlackAPI.PostMessageReturns("channel", "timestamp", errors.New("failed"))
@@ -214,15 +219,15 @@ var _ = Describe("Groups", func() {
 				"groups",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("failed"))
-			Ω(result).Should(Equal("Failed to list the groups slack-user-id is in: failed"))
+			Ω(result.String()).Should(Equal("Failed to list the groups slack-user-id is in: failed"))
 		})
 
-		It("returns an error if the GetGroups call fails", func() {
+		It("returns an error if the GetConversations call fails", func() {
 			fakeSlackAPI.GetUserInfoReturns(&slack.User{}, nil)
-			fakeSlackAPI.GetGroupsReturns([]slack.Group{}, errors.New("failed"))
+			fakeSlackAPI.GetConversationsReturns([]slackapi.Conversation{}, errors.New("failed"))
 
 			a := action.New(
 				slackapi.NewChannel("channel-name", "channel-id"),
@@ -231,10 +236,10 @@ var _ = Describe("Groups", func() {
 				"groups",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("failed"))
-			Ω(result).Should(Equal("Failed to list the groups slack-user-id is in: failed"))
+			Ω(result.String()).Should(Equal("Failed to list the groups slack-user-id is in: failed"))
 		})
 	})
 
@@ -255,9 +260,6 @@ var _ = Describe("Groups", func() {
 	})
 })
 
-func newGroup(name, member string) slack.Group {
-	group := slack.Group{IsGroup: true}
-	group.Name = name
-	group.Members = []string{member}
-	return group
+func newGroup(name string) slackapi.Conversation {
+	return slackapi.Conversation{Name: name, IsPrivate: true, IsMember: true}
 }
@@ -3,16 +3,37 @@ package action
 import (
 	"fmt"
 
+	"github.com/pivotal-golang/clock"
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotalservices/goulash/config"
 	"github.com/pivotalservices/goulash/slackapi"
 	"github.com/pivotalservices/slack"
 )
 
+func init() {
+	Register(Command{
+		Name:        "guestify",
+		Params:      []Param{{Name: "email|@username"}},
+		Description: "Convert a Restricted Account to a Single-Channel Guest",
+		Permission:  &config.PolicyRule{Admins: true},
+		Mutating:    true,
+		Destructive: true,
+		New: func(r Request) Action {
+			return NewGuestify(r.Params, r.Channel, r.CommanderName)
+		},
+	})
+}
+
 type guestify struct {
 	params          []string
 	channel         slackapi.Channel
 	guestifyingUser string
+
+	// userID is the ID of the user, once they have been found.
+	userID string
+
+	// pinnedUserID, when set, is the only user the command may act on.
+	pinnedUserID string
 }
 
 func (g guestify) searchVal() string {
@@ -27,9 +48,7 @@ func NewGuestify(
 	guestifyingUser string,
 ) Action {
 	guestifyParams := []string{""}
-	for i := range params {
-		guestifyParams[i] = params[i]
-	}
+	copy(guestifyParams, params)
 
 	return &guestify{
 		params:          guestifyParams,
@@ -38,11 +57,16 @@ func NewGuestify(
 	}
 }
 
-func (g guestify) Do(
+func (g *guestify) pinUser(userID string) {
+	g.pinnedUserID = userID
+}
+
+func (g *guestify) Do(
 	config config.Config,
 	api slackapi.SlackAPI,
+	clock clock.Clock,
 	logger lager.Logger,
-) (string, error) {
+) (slackapi.Message, error) {
 	logger = logger.Session("do")
 
 	searchVal := g.searchVal()
@@ -50,9 +74,11 @@ func (g guestify) Do(
 	user, err := g.check(searchVal, config, api, logger)
 	if err != nil {
 		logger.Error("check-failed", err)
-		return g.failureMessage(err), err
+		return slackapi.NewErrorMessage(g.failureMessage(err)), err
 	}
 
+	g.userID = user.ID
+
 	err = api.SetUltraRestricted(
 		config.SlackTeamName(),
 		user.ID,
@@ -60,17 +86,17 @@ func (g guestify) Do(
 	)
 	if err != nil {
 		logger.Error("failed-guestifying", err)
-		return g.failureMessage(err), err
+		return slackapi.NewErrorMessage(g.failureMessage(err)), err
 	}
 
-	return fmt.Sprintf("Successfully guestified user %s", searchVal), nil
+	return slackapi.NewTextMessage(fmt.Sprintf("Successfully guestified user %s", searchVal)), nil
 }
 
 func (g guestify) failureMessage(err error) string {
 	return fmt.Sprintf(
 		"Failed to guestify user '%s': %s",
 		g.searchVal(),
-		err.Error(),
+		NewSlackErr(err).Error(),
 	)
 }
 
@@ -82,6 +108,10 @@ func (g guestify) AuditMessage(api slackapi.SlackAPI) string {
 	)
 }
 
+func (g *guestify) AuditTarget() string {
+	return userTarget(g.userID, g.searchVal())
+}
+
 func (g guestify) check(
 	searchVal string,
 	config config.Config,
@@ -94,7 +124,7 @@ func (g guestify) check(
 		return slack.User{}, NewCannotFromDirectMessageErr("guestify")
 	}
 
-	user, err := findUser(searchVal, api)
+	user, err := findUserToChange(searchVal, g.pinnedUserID, api)
 	if err != nil {
 		return slack.User{}, err
 	}
@@ -2,7 +2,9 @@ package action_test
 
 import (
 	"errors"
+	"time"
 
+	"github.com/pivotal-golang/clock/fakeclock"
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotalservices/goulash/action"
 	"github.com/pivotalservices/goulash/config"
@@ -19,142 +21,143 @@ var _ = Describe("Guestify", func() {
 		var (
 			c            config.Config
 			fakeSlackAPI *slackapifakes.FakeSlackAPI
+			fakeClock    *fakeclock.FakeClock
 			logger       lager.Logger
 		)
 
 		BeforeEach(func() {
 			fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+			fakeClock = fakeclock.NewFakeClock(time.Now())
 			logger = lager.NewLogger("testlogger")
-			c = config.NewLocalConfig(
-				"slack-auth-token",
-				"/slack-slash-command",
-				"slack-team-name",
-				"slack-user-id",
-				"",
-				"uninvitable-domain.com",
-				"uninvitable-domain-message",
-			)
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:     "slack-auth-token",
+				SlackSlashCommand:  "/slack-slash-command",
+				SlackTeamName:      "slack-team-name",
+				SlackUserID:        "slack-user-id",
+				UninvitableDomain:  "uninvitable-domain.com",
+				UninvitableMessage: "uninvitable-domain-message",
+			})
 		})
 
 		It("returns an error if the user can't be found due to error", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{}, errors.New("error"))
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{}, "", errors.New("error"))
 
-			a := action.New(
+			a := action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
 				"guestify user@example.com",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("error"))
-			Ω(result).Should(Equal("Failed to guestify user 'user@example.com': error"))
+			Ω(result.String()).Should(Equal("Failed to guestify user 'user@example.com': error"))
 
 			Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(0))
 		})
 
 		It("returns an error if the user cannot be found", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{}, nil)
+			stubUsers(fakeSlackAPI, []slack.User{})
 
-			a := action.New(
+			a := action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
 				"guestify user@example.com",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("Unable to find user matching 'user@example.com'."))
-			Ω(result).Should(Equal("Failed to guestify user 'user@example.com': Unable to find user matching 'user@example.com'."))
+			Ω(result.String()).Should(Equal("Failed to guestify user 'user@example.com': Unable to find user matching 'user@example.com'."))
 
 			Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(0))
 		})
 
 		It("returns an error if the user is a full user", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:                "U1234",
 					Name:              "tsmith",
 					IsRestricted:      false,
 					IsUltraRestricted: false,
 				},
-			}, nil)
+			})
 
-			a := action.New(
+			a := action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
 				"guestify @tsmith",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("Full users cannot be guestified."))
-			Ω(result).Should(Equal("Failed to guestify user '@tsmith': Full users cannot be guestified."))
+			Ω(result.String()).Should(Equal("Failed to guestify user '@tsmith': Full users cannot be guestified."))
 
 			Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(0))
 		})
 
 		It("returns an error if the user is already a single-channel guest", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:                "U1234",
 					Name:              "tsmith",
 					IsRestricted:      false,
 					IsUltraRestricted: true,
 				},
-			}, nil)
+			})
 
-			a := action.New(
+			a := action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
 				"guestify @tsmith",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("User is already a single-channel guest."))
-			Ω(result).Should(Equal("Failed to guestify user '@tsmith': User is already a single-channel guest."))
+			Ω(result.String()).Should(Equal("Failed to guestify user '@tsmith': User is already a single-channel guest."))
 
 			Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(0))
 		})
 
 		It("returns an error if the request comes from a direct message", func() {
-			a := action.New(
+			a := action.NewConfirmed(
 				slackapi.NewChannel(slackapi.DirectMessageGroupName, "channel-id"),
 				"commander-name",
 				"commander-id",
 				"guestify @tsmith",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("Cannot guestify from a direct message. Try again from a channel or group."))
-			Ω(result).Should(Equal("Failed to guestify user '@tsmith': Cannot guestify from a direct message. Try again from a channel or group."))
+			Ω(result.String()).Should(Equal("Failed to guestify user '@tsmith': Cannot guestify from a direct message. Try again from a channel or group."))
 
 			Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(0))
 		})
 
 		It("attempts to guestify the user if they can be found by name", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:           "U1234",
 					Name:         "tsmith",
 					IsRestricted: true,
 				},
-			}, nil)
+			})
 
-			a := action.New(
+			a := action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
 				"guestify @tsmith",
 			)
 
-			_, err := a.Do(c, fakeSlackAPI, logger)
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
 
 			Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(1))
@@ -166,7 +169,7 @@ var _ = Describe("Guestify", func() {
 		})
 
 		It("attempts to guestify the user if they can be found by email", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:           "U1234",
 					IsRestricted: true,
@@ -174,16 +177,16 @@ var _ = Describe("Guestify", func() {
 						Email: "user@example.com",
 					},
 				},
-			}, nil)
+			})
 
-			a := action.New(
+			a := action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
 				"guestify user@example.com",
 			)
 
-			_, err := a.Do(c, fakeSlackAPI, logger)
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
 
 			Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(1))
@@ -195,15 +198,15 @@ var _ = Describe("Guestify", func() {
 		})
 
 		It("returns an error when guestifying fails", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:           "U1234",
 					Name:         "tsmith",
 					IsRestricted: true,
 				},
-			}, nil)
+			})
 
-			a := action.New(
+			a := action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
@@ -212,30 +215,30 @@ var _ = Describe("Guestify", func() {
 
 			fakeSlackAPI.SetUltraRestrictedReturns(errors.New("failed"))
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
-			Ω(result).Should(Equal("Failed to guestify user '@tsmith': failed"))
+			Ω(result.String()).Should(Equal("Failed to guestify user '@tsmith': failed"))
 		})
 
 		It("returns nil when guestifying succeeds", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:           "U1234",
 					Name:         "tsmith",
 					IsRestricted: true,
 				},
-			}, nil)
+			})
 
-			a := action.New(
+			a := action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
 				"guestify @tsmith",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
-			Ω(result).Should(Equal("Successfully guestified user @tsmith"))
+			Ω(result.String()).Should(Equal("Successfully guestified user @tsmith"))
 		})
 	})
 
@@ -249,7 +252,7 @@ var _ = Describe("Guestify", func() {
 		})
 
 		It("exists", func() {
-			a := action.New(
+			a := action.NewConfirmed(
 				slackapi.NewChannel("channel-name", "channel-id"),
 				"commander-name",
 				"commander-id",
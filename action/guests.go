@@ -0,0 +1,337 @@
+package action
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/audit"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/slack"
+)
+
+const guestsFilename = "guests.csv"
+
+// inviteCommands are the commands whose audit events record who invited whom.
+var inviteCommands = []string{"invite-guest", "invite-restricted"}
+
+func init() {
+	Register(Command{
+		Name:        "guests",
+		Options:     []Option{{Name: "csv"}},
+		Description: "Send you a direct message listing the Restricted Accounts and Single-Channel Guests by email domain and channel, or as a CSV file with `--csv`",
+		Permission:  &config.PolicyRule{Admins: true},
+		New: func(r Request) Action {
+			return NewGuests(r.Options["csv"] != "", auditEvents, r.CommanderName, r.CommanderID)
+		},
+	})
+}
+
+type guests struct {
+	csv           bool
+	store         audit.Store
+	commanderName string
+	commanderID   string
+}
+
+// guest is a Restricted Account or Single-Channel Guest in the report.
+type guest struct {
+	user      slack.User
+	channels  []string
+	invitedBy string
+}
+
+// NewGuests returns a new Guests action, used to send the commander a report
+// of the team's Restricted Accounts and Single-Channel Guests. Who invited
+// each of them is looked up in the given Store, if any.
+func NewGuests(
+	csv bool,
+	store audit.Store,
+	commanderName string,
+	commanderID string,
+) Action {
+	return &guests{
+		csv:           csv,
+		store:         store,
+		commanderName: commanderName,
+		commanderID:   commanderID,
+	}
+}
+
+func (g guests) Do(
+	c config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) (slackapi.Message, error) {
+	logger = logger.Session("do")
+
+	err := g.check(api, logger)
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(g.failureMessage(err)), err
+	}
+
+	report, err := g.report(api, logger)
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(g.failureMessage(err)), err
+	}
+
+	_, _, dmID, err := api.OpenIMChannel(g.commanderID)
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(g.failureMessage(err)), err
+	}
+
+	// A report too long for a message is sent as a CSV file instead.
+	message := guestsMessage(report)
+	asCSV := g.csv || len(message.Blocks) > slackapi.MaxBlocks
+
+	if asCSV {
+		var content string
+		content, err = guestsCSV(report)
+		if err == nil {
+			err = api.UploadFile(dmID, guestsFilename, content)
+		}
+	} else {
+		_, err = api.SendMessage(dmID, message)
+	}
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(g.failureMessage(err)), err
+	}
+
+	logger.Info("succeeded", lager.Data{"guests": len(report), "csv": asCSV})
+
+	if asCSV && !g.csv {
+		return slackapi.NewTextMessage(fmt.Sprintf(
+			"Successfully sent a list of %d guests as a direct message, as a CSV file as there were too many to show.",
+			len(report),
+		)), nil
+	}
+
+	return slackapi.NewTextMessage(fmt.Sprintf(
+		"Successfully sent a list of %d guests as a direct message.",
+		len(report),
+	)), nil
+}
+
+func (g guests) AuditMessage(api slackapi.SlackAPI) string {
+	return fmt.Sprintf("@%s requested the list of guests", g.commanderName)
+}
+
+func (g guests) failureMessage(err error) string {
+	return fmt.Sprintf("Failed to list the guests: %s", NewSlackErr(err).Error())
+}
+
+func (g guests) check(
+	api slackapi.SlackAPI,
+	logger lager.Logger,
+) error {
+	logger = logger.Session("check")
+
+	user, err := api.GetUserInfo(g.commanderID)
+	if err != nil {
+		logger.Error("failed", err)
+		return err
+	}
+
+	if user.IsRestricted || user.IsUltraRestricted {
+		logger.Error("failed", errUnauthorized)
+		return errUnauthorized
+	}
+
+	logger.Info("passed")
+
+	return nil
+}
+
+// report returns the team's active Restricted Accounts and Single-Channel
+// Guests, ordered by email address, with the channels and groups the
+// configured user can see them in and who invited them, where known.
+func (g guests) report(api slackapi.SlackAPI, logger lager.Logger) ([]guest, error) {
+	users, err := slackapi.AllUsers(api)
+	if err != nil {
+		return nil, err
+	}
+
+	inviters := g.inviters(logger)
+
+	var report []guest
+	for _, user := range users {
+		if user.Deleted || !(user.IsRestricted || user.IsUltraRestricted) {
+			continue
+		}
+
+		channels, err := memberChannels(user.ID, api)
+		if err != nil {
+			return nil, err
+		}
+
+		var names []string
+		for _, channel := range channels {
+			names = append(names, channel.Name)
+		}
+		sort.Strings(names)
+
+		report = append(report, guest{
+			user:      user,
+			channels:  names,
+			invitedBy: inviters[strings.ToLower(user.Profile.Email)],
+		})
+	}
+
+	sort.Sort(byEmail(report))
+
+	return report, nil
+}
+
+// inviters returns who last successfully invited each email address, as
+// recorded in the Store. It is empty when there is no Store, or it cannot be
+// queried, as the report is still useful without them.
+func (g guests) inviters(logger lager.Logger) map[string]string {
+	inviters := map[string]string{}
+	if g.store == nil {
+		return inviters
+	}
+
+	events, err := g.store.Query(audit.Query{})
+	if err != nil {
+		logger.Error("failed-to-query-audit-events", err)
+		return inviters
+	}
+
+	for _, event := range events {
+		if event.Outcome == audit.OutcomeSucceeded && matches(event.Action, inviteCommands...) {
+			inviters[strings.ToLower(event.Target)] = event.Actor
+		}
+	}
+
+	return inviters
+}
+
+// memberChannels returns the unarchived public and private channels the
+// configured user can see that the given user is a member of.
+func memberChannels(userID string, api slackapi.SlackAPI) ([]slackapi.Conversation, error) {
+	excludeArchived := true
+	return api.GetUserConversations(userID, []string{slackapi.PublicChannel, slackapi.PrivateChannel}, excludeArchived)
+}
+
+func guestsMessage(report []guest) slackapi.Message {
+	if len(report) == 0 {
+		return slackapi.NewTextMessage("There are no Restricted Accounts or Single-Channel Guests.")
+	}
+
+	var domains []string
+	byDomain := map[string][]guest{}
+	byChannel := map[string]int{}
+	for _, g := range report {
+		domain := g.domain()
+		if _, ok := byDomain[domain]; !ok {
+			domains = append(domains, domain)
+		}
+		byDomain[domain] = append(byDomain[domain], g)
+
+		for _, channel := range g.channels {
+			byChannel[channel]++
+		}
+	}
+	sort.Strings(domains)
+
+	lines := []string{fmt.Sprintf("Found %d guests.", len(report))}
+	for _, domain := range domains {
+		lines = append(lines, fmt.Sprintf("\n*%s* (%d)", domain, len(byDomain[domain])))
+		for _, g := range byDomain[domain] {
+			lines = append(lines, g.String())
+		}
+	}
+
+	var channels []string
+	for channel := range byChannel {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+
+	if len(channels) > 0 {
+		lines = append(lines, "\n*By channel*")
+		for _, channel := range channels {
+			lines = append(lines, fmt.Sprintf("#%s: %d", channel, byChannel[channel]))
+		}
+	}
+
+	return slackapi.Message{
+		Text:   strings.Join(lines, "\n"),
+		Blocks: slackapi.NewSectionBlocks(lines),
+	}
+}
+
+func guestsCSV(report []guest) (string, error) {
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"email", "username", "name", "type", "domain", "channels", "invited_by"})
+	for _, g := range report {
+		w.Write([]string{
+			g.user.Profile.Email,
+			g.user.Name,
+			g.user.RealName,
+			g.kind(),
+			g.domain(),
+			strings.Join(g.channels, " "),
+			g.invitedBy,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func (g guest) String() string {
+	line := fmt.Sprintf("@%s", g.user.Name)
+	if g.user.RealName != "" {
+		line += " " + g.user.RealName
+	}
+	line += fmt.Sprintf(" (%s), %s", g.user.Profile.Email, g.kind())
+
+	if len(g.channels) > 0 {
+		line += " in #" + strings.Join(g.channels, ", #")
+	}
+
+	if g.invitedBy != "" {
+		line += ", invited by @" + g.invitedBy
+	}
+
+	return line
+}
+
+func (g guest) kind() string {
+	if g.user.IsUltraRestricted {
+		return "single-channel guest"
+	}
+
+	return "restricted account"
+}
+
+func (g guest) domain() string {
+	email := g.user.Profile.Email
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		return strings.ToLower(email[at+1:])
+	}
+
+	return "unknown"
+}
+
+type byEmail []guest
+
+func (g byEmail) Len() int           { return len(g) }
+func (g byEmail) Swap(i, j int)      { g[i], g[j] = g[j], g[i] }
+func (g byEmail) Less(i, j int) bool { return g[i].user.Profile.Email < g[j].user.Profile.Email }
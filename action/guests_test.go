@@ -0,0 +1,248 @@
+package action_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/audit"
+	"github.com/pivotalservices/goulash/audit/auditfakes"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Guests", func() {
+	var (
+		c            config.Config
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeStore    *auditfakes.FakeStore
+		fakeClock    *fakeclock.FakeClock
+		logger       lager.Logger
+	)
+
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackSlashCommand: "/slack-slash-command",
+			SlackUserID:       "slack-user-id",
+		})
+		logger = lager.NewLogger("testlogger")
+
+		fakeSlackAPI.GetUserInfoReturns(&slack.User{ID: "commander-id"}, nil)
+		fakeSlackAPI.OpenIMChannelReturns(false, false, "dm-id", nil)
+
+		fakeSlackAPI.GetUsersPageReturns([]slack.User{
+			{ID: "U1", Name: "tsmith", RealName: "Tom Smith", IsRestricted: true, Profile: slack.UserProfile{Email: "tsmith@partner.com"}},
+			{ID: "U2", Name: "jdoe", IsUltraRestricted: true, Profile: slack.UserProfile{Email: "jdoe@example.com"}},
+			{ID: "U3", Name: "admin", Profile: slack.UserProfile{Email: "admin@example.com"}},
+			{ID: "U4", Name: "gone", IsRestricted: true, Deleted: true, Profile: slack.UserProfile{Email: "gone@example.com"}},
+			{ID: "U5", Name: "mjones", IsRestricted: true, Profile: slack.UserProfile{Email: "mjones@partner.com"}},
+		}, "", nil)
+
+		stubConversations(
+			fakeSlackAPI,
+			[]slackapi.Conversation{{ID: "C1", Name: "eng"}},
+			[]slackapi.Conversation{{ID: "G1", Name: "secret", IsPrivate: true}},
+		)
+		stubMembers(fakeSlackAPI, map[string][]string{
+			"C1": {"U1", "U3", "U5"},
+			"G1": {"U1", "U2"},
+		})
+
+		fakeStore = &auditfakes.FakeStore{}
+		fakeStore.QueryReturns([]audit.Event{
+			{Actor: "alice", Action: "invite-restricted", Target: "tsmith@partner.com", Outcome: audit.OutcomeSucceeded},
+			{Actor: "bob", Action: "invite-guest", Target: "JDoe@example.com", Outcome: audit.OutcomeFailed},
+			{Actor: "carol", Action: "guestify", Target: "mjones@partner.com", Outcome: audit.OutcomeSucceeded},
+		}, nil)
+	})
+
+	Describe("New", func() {
+		It("may only be run by admins by default", func() {
+			fakeSlackAPI.GetUserInfoReturns(&slack.User{}, nil)
+
+			err := action.Authorize("guests", "U1234", c, fakeSlackAPI, logger)
+			Ω(err).Should(Equal(action.NewNotPermittedErr("guests", "/slack-slash-command")))
+		})
+	})
+
+	Describe("Do", func() {
+		It("sends the commander a report by domain and channel", func() {
+			a := action.NewGuests(false, fakeStore, "commander-name", "commander-id")
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(Equal("Successfully sent a list of 3 guests as a direct message."))
+
+			Ω(fakeSlackAPI.OpenIMChannelArgsForCall(0)).Should(Equal("commander-id"))
+			Ω(fakeSlackAPI.SendMessageCallCount()).Should(Equal(1))
+
+			channelID, message := fakeSlackAPI.SendMessageArgsForCall(0)
+			Ω(channelID).Should(Equal("dm-id"))
+			Ω(message.Text).Should(Equal("Found 3 guests.\n" +
+				"\n*example.com* (1)\n" +
+				"@jdoe (jdoe@example.com), single-channel guest in #secret\n" +
+				"\n*partner.com* (2)\n" +
+				"@mjones (mjones@partner.com), restricted account in #eng\n" +
+				"@tsmith Tom Smith (tsmith@partner.com), restricted account in #eng, #secret, invited by @alice\n" +
+				"\n*By channel*\n" +
+				"#eng: 2\n" +
+				"#secret: 2"))
+		})
+
+		It("looks up the channels of only the guests in the report", func() {
+			a := action.NewGuests(false, fakeStore, "commander-name", "commander-id")
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(fakeSlackAPI.GetConversationMembersCallCount()).Should(Equal(0))
+			Ω(fakeSlackAPI.GetUserConversationsCallCount()).Should(Equal(3))
+
+			var looked []string
+			for i := 0; i < fakeSlackAPI.GetUserConversationsCallCount(); i++ {
+				userID, _, excludeArchived := fakeSlackAPI.GetUserConversationsArgsForCall(i)
+				Ω(excludeArchived).Should(BeTrue())
+				looked = append(looked, userID)
+			}
+			Ω(looked).Should(Equal([]string{"U1", "U2", "U5"}))
+		})
+
+		It("returns an error if a guest's channels cannot be listed", func() {
+			fakeSlackAPI.GetUserConversationsStub = nil
+			fakeSlackAPI.GetUserConversationsReturns(nil, errors.New("conversations-err"))
+
+			a := action.NewGuests(false, fakeStore, "commander-name", "commander-id")
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("conversations-err"))
+			Ω(fakeSlackAPI.SendMessageCallCount()).Should(Equal(0))
+		})
+
+		It("sends the report as a CSV file with --csv", func() {
+			a := action.NewGuests(true, fakeStore, "commander-name", "commander-id")
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(fakeSlackAPI.SendMessageCallCount()).Should(Equal(0))
+			Ω(fakeSlackAPI.UploadFileCallCount()).Should(Equal(1))
+
+			channelID, filename, content := fakeSlackAPI.UploadFileArgsForCall(0)
+			Ω(channelID).Should(Equal("dm-id"))
+			Ω(filename).Should(Equal("guests.csv"))
+			Ω(content).Should(Equal("email,username,name,type,domain,channels,invited_by\n" +
+				"jdoe@example.com,jdoe,,single-channel guest,example.com,secret,\n" +
+				"mjones@partner.com,mjones,,restricted account,partner.com,eng,\n" +
+				"tsmith@partner.com,tsmith,Tom Smith,restricted account,partner.com,eng secret,alice\n"))
+		})
+
+		It("sends the report as a CSV file when it is too long for a message", func() {
+			var users []slack.User
+			for i := 0; i < slackapi.MaxBlocks+1; i++ {
+				users = append(users, slack.User{
+					ID:           fmt.Sprintf("U%d", i),
+					Name:         fmt.Sprintf("guest%d", i),
+					RealName:     strings.Repeat("x", 2900),
+					IsRestricted: true,
+					Profile:      slack.UserProfile{Email: fmt.Sprintf("guest%d@partner.com", i)},
+				})
+			}
+			fakeSlackAPI.GetUsersPageReturns(users, "", nil)
+
+			a := action.NewGuests(false, fakeStore, "commander-name", "commander-id")
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(Equal("Successfully sent a list of 51 guests as a direct message, as a CSV file as there were too many to show."))
+
+			Ω(fakeSlackAPI.SendMessageCallCount()).Should(Equal(0))
+			Ω(fakeSlackAPI.UploadFileCallCount()).Should(Equal(1))
+		})
+
+		It("leaves out who invited the guests when there is no audit store", func() {
+			a := action.NewGuests(false, nil, "commander-name", "commander-id")
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			_, message := fakeSlackAPI.SendMessageArgsForCall(0)
+			Ω(message.Text).ShouldNot(ContainSubstring("invited by"))
+		})
+
+		It("still sends the report when the audit store cannot be queried", func() {
+			fakeStore.QueryReturns(nil, errors.New("query-err"))
+
+			a := action.NewGuests(false, fakeStore, "commander-name", "commander-id")
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeSlackAPI.SendMessageCallCount()).Should(Equal(1))
+		})
+
+		It("says when there are no guests", func() {
+			fakeSlackAPI.GetUsersPageReturns(nil, "", nil)
+
+			a := action.NewGuests(false, fakeStore, "commander-name", "commander-id")
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(Equal("Successfully sent a list of 0 guests as a direct message."))
+
+			_, message := fakeSlackAPI.SendMessageArgsForCall(0)
+			Ω(message.Text).Should(Equal("There are no Restricted Accounts or Single-Channel Guests."))
+		})
+
+		It("returns an error if the commander is a guest", func() {
+			fakeSlackAPI.GetUserInfoReturns(&slack.User{IsRestricted: true}, nil)
+
+			a := action.NewGuests(false, fakeStore, "commander-name", "commander-id")
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("Sorry, you don't have access to that function."))
+			Ω(result.String()).Should(Equal("Failed to list the guests: Sorry, you don't have access to that function."))
+
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error if the users cannot be listed", func() {
+			fakeSlackAPI.GetUsersPageReturns(nil, "", errors.New("get-users-err"))
+
+			a := action.NewGuests(false, fakeStore, "commander-name", "commander-id")
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("get-users-err"))
+			Ω(fakeSlackAPI.SendMessageCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error if the report cannot be sent", func() {
+			fakeSlackAPI.SendMessageReturns("", errors.New("send-err"))
+
+			a := action.NewGuests(false, fakeStore, "commander-name", "commander-id")
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("send-err"))
+			Ω(result.String()).Should(Equal("Failed to list the guests: send-err"))
+		})
+	})
+
+	Describe("AuditMessage", func() {
+		It("exists", func() {
+			a := action.NewGuests(false, fakeStore, "commander-name", "commander-id")
+
+			aa, ok := a.(action.AuditableAction)
+			Ω(ok).Should(BeTrue())
+			Ω(aa.AuditMessage(fakeSlackAPI)).Should(Equal("@commander-name requested the list of guests"))
+		})
+	})
+})
@@ -2,7 +2,9 @@ package action
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/pivotal-golang/clock"
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotalservices/goulash/config"
 	"github.com/pivotalservices/goulash/slackapi"
@@ -10,43 +12,44 @@ import (
 
 type help struct{}
 
+// Do describes every registered Command.
 func (h help) Do(
 	config config.Config,
 	api slackapi.SlackAPI,
+	clock clock.Clock,
 	logger lager.Logger,
-) (string, error) {
-	text := fmt.Sprintf(
-		"*USAGE*\n"+
-			"`%s [command] [args]`\n"+
-			"\n"+
-			"*COMMANDS*\n"+
-			"\n"+
-			"`disable-user [email|@username]`\n"+
-			"_Disable a Slack user_\n"+
-			"\n"+
-			"`groups`\n"+
-			"_List the groups that @%s is in_\n"+
-			"\n"+
-			"`guestify [email|@username]`\n"+
-			"_Convert a Restricted Account to a Single-Channel Guest_\n"+
-			"\n"+
-			"`info [email]`\n"+
-			"_Get information on a Slack user_\n"+
-			"\n"+
-			"`invite-guest [email] [firstname] [lastname]`\n"+
-			"_Invite a Single-Channel Guest to the current channel/group_\n"+
-			"\n"+
-			"`invite-restricted [email] [firstname] [lastname]`\n"+
-			"_Invite a Restricted Account to the current channel/group_\n"+
-			"\n"+
-			"`request-access [#channel]`\n"+
-			"_Request an invitation to a channel_\n"+
-			"\n"+
-			"`restrictify [email|@username]`\n"+
-			"_Convert a Single-Channel Guest to a Restricted Account_\n"+
-			config.SlackSlashCommand(),
-		config.SlackUserID(),
+) (slackapi.Message, error) {
+	usage := fmt.Sprintf(
+		"*USAGE*\n`%s [command] [args]`\nCommands which change Slack can be given `--%s` to describe the changes instead of making them.",
+		config.SlackSlashCommand(),
+		dryRunOption,
 	)
 
-	return text, nil
+	sections := []string{usage, "*COMMANDS*"}
+	blocks := []slackapi.Block{
+		slackapi.NewSectionBlock(usage),
+		slackapi.NewDividerBlock(),
+	}
+	for _, command := range registeredCommands() {
+		text := fmt.Sprintf("`%s`\n_%s_", command.usage(), command.description(config))
+		if len(command.Aliases) > 0 {
+			text += fmt.Sprintf("\nAlso: `%s`", strings.Join(command.Aliases, "`, `"))
+		}
+		sections = append(sections, text)
+		blocks = append(blocks, slackapi.NewSectionBlock(text))
+	}
+
+	return slackapi.Message{
+		Text:   strings.Join(sections, "\n\n"),
+		Blocks: blocks,
+	}, nil
+}
+
+// description returns how the command is described in help.
+func (c Command) description(config config.Config) string {
+	if c.Describe != nil {
+		return c.Describe(config)
+	}
+
+	return c.Description
 }
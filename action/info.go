@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/pivotal-golang/clock"
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotalservices/goulash/config"
 	"github.com/pivotalservices/goulash/slackapi"
@@ -20,6 +21,17 @@ var (
 	membershipSingleChannelGuest = "single-channel guest"
 )
 
+func init() {
+	Register(Command{
+		Name:        "info",
+		Params:      []Param{{Name: "email"}},
+		Description: "Get information on a Slack user",
+		New: func(r Request) Action {
+			return NewInfo(r.Params, r.CommanderName)
+		},
+	})
+}
+
 type info struct {
 	params         []string
 	requestingUser string
@@ -31,9 +43,7 @@ func NewInfo(
 	requestingUser string,
 ) Action {
 	infoParams := []string{""}
-	for i := range params {
-		infoParams[i] = params[i]
-	}
+	copy(infoParams, params)
 
 	return &info{
 		params:         infoParams,
@@ -44,32 +54,31 @@ func NewInfo(
 func (i info) Do(
 	config config.Config,
 	api slackapi.SlackAPI,
+	clock clock.Clock,
 	logger lager.Logger,
-) (string, error) {
+) (slackapi.Message, error) {
 	var result string
 
 	logger = logger.Session("do")
 
 	if checkErr := i.check(config, api, logger); checkErr != nil {
-		return checkErr.Error(), checkErr
+		return slackapi.NewErrorMessage(checkErr.Error()), checkErr
 	}
 
-	users, err := api.GetUsers()
+	user, found, err := i.lookUpUser(api)
 	if err != nil {
 		logger.Error("failed-getting-users", err)
-		result = fmt.Sprintf("Failed to look up user@example.com: %s", err.Error())
-		return result, err
+		result = fmt.Sprintf("Failed to look up user@example.com: %s", NewSlackErr(err).Error())
+		return slackapi.NewErrorMessage(result), err
 	}
 
-	for _, user := range users {
-		if user.Profile.Email == i.emailAddress() {
-			logger.Info("successfully-found-user")
-			return i.infoMessage(user), nil
-		}
+	if found {
+		logger.Info("successfully-found-user")
+		return i.infoMessage(user), nil
 	}
 
-	if uninvitableEmail(i.emailAddress(), config.UninvitableDomain()) {
-		result = fmt.Sprintf(uninvitableUserNotFoundMessageFmt, i.emailAddress(), config.UninvitableMessage())
+	if _, message, ok := config.DomainPolicy().Check(i.emailAddress()); !ok {
+		result = fmt.Sprintf(uninvitableUserNotFoundMessageFmt, i.emailAddress(), message)
 	} else {
 		result = fmt.Sprintf(userNotFoundMessageFmt, i.emailAddress(), config.SlackSlashCommand())
 	}
@@ -77,13 +86,17 @@ func (i info) Do(
 	err = errors.New(result)
 	logger.Error("failed-to-find-user", err)
 
-	return result, err
+	return slackapi.NewErrorMessage(result), err
 }
 
 func (i info) AuditMessage(api slackapi.SlackAPI) string {
 	return fmt.Sprintf("@%s requested info on '%s'", i.requestingUser, i.emailAddress())
 }
 
+func (i info) AuditTarget() string {
+	return i.emailAddress()
+}
+
 func (i info) emailAddress() string {
 	return i.params[0]
 }
@@ -105,20 +118,61 @@ func (i info) check(
 	return nil
 }
 
-func (i info) infoMessage(user slack.User) string {
-	membership := membershipFull
-	if user.IsRestricted {
-		membership = membershipRestrictedAccount
-	}
-	if user.IsUltraRestricted {
-		membership = membershipSingleChannelGuest
+func (i info) infoMessage(user slack.User) slackapi.Message {
+	return slackapi.Message{
+		Text:   userInfoText(user),
+		Blocks: []slackapi.Block{userInfoBlock(user)},
 	}
+}
+
+// userInfoText describes who the user is: their name, email address,
+// membership, and username.
+func userInfoText(user slack.User) string {
 	return fmt.Sprintf(
 		infoMessageFmt,
 		user.Profile.FirstName,
 		user.Profile.LastName,
 		user.Profile.Email,
-		membership,
+		membership(user),
 		user.Name,
 	)
 }
+
+// userInfoBlock shows what userInfoText describes, laid out in columns.
+func userInfoBlock(user slack.User) slackapi.Block {
+	return slackapi.NewSectionBlock(
+		fmt.Sprintf("*%s %s*", user.Profile.FirstName, user.Profile.LastName),
+		fmt.Sprintf("*Email*\n%s", user.Profile.Email),
+		fmt.Sprintf("*Membership*\n%s", membership(user)),
+		fmt.Sprintf("*Username*\n<@%s>", user.Name),
+	)
+}
+
+func membership(user slack.User) string {
+	switch {
+	case user.IsUltraRestricted:
+		return membershipSingleChannelGuest
+	case user.IsRestricted:
+		return membershipRestrictedAccount
+	default:
+		return membershipFull
+	}
+}
+
+func (i info) lookUpUser(api slackapi.SlackAPI) (slack.User, bool, error) {
+	if directory, ok := api.(slackapi.Directory); ok {
+		return directory.UserByEmail(i.emailAddress())
+	}
+
+	var found slack.User
+	var ok bool
+	err := slackapi.EachUser(api, func(user slack.User) bool {
+		if user.Profile.Email == i.emailAddress() {
+			found, ok = user, true
+		}
+
+		return !ok
+	})
+
+	return found, ok, err
+}
@@ -2,7 +2,9 @@ package action_test
 
 import (
 	"errors"
+	"time"
 
+	"github.com/pivotal-golang/clock/fakeclock"
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotalservices/slack"
 
@@ -20,21 +22,22 @@ var _ = Describe("UserInfo", func() {
 		var (
 			c            config.Config
 			fakeSlackAPI *slackapifakes.FakeSlackAPI
+			fakeClock    *fakeclock.FakeClock
 			logger       lager.Logger
 		)
 
 		BeforeEach(func() {
 			fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+			fakeClock = fakeclock.NewFakeClock(time.Now())
 			logger = lager.NewLogger("testlogger")
-			c = config.NewLocalConfig(
-				"slack-auth-token",
-				"/slack-slash-command",
-				"slack-team-name",
-				"slack-user-id",
-				"",
-				"uninvitable-domain.com",
-				"uninvitable-domain-message",
-			)
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:     "slack-auth-token",
+				SlackSlashCommand:  "/slack-slash-command",
+				SlackTeamName:      "slack-team-name",
+				SlackUserID:        "slack-user-id",
+				UninvitableDomain:  "uninvitable-domain.com",
+				UninvitableMessage: "uninvitable-domain-message",
+			})
 		})
 
 		It("asks Slack for the list of users", func() {
@@ -45,8 +48,8 @@ var _ = Describe("UserInfo", func() {
 				"info user@example.com",
 			)
 
-			_, _ = a.Do(c, fakeSlackAPI, logger)
-			Ω(fakeSlackAPI.GetUsersCallCount()).Should(Equal(1))
+			_, _ = a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(1))
 		})
 
 		It("returns an error when it can't get the list of users from Slack", func() {
@@ -57,15 +60,15 @@ var _ = Describe("UserInfo", func() {
 				"info user@example.com",
 			)
 
-			fakeSlackAPI.GetUsersReturns([]slack.User{}, errors.New("network error"))
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{}, "", errors.New("network error"))
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
-			Ω(result).Should(Equal("Failed to look up user@example.com: network error"))
+			Ω(result.String()).Should(Equal("Failed to look up user@example.com: network error"))
 		})
 
-		It("returns an error when no email address was given", func() {
-			expectedErr := action.NewMissingEmailParameterErr("/slack-slash-command")
+		It("returns a usage error when no email address was given", func() {
+			expectedErr := action.NewUsageErr("Missing required email parameter.", "info [email]", "/slack-slash-command")
 
 			a := action.New(
 				slackapi.NewChannel("channel-id", "channel-name"),
@@ -74,7 +77,7 @@ var _ = Describe("UserInfo", func() {
 				"info",
 			)
 
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{
 				{
 					Name: "tsmith",
 					Profile: slack.UserProfile{
@@ -85,12 +88,12 @@ var _ = Describe("UserInfo", func() {
 					IsRestricted:      false,
 					IsUltraRestricted: false,
 				},
-			}, nil)
+			}, "", nil)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
-			Ω(err).Should(HaveOccurred())
-			Ω(err).Should(BeAssignableToTypeOf(expectedErr))
-			Ω(result).Should(Equal(expectedErr.Error()))
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(Equal(expectedErr))
+			Ω(result.String()).Should(Equal("Missing required email parameter. Usage: `/slack-slash-command info [email]`"))
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(0))
 		})
 
 		It("returns a result for an unknown user", func() {
@@ -101,10 +104,10 @@ var _ = Describe("UserInfo", func() {
 				"info user@example.com",
 			)
 
-			fakeSlackAPI.GetUsersReturns([]slack.User{}, nil)
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{}, "", nil)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
-			Ω(result).Should(Equal("There is no user here with the email address 'user@example.com'. You can invite them to Slack as a guest or a restricted account. Type `/slack-slash-command help` for more information."))
+			Ω(result.String()).Should(Equal("There is no user here with the email address 'user@example.com'. You can invite them to Slack as a guest or a restricted account. Type `/slack-slash-command help` for more information."))
 		})
 
 		It("returns a result for a user with an uninvitable domain", func() {
@@ -115,10 +118,10 @@ var _ = Describe("UserInfo", func() {
 				"info user@uninvitable-domain.com",
 			)
 
-			fakeSlackAPI.GetUsersReturns([]slack.User{}, nil)
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{}, "", nil)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
-			Ω(result).Should(Equal("There is no user here with the email address 'user@uninvitable-domain.com'. uninvitable-domain-message"))
+			Ω(result.String()).Should(Equal("There is no user here with the email address 'user@uninvitable-domain.com'. uninvitable-domain-message"))
 		})
 
 		It("returns a result for a Slack 'full' member", func() {
@@ -129,7 +132,7 @@ var _ = Describe("UserInfo", func() {
 				"info user@example.com",
 			)
 
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{
 				{
 					Name: "tsmith",
 					Profile: slack.UserProfile{
@@ -140,11 +143,34 @@ var _ = Describe("UserInfo", func() {
 					IsRestricted:      false,
 					IsUltraRestricted: false,
 				},
-			}, nil)
+			}, "", nil)
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(Equal("Tom Smith (user@example.com) is a Slack full member, with the username <@tsmith>."))
+		})
+
+		It("finds a user past the first page of users", func() {
+			a := action.New(
+				slackapi.NewChannel("channel-id", "channel-name"),
+				"commander-name",
+				"commander-id",
+				"info user@example.com",
+			)
+
+			fakeSlackAPI.GetUsersPageStub = func(cursor string) ([]slack.User, string, error) {
+				if cursor == "" {
+					return []slack.User{{Name: "jdoe", Profile: slack.UserProfile{Email: "jdoe@example.com"}}}, "page-2", nil
+				}
+
+				user := slack.User{Name: "tsmith", Profile: slack.UserProfile{Email: "user@example.com"}}
+				return []slack.User{user}, "", nil
+			}
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
-			Ω(result).Should(Equal("Tom Smith (user@example.com) is a Slack full member, with the username <@tsmith>."))
+			Ω(result.String()).Should(ContainSubstring("with the username <@tsmith>"))
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(2))
 		})
 
 		It("responds to Slack with a message about a restricted account", func() {
@@ -155,7 +181,7 @@ var _ = Describe("UserInfo", func() {
 				"info user@example.com",
 			)
 
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{
 				{
 					Name: "tsmith",
 					Profile: slack.UserProfile{
@@ -166,11 +192,11 @@ var _ = Describe("UserInfo", func() {
 					IsRestricted:      true,
 					IsUltraRestricted: false,
 				},
-			}, nil)
+			}, "", nil)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
-			Ω(result).Should(Equal("Tom Smith (user@example.com) is a Slack restricted account, with the username <@tsmith>."))
+			Ω(result.String()).Should(Equal("Tom Smith (user@example.com) is a Slack restricted account, with the username <@tsmith>."))
 		})
 
 		It("responds to Slack with a message about a single-channel guest", func() {
@@ -181,7 +207,7 @@ var _ = Describe("UserInfo", func() {
 				"info user@example.com",
 			)
 
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{
 				{
 					Name: "tsmith",
 					Profile: slack.UserProfile{
@@ -192,11 +218,58 @@ var _ = Describe("UserInfo", func() {
 					IsRestricted:      false,
 					IsUltraRestricted: true,
 				},
-			}, nil)
+			}, "", nil)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
-			Ω(result).Should(Equal("Tom Smith (user@example.com) is a Slack single-channel guest, with the username <@tsmith>."))
+			Ω(result.String()).Should(Equal("Tom Smith (user@example.com) is a Slack single-channel guest, with the username <@tsmith>."))
+		})
+
+		It("renders the user as a card", func() {
+			a := action.New(
+				slackapi.NewChannel("channel-id", "channel-name"),
+				"commander-name",
+				"commander-id",
+				"info user@example.com",
+			)
+
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{
+				{
+					Name: "tsmith",
+					Profile: slack.UserProfile{
+						Email:     "user@example.com",
+						FirstName: "Tom",
+						LastName:  "Smith",
+					},
+					IsRestricted: true,
+				},
+			}, "", nil)
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.Blocks).Should(Equal([]slackapi.Block{
+				slackapi.NewSectionBlock(
+					"*Tom Smith*",
+					"*Email*\nuser@example.com",
+					"*Membership*\nrestricted account",
+					"*Username*\n<@tsmith>",
+				),
+			}))
+		})
+
+		It("shows a user who cannot be found as an error", func() {
+			a := action.New(
+				slackapi.NewChannel("channel-id", "channel-name"),
+				"commander-name",
+				"commander-id",
+				"info user@example.com",
+			)
+
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(HaveOccurred())
+			Ω(result.Text).Should(BeEmpty())
+			Ω(result.Attachments).Should(HaveLen(1))
+			Ω(result.Attachments[0].Color).Should(Equal("danger"))
 		})
 	})
 })
@@ -2,53 +2,195 @@ package action
 
 import (
 	"fmt"
-	"regexp"
+	"strings"
+	"time"
 
+	"github.com/pivotal-golang/clock"
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/expiry"
 	"github.com/pivotalservices/goulash/slackapi"
 )
 
+const expiryDateFormat = "Monday, January 2, 2006"
+
+var inviteCommandParams = []Param{
+	{Name: "email"},
+	{Name: "firstname", Optional: true},
+	{Name: "lastname", Optional: true},
+}
+
+var inviteCommandOptions = []Option{{Name: "expires", Value: "duration"}}
+
+var inviteRestrictedCommandOptions = append(
+	[]Option{{Name: "channels", Value: "#channel,..."}},
+	inviteCommandOptions...,
+)
+
+// RegisterInvites registers the invite commands. They may be given --expires
+// when expiries is not nil, recording in it when the accounts they invite
+// should be disabled.
+func RegisterInvites(expiries expiry.Store) {
+	newInvite := func(r Request) Action {
+		return NewInvite(r.Params, r.Command, r.Channel, r.CommanderName, r.Options["expires"], expiries, r.DryRun, channelNames(r.Options["channels"]))
+	}
+
+	Register(Command{
+		Name:        "invite-guest",
+		Params:      inviteCommandParams,
+		Options:     inviteCommandOptions,
+		Description: "Invite a Single-Channel Guest to the current channel/group, optionally disabling their account after a time such as `30d`",
+		Mutating:    true,
+		New:         newInvite,
+	})
+
+	Register(Command{
+		Name:        "invite-restricted",
+		Params:      inviteCommandParams,
+		Options:     inviteRestrictedCommandOptions,
+		Description: "Invite a Restricted Account to the current channel/group, or to the channels/groups given with `--channels`, optionally disabling their account after a time such as `30d`",
+		Mutating:    true,
+		New:         newInvite,
+	})
+}
+
 type invite struct {
 	params       []string
 	command      string
 	channel      slackapi.Channel
 	invitingUser string
+	expires      string
+	expiries     expiry.Store
+	dryRun       bool
+	channelNames []string
+
+	// channels are the channels named by channelNames, once they have been
+	// found by check.
+	channels []slackapi.Channel
 }
 
-// NewInvite returns a new invite action
+// NewInvite returns a new invite action. When expires is given, the account
+// is recorded in the given Store as expiring after that long, unless dryRun
+// is true, when it is only described as it would have been. When
+// channelNames are given, the invitee is invited to those channels or groups
+// rather than to the given channel.
 func NewInvite(
 	params []string,
 	command string,
 	channel slackapi.Channel,
 	invitingUser string,
+	expires string,
+	expiries expiry.Store,
+	dryRun bool,
+	channelNames []string,
 ) Action {
 	inviteParams := []string{"", "", ""}
-	for i := range params {
-		inviteParams[i] = params[i]
-	}
+	copy(inviteParams, params)
 
 	return &invite{
 		params:       inviteParams,
 		command:      command,
 		channel:      channel,
 		invitingUser: invitingUser,
+		expires:      expires,
+		expiries:     expiries,
+		dryRun:       dryRun,
+		channelNames: channelNames,
 	}
 }
 
-func (i invite) Do(
+func (i *invite) Do(
 	config config.Config,
 	api slackapi.SlackAPI,
+	clock clock.Clock,
 	logger lager.Logger,
-) (string, error) {
+) (slackapi.Message, error) {
 	var err error
 
 	logger = logger.Session("do")
 
+	expiresAt, err := i.expiresAt(config, clock.Now())
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(err.Error()), err
+	}
+
 	if err = i.check(config, api, logger); err != nil {
-		return err.Error(), err
+		return slackapi.NewErrorMessage(err.Error()), err
+	}
+
+	if err = i.invite(config, api); err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(i.failureMessage(api, err)), err
+	}
+
+	if expiresAt.IsZero() {
+		logger.Info("succeeded")
+		return slackapi.NewTextMessage(i.successMessage(api)), nil
+	}
+
+	expiration := expiry.Expiration{
+		EmailAddress: i.emailAddress(),
+		FirstName:    i.firstName(),
+		LastName:     i.lastName(),
+		InviterName:  i.invitingUser,
+		ChannelID:    strings.Join(i.channelIDs(), ","),
+		ChannelName:  strings.Join(i.targetNames(api), ", "),
+		ExpiresAt:    expiresAt,
+	}
+
+	if i.dryRun {
+		describe(api, fmt.Sprintf(
+			"record that the account of %s expires on %s",
+			i.emailAddress(),
+			expiresAt.UTC().Format(expiryDateFormat),
+		))
+	} else {
+		err = i.expiries.Put(expiration)
+	}
+	if err != nil {
+		logger.Error("failed-to-record-expiry", err)
+		return slackapi.NewErrorMessage(fmt.Sprintf(
+			"%s, but failed to record when their account expires: %s",
+			i.successMessage(api),
+			err.Error(),
+		)), err
+	}
+
+	logger.Info("succeeded", lager.Data{"expiresAt": expiresAt})
+
+	return slackapi.NewTextMessage(fmt.Sprintf(
+		"%s. Their account will be disabled on %s.",
+		i.successMessage(api),
+		expiresAt.UTC().Format(expiryDateFormat),
+	)), nil
+}
+
+// expiresAt returns when the account should expire, or the zero time if it
+// should not.
+func (i invite) expiresAt(config config.Config, now time.Time) (time.Time, error) {
+	if i.expires == "" {
+		return time.Time{}, nil
+	}
+
+	if i.expiries == nil {
+		return time.Time{}, NewGuestExpiryDisabledErr(config.SlackSlashCommand())
+	}
+
+	duration, ok := parseDuration(i.expires)
+	if !ok || duration == 0 {
+		command, _ := lookUpCommand(i.command)
+		return time.Time{}, NewUsageErr(fmt.Sprintf(invalidExpiresProblemFmt, i.expires), command.usage(), config.SlackSlashCommand())
 	}
 
+	return now.Add(duration), nil
+}
+
+// invite invites the invitee, treating them having already been invited as
+// success.
+func (i invite) invite(config config.Config, api slackapi.SlackAPI) error {
+	var err error
+
 	switch i.command {
 	case "invite-guest":
 		err = api.InviteGuest(
@@ -62,68 +204,93 @@ func (i invite) Do(
 	case "invite-restricted":
 		err = api.InviteRestricted(
 			config.SlackTeamName(),
-			i.channel.ID(),
+			strings.Join(i.channelIDs(), ","),
 			i.firstName(),
 			i.lastName(),
 			i.emailAddress(),
 		)
 	}
 
-	if err != nil {
-		alreadyInvited, matchErr := regexp.MatchString("already_invited", err.Error())
-		if matchErr != nil {
-			return i.failureMessage(api, matchErr), matchErr
-		}
-		if alreadyInvited {
-			return i.successMessage(api), nil
-		}
-
-		logger.Error("failed", err)
-		return i.failureMessage(api, err), err
+	if slackapi.ErrorCode(err) == slackapi.ErrorAlreadyInvited {
+		return nil
 	}
 
-	logger.Info("succeeded")
-
-	return i.successMessage(api), nil
+	return err
 }
 
 func (i invite) AuditMessage(api slackapi.SlackAPI) string {
+	var destinations []string
+	for _, channel := range i.targets() {
+		destinations = append(destinations, fmt.Sprintf("'%s' (%s)", channel.Name(api), channel.ID()))
+	}
+
 	return fmt.Sprintf(
-		"@%s invited %s %s (%s) as a %s to '%s' (%s)",
+		"@%s invited %s %s (%s) as a %s to %s",
 		i.invitingUser,
 		i.firstName(),
 		i.lastName(),
 		i.emailAddress(),
 		i.inviteeType(),
-		i.channel.Name(api),
-		i.channel.ID(),
+		strings.Join(destinations, ", "),
 	)
 }
 
+func (i invite) AuditTarget() string {
+	return i.emailAddress()
+}
+
 func (i invite) successMessage(api slackapi.SlackAPI) string {
 	return fmt.Sprintf(
-		"Successfully invited %s %s (%s) as a %s to '%s'",
+		"Successfully invited %s %s (%s) as a %s to %s",
 		i.firstName(),
 		i.lastName(),
 		i.emailAddress(),
 		i.inviteeType(),
-		i.channel.Name(api),
+		quotedList(i.targetNames(api)),
 	)
 }
 
 func (i invite) failureMessage(api slackapi.SlackAPI, err error) string {
 	return fmt.Sprintf(
-		"Failed to invite %s %s (%s) as a %s to '%s': %s",
+		"Failed to invite %s %s (%s) as a %s to %s: %s",
 		i.firstName(),
 		i.lastName(),
 		i.emailAddress(),
 		i.inviteeType(),
-		i.channel.Name(api),
-		err.Error(),
+		quotedList(i.targetNames(api)),
+		NewSlackErr(err).Error(),
 	)
 }
 
-func (i invite) check(
+// targets returns the channels the invitee is invited to: those named by
+// channelNames once check has found them, or else the current channel.
+func (i invite) targets() []slackapi.Channel {
+	if len(i.channels) == 0 {
+		return []slackapi.Channel{i.channel}
+	}
+
+	return i.channels
+}
+
+func (i invite) targetNames(api slackapi.SlackAPI) []string {
+	var names []string
+	for _, channel := range i.targets() {
+		names = append(names, channel.Name(api))
+	}
+
+	return names
+}
+
+func (i invite) channelIDs() []string {
+	var ids []string
+	for _, channel := range i.targets() {
+		ids = append(ids, channel.ID())
+	}
+
+	return ids
+}
+
+func (i *invite) check(
 	config config.Config,
 	api slackapi.SlackAPI,
 	logger lager.Logger,
@@ -135,24 +302,37 @@ func (i invite) check(
 		return NewMissingEmailParameterErr(config.SlackSlashCommand())
 	}
 
-	if uninvitableEmail(i.emailAddress(), config.UninvitableDomain()) {
+	if domain, message, ok := config.DomainPolicy().Check(i.emailAddress()); !ok {
 		logger.Info("uninvitable-email", lager.Data{
 			"emailAddress":      i.emailAddress(),
-			"uninvitableDomain": config.UninvitableDomain(),
+			"uninvitableDomain": domain,
 		})
 		return NewUninvitableDomainErr(
-			config.UninvitableDomain(),
-			config.UninvitableMessage(),
+			domain,
+			message,
 			config.SlackSlashCommand(),
 		)
 	}
 
-	if !i.channel.Visible(api) {
-		logger.Info("channel-not-visible", lager.Data{
-			"slack_user_id": config.SlackUserID(),
-			"channelID":     i.channel.ID(),
-		})
-		return NewChannelNotVisibleErr(config.SlackUserID())
+	i.channels = nil
+	for _, name := range i.channelNames {
+		channel, err := findChannelOrGroup(name, api)
+		if err != nil {
+			logger.Info("channel-not-found", lager.Data{"channelName": name})
+			return err
+		}
+
+		i.channels = append(i.channels, channel)
+	}
+
+	for _, channel := range i.targets() {
+		if !channel.Visible(api) {
+			logger.Info("channel-not-visible", lager.Data{
+				"slack_user_id": config.SlackUserID(),
+				"channelID":     channel.ID(),
+			})
+			return NewChannelNotVisibleErr(config.SlackUserID())
+		}
 	}
 
 	logger.Info("passed")
@@ -182,3 +362,14 @@ func (i invite) inviteeType() string {
 
 	return "unknown"
 }
+
+// quotedList returns the given names quoted and separated by commas, as in
+// 'eng', 'design'.
+func quotedList(names []string) string {
+	quoted := make([]string, len(names))
+	for n, name := range names {
+		quoted[n] = fmt.Sprintf("'%s'", name)
+	}
+
+	return strings.Join(quoted, ", ")
+}
@@ -2,10 +2,14 @@ package action_test
 
 import (
 	"errors"
+	"time"
 
+	"github.com/pivotal-golang/clock/fakeclock"
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotalservices/goulash/action"
 	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/expiry"
+	"github.com/pivotalservices/goulash/expiry/expiryfakes"
 	"github.com/pivotalservices/goulash/slackapi"
 	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
 
@@ -13,25 +17,34 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+var registeredExpiryStore = &expiryfakes.FakeStore{}
+
+func init() {
+	action.RegisterInvites(registeredExpiryStore)
+}
+
 var _ = Describe("Invite", func() {
 	var (
 		a            action.Action
 		c            config.Config
 		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeClock    *fakeclock.FakeClock
 		logger       lager.Logger
 	)
 
 	BeforeEach(func() {
 		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
-		c = config.NewLocalConfig(
-			"slack-auth-token",
-			"/slack-slash-command",
-			"slack-team-name",
-			"slack-user-id",
-			"audit-log-channel-id",
-			"uninvitable-domain.com",
-			"uninvitable-domain-message",
-		)
+		fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{IsMember: true}, nil)
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackAuthToken:     "slack-auth-token",
+			SlackSlashCommand:  "/slack-slash-command",
+			SlackTeamName:      "slack-team-name",
+			SlackUserID:        "slack-user-id",
+			AuditLogChannelID:  "audit-log-channel-id",
+			UninvitableDomain:  "uninvitable-domain.com",
+			UninvitableMessage: "uninvitable-domain-message",
+		})
 
 		logger = lager.NewLogger("testlogger")
 	})
@@ -47,13 +60,40 @@ var _ = Describe("Invite", func() {
 				"invite-guest user@uninvitable-domain.com Tom Smith",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(BeAssignableToTypeOf(expectedErr))
-			Ω(result).Should(Equal(expectedErr.Error()))
+			Ω(result.String()).Should(Equal(expectedErr.Error()))
 
 			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
 		})
 
+		It("returns an error when the email's domain is blocked by the domain policy", func() {
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:    "slack-auth-token",
+				SlackSlashCommand: "/slack-slash-command",
+				SlackTeamName:     "slack-team-name",
+				SlackUserID:       "slack-user-id",
+				AuditLogChannelID: "audit-log-channel-id",
+				DomainPolicy: config.DomainPolicy{
+					Blocked: map[string]string{"example.com": "Employees already have accounts."},
+				},
+			})
+
+			a = action.NewInvite([]string{"user@eng.example.com", "Tom", "Smith"}, "invite-guest", slackapi.NewChannel("channel-name", "channel-id"), "commander-name", "", nil, false, nil)
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(Equal(action.NewUninvitableDomainErr("example.com", "Employees already have accounts.", "/slack-slash-command")))
+			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
+		})
+
+		It("does not treat a domain ending in an uninvitable domain as uninvitable", func() {
+			a = action.NewInvite([]string{"user@not-uninvitable-domain.com", "Tom", "Smith"}, "invite-guest", slackapi.NewChannel("channel-name", "channel-id"), "commander-name", "", nil, false, nil)
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(1))
+		})
+
 		It("returns an error when the channel is not visible", func() {
 			expectedErr := action.NewChannelNotVisibleErr("slack-user-id")
 
@@ -67,15 +107,15 @@ var _ = Describe("Invite", func() {
 				"invite-guest user@example.com Tom Smith",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(BeAssignableToTypeOf(expectedErr))
-			Ω(result).Should(Equal(expectedErr.Error()))
+			Ω(result.String()).Should(Equal(expectedErr.Error()))
 
 			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
 		})
 
-		It("returns an error when the email address is missing", func() {
-			expectedErr := action.NewMissingEmailParameterErr("/slack-slash-command")
+		It("returns a usage error when the email address is missing", func() {
+			expectedErr := action.NewUsageErr("Missing required email parameter.", "invite-guest [email] [firstname] [lastname] [--expires duration]", "/slack-slash-command")
 
 			a = action.New(
 				slackapi.NewChannel("channel-name", "channel-id"),
@@ -84,9 +124,9 @@ var _ = Describe("Invite", func() {
 				"invite-guest",
 			)
 
-			result, err := a.Do(c, &slackapifakes.FakeSlackAPI{}, logger)
-			Ω(err).Should(BeAssignableToTypeOf(expectedErr))
-			Ω(result).Should(Equal(expectedErr.Error()))
+			result, err := a.Do(c, &slackapifakes.FakeSlackAPI{}, fakeClock, logger)
+			Ω(err).Should(Equal(expectedErr))
+			Ω(result.String()).Should(Equal(expectedErr.Error()))
 
 			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
 		})
@@ -99,9 +139,9 @@ var _ = Describe("Invite", func() {
 				"invite-guest user@example.com Tom Smith",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
-			Ω(result).Should(Equal("Successfully invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name'"))
+			Ω(result.String()).Should(Equal("Successfully invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name'"))
 
 			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(1))
 
@@ -121,9 +161,9 @@ var _ = Describe("Invite", func() {
 				"invite-restricted user@example.com Tom Smith",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
-			Ω(result).Should(Equal("Successfully invited Tom Smith (user@example.com) as a restricted account to 'channel-name'"))
+			Ω(result.String()).Should(Equal("Successfully invited Tom Smith (user@example.com) as a restricted account to 'channel-name'"))
 
 			Ω(fakeSlackAPI.InviteRestrictedCallCount()).Should(Equal(1))
 
@@ -136,7 +176,7 @@ var _ = Describe("Invite", func() {
 		})
 
 		It("returns alternate success on 'already_invited' error", func() {
-			fakeSlackAPI.InviteGuestReturns(errors.New("failed: already_invited"))
+			fakeSlackAPI.InviteGuestReturns(slackapi.Error{Code: slackapi.ErrorAlreadyInvited})
 
 			a = action.New(
 				slackapi.NewChannel("channel-name", "channel-id"),
@@ -145,8 +185,8 @@ var _ = Describe("Invite", func() {
 				"invite-guest user@example.com Tom Smith",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
-			Ω(result).Should(Equal("Successfully invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name'"))
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(result.String()).Should(Equal("Successfully invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name'"))
 			Ω(err).ShouldNot(HaveOccurred())
 		})
 
@@ -160,8 +200,8 @@ var _ = Describe("Invite", func() {
 				"invite-guest user@example.com Tom Smith",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
-			Ω(result).Should(Equal("Failed to invite Tom Smith (user@example.com) as a single-channel guest to 'channel-name': failed"))
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(result.String()).Should(Equal("Failed to invite Tom Smith (user@example.com) as a single-channel guest to 'channel-name': failed"))
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("failed"))
 		})
@@ -174,9 +214,9 @@ var _ = Describe("Invite", func() {
 				"invite-guest user@example.com Tom Smith",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
-			Ω(result).Should(Equal("Successfully invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name'"))
+			Ω(result.String()).Should(Equal("Successfully invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name'"))
 		})
 
 		It("attempts to invite a single-channel guest when the args are padded with extra spaces", func() {
@@ -187,9 +227,9 @@ var _ = Describe("Invite", func() {
 				"invite-guest user@example.com  Tom  Smith",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
-			Ω(result).Should(Equal("Successfully invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name'"))
+			Ω(result.String()).Should(Equal("Successfully invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name'"))
 
 			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(1))
 
@@ -200,5 +240,154 @@ var _ = Describe("Invite", func() {
 			Ω(actualLastName).Should(Equal("Smith"))
 			Ω(actualEmailAddress).Should(Equal("user@example.com"))
 		})
+
+		Context("when given --channels", func() {
+			BeforeEach(func() {
+				stubConversations(
+					fakeSlackAPI,
+					[]slackapi.Conversation{{ID: "C1", Name: "eng"}, {ID: "C2", Name: "design"}},
+					[]slackapi.Conversation{{ID: "G1", Name: "secret", IsPrivate: true}},
+				)
+				fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{IsMember: true}, nil)
+			})
+
+			It("invites a restricted account to each of the channels and groups", func() {
+				a = action.New(
+					slackapi.NewChannel("channel-name", "channel-id"),
+					"commander-name",
+					"commander-id",
+					"invite-restricted user@example.com Tom Smith --channels #eng,#design,#secret",
+				)
+
+				result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(result.String()).Should(Equal("Successfully invited Tom Smith (user@example.com) as a restricted account to 'eng', 'design', 'secret'"))
+
+				Ω(fakeSlackAPI.InviteRestrictedCallCount()).Should(Equal(1))
+				_, actualChannelIDs, _, _, _ := fakeSlackAPI.InviteRestrictedArgsForCall(0)
+				Ω(actualChannelIDs).Should(Equal("C1,C2,G1"))
+
+				aa := a.(action.AuditableAction)
+				Ω(aa.AuditMessage(fakeSlackAPI)).Should(Equal("@commander-name invited Tom Smith (user@example.com) as a restricted account to 'eng' (C1), 'design' (C2), 'secret' (G1)"))
+			})
+
+			It("accepts the channels separated by spaces when quoted", func() {
+				a = action.New(
+					slackapi.NewChannel("channel-name", "channel-id"),
+					"commander-name",
+					"commander-id",
+					`invite-restricted user@example.com Tom Smith --channels "#eng #design"`,
+				)
+
+				_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				_, actualChannelIDs, _, _, _ := fakeSlackAPI.InviteRestrictedArgsForCall(0)
+				Ω(actualChannelIDs).Should(Equal("C1,C2"))
+			})
+
+			It("returns an error without inviting anyone when a channel cannot be found", func() {
+				a = action.New(
+					slackapi.NewChannel("channel-name", "channel-id"),
+					"commander-name",
+					"commander-id",
+					"invite-restricted user@example.com Tom Smith --channels #eng,#nope",
+				)
+
+				result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).Should(Equal(action.NewChannelNotFoundErr("nope")))
+				Ω(result.String()).Should(Equal("Channel '#nope' not found."))
+
+				Ω(fakeSlackAPI.InviteRestrictedCallCount()).Should(Equal(0))
+			})
+
+			It("is not accepted by invite-guest", func() {
+				a = action.New(
+					slackapi.NewChannel("channel-name", "channel-id"),
+					"commander-name",
+					"commander-id",
+					"invite-guest user@example.com Tom Smith --channels=#eng",
+				)
+
+				_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring("Unknown option '--channels'."))
+
+				Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
+			})
+		})
+
+		Context("when given --expires", func() {
+			var fakeStore *expiryfakes.FakeStore
+
+			BeforeEach(func() {
+				fakeStore = &expiryfakes.FakeStore{}
+				fakeClock = fakeclock.NewFakeClock(time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC))
+			})
+
+			It("records when the account expires", func() {
+				a = action.NewInvite([]string{"user@example.com", "Tom", "Smith"}, "invite-guest", slackapi.NewChannel("channel-name", "channel-id"), "commander-name", "30d", fakeStore, false, nil)
+
+				result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(result.String()).Should(Equal("Successfully invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name'. Their account will be disabled on Tuesday, May 31, 2016."))
+
+				Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(1))
+				Ω(fakeStore.PutCallCount()).Should(Equal(1))
+				Ω(fakeStore.PutArgsForCall(0)).Should(Equal(expiry.Expiration{
+					EmailAddress: "user@example.com",
+					FirstName:    "Tom",
+					LastName:     "Smith",
+					InviterName:  "commander-name",
+					ChannelID:    "channel-id",
+					ChannelName:  "channel-name",
+					ExpiresAt:    time.Date(2016, 5, 31, 12, 0, 0, 0, time.UTC),
+				}))
+			})
+
+			It("only describes when the account expires for a dry run", func() {
+				a = action.NewInvite([]string{"user@example.com", "Tom", "Smith"}, "invite-guest", slackapi.NewChannel("channel-name", "channel-id"), "commander-name", "30d", fakeStore, true, nil)
+
+				_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(1))
+				Ω(fakeStore.PutCallCount()).Should(Equal(0))
+			})
+
+			It("returns a usage error when the expiry is not a duration", func() {
+				expectedErr := action.NewUsageErr("Expected --expires to be a time such as `30d` or `12h`, but got 'soon'.", "invite-guest [email] [firstname] [lastname] [--expires duration]", "/slack-slash-command")
+
+				a = action.NewInvite([]string{"user@example.com", "Tom", "Smith"}, "invite-guest", slackapi.NewChannel("channel-name", "channel-id"), "commander-name", "soon", fakeStore, false, nil)
+
+				result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).Should(Equal(expectedErr))
+				Ω(result.String()).Should(Equal(expectedErr.Error()))
+
+				Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
+				Ω(fakeStore.PutCallCount()).Should(Equal(0))
+			})
+
+			It("returns an error when guest expiry is not enabled", func() {
+				a = action.NewInvite([]string{"user@example.com", "Tom", "Smith"}, "invite-guest", slackapi.NewChannel("channel-name", "channel-id"), "commander-name", "30d", nil, false, nil)
+
+				_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).Should(Equal(action.NewGuestExpiryDisabledErr("/slack-slash-command")))
+
+				Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
+			})
+
+			It("reports the invitation as well as the failure when the expiry cannot be recorded", func() {
+				fakeStore.PutReturns(errors.New("put-err"))
+
+				a = action.NewInvite([]string{"user@example.com", "Tom", "Smith"}, "invite-guest", slackapi.NewChannel("channel-name", "channel-id"), "commander-name", "30d", fakeStore, false, nil)
+
+				result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).Should(MatchError("put-err"))
+				Ω(result.String()).Should(Equal("Successfully invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name', but failed to record when their account expires: put-err"))
+
+				Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(1))
+			})
+		})
 	})
 })
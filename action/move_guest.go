@@ -0,0 +1,219 @@
+package action
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/slack"
+)
+
+func init() {
+	Register(Command{
+		Name:        "move-guest",
+		Params:      []Param{{Name: "email|@username"}, {Name: "#channel"}},
+		Description: "Move a Single-Channel Guest from their channel/group to another",
+		Mutating:    true,
+		Destructive: true,
+		Permission:  &config.PolicyRule{Admins: true},
+		New: func(r Request) Action {
+			return NewMoveGuest(r.Params, r.CommanderName)
+		},
+	})
+}
+
+type moveGuest struct {
+	params     []string
+	movingUser string
+
+	// from and to are the guest's channel before and after the move, once
+	// they have been found. from is nil if the guest's channel is unknown.
+	from slackapi.Channel
+	to   slackapi.Channel
+
+	// userID is the ID of the guest, once they have been found.
+	userID string
+
+	// pinnedUserID, when set, is the only user the command may act on.
+	pinnedUserID string
+}
+
+func (m moveGuest) searchVal() string {
+	return m.params[0]
+}
+
+func (m moveGuest) channelName() string {
+	return strings.TrimPrefix(m.params[1], "#")
+}
+
+// NewMoveGuest returns a new move guest action, used to move a Single-Channel
+// Guest to the channel or group named in params.
+func NewMoveGuest(params []string, movingUser string) Action {
+	moveGuestParams := []string{"", ""}
+	copy(moveGuestParams, params)
+
+	return &moveGuest{
+		params:     moveGuestParams,
+		movingUser: movingUser,
+	}
+}
+
+func (m *moveGuest) pinUser(userID string) {
+	m.pinnedUserID = userID
+}
+
+func (m *moveGuest) Do(
+	config config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) (slackapi.Message, error) {
+	logger = logger.Session("do")
+
+	user, err := m.check(config, api, logger)
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(m.failureMessage(err)), err
+	}
+
+	m.userID = user.ID
+
+	err = api.SetUltraRestricted(config.SlackTeamName(), user.ID, m.to.ID())
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(m.failureMessage(err)), err
+	}
+
+	logger.Info("succeeded")
+
+	return slackapi.NewTextMessage(fmt.Sprintf(
+		"Successfully moved single-channel guest '%s' from %s to '%s'",
+		m.searchVal(),
+		m.fromName(api),
+		m.to.Name(api),
+	)), nil
+}
+
+func (m *moveGuest) AuditMessage(api slackapi.SlackAPI) string {
+	if m.to == nil {
+		return fmt.Sprintf(
+			"@%s moved single-channel guest %s to '%s'",
+			m.movingUser,
+			m.searchVal(),
+			m.channelName(),
+		)
+	}
+
+	from := "an unknown channel"
+	if m.from != nil {
+		from = fmt.Sprintf("'%s' (%s)", m.from.Name(api), m.from.ID())
+	}
+
+	return fmt.Sprintf(
+		"@%s moved single-channel guest %s from %s to '%s' (%s)",
+		m.movingUser,
+		m.searchVal(),
+		from,
+		m.to.Name(api),
+		m.to.ID(),
+	)
+}
+
+func (m *moveGuest) AuditTarget() string {
+	return userTarget(m.userID, m.searchVal())
+}
+
+func (m *moveGuest) fromName(api slackapi.SlackAPI) string {
+	if m.from == nil {
+		return "an unknown channel"
+	}
+
+	return fmt.Sprintf("'%s'", m.from.Name(api))
+}
+
+func (m *moveGuest) failureMessage(err error) string {
+	return fmt.Sprintf(
+		"Failed to move single-channel guest '%s' to '%s': %s",
+		m.searchVal(),
+		m.channelName(),
+		NewSlackErr(err).Error(),
+	)
+}
+
+// check returns the guest to move, provided that they are a Single-Channel
+// Guest and the channel they are to be moved to is visible and is not the one
+// they are already in.
+func (m *moveGuest) check(
+	config config.Config,
+	api slackapi.SlackAPI,
+	logger lager.Logger,
+) (slack.User, error) {
+	logger = logger.Session("check")
+
+	user, err := findUserToChange(m.searchVal(), m.pinnedUserID, api)
+	if err != nil {
+		logger.Error("failed", err)
+		return slack.User{}, err
+	}
+
+	if !(user.IsRestricted || user.IsUltraRestricted) {
+		err = NewFullUserCannotBeErr("moved")
+		logger.Error("failed", err)
+		return slack.User{}, err
+	}
+
+	if !user.IsUltraRestricted {
+		err = NewUserIsNotErr("single-channel guest")
+		logger.Error("failed", err)
+		return slack.User{}, err
+	}
+
+	to, err := findChannelOrGroup(m.channelName(), api)
+	if err != nil {
+		logger.Error("failed", err)
+		return slack.User{}, err
+	}
+
+	if !to.Visible(api) {
+		err = NewChannelNotVisibleErr(config.SlackUserID())
+		logger.Error("failed", err, lager.Data{"channelID": to.ID()})
+		return slack.User{}, err
+	}
+
+	from, err := memberChannel(user.ID, api)
+	if err != nil {
+		logger.Error("failed", err)
+		return slack.User{}, err
+	}
+
+	if from != nil && from.ID() == to.ID() {
+		err = NewUserIsAlreadyErr(fmt.Sprintf("single-channel guest in '%s'", to.Name(api)))
+		logger.Error("failed", err)
+		return slack.User{}, err
+	}
+
+	m.from = from
+	m.to = to
+
+	logger.Info("passed", lager.Data{"from": from != nil})
+
+	return user, nil
+}
+
+// memberChannel returns the first public or private channel the configured
+// user can see that the given user is a member of, or nil if there is none.
+func memberChannel(userID string, api slackapi.SlackAPI) (slackapi.Channel, error) {
+	channels, err := memberChannels(userID, api)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(channels) == 0 {
+		return nil, nil
+	}
+
+	return slackapi.NewChannel(channels[0].Name, channels[0].ID), nil
+}
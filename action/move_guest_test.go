@@ -0,0 +1,172 @@
+package action_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MoveGuest", func() {
+	var (
+		c            config.Config
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeClock    *fakeclock.FakeClock
+		logger       lager.Logger
+	)
+
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackAuthToken:    "slack-auth-token",
+			SlackSlashCommand: "/slack-slash-command",
+			SlackTeamName:     "slack-team-name",
+			SlackUserID:       "slack-user-id",
+			AuditLogChannelID: "audit-log-channel-id",
+		})
+
+		logger = lager.NewLogger("testlogger")
+
+		stubUsers(fakeSlackAPI, []slack.User{
+			{
+				ID:                "U1234",
+				Name:              "tsmith",
+				IsUltraRestricted: true,
+			},
+		})
+
+		stubConversations(
+			fakeSlackAPI,
+			[]slackapi.Conversation{{ID: "C1", Name: "eng"}, {ID: "C2", Name: "design"}},
+			[]slackapi.Conversation{{ID: "G1", Name: "secret", IsPrivate: true}},
+		)
+		stubMembers(fakeSlackAPI, map[string][]string{"C1": {"U9999", "U1234"}})
+		fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{IsMember: true}, nil)
+	})
+
+	newMoveGuest := func(text string) action.Action {
+		return action.NewConfirmed(
+			slackapi.NewChannel("channel-name", "channel-id"),
+			"commander-name",
+			"commander-id",
+			text,
+		)
+	}
+
+	Describe("Do", func() {
+		It("moves the guest to the channel", func() {
+			result, err := newMoveGuest("move-guest @tsmith #design").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(Equal("Successfully moved single-channel guest '@tsmith' from 'eng' to 'design'"))
+
+			Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(1))
+			teamName, userID, channelID := fakeSlackAPI.SetUltraRestrictedArgsForCall(0)
+			Ω(teamName).Should(Equal("slack-team-name"))
+			Ω(userID).Should(Equal("U1234"))
+			Ω(channelID).Should(Equal("C2"))
+		})
+
+		It("moves the guest to a private group", func() {
+			_, err := newMoveGuest("move-guest @tsmith #secret").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			_, _, channelID := fakeSlackAPI.SetUltraRestrictedArgsForCall(0)
+			Ω(channelID).Should(Equal("G1"))
+		})
+
+		It("moves the guest even when their current channel cannot be seen", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{
+					ID:                "U5678",
+					Name:              "jdoe",
+					IsUltraRestricted: true,
+				},
+			})
+
+			result, err := newMoveGuest("move-guest @jdoe #design").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(Equal("Successfully moved single-channel guest '@jdoe' from an unknown channel to 'design'"))
+		})
+
+		It("returns an error if the guest is already in the channel", func() {
+			_, err := newMoveGuest("move-guest @tsmith #eng").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("User is already a single-channel guest in 'eng'."))
+
+			Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error if the channel cannot be found", func() {
+			result, err := newMoveGuest("move-guest @tsmith #nope").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(Equal(action.NewChannelNotFoundErr("nope")))
+			Ω(result.String()).Should(Equal("Failed to move single-channel guest '@tsmith' to 'nope': Channel '#nope' not found."))
+
+			Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error if the user is a restricted account", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{
+					ID:           "U1234",
+					Name:         "tsmith",
+					IsRestricted: true,
+				},
+			})
+
+			_, err := newMoveGuest("move-guest @tsmith #design").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("User is not a single-channel guest."))
+
+			Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error if the user is a full user", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{
+					ID:   "U1234",
+					Name: "tsmith",
+				},
+			})
+
+			_, err := newMoveGuest("move-guest @tsmith #design").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("Full users cannot be moved."))
+		})
+
+		It("returns an error when moving the guest fails", func() {
+			fakeSlackAPI.SetUltraRestrictedReturns(errors.New("failed"))
+
+			result, err := newMoveGuest("move-guest @tsmith #design").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("failed"))
+			Ω(result.String()).Should(Equal("Failed to move single-channel guest '@tsmith' to 'design': failed"))
+		})
+	})
+
+	Describe("AuditMessage", func() {
+		It("names the old and new channels", func() {
+			a := newMoveGuest("move-guest @tsmith #design")
+			a.Do(c, fakeSlackAPI, fakeClock, logger)
+
+			ta, ok := a.(action.TargetedAction)
+			Ω(ok).Should(BeTrue())
+			Ω(ta.AuditMessage(fakeSlackAPI)).Should(Equal("@commander-name moved single-channel guest @tsmith from 'eng' (C1) to 'design' (C2)"))
+			Ω(ta.AuditTarget()).Should(Equal("U1234"))
+		})
+
+		It("names the requested channel when the move was not attempted", func() {
+			a := newMoveGuest("move-guest @tsmith #nope")
+			a.Do(c, fakeSlackAPI, fakeClock, logger)
+
+			ta := a.(action.TargetedAction)
+			Ω(ta.AuditMessage(fakeSlackAPI)).Should(Equal("@commander-name moved single-channel guest @tsmith to 'nope'"))
+			Ω(ta.AuditTarget()).Should(Equal("@tsmith"))
+		})
+	})
+})
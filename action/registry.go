@@ -0,0 +1,146 @@
+package action
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+)
+
+// Command describes a command that can be run through the Slash Command:
+// how it is named, what it accepts, how it is described in help, who may run
+// it, and how to create the Action that performs it.
+type Command struct {
+	Name        string
+	Aliases     []string
+	Params      []Param
+	Options     []Option
+	Description string
+
+	// Describe, when set, returns the description shown in help in place of
+	// Description, for descriptions which depend on the configuration.
+	Describe func(config.Config) string
+
+	// Lines is true for commands which read input from the lines after the
+	// first, rather than treating them as more parameters.
+	Lines bool
+
+	// Mutating is true for commands which change Slack. They may be given
+	// --dry-run to describe the changes instead of making them.
+	Mutating bool
+
+	// Destructive is true for commands which act on the user named by their
+	// first parameter in a way that is hard to undo. Unless given --dry-run,
+	// they show who that user is and wait for the commander to confirm. Their
+	// Actions implement pinUser, so that once confirmed they act only on the
+	// user shown.
+	Destructive bool
+
+	// Permission describes who may run the command when the configured
+	// Policy has no rule of its own for it. When nil, the Policy's default
+	// rule applies, if any.
+	Permission *config.PolicyRule
+
+	// New returns the Action for a Request whose parameters and options have
+	// already been checked against those of the command.
+	New func(Request) Action
+}
+
+// Param describes a positional parameter of a Command.
+type Param struct {
+	Name     string
+	Optional bool
+}
+
+// Option describes an option of a Command, given as --name.
+type Option struct {
+	Name string
+
+	// Value names the value the option takes, given as --name value or
+	// --name=value, such as "duration". It is empty for options given alone.
+	Value string
+}
+
+// Request is a command to be run, as given to Command.New.
+type Request struct {
+	Command       string
+	Params        []string
+	Options       map[string]string
+	Lines         []string
+	Channel       slackapi.Channel
+	CommanderName string
+	CommanderID   string
+
+	// DryRun is true when the command is to be performed as a dry run,
+	// through a SlackAPI which changes nothing. Commands which change
+	// anything other than Slack must only describe those changes instead.
+	DryRun bool
+}
+
+var registry = struct {
+	sync.RWMutex
+	commands map[string]Command
+	names    map[string]string
+}{
+	commands: map[string]Command{},
+	names:    map[string]string{},
+}
+
+// Register makes a Command available through New, Authorize, and help. It
+// panics if the Command has no name or New function, or if its name or any of
+// its aliases is already taken, as these are programming errors.
+func Register(command Command) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if command.Name == "" || command.New == nil {
+		panic("action: Register requires a command with a name and a New function")
+	}
+
+	for _, name := range append([]string{command.Name}, command.Aliases...) {
+		if name == helpCommand {
+			panic(fmt.Sprintf("action: Register called with reserved name %q", name))
+		}
+
+		if _, taken := registry.names[name]; taken {
+			panic(fmt.Sprintf("action: Register called twice for %q", name))
+		}
+	}
+
+	registry.commands[command.Name] = command
+	for _, name := range append([]string{command.Name}, command.Aliases...) {
+		registry.names[name] = command.Name
+	}
+}
+
+// lookUpCommand returns the Command registered with the given name or alias.
+func lookUpCommand(name string) (Command, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	command, ok := registry.commands[registry.names[name]]
+	return command, ok
+}
+
+// registeredCommands returns every registered Command, ordered by name.
+func registeredCommands() []Command {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	var commands []Command
+	for _, command := range registry.commands {
+		commands = append(commands, command)
+	}
+
+	sort.Sort(byName(commands))
+
+	return commands
+}
+
+type byName []Command
+
+func (c byName) Len() int           { return len(c) }
+func (c byName) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c byName) Less(i, j int) bool { return c[i].Name < c[j].Name }
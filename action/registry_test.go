@@ -0,0 +1,142 @@
+package action_test
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type echo struct {
+	request action.Request
+}
+
+func (e echo) Do(
+	config config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) (slackapi.Message, error) {
+	return slackapi.NewTextMessage(strings.Join(e.request.Params, " ")), nil
+}
+
+func init() {
+	action.Register(action.Command{
+		Name:        "test-echo",
+		Aliases:     []string{"test-say"},
+		Params:      []action.Param{{Name: "word"}, {Name: "another-word", Optional: true}},
+		Options:     []action.Option{{Name: "loud"}, {Name: "times", Value: "number"}},
+		Description: "Repeat the given words",
+		Permission:  &config.PolicyRule{UserIDs: []string{"U9999"}},
+		New: func(r action.Request) action.Action {
+			return echo{request: r}
+		},
+	})
+}
+
+var _ = Describe("Register", func() {
+	var (
+		channel      slackapi.Channel
+		c            config.Config
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeClock    *fakeclock.FakeClock
+		logger       lager.Logger
+	)
+
+	BeforeEach(func() {
+		channel = slackapi.NewChannel("channel-name", "channel-id")
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackSlashCommand: "/slack-slash-command",
+		})
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		logger = lager.NewLogger("testlogger")
+	})
+
+	It("makes the command available to New by name and by alias", func() {
+		for _, text := range []string{"test-echo hello --loud", "test-say hello --loud"} {
+			a := action.New(channel, "commander-name", "commander-id", text)
+
+			Ω(a).Should(Equal(echo{request: action.Request{
+				Command:       "test-echo",
+				Params:        []string{"hello"},
+				Options:       map[string]string{"loud": "true"},
+				Lines:         []string{},
+				Channel:       channel,
+				CommanderName: "commander-name",
+				CommanderID:   "commander-id",
+			}}))
+		}
+	})
+
+	It("checks the parameters given against those of the command", func() {
+		a := action.New(channel, "commander-name", "commander-id", "test-say")
+
+		_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+		Ω(err).Should(MatchError("Missing required word parameter. Usage: `/slack-slash-command test-echo [word] [another-word] [--loud] [--times number]`"))
+	})
+
+	It("describes the command in help", func() {
+		a := action.New(channel, "commander-name", "commander-id", "help")
+
+		result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(result.Text).Should(ContainSubstring("`test-echo [word] [another-word] [--loud] [--times number]`\n_Repeat the given words_\nAlso: `test-say`"))
+	})
+
+	Describe("authorization", func() {
+		configWithPolicy := func(policy config.Policy) config.Config {
+			return config.NewLocalConfig(config.LocalConfigOptions{
+				SlackSlashCommand: "/slack-slash-command",
+				Policy:            policy,
+			})
+		}
+
+		It("applies the command's permission when the policy has no rule for it", func() {
+			c := configWithPolicy(config.Policy{"*": config.PolicyRule{UserIDs: []string{"U1234"}}})
+
+			err := action.Authorize("test-say hello", "U1234", c, fakeSlackAPI, logger)
+			Ω(err).Should(Equal(action.NewNotPermittedErr("test-echo", "/slack-slash-command")))
+
+			err = action.Authorize("test-say hello", "U9999", c, fakeSlackAPI, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("applies the policy's rule for the command instead of its permission", func() {
+			c := configWithPolicy(config.Policy{"test-echo": config.PolicyRule{UserIDs: []string{"U1234"}}})
+
+			err := action.Authorize("test-say hello", "U1234", c, fakeSlackAPI, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	It("panics when a name or alias is already taken", func() {
+		Ω(func() {
+			action.Register(action.Command{
+				Name:    "test-repeat",
+				Aliases: []string{"test-say"},
+				New: func(r action.Request) action.Action {
+					return echo{request: r}
+				},
+			})
+		}).Should(Panic())
+
+		Ω(func() {
+			action.Register(action.Command{
+				Name: "info",
+				New: func(r action.Request) action.Action {
+					return echo{request: r}
+				},
+			})
+		}).Should(Panic())
+	})
+})
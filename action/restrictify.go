@@ -3,16 +3,33 @@ package action
 import (
 	"fmt"
 
+	"github.com/pivotal-golang/clock"
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotalservices/goulash/config"
 	"github.com/pivotalservices/goulash/slackapi"
 	"github.com/pivotalservices/slack"
 )
 
+func init() {
+	Register(Command{
+		Name:        "restrictify",
+		Params:      []Param{{Name: "email|@username"}},
+		Description: "Convert a Single-Channel Guest to a Restricted Account",
+		Permission:  &config.PolicyRule{Admins: true},
+		Mutating:    true,
+		New: func(r Request) Action {
+			return NewRestrictify(r.Params, r.Channel, r.CommanderName)
+		},
+	})
+}
+
 type restrictify struct {
 	params          []string
 	channel         slackapi.Channel
 	restrictingUser string
+
+	// userID is the ID of the user, once they have been found.
+	userID string
 }
 
 func (r restrictify) searchVal() string {
@@ -27,9 +44,7 @@ func NewRestrictify(
 	restrictingUser string,
 ) Action {
 	restrictifyParams := []string{""}
-	for i := range params {
-		restrictifyParams[i] = params[i]
-	}
+	copy(restrictifyParams, params)
 	return &restrictify{
 		params:          restrictifyParams,
 		channel:         channel,
@@ -37,11 +52,12 @@ func NewRestrictify(
 	}
 }
 
-func (r restrictify) Do(
+func (r *restrictify) Do(
 	config config.Config,
 	api slackapi.SlackAPI,
+	clock clock.Clock,
 	logger lager.Logger,
-) (string, error) {
+) (slackapi.Message, error) {
 	logger = logger.Session("do")
 
 	searchVal := r.searchVal()
@@ -49,23 +65,25 @@ func (r restrictify) Do(
 	user, err := r.check(searchVal, config, api, logger)
 	if err != nil {
 		logger.Error("check-failed", err)
-		return r.failureMessage(err), err
+		return slackapi.NewErrorMessage(r.failureMessage(err)), err
 	}
 
+	r.userID = user.ID
+
 	err = api.SetRestricted(config.SlackTeamName(), user.ID)
 	if err != nil {
 		logger.Error("failed-restrictifying", err)
-		return r.failureMessage(err), err
+		return slackapi.NewErrorMessage(r.failureMessage(err)), err
 	}
 
-	return fmt.Sprintf("Successfully restrictified user %s", searchVal), nil
+	return slackapi.NewTextMessage(fmt.Sprintf("Successfully restrictified user %s", searchVal)), nil
 }
 
 func (r restrictify) failureMessage(err error) string {
 	return fmt.Sprintf(
 		"Failed to restrictify user '%s': %s",
 		r.searchVal(),
-		err.Error(),
+		NewSlackErr(err).Error(),
 	)
 }
 
@@ -77,6 +95,10 @@ func (r restrictify) AuditMessage(api slackapi.SlackAPI) string {
 	)
 }
 
+func (r *restrictify) AuditTarget() string {
+	return userTarget(r.userID, r.searchVal())
+}
+
 func (r restrictify) check(
 	searchVal string,
 	config config.Config,
@@ -89,7 +111,7 @@ func (r restrictify) check(
 		return slack.User{}, NewCannotFromDirectMessageErr("restrictify")
 	}
 
-	user, err := findUser(searchVal, api)
+	user, err := findUserToChange(searchVal, "", api)
 	if err != nil {
 		return slack.User{}, err
 	}
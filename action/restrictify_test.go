@@ -2,7 +2,9 @@ package action_test
 
 import (
 	"errors"
+	"time"
 
+	"github.com/pivotal-golang/clock/fakeclock"
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotalservices/goulash/action"
 	"github.com/pivotalservices/goulash/config"
@@ -19,25 +21,26 @@ var _ = Describe("Restrictify", func() {
 		var (
 			c            config.Config
 			fakeSlackAPI *slackapifakes.FakeSlackAPI
+			fakeClock    *fakeclock.FakeClock
 			logger       lager.Logger
 		)
 
 		BeforeEach(func() {
 			fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+			fakeClock = fakeclock.NewFakeClock(time.Now())
 			logger = lager.NewLogger("testlogger")
-			c = config.NewLocalConfig(
-				"slack-auth-token",
-				"/slack-slash-command",
-				"slack-team-name",
-				"slack-user-id",
-				"",
-				"uninvitable-domain.com",
-				"uninvitable-domain-message",
-			)
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:     "slack-auth-token",
+				SlackSlashCommand:  "/slack-slash-command",
+				SlackTeamName:      "slack-team-name",
+				SlackUserID:        "slack-user-id",
+				UninvitableDomain:  "uninvitable-domain.com",
+				UninvitableMessage: "uninvitable-domain-message",
+			})
 		})
 
 		It("returns an error if the user can't be found due to error", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{}, errors.New("error"))
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{}, "", errors.New("error"))
 
 			a := action.New(
 				slackapi.NewChannel("channel-name", "channel-id"),
@@ -46,16 +49,16 @@ var _ = Describe("Restrictify", func() {
 				"restrictify user@example.com",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("error"))
-			Ω(result).Should(Equal("Failed to restrictify user 'user@example.com': error"))
+			Ω(result.String()).Should(Equal("Failed to restrictify user 'user@example.com': error"))
 
 			Ω(fakeSlackAPI.SetRestrictedCallCount()).Should(Equal(0))
 		})
 
 		It("returns an error if the user cannot be found", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{}, nil)
+			stubUsers(fakeSlackAPI, []slack.User{})
 
 			a := action.New(
 				slackapi.NewChannel("channel-name", "channel-id"),
@@ -64,23 +67,42 @@ var _ = Describe("Restrictify", func() {
 				"restrictify user@example.com",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("Unable to find user matching 'user@example.com'."))
-			Ω(result).Should(Equal("Failed to restrictify user 'user@example.com': Unable to find user matching 'user@example.com'."))
+			Ω(result.String()).Should(Equal("Failed to restrictify user 'user@example.com': Unable to find user matching 'user@example.com'."))
+
+			Ω(fakeSlackAPI.SetRestrictedCallCount()).Should(Equal(0))
+		})
+
+		It("refuses to guess when several users match", func() {
+			stubUsers(fakeSlackAPI, []slack.User{
+				{ID: "U1234", Name: "tsmith", RealName: "Tom Smith", IsUltraRestricted: true},
+				{ID: "U5678", Name: "tsmith2", RealName: "Tom Smith", IsUltraRestricted: true},
+			})
+
+			a := action.New(
+				slackapi.NewChannel("channel-name", "channel-id"),
+				"commander-name",
+				"commander-id",
+				`restrictify "Tom Smith"`,
+			)
+
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(Equal(action.NewAmbiguousUserErr("Tom Smith", []string{"@tsmith", "@tsmith2"})))
 
 			Ω(fakeSlackAPI.SetRestrictedCallCount()).Should(Equal(0))
 		})
 
 		It("returns an error if the user is a full user", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:                "U1234",
 					Name:              "tsmith",
 					IsRestricted:      false,
 					IsUltraRestricted: false,
 				},
-			}, nil)
+			})
 
 			a := action.New(
 				slackapi.NewChannel("channel-name", "channel-id"),
@@ -89,23 +111,23 @@ var _ = Describe("Restrictify", func() {
 				"restrictify @tsmith",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("Full users cannot be restrictified."))
-			Ω(result).Should(Equal("Failed to restrictify user '@tsmith': Full users cannot be restrictified."))
+			Ω(result.String()).Should(Equal("Failed to restrictify user '@tsmith': Full users cannot be restrictified."))
 
 			Ω(fakeSlackAPI.SetRestrictedCallCount()).Should(Equal(0))
 		})
 
 		It("returns an error if the user is already a restricted account", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:                "U1234",
 					Name:              "tsmith",
 					IsRestricted:      true,
 					IsUltraRestricted: false,
 				},
-			}, nil)
+			})
 
 			a := action.New(
 				slackapi.NewChannel("channel-name", "channel-id"),
@@ -114,10 +136,10 @@ var _ = Describe("Restrictify", func() {
 				"restrictify @tsmith",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("User is already a restricted account."))
-			Ω(result).Should(Equal("Failed to restrictify user '@tsmith': User is already a restricted account."))
+			Ω(result.String()).Should(Equal("Failed to restrictify user '@tsmith': User is already a restricted account."))
 
 			Ω(fakeSlackAPI.SetRestrictedCallCount()).Should(Equal(0))
 		})
@@ -130,22 +152,22 @@ var _ = Describe("Restrictify", func() {
 				"restrictify @tsmith",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
 			Ω(err.Error()).Should(Equal("Cannot restrictify from a direct message. Try again from a channel or group."))
-			Ω(result).Should(Equal("Failed to restrictify user '@tsmith': Cannot restrictify from a direct message. Try again from a channel or group."))
+			Ω(result.String()).Should(Equal("Failed to restrictify user '@tsmith': Cannot restrictify from a direct message. Try again from a channel or group."))
 
 			Ω(fakeSlackAPI.SetRestrictedCallCount()).Should(Equal(0))
 		})
 
 		It("attempts to restrictify the user if they can be found by name", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:                "U1234",
 					Name:              "tsmith",
 					IsUltraRestricted: true,
 				},
-			}, nil)
+			})
 
 			a := action.New(
 				slackapi.NewChannel("channel-name", "channel-id"),
@@ -154,7 +176,7 @@ var _ = Describe("Restrictify", func() {
 				"restrictify @tsmith",
 			)
 
-			_, err := a.Do(c, fakeSlackAPI, logger)
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
 
 			Ω(fakeSlackAPI.SetRestrictedCallCount()).Should(Equal(1))
@@ -165,7 +187,7 @@ var _ = Describe("Restrictify", func() {
 		})
 
 		It("attempts to restrictify the user if they can be found by email", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:                "U1234",
 					IsUltraRestricted: true,
@@ -173,7 +195,7 @@ var _ = Describe("Restrictify", func() {
 						Email: "user@example.com",
 					},
 				},
-			}, nil)
+			})
 
 			a := action.New(
 				slackapi.NewChannel("channel-name", "channel-id"),
@@ -182,7 +204,7 @@ var _ = Describe("Restrictify", func() {
 				"restrictify user@example.com",
 			)
 
-			_, err := a.Do(c, fakeSlackAPI, logger)
+			_, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
 
 			Ω(fakeSlackAPI.SetRestrictedCallCount()).Should(Equal(1))
@@ -193,13 +215,13 @@ var _ = Describe("Restrictify", func() {
 		})
 
 		It("returns an error when restrictifying fails", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:                "U1234",
 					Name:              "tsmith",
 					IsUltraRestricted: true,
 				},
-			}, nil)
+			})
 
 			a := action.New(
 				slackapi.NewChannel("channel-name", "channel-id"),
@@ -210,19 +232,19 @@ var _ = Describe("Restrictify", func() {
 
 			fakeSlackAPI.SetRestrictedReturns(errors.New("failed"))
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).Should(HaveOccurred())
-			Ω(result).Should(Equal("Failed to restrictify user '@tsmith': failed"))
+			Ω(result.String()).Should(Equal("Failed to restrictify user '@tsmith': failed"))
 		})
 
 		It("returns nil when restrictifying succeeds", func() {
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			stubUsers(fakeSlackAPI, []slack.User{
 				{
 					ID:                "U1234",
 					Name:              "tsmith",
 					IsUltraRestricted: true,
 				},
-			}, nil)
+			})
 
 			a := action.New(
 				slackapi.NewChannel("channel-name", "channel-id"),
@@ -231,9 +253,9 @@ var _ = Describe("Restrictify", func() {
 				"restrictify @tsmith",
 			)
 
-			result, err := a.Do(c, fakeSlackAPI, logger)
+			result, err := a.Do(c, fakeSlackAPI, fakeClock, logger)
 			Ω(err).ShouldNot(HaveOccurred())
-			Ω(result).Should(Equal("Successfully restrictified user @tsmith"))
+			Ω(result.String()).Should(Equal("Successfully restrictified user @tsmith"))
 		})
 	})
 
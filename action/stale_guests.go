@@ -0,0 +1,185 @@
+package action
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/slack"
+)
+
+const (
+	staleGuestsCommand = "stale-guests"
+
+	// LastActiveDateFormat is how the day a stale guest was last active is
+	// shown.
+	LastActiveDateFormat = "2006-01-02"
+)
+
+func init() {
+	Register(Command{
+		Name:        staleGuestsCommand,
+		Options:     []Option{{Name: "inactive", Value: "time"}},
+		Description: "List the Restricted Accounts and Single-Channel Guests who have not been active for a time such as `60d`, by default the configured one",
+		Permission:  &config.PolicyRule{Admins: true},
+		New: func(r Request) Action {
+			return NewStaleGuests(r.Options["inactive"], r.CommanderName)
+		},
+	})
+}
+
+// StaleGuest is a Restricted Account or Single-Channel Guest who has not been
+// active recently. LastActive is the zero time when no activity of theirs is
+// recorded.
+type StaleGuest struct {
+	User       slack.User
+	LastActive time.Time
+}
+
+// SearchVal returns the email address or @username FindUser finds the guest
+// by.
+func (g StaleGuest) SearchVal() string {
+	if g.User.Profile.Email != "" {
+		return g.User.Profile.Email
+	}
+
+	return "@" + g.User.Name
+}
+
+func (g StaleGuest) String() string {
+	line := "@" + g.User.Name
+	if g.User.Profile.Email != "" {
+		line += fmt.Sprintf(" (%s)", g.User.Profile.Email)
+	}
+
+	lastActive := "no activity recorded"
+	if !g.LastActive.IsZero() {
+		lastActive = "last active " + g.LastActive.UTC().Format(LastActiveDateFormat)
+	}
+
+	return fmt.Sprintf("%s, %s, %s", line, guest{user: g.User}.kind(), lastActive)
+}
+
+// Inactivity returns why the guest is stale, to follow "as".
+func (g StaleGuest) Inactivity() string {
+	if g.LastActive.IsZero() {
+		return "no activity of theirs is recorded"
+	}
+
+	return "they have not been active since " + g.LastActive.UTC().Format(LastActiveDateFormat)
+}
+
+// FindStaleGuests returns the active Restricted Accounts and Single-Channel
+// Guests who were last active longer than inactive before now, as recorded in
+// the team's access logs. Guests with no activity in the logs are returned
+// too. Full members are never among them.
+func FindStaleGuests(
+	api slackapi.SlackAPI,
+	inactive time.Duration,
+	now time.Time,
+	logger lager.Logger,
+) ([]StaleGuest, error) {
+	logger = logger.Session("find-stale-guests")
+
+	users, err := slackapi.AllUsers(api)
+	if err != nil {
+		logger.Error("failed", err)
+		return nil, err
+	}
+
+	lastActivities, err := api.GetLastActivities()
+	if err != nil {
+		logger.Error("failed", err)
+		return nil, err
+	}
+
+	cutoff := now.Add(-inactive)
+
+	var staleGuests []StaleGuest
+	for _, user := range users {
+		if user.Deleted || !(user.IsRestricted || user.IsUltraRestricted) {
+			continue
+		}
+
+		lastActive := lastActivities[user.ID]
+		if lastActive.After(cutoff) {
+			continue
+		}
+
+		staleGuests = append(staleGuests, StaleGuest{User: user, LastActive: lastActive})
+	}
+
+	logger.Info("succeeded", lager.Data{"staleGuests": len(staleGuests)})
+
+	return staleGuests, nil
+}
+
+type staleGuests struct {
+	inactive      string
+	commanderName string
+}
+
+// NewStaleGuests returns a new stale guests action, used to list the
+// Restricted Accounts and Single-Channel Guests who have not been active for
+// the given time, or for the configured time if it is empty.
+func NewStaleGuests(inactive string, commanderName string) Action {
+	return &staleGuests{
+		inactive:      inactive,
+		commanderName: commanderName,
+	}
+}
+
+func (s staleGuests) Do(
+	c config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) (slackapi.Message, error) {
+	logger = logger.Session("do")
+
+	inactive := c.StaleGuestInactivity()
+	if s.inactive != "" {
+		var ok bool
+		inactive, ok = parseDuration(s.inactive)
+		if !ok || inactive == 0 {
+			command, _ := lookUpCommand(staleGuestsCommand)
+			err := NewUsageErr(fmt.Sprintf(invalidInactiveProblemFmt, s.inactive), command.usage(), c.SlackSlashCommand())
+			logger.Error("failed", err)
+			return slackapi.NewErrorMessage(err.Error()), err
+		}
+	}
+
+	now := clock.Now()
+	found, err := FindStaleGuests(api, inactive, now, logger)
+	if err != nil {
+		logger.Error("failed", err)
+		return slackapi.NewErrorMessage(fmt.Sprintf("Failed to list the stale guests: %s", NewSlackErr(err).Error())), err
+	}
+
+	logger.Info("succeeded", lager.Data{"staleGuests": len(found)})
+
+	since := now.Add(-inactive).UTC().Format(LastActiveDateFormat)
+	if len(found) == 0 {
+		return slackapi.NewTextMessage(fmt.Sprintf("Found no guests inactive since %s.", since)), nil
+	}
+
+	lines := []string{fmt.Sprintf("Found %d guests inactive since %s.", len(found), since)}
+	for _, staleGuest := range found {
+		lines = append(lines, staleGuest.String())
+	}
+
+	message := slackapi.Message{
+		Text:   strings.Join(lines, "\n"),
+		Blocks: slackapi.NewSectionBlocks(lines),
+	}
+
+	return message.Fit(), nil
+}
+
+func (s staleGuests) AuditMessage(api slackapi.SlackAPI) string {
+	return fmt.Sprintf("@%s requested the list of stale guests", s.commanderName)
+}
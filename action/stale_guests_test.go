@@ -0,0 +1,185 @@
+package action_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StaleGuests", func() {
+	var (
+		c            config.Config
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeClock    *fakeclock.FakeClock
+		logger       lager.Logger
+		lastActivity map[string]time.Time
+	)
+
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeClock = fakeclock.NewFakeClock(time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC))
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackSlashCommand:    "/slack-slash-command",
+			SlackUserID:          "slack-user-id",
+			StaleGuestInactivity: 60 * 24 * time.Hour,
+		})
+		logger = lager.NewLogger("testlogger")
+
+		stubUsers(fakeSlackAPI, []slack.User{
+			{ID: "U1", Name: "tsmith", IsRestricted: true, Profile: slack.UserProfile{Email: "tsmith@partner.com"}},
+			{ID: "U2", Name: "jdoe", IsUltraRestricted: true},
+			{ID: "U3", Name: "admin", Profile: slack.UserProfile{Email: "admin@example.com"}},
+			{ID: "U4", Name: "gone", IsRestricted: true, Deleted: true, Profile: slack.UserProfile{Email: "gone@example.com"}},
+			{ID: "U5", Name: "mjones", IsRestricted: true, Profile: slack.UserProfile{Email: "mjones@partner.com"}},
+			{ID: "U6", Name: "unknown", IsRestricted: true, Profile: slack.UserProfile{Email: "unknown@partner.com"}},
+		})
+
+		lastActivity = map[string]time.Time{
+			"U1": time.Date(2016, 1, 15, 0, 0, 0, 0, time.UTC),
+			"U2": time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC),
+			"U3": time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC),
+			"U4": time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC),
+			"U5": time.Date(2016, 4, 20, 0, 0, 0, 0, time.UTC),
+		}
+		fakeSlackAPI.GetLastActivitiesStub = func() (map[string]time.Time, error) {
+			return lastActivity, nil
+		}
+	})
+
+	newStaleGuests := func(text string) action.Action {
+		return action.New(
+			slackapi.NewChannel("channel-name", "channel-id"),
+			"commander-name",
+			"commander-id",
+			text,
+		)
+	}
+
+	Describe("FindStaleGuests", func() {
+		It("returns the guests inactive for longer than the given time", func() {
+			staleGuests, err := action.FindStaleGuests(fakeSlackAPI, 60*24*time.Hour, fakeClock.Now(), logger)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(staleGuests).Should(HaveLen(3))
+			Ω(staleGuests[0].SearchVal()).Should(Equal("tsmith@partner.com"))
+			Ω(staleGuests[0].LastActive).Should(Equal(lastActivity["U1"]))
+			Ω(staleGuests[1].SearchVal()).Should(Equal("@jdoe"))
+			Ω(fakeSlackAPI.GetLastActivitiesCallCount()).Should(Equal(1))
+		})
+
+		It("lists the users only once, however many guests are stale", func() {
+			_, err := action.FindStaleGuests(fakeSlackAPI, 60*24*time.Hour, fakeClock.Now(), logger)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(1))
+			Ω(fakeSlackAPI.GetUserInfoCallCount()).Should(Equal(0))
+		})
+
+		It("returns the guests with no recorded activity", func() {
+			staleGuests, err := action.FindStaleGuests(fakeSlackAPI, 60*24*time.Hour, fakeClock.Now(), logger)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(staleGuests[2].SearchVal()).Should(Equal("unknown@partner.com"))
+			Ω(staleGuests[2].LastActive.IsZero()).Should(BeTrue())
+		})
+
+		It("never returns full users", func() {
+			staleGuests, err := action.FindStaleGuests(fakeSlackAPI, 60*24*time.Hour, fakeClock.Now(), logger)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			for _, staleGuest := range staleGuests {
+				Ω(staleGuest.User.ID).ShouldNot(Equal("U3"))
+			}
+		})
+
+		It("returns an error if the access logs cannot be read", func() {
+			fakeSlackAPI.GetLastActivitiesStub = nil
+			fakeSlackAPI.GetLastActivitiesReturns(nil, errors.New("paid_only"))
+
+			_, err := action.FindStaleGuests(fakeSlackAPI, 60*24*time.Hour, fakeClock.Now(), logger)
+			Ω(err).Should(MatchError("paid_only"))
+		})
+
+		It("returns an error if the users cannot be listed", func() {
+			fakeSlackAPI.GetUsersPageReturns(nil, "", errors.New("get-users-err"))
+
+			_, err := action.FindStaleGuests(fakeSlackAPI, 60*24*time.Hour, fakeClock.Now(), logger)
+			Ω(err).Should(MatchError("get-users-err"))
+		})
+	})
+
+	Describe("Do", func() {
+		It("lists the guests inactive for the configured time", func() {
+			result, err := newStaleGuests("stale-guests").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(Equal("Found 3 guests inactive since 2016-03-02.\n" +
+				"@tsmith (tsmith@partner.com), restricted account, last active 2016-01-15\n" +
+				"@jdoe, single-channel guest, last active 2016-02-01\n" +
+				"@unknown (unknown@partner.com), restricted account, no activity recorded"))
+		})
+
+		It("lists the guests inactive for the given time with --inactive", func() {
+			result, err := newStaleGuests("stale-guests --inactive=7d").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(HavePrefix("Found 4 guests inactive since 2016-04-24."))
+		})
+
+		It("says when no guests are inactive", func() {
+			lastActivity["U6"] = time.Date(2016, 4, 30, 0, 0, 0, 0, time.UTC)
+
+			result, err := newStaleGuests("stale-guests --inactive=365d").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(result.String()).Should(Equal("Found no guests inactive since 2015-05-02."))
+		})
+
+		It("returns a usage error when --inactive is not a time", func() {
+			_, err := newStaleGuests("stale-guests --inactive=ages").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("Expected --inactive to be a time such as `60d` or `12h`, but got 'ages'."))
+
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(0))
+		})
+
+		It("returns a usage error when --inactive is no time at all", func() {
+			for _, inactive := range []string{"0d", "0s"} {
+				_, err := newStaleGuests("stale-guests --inactive=" + inactive).Do(c, fakeSlackAPI, fakeClock, logger)
+				Ω(err).Should(HaveOccurred())
+				Ω(err.Error()).Should(ContainSubstring("but got '" + inactive + "'."))
+			}
+
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(0))
+		})
+
+		It("never disables anyone", func() {
+			_, err := newStaleGuests("stale-guests").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+		})
+
+		It("returns an error if the users cannot be listed", func() {
+			fakeSlackAPI.GetUsersPageReturns(nil, "", errors.New("get-users-err"))
+
+			result, err := newStaleGuests("stale-guests").Do(c, fakeSlackAPI, fakeClock, logger)
+			Ω(err).Should(MatchError("get-users-err"))
+			Ω(result.String()).Should(Equal("Failed to list the stale guests: get-users-err"))
+		})
+	})
+
+	Describe("AuditMessage", func() {
+		It("exists", func() {
+			aa, ok := newStaleGuests("stale-guests").(action.AuditableAction)
+			Ω(ok).Should(BeTrue())
+			Ω(aa.AuditMessage(fakeSlackAPI)).Should(Equal("@commander-name requested the list of stale guests"))
+		})
+	})
+})
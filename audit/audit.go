@@ -0,0 +1,90 @@
+// Package audit provides storage for a structured record of the commands run
+// through Goulash, so that who did what to whom can be looked up later.
+package audit
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// OutcomeSucceeded is the Outcome of an Event for a command which did what
+	// it was asked to.
+	OutcomeSucceeded = "succeeded"
+
+	// OutcomeFailed is the Outcome of an Event for a command which was not
+	// permitted or returned an error.
+	OutcomeFailed = "failed"
+
+	// RoleRestricted is the TargetRole of a Restricted Account.
+	RoleRestricted = "restricted"
+
+	// RoleGuest is the TargetRole of a Single-Channel Guest.
+	RoleGuest = "guest"
+)
+
+// Event records a command run by a Slack user.
+type Event struct {
+	Time        time.Time `json:"time"`
+	Actor       string    `json:"actor"`
+	ActorID     string    `json:"actor_id"`
+	Action      string    `json:"action"`
+	Target      string    `json:"target,omitempty"`
+	ChannelID   string    `json:"channel_id"`
+	ChannelName string    `json:"channel_name"`
+	Message     string    `json:"message"`
+	Outcome     string    `json:"outcome"`
+	Error       string    `json:"error,omitempty"`
+	DryRun      bool      `json:"dry_run,omitempty"`
+
+	// TargetRole and TargetChannelID are, for Events disabling a user, the
+	// role they had and the channel a Single-Channel Guest was in, so that
+	// they can be restored as they were.
+	TargetRole      string `json:"target_role,omitempty"`
+	TargetChannelID string `json:"target_channel_id,omitempty"`
+}
+
+// Store is somewhere Events are kept.
+type Store interface {
+	Record(Event) error
+	Query(Query) ([]Event, error)
+}
+
+// Query selects Events from a Store.
+type Query struct {
+	// User, when given, selects Events whose target is the user, or whose
+	// actor is the user when given as @username. It is compared without
+	// regard to case.
+	User string
+
+	// UserID and Email, when given, are the ID and email address of the user
+	// User was found to be. They also select Events whose target is either,
+	// or whose actor has the ID.
+	UserID string
+	Email  string
+
+	// Since, when given, selects Events which happened at or after it.
+	Since time.Time
+}
+
+// Matches returns true if the Event is selected by the Query.
+func (q Query) Matches(event Event) bool {
+	if !q.Since.IsZero() && event.Time.Before(q.Since) {
+		return false
+	}
+
+	if q.User == "" {
+		return true
+	}
+
+	if q.UserID != "" && (q.UserID == event.Target || q.UserID == event.ActorID) {
+		return true
+	}
+
+	if q.Email != "" && strings.EqualFold(q.Email, event.Target) {
+		return true
+	}
+
+	return strings.EqualFold(q.User, event.Target) ||
+		strings.EqualFold(q.User, "@"+event.Actor)
+}
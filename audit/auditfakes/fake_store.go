@@ -0,0 +1,95 @@
+// This file was generated by counterfeiter
+package auditfakes
+
+import (
+	"sync"
+
+	"github.com/pivotalservices/goulash/audit"
+)
+
+type FakeStore struct {
+	RecordStub        func(audit.Event) error
+	recordMutex       sync.RWMutex
+	recordArgsForCall []struct {
+		arg1 audit.Event
+	}
+	recordReturns struct {
+		result1 error
+	}
+	QueryStub        func(audit.Query) ([]audit.Event, error)
+	queryMutex       sync.RWMutex
+	queryArgsForCall []struct {
+		arg1 audit.Query
+	}
+	queryReturns struct {
+		result1 []audit.Event
+		result2 error
+	}
+}
+
+func (fake *FakeStore) Record(arg1 audit.Event) error {
+	fake.recordMutex.Lock()
+	fake.recordArgsForCall = append(fake.recordArgsForCall, struct {
+		arg1 audit.Event
+	}{arg1})
+	fake.recordMutex.Unlock()
+	if fake.RecordStub != nil {
+		return fake.RecordStub(arg1)
+	} else {
+		return fake.recordReturns.result1
+	}
+}
+
+func (fake *FakeStore) RecordCallCount() int {
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	return len(fake.recordArgsForCall)
+}
+
+func (fake *FakeStore) RecordArgsForCall(i int) audit.Event {
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	return fake.recordArgsForCall[i].arg1
+}
+
+func (fake *FakeStore) RecordReturns(result1 error) {
+	fake.RecordStub = nil
+	fake.recordReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStore) Query(arg1 audit.Query) ([]audit.Event, error) {
+	fake.queryMutex.Lock()
+	fake.queryArgsForCall = append(fake.queryArgsForCall, struct {
+		arg1 audit.Query
+	}{arg1})
+	fake.queryMutex.Unlock()
+	if fake.QueryStub != nil {
+		return fake.QueryStub(arg1)
+	} else {
+		return fake.queryReturns.result1, fake.queryReturns.result2
+	}
+}
+
+func (fake *FakeStore) QueryCallCount() int {
+	fake.queryMutex.RLock()
+	defer fake.queryMutex.RUnlock()
+	return len(fake.queryArgsForCall)
+}
+
+func (fake *FakeStore) QueryArgsForCall(i int) audit.Query {
+	fake.queryMutex.RLock()
+	defer fake.queryMutex.RUnlock()
+	return fake.queryArgsForCall[i].arg1
+}
+
+func (fake *FakeStore) QueryReturns(result1 []audit.Event, result2 error) {
+	fake.QueryStub = nil
+	fake.queryReturns = struct {
+		result1 []audit.Event
+		result2 error
+	}{result1, result2}
+}
+
+var _ audit.Store = new(FakeStore)
@@ -0,0 +1,112 @@
+package audit_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pivotalservices/goulash/audit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileStore", func() {
+	var (
+		dir   string
+		path  string
+		store audit.Store
+		now   time.Time
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "goulash-audit")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		path = filepath.Join(dir, "audit.log")
+		store = audit.NewFileStore(path)
+		now = time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	event := func(actor string, action string, target string, at time.Time) audit.Event {
+		return audit.Event{
+			Time:    at,
+			Actor:   actor,
+			Action:  action,
+			Target:  target,
+			Outcome: audit.OutcomeSucceeded,
+		}
+	}
+
+	It("returns no events before any are recorded", func() {
+		events, err := store.Query(audit.Query{})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(events).Should(BeEmpty())
+	})
+
+	It("returns the recorded events, oldest first", func() {
+		first := event("alice", "invite-guest", "tsmith@example.com", now)
+		second := event("bob", "disable-user", "@tsmith", now.Add(time.Hour))
+		second.Outcome = audit.OutcomeFailed
+		second.Error = "user_not_found"
+
+		Ω(store.Record(first)).Should(Succeed())
+		Ω(store.Record(second)).Should(Succeed())
+
+		events, err := audit.NewFileStore(path).Query(audit.Query{})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(events).Should(Equal([]audit.Event{first, second}))
+	})
+
+	It("returns the events selected by the query", func() {
+		invited := event("alice", "invite-guest", "tsmith@example.com", now)
+		disabled := event("bob", "disable-user", "TSmith@example.com", now.Add(time.Hour))
+		other := event("carol", "guestify", "jdoe@example.com", now.Add(2*time.Hour))
+
+		for _, e := range []audit.Event{invited, disabled, other} {
+			Ω(store.Record(e)).Should(Succeed())
+		}
+
+		events, err := store.Query(audit.Query{User: "tsmith@example.com"})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(events).Should(Equal([]audit.Event{invited, disabled}))
+
+		events, err = store.Query(audit.Query{User: "tsmith@example.com", Since: now.Add(time.Minute)})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(events).Should(Equal([]audit.Event{disabled}))
+
+		events, err = store.Query(audit.Query{User: "@Carol"})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(events).Should(Equal([]audit.Event{other}))
+	})
+
+	It("returns the events for the user however they were given", func() {
+		invited := event("alice", "invite-guest", "tsmith@example.com", now)
+		added := event("bob", "add-to-channel", "U1234", now.Add(time.Hour))
+		byThem := event("tsmith", "request-access", "#general", now.Add(2*time.Hour))
+		byThem.ActorID = "U1234"
+		other := event("carol", "guestify", "U5678", now.Add(3*time.Hour))
+
+		for _, e := range []audit.Event{invited, added, byThem, other} {
+			Ω(store.Record(e)).Should(Succeed())
+		}
+
+		events, err := store.Query(audit.Query{User: "@tsmith", UserID: "U1234", Email: "tsmith@example.com"})
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(events).Should(Equal([]audit.Event{invited, added, byThem}))
+	})
+
+	It("returns an error when the file holds something other than events", func() {
+		err := ioutil.WriteFile(path, []byte("not json\n"), 0600)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		_, err = store.Query(audit.Query{})
+		Ω(err).Should(HaveOccurred())
+	})
+})
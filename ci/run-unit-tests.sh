@@ -11,5 +11,5 @@ source_dir="$(cd "$(dirname "$0")" && pwd)"
 pushd $source_dir/..
   glide install
   go get -u github.com/onsi/ginkgo/ginkgo
-  ginkgo -p -randomizeAllSpecs action config handler slackapi
+  ginkgo -p -randomizeAllSpecs action config handler slackapi slackapi/slacktest
 popd
@@ -1,28 +1,61 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/cloudfoundry-community/go-cfenv"
 	"github.com/pivotal-golang/clock"
 	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/accessrequest"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/audit"
 	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/expiry"
 	"github.com/pivotalservices/goulash/handler"
-	"github.com/pivotalservices/slack"
+	"github.com/pivotalservices/goulash/scheduler"
+	"github.com/pivotalservices/goulash/slackapi"
 )
 
 const (
 	defaultlistenPort = "8080"
 	listenPortVar     = "VCAP_APP_PORT"
 
+	// shutdownTimeout is how long open connections are given to finish
+	// when the server is asked to stop.
+	shutdownTimeout = 10 * time.Second
+
+	// scheduleInterval is how often background jobs, such as disabling
+	// expired guests, are run.
+	scheduleInterval = time.Hour
+
+	// interactionsPath is where Slack sends interactivity requests, such as
+	// button clicks.
+	interactionsPath = "/interactions"
+
+	accessRequestsPathVar       = "ACCESS_REQUESTS_PATH"
+	auditLogPathVar             = "AUDIT_LOG_PATH"
+	commandPolicyVar            = "COMMAND_POLICY"
+	directoryCacheTTLVar        = "DIRECTORY_CACHE_TTL"
+	domainPolicyVar             = "DOMAIN_POLICY"
+	guestExpiryPathVar          = "GUEST_EXPIRY_PATH"
+	maxConcurrentCommandsVar    = "MAX_CONCURRENT_COMMANDS"
+	skipRequestVerificationVar  = "SKIP_REQUEST_VERIFICATION"
 	slackAuditLogChannelIDVar   = "SLACK_AUDIT_LOG_CHANNEL_ID"
 	slackAuthTokenVar           = "SLACK_AUTH_TOKEN"
+	slackSigningSecretVar       = "SLACK_SIGNING_SECRET"
 	slackSlashCommandVar        = "SLACK_SLASH_COMMAND"
 	slackTeamNameVar            = "SLACK_TEAM_NAME"
 	slackUserIDVar              = "SLACK_USER_ID"
+	slackVerificationTokenVar   = "SLACK_VERIFICATION_TOKEN"
+	staleGuestInactivityVar     = "STALE_GUEST_INACTIVITY"
+	staleGuestReaperVar         = "STALE_GUEST_REAPER"
 	uninvitableDomainMessageVar = "UNINVITABLE_DOMAIN_MESSAGE"
 	uninvitableDomainVar        = "UNINVITABLE_DOMAIN"
 	configServiceNameVar        = "CONFIG_SERVICE_NAME"
@@ -32,11 +65,13 @@ var (
 	listenPort string
 	listenAddr string
 
-	slackAPI   *slack.Slack
+	slackAPI   slackapi.SlackAPI
+	auditStore audit.Store
 	timekeeper clock.Clock
 	logger     lager.Logger
 	c          config.Config
 	h          *handler.Handler
+	s          *scheduler.Scheduler
 )
 
 func init() {
@@ -52,25 +87,104 @@ func init() {
 	app, _ := cfenv.Current()
 	c = config.NewEnvConfig(
 		app,
+		accessRequestsPathVar,
+		auditLogPathVar,
+		commandPolicyVar,
 		configServiceNameVar,
+		directoryCacheTTLVar,
+		domainPolicyVar,
+		guestExpiryPathVar,
+		maxConcurrentCommandsVar,
+		skipRequestVerificationVar,
 		slackAuditLogChannelIDVar,
 		slackAuthTokenVar,
+		slackSigningSecretVar,
 		slackSlashCommandVar,
 		slackTeamNameVar,
 		slackUserIDVar,
+		slackVerificationTokenVar,
+		staleGuestInactivityVar,
+		staleGuestReaperVar,
 		uninvitableDomainMessageVar,
 		uninvitableDomainVar,
 		logger,
 	)
 
-	slackAPI = slack.New(c.SlackAuthToken())
+	if c.SlackSigningSecret() == "" && c.SlackVerificationToken() == "" {
+		if !c.SkipRequestVerification() {
+			log.Fatalf("Set %s or %s so that requests can be verified as coming from Slack, or set %s=true to accept any request", slackSigningSecretVar, slackVerificationTokenVar, skipRequestVerificationVar)
+		}
+
+		logger.Info("request-verification-disabled")
+	}
+
 	timekeeper = clock.NewClock()
+	slackAPI = slackapi.New(c.SlackAuthToken(), slackapi.DefaultAPIURL)
+	slackAPI = slackapi.NewRetrying(slackAPI, timekeeper, logger)
+	if ttl := c.DirectoryCacheTTL(); ttl > 0 {
+		slackAPI = slackapi.NewCache(slackAPI, ttl, timekeeper, logger)
+	}
 
-	h = handler.New(c, slackAPI, timekeeper, logger)
+	if path := c.AuditLogPath(); path != "" {
+		auditStore = audit.NewFileStore(path)
+		action.RegisterAudit(auditStore)
+	}
+
+	accessRequestStore := accessrequest.NewMemoryStore()
+	if path := c.AccessRequestsPath(); path != "" {
+		accessRequestStore = accessrequest.NewFileStore(path)
+	}
+	action.RegisterAccessRequests(accessRequestStore)
+
+	h = handler.New(c, slackAPI, auditStore, timekeeper, logger)
+
+	var jobs []scheduler.Job
+	var expiryStore expiry.Store
+	if path := c.GuestExpiryPath(); path != "" {
+		expiryStore = expiry.NewFileStore(path)
+		jobs = append(jobs, scheduler.NewGuestExpiry(c, slackAPI, expiryStore, timekeeper, h.RecordAuditEvent))
+	}
+	action.RegisterInvites(expiryStore)
+
+	if c.StaleGuestReaper() != config.StaleGuestReaperOff {
+		jobs = append(jobs, scheduler.NewStaleGuestReaper(c, slackAPI, timekeeper, h.RecordAuditEvent))
+	}
+
+	s = scheduler.New(scheduleInterval, timekeeper, logger, jobs...)
 }
 
 func main() {
-	if err := http.ListenAndServe(listenAddr, h); err != nil {
+	s.Start()
+
+	mux := http.NewServeMux()
+	mux.Handle("/", h)
+	mux.Handle(interactionsPath, h.Interactions())
+
+	server := &http.Server{Addr: listenAddr, Handler: mux}
+	serverStopped := make(chan struct{})
+
+	go func() {
+		defer close(serverStopped)
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		<-signals
+
+		logger.Info("shutting-down")
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Error("failed-to-shut-down-server", err)
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
 		log.Fatal("Failed to start server", err)
 	}
+
+	<-serverStopped
+	h.Shutdown()
+	s.Stop()
 }
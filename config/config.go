@@ -1,12 +1,56 @@
 package config
 
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
 // Config is an interface that provides configuration values.
 type Config interface {
+	AccessRequestsPath() string
 	AuditLogChannelID() string
+	AuditLogPath() string
+	DirectoryCacheTTL() time.Duration
+	DomainPolicy() DomainPolicy
+	GuestExpiryPath() string
+	MaxConcurrentCommands() int
+	Policy() Policy
+	SkipRequestVerification() bool
 	SlackAuthToken() string
 	SlackTeamName() string
 	SlackUserID() string
 	SlackSlashCommand() string
+	SlackSigningSecret() string
+	SlackVerificationToken() string
+	StaleGuestInactivity() time.Duration
+	StaleGuestReaper() string
 	UninvitableDomain() string
 	UninvitableMessage() string
 }
+
+const (
+	// StaleGuestReaperOff is the StaleGuestReaper setting under which stale
+	// guests are only found when asked for.
+	StaleGuestReaperOff = "off"
+
+	// StaleGuestReaperDryRun is the StaleGuestReaper setting under which stale
+	// guests are regularly found and recorded, but not disabled.
+	StaleGuestReaperDryRun = "dry-run"
+
+	// StaleGuestReaperDisable is the StaleGuestReaper setting under which
+	// stale guests are regularly found and disabled.
+	StaleGuestReaperDisable = "disable"
+)
+
+// ParseDuration parses a duration such as "90s" or "12h", or a number of days
+// such as "30d". It returns false if the value is neither, or is negative.
+func ParseDuration(value string) (time.Duration, bool) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		return time.Duration(days) * 24 * time.Hour, err == nil && days >= 0
+	}
+
+	duration, err := time.ParseDuration(value)
+	return duration, err == nil && duration >= 0
+}
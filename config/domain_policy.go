@@ -0,0 +1,100 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// DomainPolicy describes whose email addresses may be invited, by domain. A
+// domain matches itself and its subdomains, so example.com matches
+// eng.example.com but not notexample.com.
+type DomainPolicy struct {
+	// Blocked maps each domain whose people may not be invited to the
+	// message explaining why.
+	Blocked map[string]string `json:"blocked"`
+
+	// Allowed, when not nil, lists the only domains whose people may be
+	// invited. Blocked domains are refused even if they are allowed.
+	Allowed []string `json:"allowed"`
+
+	// NotAllowedMessage explains why people whose domains are not allowed
+	// may not be invited.
+	NotAllowedMessage string `json:"not_allowed_message"`
+}
+
+// ParseDomainPolicy parses a JSON-encoded DomainPolicy, for example:
+//
+//	{"blocked": {"example.com": "Employees already have accounts."}, "allowed": ["partner.com"]}
+func ParseDomainPolicy(text string) (DomainPolicy, error) {
+	var policy DomainPolicy
+	if text == "" {
+		return policy, nil
+	}
+
+	if err := json.Unmarshal([]byte(text), &policy); err != nil {
+		return DomainPolicy{}, err
+	}
+
+	return policy, nil
+}
+
+// WithBlocked returns a copy of the policy also blocking the given domain,
+// unless it already has a message for it.
+func (p DomainPolicy) WithBlocked(domain string, message string) DomainPolicy {
+	blocked := map[string]string{}
+	for d, m := range p.Blocked {
+		blocked[d] = m
+	}
+
+	if _, ok := blocked[domain]; !ok {
+		blocked[domain] = message
+	}
+
+	p.Blocked = blocked
+	return p
+}
+
+// Check returns true if the person with the given email address may be
+// invited. Otherwise it returns the domain they may not be invited from, and
+// the message explaining why. When several blocked domains match, the most
+// specific applies.
+func (p DomainPolicy) Check(emailAddress string) (string, string, bool) {
+	domain := strings.ToLower(emailAddress[strings.LastIndex(emailAddress, "@")+1:])
+
+	var blockedDomain, message string
+	for d, m := range p.Blocked {
+		if matchesDomain(domain, d) && len(normalizeDomain(d)) > len(normalizeDomain(blockedDomain)) {
+			blockedDomain, message = d, m
+		}
+	}
+	if blockedDomain != "" {
+		return blockedDomain, message, false
+	}
+
+	if p.Allowed == nil {
+		return "", "", true
+	}
+
+	for _, d := range p.Allowed {
+		if matchesDomain(domain, d) {
+			return "", "", true
+		}
+	}
+
+	return domain, p.NotAllowedMessage, false
+}
+
+func matchesDomain(domain string, policyDomain string) bool {
+	policyDomain = normalizeDomain(policyDomain)
+	if policyDomain == "" {
+		return false
+	}
+
+	return domain == policyDomain || strings.HasSuffix(domain, "."+policyDomain)
+}
+
+// normalizeDomain returns the domain in lower case without any leading @ or
+// dot, which are sometimes written to mean the same thing.
+func normalizeDomain(domain string) string {
+	return strings.ToLower(strings.TrimLeft(strings.TrimSpace(domain), "@."))
+}
@@ -0,0 +1,117 @@
+package config_test
+
+import (
+	"github.com/pivotalservices/goulash/config"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DomainPolicy", func() {
+	Describe("ParseDomainPolicy", func() {
+		It("parses a JSON-encoded domain policy", func() {
+			policy, err := config.ParseDomainPolicy(`{
+				"blocked": {"example.com": "Employees already have accounts."},
+				"allowed": ["partner.com"],
+				"not_allowed_message": "Only partners may be invited."
+			}`)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(policy).Should(Equal(config.DomainPolicy{
+				Blocked:           map[string]string{"example.com": "Employees already have accounts."},
+				Allowed:           []string{"partner.com"},
+				NotAllowedMessage: "Only partners may be invited.",
+			}))
+		})
+
+		It("returns an empty policy when given nothing", func() {
+			policy, err := config.ParseDomainPolicy("")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(policy).Should(Equal(config.DomainPolicy{}))
+		})
+
+		It("returns an error when given invalid JSON", func() {
+			_, err := config.ParseDomainPolicy("{")
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("Check", func() {
+		var policy config.DomainPolicy
+
+		BeforeEach(func() {
+			policy = config.DomainPolicy{
+				Blocked: map[string]string{
+					"example.com":     "Employees already have accounts.",
+					"eng.example.com": "Engineers already have accounts.",
+					"@acquired.com":   "Acquired employees already have accounts.",
+				},
+			}
+		})
+
+		It("permits everyone else when there is no allow-list", func() {
+			_, _, ok := policy.Check("user@partner.com")
+			Ω(ok).Should(BeTrue())
+		})
+
+		It("refuses exact domains and subdomains of blocked domains", func() {
+			domain, message, ok := policy.Check("user@Example.com")
+			Ω(ok).Should(BeFalse())
+			Ω(domain).Should(Equal("example.com"))
+			Ω(message).Should(Equal("Employees already have accounts."))
+
+			domain, _, ok = policy.Check("user@sales.example.com")
+			Ω(ok).Should(BeFalse())
+			Ω(domain).Should(Equal("example.com"))
+
+			domain, _, ok = policy.Check("user@acquired.com")
+			Ω(ok).Should(BeFalse())
+			Ω(domain).Should(Equal("@acquired.com"))
+		})
+
+		It("applies the most specific blocked domain", func() {
+			domain, message, ok := policy.Check("user@team.eng.example.com")
+			Ω(ok).Should(BeFalse())
+			Ω(domain).Should(Equal("eng.example.com"))
+			Ω(message).Should(Equal("Engineers already have accounts."))
+		})
+
+		It("does not refuse domains merely ending in a blocked domain", func() {
+			_, _, ok := policy.Check("user@notexample.com")
+			Ω(ok).Should(BeTrue())
+		})
+
+		Describe("with an allow-list", func() {
+			BeforeEach(func() {
+				policy.Allowed = []string{"partner.com", "example.com"}
+				policy.NotAllowedMessage = "Only partners may be invited."
+			})
+
+			It("permits allowed domains and their subdomains", func() {
+				_, _, ok := policy.Check("user@partner.com")
+				Ω(ok).Should(BeTrue())
+
+				_, _, ok = policy.Check("user@eu.partner.com")
+				Ω(ok).Should(BeTrue())
+			})
+
+			It("refuses other domains", func() {
+				domain, message, ok := policy.Check("user@notpartner.com")
+				Ω(ok).Should(BeFalse())
+				Ω(domain).Should(Equal("notpartner.com"))
+				Ω(message).Should(Equal("Only partners may be invited."))
+			})
+
+			It("refuses blocked domains even when they are allowed", func() {
+				domain, _, ok := policy.Check("user@example.com")
+				Ω(ok).Should(BeFalse())
+				Ω(domain).Should(Equal("example.com"))
+			})
+		})
+
+		It("refuses everyone when the allow-list is empty", func() {
+			_, _, ok := config.DomainPolicy{Allowed: []string{}}.Check("user@partner.com")
+			Ω(ok).Should(BeFalse())
+		})
+	})
+})
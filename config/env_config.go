@@ -2,23 +2,42 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/cloudfoundry-community/go-cfenv"
 	"github.com/pivotal-golang/lager"
 )
 
 const (
-	slackAuthTokenCredentialKey = "slack-auth-token"
+	slackAuthTokenCredentialKey         = "slack-auth-token"
+	slackSigningSecretCredentialKey     = "slack-signing-secret"
+	slackVerificationTokenCredentialKey = "slack-verification-token"
+
+	defaultDirectoryCacheTTL    = 5 * time.Minute
+	defaultStaleGuestInactivity = 60 * 24 * time.Hour
 )
 
 type envConfig struct {
 	app                         *cfenv.App
+	accessRequestsPathVar       string
+	auditLogPathVar             string
+	commandPolicyVar            string
 	configServiceNameVar        string
+	directoryCacheTTLVar        string
+	domainPolicyVar             string
+	guestExpiryPathVar          string
+	maxConcurrentCommandsVar    string
+	skipRequestVerificationVar  string
 	slackAuditLogChannelIDVar   string
 	slackAuthTokenVar           string
+	slackSigningSecretVar       string
 	slackSlashCommandVar        string
 	slackTeamNameVar            string
 	slackUserIDVar              string
+	slackVerificationTokenVar   string
+	staleGuestInactivityVar     string
+	staleGuestReaperVar         string
 	uninvitableDomainMessageVar string
 	uninvitableDomainVar        string
 
@@ -29,12 +48,24 @@ type envConfig struct {
 // its source.
 func NewEnvConfig(
 	app *cfenv.App,
+	accessRequestsPathVar string,
+	auditLogPathVar string,
+	commandPolicyVar string,
 	configServiceNameVar string,
+	directoryCacheTTLVar string,
+	domainPolicyVar string,
+	guestExpiryPathVar string,
+	maxConcurrentCommandsVar string,
+	skipRequestVerificationVar string,
 	slackAuditLogChannelIDVar string,
 	slackAuthTokenVar string,
+	slackSigningSecretVar string,
 	slackSlashCommandVar string,
 	slackTeamNameVar string,
 	slackUserIDVar string,
+	slackVerificationTokenVar string,
+	staleGuestInactivityVar string,
+	staleGuestReaperVar string,
 	uninvitableDomainMessageVar string,
 	uninvitableDomainVar string,
 
@@ -42,12 +73,24 @@ func NewEnvConfig(
 ) Config {
 	return &envConfig{
 		app:                         app,
+		accessRequestsPathVar:       accessRequestsPathVar,
+		auditLogPathVar:             auditLogPathVar,
+		commandPolicyVar:            commandPolicyVar,
 		configServiceNameVar:        configServiceNameVar,
+		directoryCacheTTLVar:        directoryCacheTTLVar,
+		domainPolicyVar:             domainPolicyVar,
+		guestExpiryPathVar:          guestExpiryPathVar,
+		maxConcurrentCommandsVar:    maxConcurrentCommandsVar,
+		skipRequestVerificationVar:  skipRequestVerificationVar,
 		slackAuditLogChannelIDVar:   slackAuditLogChannelIDVar,
 		slackAuthTokenVar:           slackAuthTokenVar,
+		slackSigningSecretVar:       slackSigningSecretVar,
 		slackSlashCommandVar:        slackSlashCommandVar,
 		slackTeamNameVar:            slackTeamNameVar,
 		slackUserIDVar:              slackUserIDVar,
+		slackVerificationTokenVar:   slackVerificationTokenVar,
+		staleGuestInactivityVar:     staleGuestInactivityVar,
+		staleGuestReaperVar:         staleGuestReaperVar,
 		uninvitableDomainMessageVar: uninvitableDomainMessageVar,
 		uninvitableDomainVar:        uninvitableDomainVar,
 
@@ -55,29 +98,159 @@ func NewEnvConfig(
 	}
 }
 
+func (c envConfig) AccessRequestsPath() string {
+	return os.Getenv(c.accessRequestsPathVar)
+}
+
 func (c envConfig) AuditLogChannelID() string {
 	return os.Getenv(c.slackAuditLogChannelIDVar)
 }
 
+func (c envConfig) AuditLogPath() string {
+	return os.Getenv(c.auditLogPathVar)
+}
+
+// DirectoryCacheTTL returns the duration held in the directory cache TTL
+// environment variable, or five minutes if it is unset or not a duration.
+func (c envConfig) DirectoryCacheTTL() time.Duration {
+	value := os.Getenv(c.directoryCacheTTLVar)
+	if value == "" {
+		return defaultDirectoryCacheTTL
+	}
+
+	directoryCacheTTL, err := time.ParseDuration(value)
+	if err != nil {
+		c.logger.Session("directory-cache-ttl").Error("failed-to-parse", err)
+		return defaultDirectoryCacheTTL
+	}
+
+	return directoryCacheTTL
+}
+
+// DomainPolicy returns the DomainPolicy held in the domain policy environment
+// variable, also blocking the domain held in the uninvitable domain variable.
+// A policy which cannot be parsed permits no one to be invited.
+func (c envConfig) DomainPolicy() DomainPolicy {
+	logger := c.logger.Session("domain-policy")
+
+	policy, err := ParseDomainPolicy(os.Getenv(c.domainPolicyVar))
+	if err != nil {
+		logger.Error("failed-to-parse-domain-policy", err)
+		policy = DomainPolicy{Allowed: []string{}}
+	}
+
+	if uninvitableDomain := c.UninvitableDomain(); uninvitableDomain != "" {
+		policy = policy.WithBlocked(uninvitableDomain, c.UninvitableMessage())
+	}
+
+	return policy
+}
+
+func (c envConfig) GuestExpiryPath() string {
+	return os.Getenv(c.guestExpiryPathVar)
+}
+
+// MaxConcurrentCommands returns the number held in the max concurrent commands
+// environment variable, or zero if it is unset or not a number.
+func (c envConfig) MaxConcurrentCommands() int {
+	value := os.Getenv(c.maxConcurrentCommandsVar)
+	if value == "" {
+		return 0
+	}
+
+	maxConcurrentCommands, err := strconv.Atoi(value)
+	if err != nil {
+		c.logger.Session("max-concurrent-commands").Error("failed-to-parse", err)
+		return 0
+	}
+
+	return maxConcurrentCommands
+}
+
+// Policy returns the Policy held in the command policy environment variable.
+// A policy which cannot be parsed is replaced by one whose default rule
+// permits no one, so that no one may run a command without a permission of
+// its own. Commands with one, such as those only admins may run by default,
+// may still be run by whoever it permits.
+func (c envConfig) Policy() Policy {
+	logger := c.logger.Session("policy")
+
+	policy, err := ParsePolicy(os.Getenv(c.commandPolicyVar))
+	if err != nil {
+		logger.Error("failed-to-parse-policy", err)
+		return Policy{DefaultPolicyKey: PolicyRule{}}
+	}
+
+	return policy
+}
+
+// SkipRequestVerification returns true if the skip request verification
+// environment variable is set to true, and false if it is unset or not a
+// boolean.
+func (c envConfig) SkipRequestVerification() bool {
+	value := os.Getenv(c.skipRequestVerificationVar)
+	if value == "" {
+		return false
+	}
+
+	skipRequestVerification, err := strconv.ParseBool(value)
+	if err != nil {
+		c.logger.Session("skip-request-verification").Error("failed-to-parse", err)
+		return false
+	}
+
+	return skipRequestVerification
+}
+
 func (c envConfig) SlackAuthToken() string {
-	logger := c.logger.Session("slack-auth-token")
+	return c.secret("slack-auth-token", c.slackAuthTokenVar, slackAuthTokenCredentialKey)
+}
 
-	if c.configServiceNameVar == "" {
-		logger.Info("successfully-found")
-		return os.Getenv(c.slackAuthTokenVar)
+func (c envConfig) SlackSigningSecret() string {
+	return c.secret("slack-signing-secret", c.slackSigningSecretVar, slackSigningSecretCredentialKey)
+}
+
+func (c envConfig) SlackVerificationToken() string {
+	return c.secret("slack-verification-token", c.slackVerificationTokenVar, slackVerificationTokenCredentialKey)
+}
+
+// secret returns the value of the given credential key of the configured
+// config service, falling back to the given environment variable when there
+// is no config service or the credential is not found in it.
+func (c envConfig) secret(
+	session string,
+	envVar string,
+	credentialKey string,
+) string {
+	logger := c.logger.Session(session)
+
+	if c.configServiceNameVar != "" {
+		if credential, ok := c.serviceCredential(credentialKey, logger); ok {
+			logger.Info("successfully-found")
+			return credential
+		}
 	}
 
+	logger.Info("successfully-found")
+
+	return os.Getenv(envVar)
+}
+
+func (c envConfig) serviceCredential(
+	credentialKey string,
+	logger lager.Logger,
+) (string, bool) {
 	configServiceName := os.Getenv(c.configServiceNameVar)
 	if configServiceName == "" {
 		logger.Error("failed-to-find-config-service-name", nil, lager.Data{
 			"configServiceName": configServiceName,
 		})
-		return ""
+		return "", false
 	}
 
 	if c.app == nil {
 		logger.Error("no-app-given", nil)
-		return ""
+		return "", false
 	}
 
 	service, err := c.app.Services.WithName(configServiceName)
@@ -85,26 +258,26 @@ func (c envConfig) SlackAuthToken() string {
 		logger.Error("failed-to-find-service", nil, lager.Data{
 			"configServiceName": configServiceName,
 		})
-		return ""
+		return "", false
 	}
 
-	slackAuthToken, ok := service.Credentials[slackAuthTokenCredentialKey]
+	credential, ok := service.Credentials[credentialKey]
 	if !ok {
 		logger.Error("failed-to-find-service-credential", nil, lager.Data{
-			"slackAuthTokenCredentialKey": slackAuthTokenCredentialKey,
+			"credentialKey": credentialKey,
 		})
-		return ""
+		return "", false
 	}
 
-	slackAuthTokenString, ok := slackAuthToken.(string)
+	credentialString, ok := credential.(string)
 	if !ok {
-		logger.Error("failed-to-convert-slack-auth-token-to-string", nil)
-		return ""
+		logger.Error("failed-to-convert-credential-to-string", nil, lager.Data{
+			"credentialKey": credentialKey,
+		})
+		return "", false
 	}
 
-	logger.Info("successfully-found")
-
-	return slackAuthTokenString
+	return credentialString, true
 }
 
 func (c envConfig) SlackTeamName() string {
@@ -119,6 +292,41 @@ func (c envConfig) SlackSlashCommand() string {
 	return os.Getenv(c.slackSlashCommandVar)
 }
 
+// StaleGuestInactivity returns the duration held in the stale guest inactivity
+// environment variable, such as 60d, or sixty days if it is unset or not a
+// duration.
+func (c envConfig) StaleGuestInactivity() time.Duration {
+	value := os.Getenv(c.staleGuestInactivityVar)
+	if value == "" {
+		return defaultStaleGuestInactivity
+	}
+
+	staleGuestInactivity, ok := ParseDuration(value)
+	if !ok || staleGuestInactivity == 0 {
+		c.logger.Session("stale-guest-inactivity").Error("failed-to-parse", nil, lager.Data{"value": value})
+		return defaultStaleGuestInactivity
+	}
+
+	return staleGuestInactivity
+}
+
+// StaleGuestReaper returns the setting held in the stale guest reaper
+// environment variable, or StaleGuestReaperOff if it is unset or unknown.
+func (c envConfig) StaleGuestReaper() string {
+	value := os.Getenv(c.staleGuestReaperVar)
+
+	switch value {
+	case StaleGuestReaperDryRun, StaleGuestReaperDisable:
+		return value
+	case "", StaleGuestReaperOff:
+		return StaleGuestReaperOff
+	}
+
+	c.logger.Session("stale-guest-reaper").Error("unknown-setting", nil, lager.Data{"value": value})
+
+	return StaleGuestReaperOff
+}
+
 func (c envConfig) UninvitableDomain() string {
 	return os.Getenv(c.uninvitableDomainVar)
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/pivotalservices/goulash/config"
 
 	"os"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -45,14 +46,26 @@ var _ = Describe("EnvConfig", func() {
 
 			c := config.NewEnvConfig(
 				app,
+				"",
+				"",
+				"",
 				"GOULASH_TEST_CONFIG_SERVICE_NAME",
 				"",
+				"",
+				"",
+				"",
+				"",
+				"",
 				"slack-auth-token",
 				"",
 				"",
 				"",
 				"",
 				"",
+				"",
+				"",
+				"",
+				"",
 				logger,
 			)
 
@@ -62,11 +75,205 @@ var _ = Describe("EnvConfig", func() {
 		It("returns an env-based audit log channel id", func() {
 			app, err := cfenv.New(cfenv.Env([]string{`VCAP_APPLICATION={}`, `VCAP_SERVICES={}`}))
 			Ω(err).ShouldNot(HaveOccurred())
-			c := config.NewEnvConfig(app, "", "", "GOULASH_TEST_SLACK_AUTH_TOKEN", "", "", "", "", "", logger)
+			c := config.NewEnvConfig(app, "", "", "", "", "", "", "", "", "", "", "GOULASH_TEST_SLACK_AUTH_TOKEN", "", "", "", "", "", "", "", "", "", logger)
 			err = os.Setenv("GOULASH_TEST_SLACK_AUTH_TOKEN", "slack-auth-token-value")
 			Ω(err).ShouldNot(HaveOccurred())
 
 			Ω(c.SlackAuthToken()).Should(Equal("slack-auth-token-value"))
 		})
 	})
+
+	Describe("SlackSigningSecret", func() {
+		var logger lager.Logger
+
+		BeforeEach(func() {
+			logger = lager.NewLogger("testlogger")
+		})
+
+		AfterEach(func() {
+			err := os.Unsetenv("GOULASH_TEST_CONFIG_SERVICE_NAME")
+			Expect(err).NotTo(HaveOccurred())
+			err = os.Unsetenv("GOULASH_TEST_SLACK_SIGNING_SECRET")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns a service-based signing secret", func() {
+			err := os.Setenv("GOULASH_TEST_CONFIG_SERVICE_NAME", "config-service-name")
+			Ω(err).ShouldNot(HaveOccurred())
+
+			env := []string{
+				`VCAP_APPLICATION={}`,
+				`VCAP_SERVICES={
+					"user-provided":[{
+						"name":"config-service-name",
+						"credentials":{"slack-signing-secret":"slack-signing-secret-value"}
+					}]
+				}`,
+			}
+
+			app, err := cfenv.New(cfenv.Env(env))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			c := config.NewEnvConfig(app, "", "", "", "GOULASH_TEST_CONFIG_SERVICE_NAME", "", "", "", "", "", "", "", "GOULASH_TEST_SLACK_SIGNING_SECRET", "", "", "", "", "", "", "", "", logger)
+
+			Ω(c.SlackSigningSecret()).Should(Equal("slack-signing-secret-value"))
+		})
+
+		It("falls back to an env-based signing secret when the service does not provide one", func() {
+			err := os.Setenv("GOULASH_TEST_CONFIG_SERVICE_NAME", "config-service-name")
+			Ω(err).ShouldNot(HaveOccurred())
+			err = os.Setenv("GOULASH_TEST_SLACK_SIGNING_SECRET", "slack-signing-secret-value")
+			Ω(err).ShouldNot(HaveOccurred())
+
+			env := []string{
+				`VCAP_APPLICATION={}`,
+				`VCAP_SERVICES={
+					"user-provided":[{
+						"name":"config-service-name",
+						"credentials":{"slack-auth-token":"slack-auth-token-value"}
+					}]
+				}`,
+			}
+
+			app, err := cfenv.New(cfenv.Env(env))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			c := config.NewEnvConfig(app, "", "", "", "GOULASH_TEST_CONFIG_SERVICE_NAME", "", "", "", "", "", "", "", "GOULASH_TEST_SLACK_SIGNING_SECRET", "", "", "", "", "", "", "", "", logger)
+
+			Ω(c.SlackSigningSecret()).Should(Equal("slack-signing-secret-value"))
+		})
+	})
+
+	Describe("DirectoryCacheTTL", func() {
+		var c config.Config
+
+		BeforeEach(func() {
+			c = config.NewEnvConfig(nil, "", "", "", "", "GOULASH_TEST_DIRECTORY_CACHE_TTL", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", lager.NewLogger("testlogger"))
+		})
+
+		AfterEach(func() {
+			err := os.Unsetenv("GOULASH_TEST_DIRECTORY_CACHE_TTL")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns an env-based duration", func() {
+			err := os.Setenv("GOULASH_TEST_DIRECTORY_CACHE_TTL", "90s")
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(c.DirectoryCacheTTL()).Should(Equal(90 * time.Second))
+		})
+
+		It("returns five minutes when the variable is unset", func() {
+			Ω(c.DirectoryCacheTTL()).Should(Equal(5 * time.Minute))
+		})
+
+		It("returns five minutes when the variable is not a duration", func() {
+			err := os.Setenv("GOULASH_TEST_DIRECTORY_CACHE_TTL", "forever")
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(c.DirectoryCacheTTL()).Should(Equal(5 * time.Minute))
+		})
+	})
+
+	Describe("SkipRequestVerification", func() {
+		var c config.Config
+
+		BeforeEach(func() {
+			c = config.NewEnvConfig(nil, "", "", "", "", "", "", "", "", "GOULASH_TEST_SKIP_REQUEST_VERIFICATION", "", "", "", "", "", "", "", "", "", "", "", lager.NewLogger("testlogger"))
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv("GOULASH_TEST_SKIP_REQUEST_VERIFICATION")).To(Succeed())
+		})
+
+		It("returns true when the variable is set to true", func() {
+			Ω(os.Setenv("GOULASH_TEST_SKIP_REQUEST_VERIFICATION", "true")).Should(Succeed())
+
+			Ω(c.SkipRequestVerification()).Should(BeTrue())
+		})
+
+		It("returns false when the variable is unset or not a boolean", func() {
+			Ω(c.SkipRequestVerification()).Should(BeFalse())
+
+			Ω(os.Setenv("GOULASH_TEST_SKIP_REQUEST_VERIFICATION", "please")).Should(Succeed())
+
+			Ω(c.SkipRequestVerification()).Should(BeFalse())
+		})
+	})
+
+	Describe("stale guests", func() {
+		var c config.Config
+
+		BeforeEach(func() {
+			c = config.NewEnvConfig(nil, "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "GOULASH_TEST_STALE_GUEST_INACTIVITY", "GOULASH_TEST_STALE_GUEST_REAPER", "", "", lager.NewLogger("testlogger"))
+		})
+
+		AfterEach(func() {
+			Expect(os.Unsetenv("GOULASH_TEST_STALE_GUEST_INACTIVITY")).To(Succeed())
+			Expect(os.Unsetenv("GOULASH_TEST_STALE_GUEST_REAPER")).To(Succeed())
+		})
+
+		It("returns an env-based inactivity in days", func() {
+			Ω(os.Setenv("GOULASH_TEST_STALE_GUEST_INACTIVITY", "90d")).Should(Succeed())
+
+			Ω(c.StaleGuestInactivity()).Should(Equal(90 * 24 * time.Hour))
+		})
+
+		It("returns sixty days when the inactivity is unset or not a duration", func() {
+			Ω(c.StaleGuestInactivity()).Should(Equal(60 * 24 * time.Hour))
+
+			Ω(os.Setenv("GOULASH_TEST_STALE_GUEST_INACTIVITY", "ages")).Should(Succeed())
+
+			Ω(c.StaleGuestInactivity()).Should(Equal(60 * 24 * time.Hour))
+		})
+
+		It("returns an env-based reaper setting", func() {
+			Ω(os.Setenv("GOULASH_TEST_STALE_GUEST_REAPER", "dry-run")).Should(Succeed())
+			Ω(c.StaleGuestReaper()).Should(Equal(config.StaleGuestReaperDryRun))
+
+			Ω(os.Setenv("GOULASH_TEST_STALE_GUEST_REAPER", "disable")).Should(Succeed())
+			Ω(c.StaleGuestReaper()).Should(Equal(config.StaleGuestReaperDisable))
+		})
+
+		It("turns the reaper off when the setting is unset or unknown", func() {
+			Ω(c.StaleGuestReaper()).Should(Equal(config.StaleGuestReaperOff))
+
+			Ω(os.Setenv("GOULASH_TEST_STALE_GUEST_REAPER", "yes")).Should(Succeed())
+			Ω(c.StaleGuestReaper()).Should(Equal(config.StaleGuestReaperOff))
+		})
+	})
+
+	Describe("DomainPolicy", func() {
+		var c config.Config
+
+		BeforeEach(func() {
+			c = config.NewEnvConfig(nil, "", "", "", "", "", "GOULASH_TEST_DOMAIN_POLICY", "", "", "", "", "", "", "", "", "", "", "", "", "GOULASH_TEST_UNINVITABLE_DOMAIN_MESSAGE", "GOULASH_TEST_UNINVITABLE_DOMAIN", lager.NewLogger("testlogger"))
+		})
+
+		AfterEach(func() {
+			for _, name := range []string{"GOULASH_TEST_DOMAIN_POLICY", "GOULASH_TEST_UNINVITABLE_DOMAIN", "GOULASH_TEST_UNINVITABLE_DOMAIN_MESSAGE"} {
+				Ω(os.Unsetenv(name)).Should(Succeed())
+			}
+		})
+
+		It("returns an env-based policy also blocking the uninvitable domain", func() {
+			Ω(os.Setenv("GOULASH_TEST_DOMAIN_POLICY", `{"blocked": {"acquired.com": "acquired-message"}}`)).Should(Succeed())
+			Ω(os.Setenv("GOULASH_TEST_UNINVITABLE_DOMAIN", "example.com")).Should(Succeed())
+			Ω(os.Setenv("GOULASH_TEST_UNINVITABLE_DOMAIN_MESSAGE", "example-message")).Should(Succeed())
+
+			Ω(c.DomainPolicy()).Should(Equal(config.DomainPolicy{
+				Blocked: map[string]string{
+					"acquired.com": "acquired-message",
+					"example.com":  "example-message",
+				},
+			}))
+		})
+
+		It("permits no one to be invited when the policy is not valid JSON", func() {
+			Ω(os.Setenv("GOULASH_TEST_DOMAIN_POLICY", "{")).Should(Succeed())
+
+			_, _, ok := c.DomainPolicy().Check("user@partner.com")
+			Ω(ok).Should(BeFalse())
+		})
+	})
 })
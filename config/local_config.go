@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type localConfig struct {
 	slackAuthToken    string
 	slackSlashCommand string
@@ -9,36 +11,138 @@ type localConfig struct {
 	uninvitableDomain  string
 	uninvitableMessage string
 	auditLogChannelID  string
+
+	slackSigningSecret      string
+	slackVerificationToken  string
+	skipRequestVerification bool
+
+	policy                Policy
+	maxConcurrentCommands int
+
+	directoryCacheTTL time.Duration
+
+	auditLogPath string
+
+	domainPolicy DomainPolicy
+
+	guestExpiryPath string
+
+	accessRequestsPath string
+
+	staleGuestReaper     string
+	staleGuestInactivity time.Duration
+}
+
+// LocalConfigOptions holds the values of a Config made by NewLocalConfig.
+// Those left unset are the zero value.
+type LocalConfigOptions struct {
+	SlackAuthToken    string
+	SlackSlashCommand string
+	SlackTeamName     string
+	SlackUserID       string
+
+	AuditLogChannelID  string
+	UninvitableDomain  string
+	UninvitableMessage string
+
+	SlackSigningSecret      string
+	SlackVerificationToken  string
+	SkipRequestVerification bool
+
+	Policy                Policy
+	MaxConcurrentCommands int
+
+	DirectoryCacheTTL time.Duration
+
+	AuditLogPath string
+
+	DomainPolicy DomainPolicy
+
+	GuestExpiryPath string
+
+	AccessRequestsPath string
+
+	StaleGuestReaper     string
+	StaleGuestInactivity time.Duration
 }
 
 // NewLocalConfig returns a new Config which will use the provided
 // values as its source.
-func NewLocalConfig(
-	slackAuthToken string,
-	slackSlashCommand string,
-	slackTeamName string,
-	slackUserID string,
-
-	auditLogChannelID string,
-	uninvitableDomain string,
-	uninvitableMessage string,
-) Config {
+func NewLocalConfig(options LocalConfigOptions) Config {
 	return &localConfig{
-		slackAuthToken:    slackAuthToken,
-		slackSlashCommand: slackSlashCommand,
-		slackTeamName:     slackTeamName,
-		slackUserID:       slackUserID,
-
-		auditLogChannelID:  auditLogChannelID,
-		uninvitableDomain:  uninvitableDomain,
-		uninvitableMessage: uninvitableMessage,
+		slackAuthToken:    options.SlackAuthToken,
+		slackSlashCommand: options.SlackSlashCommand,
+		slackTeamName:     options.SlackTeamName,
+		slackUserID:       options.SlackUserID,
+
+		auditLogChannelID:  options.AuditLogChannelID,
+		uninvitableDomain:  options.UninvitableDomain,
+		uninvitableMessage: options.UninvitableMessage,
+
+		slackSigningSecret:      options.SlackSigningSecret,
+		slackVerificationToken:  options.SlackVerificationToken,
+		skipRequestVerification: options.SkipRequestVerification,
+
+		policy:                options.Policy,
+		maxConcurrentCommands: options.MaxConcurrentCommands,
+
+		directoryCacheTTL: options.DirectoryCacheTTL,
+
+		auditLogPath: options.AuditLogPath,
+
+		domainPolicy: options.DomainPolicy,
+
+		guestExpiryPath: options.GuestExpiryPath,
+
+		accessRequestsPath: options.AccessRequestsPath,
+
+		staleGuestReaper:     options.StaleGuestReaper,
+		staleGuestInactivity: options.StaleGuestInactivity,
 	}
 }
 
+func (c localConfig) AccessRequestsPath() string {
+	return c.accessRequestsPath
+}
+
 func (c localConfig) AuditLogChannelID() string {
 	return c.auditLogChannelID
 }
 
+func (c localConfig) AuditLogPath() string {
+	return c.auditLogPath
+}
+
+func (c localConfig) DirectoryCacheTTL() time.Duration {
+	return c.directoryCacheTTL
+}
+
+// DomainPolicy returns the given DomainPolicy, also blocking the given
+// uninvitable domain.
+func (c localConfig) DomainPolicy() DomainPolicy {
+	if c.uninvitableDomain == "" {
+		return c.domainPolicy
+	}
+
+	return c.domainPolicy.WithBlocked(c.uninvitableDomain, c.uninvitableMessage)
+}
+
+func (c localConfig) GuestExpiryPath() string {
+	return c.guestExpiryPath
+}
+
+func (c localConfig) MaxConcurrentCommands() int {
+	return c.maxConcurrentCommands
+}
+
+func (c localConfig) Policy() Policy {
+	return c.policy
+}
+
+func (c localConfig) SkipRequestVerification() bool {
+	return c.skipRequestVerification
+}
+
 func (c localConfig) SlackAuthToken() string {
 	return c.slackAuthToken
 }
@@ -55,6 +159,22 @@ func (c localConfig) SlackSlashCommand() string {
 	return c.slackSlashCommand
 }
 
+func (c localConfig) SlackSigningSecret() string {
+	return c.slackSigningSecret
+}
+
+func (c localConfig) SlackVerificationToken() string {
+	return c.slackVerificationToken
+}
+
+func (c localConfig) StaleGuestInactivity() time.Duration {
+	return c.staleGuestInactivity
+}
+
+func (c localConfig) StaleGuestReaper() string {
+	return c.staleGuestReaper
+}
+
 func (c localConfig) UninvitableDomain() string {
 	return c.uninvitableDomain
 }
@@ -0,0 +1,47 @@
+package config
+
+import "encoding/json"
+
+// DefaultPolicyKey is the key of the PolicyRule that applies to commands
+// which have no rule of their own.
+const DefaultPolicyKey = "*"
+
+// Policy maps command names to the PolicyRule describing who may run them. A
+// command with no rule, when there is no default rule, may be run by anyone.
+type Policy map[string]PolicyRule
+
+// PolicyRule describes who may run a command. A user matching any of its
+// criteria is permitted; a rule with no criteria permits no one.
+type PolicyRule struct {
+	UserIDs      []string `json:"users"`
+	UserGroupIDs []string `json:"user_groups"`
+	Admins       bool     `json:"admins"`
+	Owners       bool     `json:"owners"`
+}
+
+// ParsePolicy parses a JSON-encoded Policy, for example:
+//
+//	{"disable-user": {"users": ["U1234"], "user_groups": ["S1234"], "admins": true}}
+func ParsePolicy(text string) (Policy, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	var policy Policy
+	if err := json.Unmarshal([]byte(text), &policy); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// Rule returns the PolicyRule for the given command, falling back to the
+// default rule. It returns false if neither exists.
+func (p Policy) Rule(command string) (PolicyRule, bool) {
+	if rule, ok := p[command]; ok {
+		return rule, true
+	}
+
+	rule, ok := p[DefaultPolicyKey]
+	return rule, ok
+}
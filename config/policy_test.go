@@ -0,0 +1,75 @@
+package config_test
+
+import (
+	"github.com/pivotalservices/goulash/config"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Policy", func() {
+	Describe("ParsePolicy", func() {
+		It("parses a JSON-encoded policy", func() {
+			policy, err := config.ParsePolicy(`{
+				"disable-user": {"users": ["U1234"], "user_groups": ["S1234"], "admins": true},
+				"*": {"owners": true}
+			}`)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(policy).Should(Equal(config.Policy{
+				"disable-user": config.PolicyRule{
+					UserIDs:      []string{"U1234"},
+					UserGroupIDs: []string{"S1234"},
+					Admins:       true,
+				},
+				"*": config.PolicyRule{
+					Owners: true,
+				},
+			}))
+		})
+
+		It("returns a nil policy when given nothing", func() {
+			policy, err := config.ParsePolicy("")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(policy).Should(BeNil())
+		})
+
+		It("returns an error when given invalid JSON", func() {
+			_, err := config.ParsePolicy("{")
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Describe("Rule", func() {
+		It("returns the rule for the command", func() {
+			policy := config.Policy{
+				"disable-user": config.PolicyRule{Admins: true},
+				"*":            config.PolicyRule{Owners: true},
+			}
+
+			rule, ok := policy.Rule("disable-user")
+			Ω(ok).Should(BeTrue())
+			Ω(rule).Should(Equal(config.PolicyRule{Admins: true}))
+		})
+
+		It("falls back to the default rule", func() {
+			policy := config.Policy{
+				"disable-user": config.PolicyRule{Admins: true},
+				"*":            config.PolicyRule{Owners: true},
+			}
+
+			rule, ok := policy.Rule("guestify")
+			Ω(ok).Should(BeTrue())
+			Ω(rule).Should(Equal(config.PolicyRule{Owners: true}))
+		})
+
+		It("returns false when there is no rule", func() {
+			policy := config.Policy{
+				"disable-user": config.PolicyRule{Admins: true},
+			}
+
+			_, ok := policy.Rule("guestify")
+			Ω(ok).Should(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,28 @@
+// Package expiry provides storage for when the accounts of invited guests
+// should be disabled.
+package expiry
+
+import "time"
+
+// Expiration records when the account of an invited person should be
+// disabled.
+type Expiration struct {
+	EmailAddress string    `json:"email"`
+	FirstName    string    `json:"first_name"`
+	LastName     string    `json:"last_name"`
+	InviterName  string    `json:"inviter_name"`
+	ChannelID    string    `json:"channel_id"`
+	ChannelName  string    `json:"channel_name"`
+	ExpiresAt    time.Time `json:"expires_at"`
+
+	// Warned is true once the person and their inviter have been told that
+	// the account is about to expire.
+	Warned bool `json:"warned"`
+}
+
+// Store is somewhere Expirations are kept, one per email address.
+type Store interface {
+	Put(Expiration) error
+	Remove(emailAddress string) error
+	All() ([]Expiration, error)
+}
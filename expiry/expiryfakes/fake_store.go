@@ -0,0 +1,125 @@
+// This file was generated by counterfeiter
+package expiryfakes
+
+import (
+	"sync"
+
+	"github.com/pivotalservices/goulash/expiry"
+)
+
+type FakeStore struct {
+	PutStub        func(expiry.Expiration) error
+	putMutex       sync.RWMutex
+	putArgsForCall []struct {
+		arg1 expiry.Expiration
+	}
+	putReturns struct {
+		result1 error
+	}
+	RemoveStub        func(emailAddress string) error
+	removeMutex       sync.RWMutex
+	removeArgsForCall []struct {
+		emailAddress string
+	}
+	removeReturns struct {
+		result1 error
+	}
+	AllStub        func() ([]expiry.Expiration, error)
+	allMutex       sync.RWMutex
+	allArgsForCall []struct{}
+	allReturns     struct {
+		result1 []expiry.Expiration
+		result2 error
+	}
+}
+
+func (fake *FakeStore) Put(arg1 expiry.Expiration) error {
+	fake.putMutex.Lock()
+	fake.putArgsForCall = append(fake.putArgsForCall, struct {
+		arg1 expiry.Expiration
+	}{arg1})
+	fake.putMutex.Unlock()
+	if fake.PutStub != nil {
+		return fake.PutStub(arg1)
+	} else {
+		return fake.putReturns.result1
+	}
+}
+
+func (fake *FakeStore) PutCallCount() int {
+	fake.putMutex.RLock()
+	defer fake.putMutex.RUnlock()
+	return len(fake.putArgsForCall)
+}
+
+func (fake *FakeStore) PutArgsForCall(i int) expiry.Expiration {
+	fake.putMutex.RLock()
+	defer fake.putMutex.RUnlock()
+	return fake.putArgsForCall[i].arg1
+}
+
+func (fake *FakeStore) PutReturns(result1 error) {
+	fake.PutStub = nil
+	fake.putReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStore) Remove(emailAddress string) error {
+	fake.removeMutex.Lock()
+	fake.removeArgsForCall = append(fake.removeArgsForCall, struct {
+		emailAddress string
+	}{emailAddress})
+	fake.removeMutex.Unlock()
+	if fake.RemoveStub != nil {
+		return fake.RemoveStub(emailAddress)
+	} else {
+		return fake.removeReturns.result1
+	}
+}
+
+func (fake *FakeStore) RemoveCallCount() int {
+	fake.removeMutex.RLock()
+	defer fake.removeMutex.RUnlock()
+	return len(fake.removeArgsForCall)
+}
+
+func (fake *FakeStore) RemoveArgsForCall(i int) string {
+	fake.removeMutex.RLock()
+	defer fake.removeMutex.RUnlock()
+	return fake.removeArgsForCall[i].emailAddress
+}
+
+func (fake *FakeStore) RemoveReturns(result1 error) {
+	fake.RemoveStub = nil
+	fake.removeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStore) All() ([]expiry.Expiration, error) {
+	fake.allMutex.Lock()
+	fake.allArgsForCall = append(fake.allArgsForCall, struct{}{})
+	fake.allMutex.Unlock()
+	if fake.AllStub != nil {
+		return fake.AllStub()
+	} else {
+		return fake.allReturns.result1, fake.allReturns.result2
+	}
+}
+
+func (fake *FakeStore) AllCallCount() int {
+	fake.allMutex.RLock()
+	defer fake.allMutex.RUnlock()
+	return len(fake.allArgsForCall)
+}
+
+func (fake *FakeStore) AllReturns(result1 []expiry.Expiration, result2 error) {
+	fake.AllStub = nil
+	fake.allReturns = struct {
+		result1 []expiry.Expiration
+		result2 error
+	}{result1, result2}
+}
+
+var _ expiry.Store = new(FakeStore)
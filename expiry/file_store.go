@@ -0,0 +1,143 @@
+package expiry
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type fileStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileStore returns a Store which keeps every Expiration in the file at the
+// given path as a JSON array, creating the file if it does not exist. The file
+// is replaced as a whole, so it is never left partly written.
+func NewFileStore(path string) Store {
+	return &fileStore{path: path}
+}
+
+// Put adds the Expiration, replacing any for the same email address.
+func (s *fileStore) Put(expiration Expiration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	expirations, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	expirations[key(expiration.EmailAddress)] = expiration
+
+	return s.write(expirations)
+}
+
+func (s *fileStore) Remove(emailAddress string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	expirations, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := expirations[key(emailAddress)]; !ok {
+		return nil
+	}
+
+	delete(expirations, key(emailAddress))
+
+	return s.write(expirations)
+}
+
+// All returns every Expiration, soonest first.
+func (s *fileStore) All() ([]Expiration, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	expirations, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return sorted(expirations), nil
+}
+
+func (s *fileStore) read() (map[string]Expiration, error) {
+	expirations := map[string]Expiration{}
+
+	contents, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return expirations, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Expiration
+	if err = json.Unmarshal(contents, &list); err != nil {
+		return nil, err
+	}
+
+	for _, expiration := range list {
+		expirations[key(expiration.EmailAddress)] = expiration
+	}
+
+	return expirations, nil
+}
+
+func (s *fileStore) write(expirations map[string]Expiration) error {
+	contents, err := json.MarshalIndent(sorted(expirations), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = file.Write(contents); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), s.path)
+}
+
+func sorted(expirations map[string]Expiration) []Expiration {
+	list := []Expiration{}
+	for _, expiration := range expirations {
+		list = append(list, expiration)
+	}
+
+	sort.Sort(bySoonest(list))
+
+	return list
+}
+
+func key(emailAddress string) string {
+	return strings.ToLower(emailAddress)
+}
+
+type bySoonest []Expiration
+
+func (e bySoonest) Len() int      { return len(e) }
+func (e bySoonest) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+func (e bySoonest) Less(i, j int) bool {
+	if e[i].ExpiresAt.Equal(e[j].ExpiresAt) {
+		return e[i].EmailAddress < e[j].EmailAddress
+	}
+
+	return e[i].ExpiresAt.Before(e[j].ExpiresAt)
+}
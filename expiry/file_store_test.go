@@ -0,0 +1,99 @@
+package expiry_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pivotalservices/goulash/expiry"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileStore", func() {
+	var (
+		dir   string
+		path  string
+		store expiry.Store
+		now   time.Time
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "goulash-expiry")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		path = filepath.Join(dir, "expirations.json")
+		store = expiry.NewFileStore(path)
+		now = time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	expiration := func(emailAddress string, expiresAt time.Time) expiry.Expiration {
+		return expiry.Expiration{
+			EmailAddress: emailAddress,
+			FirstName:    "Tom",
+			LastName:     "Smith",
+			InviterName:  "alice",
+			ChannelID:    "C1234",
+			ExpiresAt:    expiresAt,
+		}
+	}
+
+	It("returns no expirations before any are put", func() {
+		expirations, err := store.All()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(expirations).Should(BeEmpty())
+	})
+
+	It("returns the expirations put, soonest first", func() {
+		later := expiration("later@example.com", now.Add(48*time.Hour))
+		sooner := expiration("sooner@example.com", now.Add(24*time.Hour))
+
+		Ω(store.Put(later)).Should(Succeed())
+		Ω(store.Put(sooner)).Should(Succeed())
+
+		expirations, err := expiry.NewFileStore(path).All()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(expirations).Should(Equal([]expiry.Expiration{sooner, later}))
+	})
+
+	It("replaces the expiration for the same email address", func() {
+		Ω(store.Put(expiration("user@example.com", now))).Should(Succeed())
+
+		replacement := expiration("User@Example.com", now.Add(time.Hour))
+		replacement.Warned = true
+		Ω(store.Put(replacement)).Should(Succeed())
+
+		expirations, err := store.All()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(expirations).Should(Equal([]expiry.Expiration{replacement}))
+	})
+
+	It("removes the expiration for an email address", func() {
+		Ω(store.Put(expiration("user@example.com", now))).Should(Succeed())
+		Ω(store.Put(expiration("other@example.com", now))).Should(Succeed())
+
+		Ω(store.Remove("USER@example.com")).Should(Succeed())
+		Ω(store.Remove("unknown@example.com")).Should(Succeed())
+
+		expirations, err := store.All()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(expirations).Should(Equal([]expiry.Expiration{expiration("other@example.com", now)}))
+	})
+
+	It("returns an error when the file holds something other than expirations", func() {
+		err := ioutil.WriteFile(path, []byte("not json"), 0600)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		_, err = store.All()
+		Ω(err).Should(HaveOccurred())
+
+		Ω(store.Put(expiration("user@example.com", now))).ShouldNot(Succeed())
+	})
+})
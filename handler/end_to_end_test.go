@@ -0,0 +1,173 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/handler"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slacktest"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handler end to end", func() {
+	var (
+		server            *slacktest.Server
+		originalTransport http.RoundTripper
+		h                 http.Handler
+	)
+
+	BeforeEach(func() {
+		server = slacktest.NewServer("xoxp-token", "U0BOT")
+		server.AddUser(slack.User{ID: "U0BOT", Name: "goulash", IsAdmin: true, IsBot: true})
+		server.AddUser(slack.User{ID: "UADMIN", Name: "commander", IsAdmin: true})
+		server.AddUser(slack.User{
+			ID:   "U1",
+			Name: "tsmith",
+			Profile: slack.UserProfile{
+				FirstName: "Tom",
+				LastName:  "Smith",
+				Email:     "tom@example.com",
+			},
+		})
+		server.AddUser(slack.User{ID: "U2", Name: "restricted", IsRestricted: true})
+		server.AddUser(slack.User{ID: "U3", Name: "guest", IsUltraRestricted: true})
+		server.AddConversation(slackapi.Conversation{ID: "C1", Name: "general", IsChannel: true}, "U0BOT", "UADMIN", "U1", "U2")
+		server.AddConversation(slackapi.Conversation{ID: "C2", Name: "outside", IsChannel: true}, "UADMIN", "U1")
+		server.AddConversation(slackapi.Conversation{ID: "G1", Name: "secret", IsPrivate: true}, "U0BOT", "U3")
+		server.AddConversation(slackapi.Conversation{ID: "C3", Name: "audit-log", IsChannel: true}, "U0BOT")
+
+		originalTransport = http.DefaultTransport
+		http.DefaultTransport = server.Transport()
+
+		c := config.NewLocalConfig(config.LocalConfigOptions{
+			SlackAuthToken:          "xoxp-token",
+			SlackSlashCommand:       "/goulash",
+			SlackTeamName:           "example",
+			SlackUserID:             "U0BOT",
+			AuditLogChannelID:       "C3",
+			UninvitableDomain:       "uninvitable-domain.com",
+			UninvitableMessage:      "uninvitable-domain-message",
+			SkipRequestVerification: true,
+		})
+
+		fakeClock := fakeclock.NewFakeClock(time.Date(2014, 1, 31, 10, 59, 53, 124235, time.UTC))
+		h = handler.New(c, slackapi.New("xoxp-token", server.URL()), nil, fakeClock, lager.NewLogger("fakelogger"))
+	})
+
+	AfterEach(func() {
+		http.DefaultTransport = originalTransport
+		server.Close()
+	})
+
+	newRequest := func(channelID string, text string, responseURL string) *http.Request {
+		v := url.Values{
+			"channel_id":   {channelID},
+			"channel_name": {"channel-name"},
+			"command":      {"/goulash"},
+			"text":         {text},
+			"user_id":      {"UADMIN"},
+			"user_name":    {"commander"},
+		}
+		if responseURL != "" {
+			v.Set("response_url", responseURL)
+		}
+
+		r, err := http.NewRequest("POST", "http://localhost", strings.NewReader(v.Encode()))
+		Ω(err).ShouldNot(HaveOccurred())
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		return r
+	}
+
+	serve := func(channelID string, text string) string {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, newRequest(channelID, text, ""))
+		Ω(w.Code).Should(Equal(http.StatusOK))
+
+		return responseText(w)
+	}
+
+	It("looks users up a page at a time", func() {
+		server.SetPageSize(1)
+
+		Ω(serve("C1", "info tom@example.com")).Should(ContainSubstring("Tom Smith"))
+
+		var pages int
+		for _, method := range server.Requests() {
+			if method == "users.list" {
+				pages++
+			}
+		}
+		Ω(pages).Should(Equal(3))
+	})
+
+	It("invites Single-Channel Guests to the channel the command is used in", func() {
+		serve("C1", "invite-guest new@example.com Ann Bee")
+
+		Ω(server.Invites()).Should(Equal([]slacktest.Invite{{
+			Email:           "new@example.com",
+			FirstName:       "Ann",
+			LastName:        "Bee",
+			Channels:        []string{"C1"},
+			UltraRestricted: true,
+		}}))
+
+		auditLog := server.Messages("C3")
+		Ω(auditLog).Should(HaveLen(1))
+		Ω(auditLog[0].Text).Should(ContainSubstring("new@example.com"))
+	})
+
+	It("does not invite guests to a channel Goulash is not in", func() {
+		serve("C2", "invite-guest new@example.com Ann Bee")
+
+		Ω(server.Invites()).Should(BeEmpty())
+	})
+
+	It("disables users once the commander confirms", func() {
+		token := regexp.MustCompile("`/goulash confirm ([0-9a-f]+)`").FindStringSubmatch(serve("C1", "disable-user @restricted"))
+		Ω(token).Should(HaveLen(2))
+
+		user, _ := server.User("U2")
+		Ω(user.Deleted).Should(BeFalse())
+
+		Ω(serve("C1", "confirm "+token[1])).Should(Equal("Successfully disabled user '@restricted'"))
+
+		user, _ = server.User("U2")
+		Ω(user.Deleted).Should(BeTrue())
+	})
+
+	It("adds Restricted Accounts to channels", func() {
+		serve("C1", "add-to-channel @restricted #secret")
+
+		Ω(server.Members("G1")).Should(ContainElement("U2"))
+	})
+
+	It("sends the groups Goulash is in as a direct message", func() {
+		serve("C1", "groups")
+
+		messages := server.DirectMessages("UADMIN")
+		Ω(messages).Should(HaveLen(1))
+		Ω(messages[0].Text).Should(ContainSubstring("secret"))
+	})
+
+	It("posts the result to the response_url when given one", func() {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, newRequest("C1", "invite-guest new@example.com Ann Bee", server.ResponseURL()))
+		Ω(w.Code).Should(Equal(http.StatusOK))
+
+		Eventually(server.Responses).Should(HaveLen(1))
+		Ω(server.Responses()[0].String()).Should(ContainSubstring("new@example.com"))
+		Ω(server.Invites()).Should(HaveLen(1))
+	})
+})
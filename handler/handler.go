@@ -3,46 +3,100 @@
 package handler
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/pivotal-golang/clock"
 	"github.com/pivotal-golang/lager"
 	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/audit"
 	"github.com/pivotalservices/goulash/config"
 	"github.com/pivotalservices/goulash/slackapi"
 	"github.com/pivotalservices/slack"
 )
 
+const (
+	// defaultMaxConcurrentCommands is the number of commands run at once when
+	// the configuration does not say otherwise.
+	defaultMaxConcurrentCommands = 4
+
+	// queuedCommandsPerWorker is how many commands may wait for each worker
+	// before further commands are turned away.
+	queuedCommandsPerWorker = 16
+
+	responseURLTimeout = 10 * time.Second
+
+	busyMessage = "Too many commands are in progress. Please try again shortly."
+
+	panicMessage = "Something went wrong while performing the command. Please check the audit log before trying again."
+
+	// blockActionsType is the type of interactivity request sent when a
+	// button is clicked.
+	blockActionsType = "block_actions"
+)
+
 // Handler is an HTTP handler.
 type Handler struct {
-	config config.Config
-	api    slackapi.SlackAPI
-	clock  clock.Clock
-	logger lager.Logger
+	config     config.Config
+	api        slackapi.SlackAPI
+	store      audit.Store
+	clock      clock.Clock
+	logger     lager.Logger
+	pool       *workerPool
+	httpClient *http.Client
 }
 
-// New returns a new Handler.
+// New returns a new Handler. Audit events are recorded in the given Store
+// unless it is nil.
 func New(
 	config config.Config,
 	api slackapi.SlackAPI,
+	store audit.Store,
 	clock clock.Clock,
 	logger lager.Logger,
 ) *Handler {
+	workers := config.MaxConcurrentCommands()
+	if workers <= 0 {
+		workers = defaultMaxConcurrentCommands
+	}
+
 	return &Handler{
-		api:    api,
-		config: config,
-		clock:  clock,
-		logger: logger,
+		api:        api,
+		config:     config,
+		store:      store,
+		clock:      clock,
+		logger:     logger,
+		pool:       newWorkerPool(workers, workers*queuedCommandsPerWorker, logger.Session("worker-pool")),
+		httpClient: &http.Client{Timeout: responseURLTimeout},
 	}
 }
 
+// Shutdown stops the Handler from accepting commands which would be performed
+// asynchronously, and waits for those already accepted to finish.
+func (h *Handler) Shutdown() {
+	h.logger.Info("draining-commands")
+	h.pool.stop()
+	h.logger.Info("drained-commands")
+}
+
+// maxRequestBodySize is the largest request body that will be read from Slack.
+const maxRequestBodySize = 1 << 20
+
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.verified(w, r) {
+		return
+	}
+
 	channelID := r.PostFormValue("channel_id")
 	channelName := r.PostFormValue("channel_name")
 	commanderID := r.PostFormValue("user_id")
 	commanderName := r.PostFormValue("user_name")
+	responseURL := r.PostFormValue("response_url")
 	text := r.PostFormValue("text")
 
 	if channelID == "" || text == "" {
@@ -58,7 +112,109 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		"text":          text,
 	})
 
-	channel := slackapi.NewChannel(channelName, channelID)
+	h.handle(w, slackapi.NewChannel(channelName, channelID), commanderName, commanderID, text, responseURL)
+}
+
+// Interactions returns an http.Handler for Slack's interactivity requests,
+// sent when someone clicks a button in a message posted by Goulash. See
+// https://api.slack.com/interactivity/handling for more information. Each
+// button runs the command named by its action ID, given its value, as if it
+// had been typed by whoever clicked it.
+func (h *Handler) Interactions() http.Handler {
+	return http.HandlerFunc(h.serveInteraction)
+}
+
+type interactionPayload struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+		Name     string `json:"name"`
+	} `json:"user"`
+	Channel struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"channel"`
+	ResponseURL string `json:"response_url"`
+	Actions     []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+func (h *Handler) serveInteraction(w http.ResponseWriter, r *http.Request) {
+	if !h.verified(w, r) {
+		return
+	}
+
+	var payload interactionPayload
+	if err := json.Unmarshal([]byte(r.PostFormValue("payload")), &payload); err != nil {
+		h.logger.Error("failed-decoding-interaction-payload", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if payload.Type != blockActionsType || len(payload.Actions) == 0 || payload.Channel.ID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	commanderName := payload.User.Username
+	if commanderName == "" {
+		commanderName = payload.User.Name
+	}
+
+	text := strings.TrimSpace(payload.Actions[0].ActionID + " " + payload.Actions[0].Value)
+
+	h.logger.Info("started-processing-interaction", lager.Data{
+		"channelID":     payload.Channel.ID,
+		"channelName":   payload.Channel.Name,
+		"commanderID":   payload.User.ID,
+		"commanderName": commanderName,
+		"text":          text,
+	})
+
+	h.handle(w, slackapi.NewChannel(payload.Channel.Name, payload.Channel.ID), commanderName, payload.User.ID, text, payload.ResponseURL)
+}
+
+// verified reads the request's body and checks that it came from Slack,
+// responding with an error and returning false if not. The body is left to be
+// read again.
+func (h *Handler) verified(w http.ResponseWriter, r *http.Request) bool {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	if err != nil {
+		h.logger.Error("failed-reading-request-body", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return false
+	}
+
+	v := verifier{
+		signingSecret:     h.config.SlackSigningSecret(),
+		verificationToken: h.config.SlackVerificationToken(),
+		skip:              h.config.SkipRequestVerification(),
+		clock:             h.clock,
+	}
+	if err = v.verify(r.Header, body); err != nil {
+		h.logger.Error("failed-to-verify-request", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return true
+}
+
+// handle performs the command given in text, responding with its result or,
+// when there is somewhere to send the result later, acknowledging it.
+func (h *Handler) handle(
+	w http.ResponseWriter,
+	channel slackapi.Channel,
+	commanderName string,
+	commanderID string,
+	text string,
+	responseURL string,
+) {
 	a := action.New(
 		channel,
 		commanderName,
@@ -66,11 +222,96 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		text,
 	)
 
-	result, err := a.Do(h.config, h.api, h.logger)
+	// Slack only waits a few seconds for a response, so when it gives us
+	// somewhere to send the result later we acknowledge the command now and
+	// perform it in the background.
+	if responseURL != "" {
+		submitted := h.pool.submit(func() {
+			// A panic would otherwise leave the commander waiting for a
+			// result which never comes.
+			defer func() {
+				if r := recover(); r != nil {
+					h.logger.Error("panicked-processing-request", fmt.Errorf("%v", r), lager.Data{"text": text})
+					message := slackapi.NewErrorMessage(panicMessage)
+					message.ResponseType = slackapi.ResponseTypeEphemeral
+					h.postToResponseURL(responseURL, message)
+				}
+			}()
 
-	if h.config.AuditLogChannelID() != "" {
-		if auditableAction, ok := a.(action.AuditableAction); ok {
-			h.postAuditLogEntry(auditableAction.AuditMessage(h.api), err)
+			result := h.perform(a, text, channel, commanderName, commanderID)
+			h.postToResponseURL(responseURL, result)
+			h.logger.Info("finished-processing-request")
+		})
+
+		if !submitted {
+			h.logger.Info("rejected-request", lager.Data{"text": text})
+			respondWith(http.StatusServiceUnavailable, slackapi.NewErrorMessage(busyMessage), w, h.logger)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		h.logger.Info("accepted-request")
+		return
+	}
+
+	result := h.perform(a, text, channel, commanderName, commanderID)
+
+	respondWith(http.StatusOK, result, w, h.logger)
+
+	h.logger.Info("finished-processing-request")
+}
+
+// perform authorizes and performs the action, recording an audit event for
+// it, and returns the message to show the commander.
+func (h *Handler) perform(
+	a action.Action,
+	text string,
+	channel slackapi.Channel,
+	commanderName string,
+	commanderID string,
+) slackapi.Message {
+	var result slackapi.Message
+	authErr := action.Authorize(text, commanderID, h.config, h.api, h.logger)
+	err := authErr
+	if authErr == nil {
+		result, err = a.Do(h.config, h.api, h.clock, h.logger)
+	} else {
+		result = slackapi.NewErrorMessage(authErr.Error())
+	}
+
+	if h.config.AuditLogChannelID() != "" || h.store != nil {
+		var entries []action.AuditEntry
+		if multiAuditableAction, ok := a.(action.MultiAuditableAction); ok && authErr == nil {
+			entries = multiAuditableAction.AuditEntries(h.api)
+		} else if auditableAction, ok := a.(action.AuditableAction); ok {
+			entry := action.AuditEntry{
+				Message: auditableAction.AuditMessage(h.api),
+				Command: action.CommandName(text),
+				Err:     err,
+				DryRun:  action.IsDryRun(a),
+			}
+			if targetedAction, ok := a.(action.TargetedAction); ok {
+				entry.Target = targetedAction.AuditTarget()
+			}
+			entries = []action.AuditEntry{entry}
+		}
+
+		for _, entry := range entries {
+			h.RecordAuditEvent(audit.Event{
+				Time:            h.clock.Now().UTC(),
+				Actor:           commanderName,
+				ActorID:         commanderID,
+				Action:          entry.Command,
+				Target:          entry.Target,
+				ChannelID:       channel.ID(),
+				ChannelName:     channel.Name(h.api),
+				Message:         entry.Message,
+				Outcome:         outcome(entry.Err),
+				Error:           errorText(entry.Err),
+				DryRun:          entry.DryRun,
+				TargetRole:      entry.TargetRole,
+				TargetChannelID: entry.TargetChannelID,
+			})
 		}
 	}
 
@@ -78,26 +319,69 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.logger.Error("failed-to-perform-request", err)
 	}
 
-	respondWith(result, w, h.logger)
+	if result.ResponseType == "" {
+		result.ResponseType = slackapi.ResponseTypeEphemeral
+	}
 
-	h.logger.Info("finished-processing-request")
+	return result
+}
+
+func (h *Handler) postToResponseURL(responseURL string, message slackapi.Message) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		h.logger.Error("failed-encoding-response", err)
+		return
+	}
+
+	resp, err := h.httpClient.Post(responseURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		h.logger.Error("failed-posting-to-response-url", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		h.logger.Error("failed-posting-to-response-url", nil, lager.Data{
+			"statusCode": resp.StatusCode,
+		})
+		return
+	}
+
+	h.logger.Info("successfully-posted-to-response-url")
+}
+
+// RecordAuditEvent posts the event to the audit log channel and records it in
+// the Store, whichever are configured.
+func (h *Handler) RecordAuditEvent(event audit.Event) {
+	if h.config.AuditLogChannelID() != "" {
+		h.postAuditLogEntry(event)
+	}
+
+	if h.store != nil {
+		if err := h.store.Record(event); err != nil {
+			h.logger.Error("failed-to-record-audit-event", err)
+			return
+		}
+
+		h.logger.Info("successfully-recorded-audit-event")
+	}
 }
 
-func (h *Handler) postAuditLogEntry(text string, err error) {
+func (h *Handler) postAuditLogEntry(event audit.Event) {
 	var outcome string
-	if err == nil {
+	if event.Outcome == audit.OutcomeSucceeded {
 		outcome = "was successful."
 	} else {
-		outcome = fmt.Sprintf("failed with error: %s", err.Error())
+		outcome = fmt.Sprintf("failed with error: %s", event.Error)
 	}
 
-	message := fmt.Sprintf("%s at %s, which %s", text, h.clock.Now().UTC().Round(time.Second), outcome)
+	message := fmt.Sprintf("%s at %s, which %s", event.Message, event.Time.Round(time.Second), outcome)
 
 	postMessageParameters := slack.NewPostMessageParameters()
 	postMessageParameters.AsUser = true
 	postMessageParameters.Parse = "full"
 
-	_, _, err = h.api.PostMessage(h.config.AuditLogChannelID(), message, postMessageParameters)
+	_, _, err := h.api.PostMessage(h.config.AuditLogChannelID(), message, postMessageParameters)
 	if err != nil {
 		h.logger.Error("failed-to-add-audit-log-entry", err)
 		return
@@ -106,10 +390,40 @@ func (h *Handler) postAuditLogEntry(text string, err error) {
 	h.logger.Info("successfully-added-audit-log-entry")
 }
 
-func respondWith(text string, w http.ResponseWriter, logger lager.Logger) {
-	_, err := w.Write([]byte(text))
+func outcome(err error) string {
 	if err != nil {
-		logger.Error("failed-writing-response-body", err)
+		return audit.OutcomeFailed
+	}
+
+	return audit.OutcomeSucceeded
+}
+
+func errorText(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+
+	return ""
+}
+
+func respondWith(
+	statusCode int,
+	message slackapi.Message,
+	w http.ResponseWriter,
+	logger lager.Logger,
+) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		logger.Error("failed-encoding-response", err)
 		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	_, err = w.Write(body)
+	if err != nil {
+		logger.Error("failed-writing-response-body", err)
 	}
 }
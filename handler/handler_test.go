@@ -1,15 +1,26 @@
 package handler_test
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/pivotal-golang/clock/fakeclock"
 	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/accessrequest"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/audit"
+	"github.com/pivotalservices/goulash/audit/auditfakes"
 	"github.com/pivotalservices/goulash/config"
 	"github.com/pivotalservices/goulash/handler"
 	"github.com/pivotalservices/goulash/slackapi"
@@ -20,6 +31,13 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+var registeredAccessRequests = accessrequest.NewMemoryStore()
+
+func init() {
+	action.RegisterAccessRequests(registeredAccessRequests)
+	action.RegisterInvites(nil)
+}
+
 var _ = Describe("Handler", func() {
 	var (
 		c           config.Config
@@ -30,15 +48,15 @@ var _ = Describe("Handler", func() {
 	BeforeEach(func() {
 		initialTime = time.Date(2014, 1, 31, 10, 59, 53, 124235, time.UTC)
 		fakeClock = fakeclock.NewFakeClock(initialTime)
-		c = config.NewLocalConfig(
-			"fake-slack-auth-token",
-			"/slack-slash-command",
-			"slack-team-name",
-			"slack-user-id",
-			"",
-			"uninvitable-domain.com",
-			"uninvitable-domain-message",
-		)
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackAuthToken:          "fake-slack-auth-token",
+			SlackSlashCommand:       "/slack-slash-command",
+			SlackTeamName:           "slack-team-name",
+			SlackUserID:             "slack-user-id",
+			UninvitableDomain:       "uninvitable-domain.com",
+			UninvitableMessage:      "uninvitable-domain-message",
+			SkipRequestVerification: true,
+		})
 	})
 
 	It("returns 400 when given a request with a form not including a channel_id field", func() {
@@ -55,8 +73,8 @@ var _ = Describe("Handler", func() {
 		r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 		w := httptest.NewRecorder()
-		fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-		h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+		fakeSlackAPI := newFakeSlackAPI()
+		h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 		h.ServeHTTP(w, r)
 
 		Ω(w.Code).Should(Equal(http.StatusBadRequest))
@@ -76,13 +94,489 @@ var _ = Describe("Handler", func() {
 		r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 		w := httptest.NewRecorder()
-		fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-		h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+		fakeSlackAPI := newFakeSlackAPI()
+		h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 		h.ServeHTTP(w, r)
 
 		Ω(w.Code).Should(Equal(http.StatusBadRequest))
 	})
 
+	Describe("request verification", func() {
+		var (
+			fakeSlackAPI *slackapifakes.FakeSlackAPI
+			body         string
+		)
+
+		BeforeEach(func() {
+			fakeSlackAPI = newFakeSlackAPI()
+			v := url.Values{
+				"token":        {"some-token"},
+				"channel_id":   {"C1234567890"},
+				"channel_name": {"channel-name"},
+				"command":      {"/slack-slash-command"},
+				"text":         {"invite-guest user@example.com Tom Smith"},
+				"user_name":    {"requesting_user"},
+			}
+			body = v.Encode()
+		})
+
+		newRequest := func(timestamp string, signature string) *http.Request {
+			r, err := http.NewRequest("POST", "http://localhost", strings.NewReader(body))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
+			if timestamp != "" {
+				r.Header.Set("X-Slack-Request-Timestamp", timestamp)
+			}
+			if signature != "" {
+				r.Header.Set("X-Slack-Signature", signature)
+			}
+
+			return r
+		}
+
+		Describe("with a signing secret", func() {
+			BeforeEach(func() {
+				c = config.NewLocalConfig(config.LocalConfigOptions{
+					SlackAuthToken:     "fake-slack-auth-token",
+					SlackSlashCommand:  "/slack-slash-command",
+					SlackTeamName:      "slack-team-name",
+					SlackUserID:        "slack-user-id",
+					UninvitableDomain:  "uninvitable-domain.com",
+					UninvitableMessage: "uninvitable-domain-message",
+					SlackSigningSecret: "signing-secret",
+				})
+			})
+
+			It("performs the action when the signature is valid", func() {
+				timestamp := fmt.Sprintf("%d", initialTime.Unix())
+				r := newRequest(timestamp, sign("signing-secret", timestamp, body))
+
+				w := httptest.NewRecorder()
+				h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+				h.ServeHTTP(w, r)
+
+				Ω(w.Code).Should(Equal(http.StatusOK))
+				Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(1))
+			})
+
+			It("returns 401 when the signature is missing", func() {
+				r := newRequest("", "")
+
+				w := httptest.NewRecorder()
+				h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+				h.ServeHTTP(w, r)
+
+				Ω(w.Code).Should(Equal(http.StatusUnauthorized))
+				Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
+			})
+
+			It("returns 401 when the signature was made with a different secret", func() {
+				timestamp := fmt.Sprintf("%d", initialTime.Unix())
+				r := newRequest(timestamp, sign("other-secret", timestamp, body))
+
+				w := httptest.NewRecorder()
+				h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+				h.ServeHTTP(w, r)
+
+				Ω(w.Code).Should(Equal(http.StatusUnauthorized))
+				Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
+			})
+
+			It("returns 401 when the body has been tampered with", func() {
+				timestamp := fmt.Sprintf("%d", initialTime.Unix())
+				signature := sign("signing-secret", timestamp, body)
+				body = strings.Replace(body, "invite-guest", "disable-user", 1)
+				r := newRequest(timestamp, signature)
+
+				w := httptest.NewRecorder()
+				h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+				h.ServeHTTP(w, r)
+
+				Ω(w.Code).Should(Equal(http.StatusUnauthorized))
+				Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+			})
+
+			It("returns 401 when the timestamp is stale", func() {
+				timestamp := fmt.Sprintf("%d", initialTime.Unix())
+				r := newRequest(timestamp, sign("signing-secret", timestamp, body))
+				fakeClock.Increment(6 * time.Minute)
+
+				w := httptest.NewRecorder()
+				h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+				h.ServeHTTP(w, r)
+
+				Ω(w.Code).Should(Equal(http.StatusUnauthorized))
+				Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
+			})
+
+			It("ignores the verification token", func() {
+				body = strings.Replace(body, "some-token", "other-token", 1)
+				timestamp := fmt.Sprintf("%d", initialTime.Unix())
+				r := newRequest(timestamp, sign("signing-secret", timestamp, body))
+
+				w := httptest.NewRecorder()
+				h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+				h.ServeHTTP(w, r)
+
+				Ω(w.Code).Should(Equal(http.StatusOK))
+			})
+		})
+
+		Describe("with a verification token", func() {
+			BeforeEach(func() {
+				c = config.NewLocalConfig(config.LocalConfigOptions{
+					SlackAuthToken:         "fake-slack-auth-token",
+					SlackSlashCommand:      "/slack-slash-command",
+					SlackTeamName:          "slack-team-name",
+					SlackUserID:            "slack-user-id",
+					UninvitableDomain:      "uninvitable-domain.com",
+					UninvitableMessage:     "uninvitable-domain-message",
+					SlackVerificationToken: "some-token",
+				})
+			})
+
+			It("performs the action when the token matches", func() {
+				w := httptest.NewRecorder()
+				h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+				h.ServeHTTP(w, newRequest("", ""))
+
+				Ω(w.Code).Should(Equal(http.StatusOK))
+				Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(1))
+			})
+
+			It("returns 401 when the token does not match", func() {
+				body = strings.Replace(body, "some-token", "other-token", 1)
+
+				w := httptest.NewRecorder()
+				h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+				h.ServeHTTP(w, newRequest("", ""))
+
+				Ω(w.Code).Should(Equal(http.StatusUnauthorized))
+				Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
+			})
+		})
+
+		Describe("with neither a signing secret nor a verification token", func() {
+			newConfig := func(skipRequestVerification bool) config.Config {
+				return config.NewLocalConfig(config.LocalConfigOptions{
+					SlackAuthToken:          "fake-slack-auth-token",
+					SlackSlashCommand:       "/slack-slash-command",
+					SlackTeamName:           "slack-team-name",
+					SlackUserID:             "slack-user-id",
+					SkipRequestVerification: skipRequestVerification,
+				})
+			}
+
+			It("returns 401", func() {
+				w := httptest.NewRecorder()
+				h := handler.New(newConfig(false), fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+				h.ServeHTTP(w, newRequest("", ""))
+
+				Ω(w.Code).Should(Equal(http.StatusUnauthorized))
+				Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
+			})
+
+			It("performs the action when verification is skipped", func() {
+				w := httptest.NewRecorder()
+				h := handler.New(newConfig(true), fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+				h.ServeHTTP(w, newRequest("", ""))
+
+				Ω(w.Code).Should(Equal(http.StatusOK))
+				Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(1))
+			})
+		})
+	})
+
+	Describe("authorization", func() {
+		var (
+			fakeSlackAPI *slackapifakes.FakeSlackAPI
+			r            *http.Request
+		)
+
+		BeforeEach(func() {
+			v := url.Values{
+				"token":        {"some-token"},
+				"channel_id":   {"C1234567890"},
+				"channel_name": {"channel-name"},
+				"command":      {"/slack-slash-command"},
+				"text":         {"disable-user @tsmith"},
+				"user_id":      {"U1234"},
+				"user_name":    {"requesting_user"},
+			}
+			var err error
+			r, err = http.NewRequest("POST", "http://localhost", strings.NewReader(v.Encode()))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
+
+			fakeSlackAPI = newFakeSlackAPI()
+			fakeSlackAPI.GetUserInfoReturns(&slack.User{ID: "U1234"}, nil)
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{
+				{ID: "U5678", Name: "tsmith", IsRestricted: true},
+			}, "", nil)
+
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:          "fake-slack-auth-token",
+				SlackSlashCommand:       "/slack-slash-command",
+				SlackTeamName:           "slack-team-name",
+				SlackUserID:             "slack-user-id",
+				AuditLogChannelID:       "audit-log-channel-id",
+				UninvitableDomain:       "uninvitable-domain.com",
+				UninvitableMessage:      "uninvitable-domain-message",
+				SkipRequestVerification: true,
+				Policy:                  config.Policy{"disable-user": config.PolicyRule{Admins: true}},
+			})
+		})
+
+		It("does not perform the action when the commander is not permitted", func() {
+			w := httptest.NewRecorder()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+			h.ServeHTTP(w, r)
+
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+			Ω(responseText(w)).Should(Equal("You are not permitted to use `/slack-slash-command disable-user`."))
+		})
+
+		It("shows the refusal as an error", func() {
+			w := httptest.NewRecorder()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+			h.ServeHTTP(w, r)
+
+			var message slackapi.Message
+			Ω(json.Unmarshal(w.Body.Bytes(), &message)).Should(Succeed())
+			Ω(message.Attachments).Should(HaveLen(1))
+			Ω(message.Attachments[0].Color).Should(Equal("danger"))
+		})
+
+		It("posts the refusal to the configured audit log channel", func() {
+			w := httptest.NewRecorder()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+			h.ServeHTTP(w, r)
+
+			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(1))
+
+			_, actualText, _ := fakeSlackAPI.PostMessageArgsForCall(0)
+			Ω(actualText).Should(Equal("@requesting_user disabled user @tsmith at 2014-01-31 10:59:53 +0000 UTC, which failed with error: You are not permitted to use `/slack-slash-command disable-user`."))
+		})
+
+		It("asks the commander to confirm the action when they are permitted", func() {
+			stubUsers(fakeSlackAPI,
+				slack.User{ID: "U1234", IsAdmin: true},
+				slack.User{ID: "U5678", Name: "tsmith", IsRestricted: true},
+			)
+
+			w := httptest.NewRecorder()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+			h.ServeHTTP(w, r)
+
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(0))
+			Ω(responseText(w)).Should(HavePrefix("Are you sure? `disable-user` will:\n• disable <@U5678>\n"))
+		})
+
+		It("performs the action once the commander confirms it", func() {
+			stubUsers(fakeSlackAPI,
+				slack.User{ID: "U1234", IsAdmin: true},
+				slack.User{ID: "U5678", Name: "tsmith", IsRestricted: true},
+			)
+
+			w := httptest.NewRecorder()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+			h.ServeHTTP(w, r)
+
+			token := regexp.MustCompile("`/slack-slash-command confirm ([0-9a-f]+)`").FindStringSubmatch(responseText(w))
+			Ω(token).Should(HaveLen(2))
+
+			v := url.Values{
+				"token":        {"some-token"},
+				"channel_id":   {"C1234567890"},
+				"channel_name": {"channel-name"},
+				"command":      {"/slack-slash-command"},
+				"text":         {"confirm " + token[1]},
+				"user_id":      {"U1234"},
+				"user_name":    {"requesting_user"},
+			}
+			confirmation, err := http.NewRequest("POST", "http://localhost", strings.NewReader(v.Encode()))
+			Ω(err).ShouldNot(HaveOccurred())
+			confirmation.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
+
+			w = httptest.NewRecorder()
+			h.ServeHTTP(w, confirmation)
+
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(1))
+			Ω(responseText(w)).Should(Equal("Successfully disabled user '@tsmith'"))
+
+			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(1))
+			_, actualText, _ := fakeSlackAPI.PostMessageArgsForCall(0)
+			Ω(actualText).Should(Equal("@requesting_user disabled user @tsmith at 2014-01-31 10:59:53 +0000 UTC, which was successful."))
+		})
+	})
+
+	Describe("when given a response_url", func() {
+		var (
+			fakeSlackAPI   *slackapifakes.FakeSlackAPI
+			responseServer *httptest.Server
+			responses      chan slackapi.Message
+			newRequest     func(text string) *http.Request
+		)
+
+		BeforeEach(func() {
+			fakeSlackAPI = newFakeSlackAPI()
+			responses = make(chan slackapi.Message, 10)
+
+			responseServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				defer GinkgoRecover()
+
+				Ω(r.Header.Get("Content-Type")).Should(Equal("application/json"))
+
+				body, err := ioutil.ReadAll(r.Body)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				var response slackapi.Message
+				Ω(json.Unmarshal(body, &response)).Should(Succeed())
+				responses <- response
+			}))
+
+			newRequest = func(text string) *http.Request {
+				v := url.Values{
+					"token":        {"some-token"},
+					"channel_id":   {"C1234567890"},
+					"channel_name": {"channel-name"},
+					"command":      {"/slack-slash-command"},
+					"text":         {text},
+					"user_name":    {"requesting_user"},
+					"response_url": {responseServer.URL},
+				}
+				r, err := http.NewRequest("POST", "http://localhost", strings.NewReader(v.Encode()))
+				Ω(err).ShouldNot(HaveOccurred())
+
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
+
+				return r
+			}
+		})
+
+		AfterEach(func() {
+			responseServer.Close()
+		})
+
+		It("acknowledges the command without a body", func() {
+			w := httptest.NewRecorder()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+			h.ServeHTTP(w, newRequest("invite-guest user@example.com Tom Smith"))
+			h.Shutdown()
+
+			Ω(w.Code).Should(Equal(http.StatusOK))
+			Ω(w.Body.String()).Should(BeEmpty())
+		})
+
+		It("posts the result of the command to the response_url", func() {
+			w := httptest.NewRecorder()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+			h.ServeHTTP(w, newRequest("invite-guest user@example.com Tom Smith"))
+
+			Eventually(responses).Should(Receive(Equal(slackapi.Message{
+				ResponseType: slackapi.ResponseTypeEphemeral,
+				Text:         "Successfully invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name'",
+			})))
+			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(1))
+
+			h.Shutdown()
+		})
+
+		It("finishes commands in progress before Shutdown returns", func() {
+			release := make(chan struct{})
+			fakeSlackAPI.InviteGuestStub = func(string, string, string, string, string) error {
+				<-release
+				return nil
+			}
+
+			w := httptest.NewRecorder()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+			h.ServeHTTP(w, newRequest("invite-guest user@example.com Tom Smith"))
+
+			shutdown := make(chan struct{})
+			go func() {
+				h.Shutdown()
+				close(shutdown)
+			}()
+
+			Consistently(shutdown).ShouldNot(BeClosed())
+			close(release)
+			Eventually(shutdown).Should(BeClosed())
+
+			Ω(responses).Should(HaveLen(1))
+		})
+
+		It("tells the commander something went wrong when a command panics, and goes on to the next", func() {
+			fakeSlackAPI.InviteGuestStub = func(string, string, string, string, string) error {
+				if fakeSlackAPI.InviteGuestCallCount() == 1 {
+					panic("boom")
+				}
+				return nil
+			}
+
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+			h.ServeHTTP(httptest.NewRecorder(), newRequest("invite-guest user1@example.com Tom Smith"))
+
+			var response slackapi.Message
+			Eventually(responses).Should(Receive(&response))
+			Ω(response.ResponseType).Should(Equal(slackapi.ResponseTypeEphemeral))
+			Ω(response.Attachments).Should(HaveLen(1))
+			Ω(response.Attachments[0].Color).Should(Equal("danger"))
+			Ω(response.Attachments[0].Text).Should(ContainSubstring("Something went wrong"))
+
+			h.ServeHTTP(httptest.NewRecorder(), newRequest("invite-guest user2@example.com Tom Smith"))
+			Eventually(responses).Should(Receive(&response))
+			Ω(response.Text).Should(HavePrefix("Successfully invited"))
+
+			h.Shutdown()
+		})
+
+		It("turns away commands once shut down", func() {
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+			h.Shutdown()
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, newRequest("invite-guest user@example.com Tom Smith"))
+
+			Ω(w.Code).Should(Equal(http.StatusServiceUnavailable))
+			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
+		})
+
+		It("runs no more than the configured number of commands at once", func() {
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:          "fake-slack-auth-token",
+				SlackSlashCommand:       "/slack-slash-command",
+				SlackTeamName:           "slack-team-name",
+				SlackUserID:             "slack-user-id",
+				UninvitableDomain:       "uninvitable-domain.com",
+				UninvitableMessage:      "uninvitable-domain-message",
+				SkipRequestVerification: true,
+				MaxConcurrentCommands:   1,
+			})
+
+			release := make(chan struct{})
+			fakeSlackAPI.InviteGuestStub = func(string, string, string, string, string) error {
+				<-release
+				return nil
+			}
+
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+			h.ServeHTTP(httptest.NewRecorder(), newRequest("invite-guest user1@example.com Tom Smith"))
+			h.ServeHTTP(httptest.NewRecorder(), newRequest("invite-guest user2@example.com Tom Smith"))
+
+			Eventually(fakeSlackAPI.InviteGuestCallCount).Should(Equal(1))
+			Consistently(fakeSlackAPI.InviteGuestCallCount).Should(Equal(1))
+
+			close(release)
+			Eventually(fakeSlackAPI.InviteGuestCallCount).Should(Equal(2))
+
+			h.Shutdown()
+		})
+	})
+
 	Describe("invite-guest", func() {
 		It("invites a single channel guest", func() {
 			v := url.Values{
@@ -100,8 +594,8 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			fakeSlackAPI := newFakeSlackAPI()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
 			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(1))
@@ -130,8 +624,8 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			fakeSlackAPI := newFakeSlackAPI()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
 			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(1))
@@ -160,10 +654,10 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			fakeSlackAPI := newFakeSlackAPI()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
-			Ω(w.Body.String()).Should(Equal("Successfully invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name'"))
+			Ω(responseText(w)).Should(Equal("Successfully invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name'"))
 		})
 
 		It("responds to Slack with the result of the command on failure", func() {
@@ -181,13 +675,13 @@ var _ = Describe("Handler", func() {
 
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
+			fakeSlackAPI := newFakeSlackAPI()
 			fakeSlackAPI.InviteGuestReturns(errors.New("failed to invite user"))
 
 			w := httptest.NewRecorder()
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
-			Ω(w.Body.String()).Should(Equal("Failed to invite Tom Smith (user@example.com) as a single-channel guest to 'channel-name': failed to invite user"))
+			Ω(responseText(w)).Should(Equal("Failed to invite Tom Smith (user@example.com) as a single-channel guest to 'channel-name': failed to invite user"))
 		})
 
 		It("responds to Slack when it isn't a member of the private group", func() {
@@ -206,15 +700,12 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			fakeSlackAPI.GetGroupsReturns([]slack.Group{
-				newGroup("unexpected-group-1", "C1111111111"),
-				newGroup("unexpected-group-2", "C9999999999"),
-			}, nil)
+			fakeSlackAPI := newFakeSlackAPI()
+			fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{ID: "C1234567890", IsPrivate: true}, nil)
 
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
-			Ω(w.Body.String()).Should(Equal("<@slack-user-id> can only invite people to channels or private groups it is a member of. You can invite <@slack-user-id> by typing `/invite @slack-user-id` from the channel or private group you would like <@slack-user-id> to invite people to."))
+			Ω(responseText(w)).Should(Equal("<@slack-user-id> can only invite people to channels or private groups it is a member of. You can invite <@slack-user-id> by typing `/invite @slack-user-id` from the channel or private group you would like <@slack-user-id> to invite people to."))
 		})
 
 		It("responds to Slack when an email with an uninvitable domain is invited", func() {
@@ -233,11 +724,11 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			fakeSlackAPI := newFakeSlackAPI()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
-			Ω(w.Body.String()).Should(Equal("Users for the 'uninvitable-domain.com' domain are unable to be invited through /slack-slash-command. uninvitable-domain-message"))
+			Ω(responseText(w)).Should(Equal("Users for the 'uninvitable-domain.com' domain are unable to be invited through /slack-slash-command. uninvitable-domain-message"))
 		})
 
 		It("posts a message to the configured audit log channel on success", func() {
@@ -256,18 +747,19 @@ var _ = Describe("Handler", func() {
 
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
+			fakeSlackAPI := newFakeSlackAPI()
 			w := httptest.NewRecorder()
-			c = config.NewLocalConfig(
-				"fake-slack-auth-token",
-				"/slack-slash-command",
-				"slack-team-name",
-				"slack-user-id",
-				"audit-log-channel-id",
-				"uninvitable-domain.com",
-				"uninvitable-domain-message",
-			)
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:          "fake-slack-auth-token",
+				SlackSlashCommand:       "/slack-slash-command",
+				SlackTeamName:           "slack-team-name",
+				SlackUserID:             "slack-user-id",
+				AuditLogChannelID:       "audit-log-channel-id",
+				UninvitableDomain:       "uninvitable-domain.com",
+				UninvitableMessage:      "uninvitable-domain-message",
+				SkipRequestVerification: true,
+			})
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(1))
@@ -298,24 +790,26 @@ var _ = Describe("Handler", func() {
 
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			fakeSlackAPI.GetGroupsReturns([]slack.Group{
-				newGroup("unexpected-group-1", "C1111111111"),
-				newGroup("channel-name", "C1234567890"),
-				newGroup("unexpected-group-2", "C9999999999"),
+			fakeSlackAPI := newFakeSlackAPI()
+			fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{
+				ID:        "C1234567890",
+				Name:      "channel-name",
+				IsPrivate: true,
+				IsMember:  true,
 			}, nil)
 
 			w := httptest.NewRecorder()
-			c = config.NewLocalConfig(
-				"fake-slack-auth-token",
-				"/slack-slash-command",
-				"slack-team-name",
-				"slack-user-id",
-				"audit-log-channel-id",
-				"uninvitable-domain.com",
-				"uninvitable-domain-message",
-			)
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:          "fake-slack-auth-token",
+				SlackSlashCommand:       "/slack-slash-command",
+				SlackTeamName:           "slack-team-name",
+				SlackUserID:             "slack-user-id",
+				AuditLogChannelID:       "audit-log-channel-id",
+				UninvitableDomain:       "uninvitable-domain.com",
+				UninvitableMessage:      "uninvitable-domain-message",
+				SkipRequestVerification: true,
+			})
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(1))
@@ -346,20 +840,21 @@ var _ = Describe("Handler", func() {
 
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
+			fakeSlackAPI := newFakeSlackAPI()
 			fakeSlackAPI.InviteGuestReturns(errors.New("failed to invite user"))
 
 			w := httptest.NewRecorder()
-			c = config.NewLocalConfig(
-				"fake-slack-auth-token",
-				"/slack-slash-command",
-				"slack-team-name",
-				"slack-user-id",
-				"audit-log-channel-id",
-				"uninvitable-domain.com",
-				"uninvitable-domain-message",
-			)
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:          "fake-slack-auth-token",
+				SlackSlashCommand:       "/slack-slash-command",
+				SlackTeamName:           "slack-team-name",
+				SlackUserID:             "slack-user-id",
+				AuditLogChannelID:       "audit-log-channel-id",
+				UninvitableDomain:       "uninvitable-domain.com",
+				UninvitableMessage:      "uninvitable-domain-message",
+				SkipRequestVerification: true,
+			})
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(1))
@@ -392,8 +887,8 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			fakeSlackAPI := newFakeSlackAPI()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
 			Ω(fakeSlackAPI.InviteRestrictedCallCount()).Should(Equal(1))
@@ -421,12 +916,12 @@ var _ = Describe("Handler", func() {
 
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
+			fakeSlackAPI := newFakeSlackAPI()
 			w := httptest.NewRecorder()
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
-			Ω(w.Body.String()).Should(Equal("Users for the 'uninvitable-domain.com' domain are unable to be invited through /slack-slash-command. uninvitable-domain-message"))
+			Ω(responseText(w)).Should(Equal("Users for the 'uninvitable-domain.com' domain are unable to be invited through /slack-slash-command. uninvitable-domain-message"))
 		})
 
 		It("invites a restricted account when first/last name are missing", func() {
@@ -445,8 +940,8 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			fakeSlackAPI := newFakeSlackAPI()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
 			Ω(fakeSlackAPI.InviteRestrictedCallCount()).Should(Equal(1))
@@ -475,15 +970,12 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			fakeSlackAPI.GetGroupsReturns([]slack.Group{
-				newGroup("unexpected-group-1", "C1111111111"),
-				newGroup("unexpected-group-2", "C9999999999"),
-			}, nil)
+			fakeSlackAPI := newFakeSlackAPI()
+			fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{ID: "C1234567890", IsPrivate: true}, nil)
 
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
-			Ω(w.Body.String()).Should(Equal("<@slack-user-id> can only invite people to channels or private groups it is a member of. You can invite <@slack-user-id> by typing `/invite @slack-user-id` from the channel or private group you would like <@slack-user-id> to invite people to."))
+			Ω(responseText(w)).Should(Equal("<@slack-user-id> can only invite people to channels or private groups it is a member of. You can invite <@slack-user-id> by typing `/invite @slack-user-id` from the channel or private group you would like <@slack-user-id> to invite people to."))
 		})
 
 		It("responds to Slack with the result of the command on success", func() {
@@ -502,11 +994,11 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			fakeSlackAPI := newFakeSlackAPI()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
-			Ω(w.Body.String()).Should(Equal("Successfully invited Tom Smith (user@example.com) as a restricted account to 'channel-name'"))
+			Ω(responseText(w)).Should(Equal("Successfully invited Tom Smith (user@example.com) as a restricted account to 'channel-name'"))
 		})
 
 		It("responds to Slack with the result of the command on failure", func() {
@@ -524,14 +1016,14 @@ var _ = Describe("Handler", func() {
 
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
+			fakeSlackAPI := newFakeSlackAPI()
 			fakeSlackAPI.InviteRestrictedReturns(errors.New("failed to invite user"))
 
 			w := httptest.NewRecorder()
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
-			Ω(w.Body.String()).Should(Equal("Failed to invite Tom Smith (user@example.com) as a restricted account to 'channel-name': failed to invite user"))
+			Ω(responseText(w)).Should(Equal("Failed to invite Tom Smith (user@example.com) as a restricted account to 'channel-name': failed to invite user"))
 		})
 
 		It("posts a message to the configured audit log channel on success", func() {
@@ -550,18 +1042,19 @@ var _ = Describe("Handler", func() {
 
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
+			fakeSlackAPI := newFakeSlackAPI()
 			w := httptest.NewRecorder()
-			c = config.NewLocalConfig(
-				"fake-slack-auth-token",
-				"/slack-slash-command",
-				"slack-team-name",
-				"slack-user-id",
-				"audit-log-channel-id",
-				"uninvitable-domain.com",
-				"uninvitable-domain-message",
-			)
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:          "fake-slack-auth-token",
+				SlackSlashCommand:       "/slack-slash-command",
+				SlackTeamName:           "slack-team-name",
+				SlackUserID:             "slack-user-id",
+				AuditLogChannelID:       "audit-log-channel-id",
+				UninvitableDomain:       "uninvitable-domain.com",
+				UninvitableMessage:      "uninvitable-domain-message",
+				SkipRequestVerification: true,
+			})
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(1))
@@ -592,20 +1085,21 @@ var _ = Describe("Handler", func() {
 
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
+			fakeSlackAPI := newFakeSlackAPI()
 			fakeSlackAPI.InviteRestrictedReturns(errors.New("failed to invite user"))
 
 			w := httptest.NewRecorder()
-			c = config.NewLocalConfig(
-				"fake-slack-auth-token",
-				"/slack-slash-command",
-				"slack-team-name",
-				"slack-user-id",
-				"audit-log-channel-id",
-				"uninvitable-domain.com",
-				"uninvitable-domain-message",
-			)
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:          "fake-slack-auth-token",
+				SlackSlashCommand:       "/slack-slash-command",
+				SlackTeamName:           "slack-team-name",
+				SlackUserID:             "slack-user-id",
+				AuditLogChannelID:       "audit-log-channel-id",
+				UninvitableDomain:       "uninvitable-domain.com",
+				UninvitableMessage:      "uninvitable-domain-message",
+				SkipRequestVerification: true,
+			})
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(1))
@@ -621,6 +1115,343 @@ var _ = Describe("Handler", func() {
 		})
 	})
 
+	Describe("invite-bulk", func() {
+		It("posts a message to the configured audit log channel for each invitee", func() {
+			v := url.Values{
+				"token":        {"some-token"},
+				"channel_id":   {"C1234567890"},
+				"channel_name": {"channel-name"},
+				"command":      {"/slack-slash-command"},
+				"text":         {"invite-bulk guest\nuser@example.com,Tom,Smith\nuser@uninvitable-domain.com,Jane,Doe"},
+				"user_name":    {"requesting_user"},
+			}
+			reqBody := strings.NewReader(v.Encode())
+			r, err := http.NewRequest("POST", "http://localhost", reqBody)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
+
+			fakeSlackAPI := newFakeSlackAPI()
+
+			w := httptest.NewRecorder()
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:          "fake-slack-auth-token",
+				SlackSlashCommand:       "/slack-slash-command",
+				SlackTeamName:           "slack-team-name",
+				SlackUserID:             "slack-user-id",
+				AuditLogChannelID:       "audit-log-channel-id",
+				UninvitableDomain:       "uninvitable-domain.com",
+				UninvitableMessage:      "uninvitable-domain-message",
+				SkipRequestVerification: true,
+			})
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+			h.ServeHTTP(w, r)
+
+			Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(1))
+			Ω(responseText(w)).Should(HavePrefix("Invited 1 of 2 people as single-channel guests to 'channel-name'."))
+
+			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(2))
+
+			_, actualText, _ := fakeSlackAPI.PostMessageArgsForCall(0)
+			Ω(actualText).Should(Equal("@requesting_user invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name' (C1234567890) at 2014-01-31 10:59:53 +0000 UTC, which was successful."))
+
+			_, actualText, _ = fakeSlackAPI.PostMessageArgsForCall(1)
+			Ω(actualText).Should(HavePrefix("@requesting_user invited Jane Doe (user@uninvitable-domain.com) as a single-channel guest to 'channel-name' (C1234567890) at 2014-01-31 10:59:53 +0000 UTC, which failed with error: Users for the 'uninvitable-domain.com' domain"))
+		})
+	})
+
+	Describe("audit store", func() {
+		It("records an event for each invitee", func() {
+			v := url.Values{
+				"token":        {"some-token"},
+				"channel_id":   {"C1234567890"},
+				"channel_name": {"channel-name"},
+				"command":      {"/slack-slash-command"},
+				"text":         {"invite-bulk guest\nuser@example.com,Tom,Smith\nuser@uninvitable-domain.com,Jane,Doe"},
+				"user_id":      {"U1234"},
+				"user_name":    {"requesting_user"},
+			}
+			reqBody := strings.NewReader(v.Encode())
+			r, err := http.NewRequest("POST", "http://localhost", reqBody)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
+
+			fakeSlackAPI := newFakeSlackAPI()
+			fakeStore := &auditfakes.FakeStore{}
+
+			w := httptest.NewRecorder()
+			h := handler.New(c, fakeSlackAPI, fakeStore, fakeClock, lager.NewLogger("fakelogger"))
+			h.ServeHTTP(w, r)
+
+			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(0))
+			Ω(fakeStore.RecordCallCount()).Should(Equal(2))
+
+			Ω(fakeStore.RecordArgsForCall(0)).Should(Equal(audit.Event{
+				Time:        initialTime,
+				Actor:       "requesting_user",
+				ActorID:     "U1234",
+				Action:      "invite-guest",
+				Target:      "user@example.com",
+				ChannelID:   "C1234567890",
+				ChannelName: "channel-name",
+				Message:     "@requesting_user invited Tom Smith (user@example.com) as a single-channel guest to 'channel-name' (C1234567890)",
+				Outcome:     audit.OutcomeSucceeded,
+			}))
+
+			event := fakeStore.RecordArgsForCall(1)
+			Ω(event.Target).Should(Equal("user@uninvitable-domain.com"))
+			Ω(event.Outcome).Should(Equal(audit.OutcomeFailed))
+			Ω(event.Error).Should(HavePrefix("Users for the 'uninvitable-domain.com' domain"))
+		})
+
+		It("records the target and outcome of a refused command", func() {
+			v := url.Values{
+				"token":        {"some-token"},
+				"channel_id":   {"C1234567890"},
+				"channel_name": {"channel-name"},
+				"command":      {"/slack-slash-command"},
+				"text":         {"disable-user @tsmith"},
+				"user_id":      {"U1234"},
+				"user_name":    {"requesting_user"},
+			}
+			reqBody := strings.NewReader(v.Encode())
+			r, err := http.NewRequest("POST", "http://localhost", reqBody)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
+
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:          "fake-slack-auth-token",
+				SlackSlashCommand:       "/slack-slash-command",
+				SlackTeamName:           "slack-team-name",
+				SlackUserID:             "slack-user-id",
+				SkipRequestVerification: true,
+				Policy:                  config.Policy{"disable-user": config.PolicyRule{}},
+			})
+
+			fakeSlackAPI := newFakeSlackAPI()
+			fakeStore := &auditfakes.FakeStore{}
+			fakeStore.RecordReturns(errors.New("disk full"))
+
+			w := httptest.NewRecorder()
+			h := handler.New(c, fakeSlackAPI, fakeStore, fakeClock, lager.NewLogger("fakelogger"))
+			h.ServeHTTP(w, r)
+
+			Ω(w.Code).Should(Equal(http.StatusOK))
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+			Ω(fakeStore.RecordCallCount()).Should(Equal(1))
+
+			event := fakeStore.RecordArgsForCall(0)
+			Ω(event.Action).Should(Equal("disable-user"))
+			Ω(event.Target).Should(Equal("@tsmith"))
+			Ω(event.Outcome).Should(Equal(audit.OutcomeFailed))
+			Ω(event.Error).Should(Equal("You are not permitted to use `/slack-slash-command disable-user`."))
+		})
+
+		It("marks the event for a dry run as one", func() {
+			v := url.Values{
+				"token":        {"some-token"},
+				"channel_id":   {"C1234567890"},
+				"channel_name": {"channel-name"},
+				"command":      {"/slack-slash-command"},
+				"text":         {"disable-user @tsmith --dry-run"},
+				"user_id":      {"U1234"},
+				"user_name":    {"requesting_user"},
+			}
+			reqBody := strings.NewReader(v.Encode())
+			r, err := http.NewRequest("POST", "http://localhost", reqBody)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
+
+			fakeSlackAPI := newFakeSlackAPI()
+			stubUsers(fakeSlackAPI,
+				slack.User{ID: "U1234", IsAdmin: true},
+				slack.User{ID: "U5678", Name: "tsmith", IsRestricted: true},
+			)
+			fakeStore := &auditfakes.FakeStore{}
+
+			w := httptest.NewRecorder()
+			h := handler.New(c, fakeSlackAPI, fakeStore, fakeClock, lager.NewLogger("fakelogger"))
+			h.ServeHTTP(w, r)
+
+			Ω(w.Code).Should(Equal(http.StatusOK))
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+			Ω(fakeStore.RecordCallCount()).Should(Equal(1))
+
+			event := fakeStore.RecordArgsForCall(0)
+			Ω(event.Action).Should(Equal("disable-user"))
+			Ω(event.Target).Should(Equal("U5678"))
+			Ω(event.Message).Should(Equal("@requesting_user disabled user @tsmith (dry run)"))
+			Ω(event.Outcome).Should(Equal(audit.OutcomeSucceeded))
+			Ω(event.DryRun).Should(BeTrue())
+			Ω(event.TargetRole).Should(Equal(audit.RoleRestricted))
+		})
+	})
+
+	Describe("Interactions", func() {
+		var (
+			fakeSlackAPI *slackapifakes.FakeSlackAPI
+			request      accessrequest.Request
+			payload      map[string]interface{}
+		)
+
+		BeforeEach(func() {
+			fakeSlackAPI = newFakeSlackAPI()
+			fakeSlackAPI.GetUserInfoReturns(&slack.User{}, nil)
+			fakeSlackAPI.GetConversationMembersReturns([]string{"U0000000002"}, nil)
+
+			var err error
+			request, err = registeredAccessRequests.Add(accessrequest.Request{
+				RequesterID:   "U0000000001",
+				RequesterName: "requester",
+				ChannelID:     "C1234567890",
+				ChannelName:   "channel-name",
+				Status:        accessrequest.StatusPending,
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			payload = map[string]interface{}{
+				"type":    "block_actions",
+				"token":   "some-token",
+				"user":    map[string]string{"id": "U0000000002", "username": "approver"},
+				"channel": map[string]string{"id": "C1234567890", "name": "channel-name"},
+				"actions": []map[string]string{
+					{"action_id": "approve-access-request", "value": fmt.Sprintf("%d", request.ID)},
+				},
+			}
+		})
+
+		newInteraction := func() *http.Request {
+			encoded, err := json.Marshal(payload)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			v := url.Values{"payload": {string(encoded)}}
+			r, err := http.NewRequest("POST", "http://localhost/interactions", strings.NewReader(v.Encode()))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			return r
+		}
+
+		It("runs the command named by the button that was clicked", func() {
+			w := httptest.NewRecorder()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+			h.Interactions().ServeHTTP(w, newInteraction())
+
+			Ω(w.Code).Should(Equal(http.StatusOK))
+			Ω(responseText(w)).Should(Equal("Approved @requester's request for access to #channel-name; they have been invited."))
+
+			Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(1))
+			channelID, userID := fakeSlackAPI.InviteToConversationArgsForCall(0)
+			Ω(channelID).Should(Equal("C1234567890"))
+			Ω(userID).Should(Equal("U0000000001"))
+
+			decided, _, err := registeredAccessRequests.Get(request.ID)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(decided.Status).Should(Equal(accessrequest.StatusApproved))
+			Ω(decided.DeciderName).Should(Equal("approver"))
+		})
+
+		It("records the command in the audit store as done by whoever clicked", func() {
+			fakeStore := &auditfakes.FakeStore{}
+
+			w := httptest.NewRecorder()
+			h := handler.New(c, fakeSlackAPI, fakeStore, fakeClock, lager.NewLogger("fakelogger"))
+			h.Interactions().ServeHTTP(w, newInteraction())
+
+			Ω(fakeStore.RecordCallCount()).Should(Equal(1))
+
+			event := fakeStore.RecordArgsForCall(0)
+			Ω(event.Actor).Should(Equal("approver"))
+			Ω(event.ActorID).Should(Equal("U0000000002"))
+			Ω(event.Action).Should(Equal("approve-access-request"))
+			Ω(event.Target).Should(Equal("U0000000001"))
+			Ω(event.Message).Should(Equal("@approver approved @requester's request for access to #channel-name"))
+		})
+
+		It("posts the result to the response_url when given one", func() {
+			posted := make(chan slackapi.Message, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var message slackapi.Message
+				json.NewDecoder(r.Body).Decode(&message)
+				posted <- message
+			}))
+			defer server.Close()
+
+			payload["response_url"] = server.URL
+
+			w := httptest.NewRecorder()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+			h.Interactions().ServeHTTP(w, newInteraction())
+			defer h.Shutdown()
+
+			Ω(w.Code).Should(Equal(http.StatusOK))
+			Ω(w.Body.Len()).Should(Equal(0))
+
+			var message slackapi.Message
+			Eventually(posted).Should(Receive(&message))
+			Ω(message.String()).Should(Equal("Approved @requester's request for access to #channel-name; they have been invited."))
+		})
+
+		It("returns 400 when the payload cannot be decoded", func() {
+			v := url.Values{"payload": {"not json"}}
+			r, err := http.NewRequest("POST", "http://localhost/interactions", strings.NewReader(v.Encode()))
+			Ω(err).ShouldNot(HaveOccurred())
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			w := httptest.NewRecorder()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+			h.Interactions().ServeHTTP(w, r)
+
+			Ω(w.Code).Should(Equal(http.StatusBadRequest))
+		})
+
+		It("returns 400 when no button was clicked", func() {
+			payload["actions"] = []map[string]string{}
+
+			w := httptest.NewRecorder()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+			h.Interactions().ServeHTTP(w, newInteraction())
+
+			Ω(w.Code).Should(Equal(http.StatusBadRequest))
+			Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(0))
+		})
+
+		Describe("with a verification token", func() {
+			BeforeEach(func() {
+				c = config.NewLocalConfig(config.LocalConfigOptions{
+					SlackAuthToken:         "fake-slack-auth-token",
+					SlackSlashCommand:      "/slack-slash-command",
+					SlackTeamName:          "slack-team-name",
+					SlackUserID:            "slack-user-id",
+					SlackVerificationToken: "some-token",
+				})
+			})
+
+			It("checks the token within the payload", func() {
+				w := httptest.NewRecorder()
+				h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+				h.Interactions().ServeHTTP(w, newInteraction())
+
+				Ω(w.Code).Should(Equal(http.StatusOK))
+			})
+
+			It("returns 401 when the token does not match", func() {
+				payload["token"] = "other-token"
+
+				w := httptest.NewRecorder()
+				h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
+				h.Interactions().ServeHTTP(w, newInteraction())
+
+				Ω(w.Code).Should(Equal(http.StatusUnauthorized))
+				Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(0))
+			})
+		})
+	})
+
 	Describe("help", func() {
 		It("responds to Slack with the help text", func() {
 			v := url.Values{
@@ -638,11 +1469,18 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			fakeSlackAPI := newFakeSlackAPI()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
-			Ω(w.Body.String()).ShouldNot(BeEmpty())
+			Ω(w.Header().Get("Content-Type")).Should(Equal("application/json"))
+
+			var message slackapi.Message
+			Ω(json.Unmarshal(w.Body.Bytes(), &message)).Should(Succeed())
+			Ω(message.ResponseType).Should(Equal(slackapi.ResponseTypeEphemeral))
+			Ω(message.Text).Should(ContainSubstring("`/slack-slash-command [command] [args]`"))
+			Ω(message.Text).Should(ContainSubstring("_List the groups that @slack-user-id is in_"))
+			Ω(message.Blocks).ShouldNot(BeEmpty())
 		})
 	})
 
@@ -663,11 +1501,11 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			fakeSlackAPI := newFakeSlackAPI()
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
-			Ω(fakeSlackAPI.GetUsersCallCount()).Should(Equal(1))
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(1))
 		})
 
 		It("responds to Slack with a message about an unknown user", func() {
@@ -686,12 +1524,12 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			fakeSlackAPI.GetUsersReturns([]slack.User{}, nil)
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			fakeSlackAPI := newFakeSlackAPI()
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{}, "", nil)
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
-			Ω(w.Body.String()).Should(Equal("There is no user here with the email address 'user@example.com'. You can invite them to Slack as a guest or a restricted account. Type `/slack-slash-command help` for more information."))
+			Ω(responseText(w)).Should(Equal("There is no user here with the email address 'user@example.com'. You can invite them to Slack as a guest or a restricted account. Type `/slack-slash-command help` for more information."))
 		})
 
 		It("responds to Slack with a message about an unknown user with an uninvitable domain", func() {
@@ -710,12 +1548,12 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			fakeSlackAPI.GetUsersReturns([]slack.User{}, nil)
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			fakeSlackAPI := newFakeSlackAPI()
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{}, "", nil)
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
-			Ω(w.Body.String()).Should(Equal("There is no user here with the email address 'user@uninvitable-domain.com'. uninvitable-domain-message"))
+			Ω(responseText(w)).Should(Equal("There is no user here with the email address 'user@uninvitable-domain.com'. uninvitable-domain-message"))
 		})
 
 		It("responds to Slack with a message about a full member", func() {
@@ -734,8 +1572,8 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			fakeSlackAPI := newFakeSlackAPI()
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{
 				{
 					Name: "tsmith",
 					Profile: slack.UserProfile{
@@ -746,11 +1584,11 @@ var _ = Describe("Handler", func() {
 					IsRestricted:      false,
 					IsUltraRestricted: false,
 				},
-			}, nil)
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			}, "", nil)
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
-			Ω(w.Body.String()).Should(Equal("Tom Smith (user@example.com) is a Slack full member, with the username <@tsmith>."))
+			Ω(responseText(w)).Should(Equal("Tom Smith (user@example.com) is a Slack full member, with the username <@tsmith>."))
 		})
 
 		It("responds to Slack with a message about a restricted account", func() {
@@ -769,8 +1607,8 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			fakeSlackAPI := newFakeSlackAPI()
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{
 				{
 					Name: "tsmith",
 					Profile: slack.UserProfile{
@@ -781,11 +1619,11 @@ var _ = Describe("Handler", func() {
 					IsRestricted:      true,
 					IsUltraRestricted: false,
 				},
-			}, nil)
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			}, "", nil)
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
-			Ω(w.Body.String()).Should(Equal("Tom Smith (user@example.com) is a Slack restricted account, with the username <@tsmith>."))
+			Ω(responseText(w)).Should(Equal("Tom Smith (user@example.com) is a Slack restricted account, with the username <@tsmith>."))
 		})
 
 		It("responds to Slack with a message about a single-channel guest", func() {
@@ -804,8 +1642,8 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			fakeSlackAPI := newFakeSlackAPI()
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{
 				{
 					Name: "tsmith",
 					Profile: slack.UserProfile{
@@ -816,11 +1654,11 @@ var _ = Describe("Handler", func() {
 					IsRestricted:      false,
 					IsUltraRestricted: true,
 				},
-			}, nil)
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			}, "", nil)
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
-			Ω(w.Body.String()).Should(Equal("Tom Smith (user@example.com) is a Slack single-channel guest, with the username <@tsmith>."))
+			Ω(responseText(w)).Should(Equal("Tom Smith (user@example.com) is a Slack single-channel guest, with the username <@tsmith>."))
 		})
 
 		It("responds to Slack when it can't get the list of users", func() {
@@ -839,12 +1677,12 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			fakeSlackAPI.GetUsersReturns([]slack.User{}, errors.New("network error"))
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			fakeSlackAPI := newFakeSlackAPI()
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{}, "", errors.New("network error"))
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
-			Ω(w.Body.String()).Should(Equal("Failed to look up user@example.com: network error"))
+			Ω(responseText(w)).Should(Equal("Failed to look up user@example.com: network error"))
 		})
 
 		It("posts a message to the configured audit log channel on success", func() {
@@ -863,8 +1701,8 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			fakeSlackAPI.GetUsersReturns([]slack.User{
+			fakeSlackAPI := newFakeSlackAPI()
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{
 				{
 					Name: "tsmith",
 					Profile: slack.UserProfile{
@@ -875,17 +1713,18 @@ var _ = Describe("Handler", func() {
 					IsRestricted:      true,
 					IsUltraRestricted: true,
 				},
-			}, nil)
-			c = config.NewLocalConfig(
-				"fake-slack-auth-token",
-				"/slack-slash-command",
-				"slack-team-name",
-				"slack-user-id",
-				"audit-log-channel-id",
-				"uninvitable-domain.com",
-				"uninvitable-domain-message",
-			)
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			}, "", nil)
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:          "fake-slack-auth-token",
+				SlackSlashCommand:       "/slack-slash-command",
+				SlackTeamName:           "slack-team-name",
+				SlackUserID:             "slack-user-id",
+				AuditLogChannelID:       "audit-log-channel-id",
+				UninvitableDomain:       "uninvitable-domain.com",
+				UninvitableMessage:      "uninvitable-domain-message",
+				SkipRequestVerification: true,
+			})
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(1))
@@ -916,18 +1755,19 @@ var _ = Describe("Handler", func() {
 			r.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 
 			w := httptest.NewRecorder()
-			fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
-			fakeSlackAPI.GetUsersReturns([]slack.User{}, errors.New("network error"))
-			c = config.NewLocalConfig(
-				"fake-slack-auth-token",
-				"/slack-slash-command",
-				"slack-team-name",
-				"slack-user-id",
-				"audit-log-channel-id",
-				"uninvitable-domain.com",
-				"uninvitable-domain-message",
-			)
-			h := handler.New(c, fakeSlackAPI, fakeClock, lager.NewLogger("fakelogger"))
+			fakeSlackAPI := newFakeSlackAPI()
+			fakeSlackAPI.GetUsersPageReturns([]slack.User{}, "", errors.New("network error"))
+			c = config.NewLocalConfig(config.LocalConfigOptions{
+				SlackAuthToken:          "fake-slack-auth-token",
+				SlackSlashCommand:       "/slack-slash-command",
+				SlackTeamName:           "slack-team-name",
+				SlackUserID:             "slack-user-id",
+				AuditLogChannelID:       "audit-log-channel-id",
+				UninvitableDomain:       "uninvitable-domain.com",
+				UninvitableMessage:      "uninvitable-domain-message",
+				SkipRequestVerification: true,
+			})
+			h := handler.New(c, fakeSlackAPI, nil, fakeClock, lager.NewLogger("fakelogger"))
 			h.ServeHTTP(w, r)
 
 			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(1))
@@ -944,10 +1784,42 @@ var _ = Describe("Handler", func() {
 	})
 })
 
-func newGroup(name, id string) slack.Group {
-	group := slack.Group{}
-	group.Name = name
-	group.ID = id
+// newFakeSlackAPI returns a FakeSlackAPI for which the configured user is a
+// member of every channel.
+func newFakeSlackAPI() *slackapifakes.FakeSlackAPI {
+	fakeSlackAPI := &slackapifakes.FakeSlackAPI{}
+	fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{IsMember: true}, nil)
+
+	return fakeSlackAPI
+}
+
+// stubUsers makes fakeSlackAPI list the given users, and look each of them up
+// by ID as Slack would.
+func stubUsers(fakeSlackAPI *slackapifakes.FakeSlackAPI, users ...slack.User) {
+	fakeSlackAPI.GetUsersPageReturns(users, "", nil)
+	fakeSlackAPI.GetUserInfoStub = func(userID string) (*slack.User, error) {
+		for _, user := range users {
+			if user.ID == userID {
+				return &user, nil
+			}
+		}
+
+		return &slack.User{ID: userID}, nil
+	}
+}
+
+func sign(signingSecret string, timestamp string, body string) string {
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func responseText(w *httptest.ResponseRecorder) string {
+	Ω(w.Header().Get("Content-Type")).Should(Equal("application/json"))
+
+	var message slackapi.Message
+	Ω(json.Unmarshal(w.Body.Bytes(), &message)).Should(Succeed())
 
-	return group
+	return message.String()
 }
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+)
+
+const (
+	signatureHeader = "X-Slack-Signature"
+	timestampHeader = "X-Slack-Request-Timestamp"
+
+	signatureVersion = "v0"
+
+	// maxRequestAge is how far a request's timestamp may be from the current
+	// time before it is considered a replay.
+	maxRequestAge = 5 * time.Minute
+)
+
+var (
+	errMissingSignature     = errors.New("request is missing a signature or timestamp")
+	errInvalidTimestamp     = errors.New("request timestamp is invalid")
+	errStaleTimestamp       = errors.New("request timestamp is too old")
+	errSignatureMismatch    = errors.New("request signature does not match")
+	errVerificationMismatch = errors.New("request verification token does not match")
+	errNotConfigured        = errors.New("neither a signing secret nor a verification token is configured")
+)
+
+// verifier checks that a request was sent by Slack. When a signing secret is
+// configured the request's v0 signature is checked; otherwise, when a
+// verification token is configured, the request's token field is checked.
+// With neither configured, every request is refused unless verification is
+// explicitly skipped.
+type verifier struct {
+	signingSecret     string
+	verificationToken string
+	skip              bool
+	clock             clock.Clock
+}
+
+func (v verifier) verify(header http.Header, body []byte) error {
+	if v.signingSecret != "" {
+		return v.verifySignature(header, body)
+	}
+
+	if v.verificationToken != "" {
+		return v.verifyToken(body)
+	}
+
+	if v.skip {
+		return nil
+	}
+
+	return errNotConfigured
+}
+
+func (v verifier) verifySignature(header http.Header, body []byte) error {
+	signature := header.Get(signatureHeader)
+	timestamp := header.Get(timestampHeader)
+
+	if signature == "" || timestamp == "" {
+		return errMissingSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errInvalidTimestamp
+	}
+
+	age := v.clock.Now().Sub(time.Unix(seconds, 0))
+	if age > maxRequestAge || age < -maxRequestAge {
+		return errStaleTimestamp
+	}
+
+	expected := sign(v.signingSecret, timestamp, body)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return errSignatureMismatch
+	}
+
+	return nil
+}
+
+func (v verifier) verifyToken(body []byte) error {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return errVerificationMismatch
+	}
+
+	token := values.Get("token")
+
+	// Interactivity requests carry their token within a JSON payload.
+	if payload := values.Get("payload"); payload != "" {
+		var fields struct {
+			Token string `json:"token"`
+		}
+		if err = json.Unmarshal([]byte(payload), &fields); err != nil {
+			return errVerificationMismatch
+		}
+		token = fields.Token
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(v.verificationToken)) != 1 {
+		return errVerificationMismatch
+	}
+
+	return nil
+}
+
+// sign returns the v0 signature Slack sends in the X-Slack-Signature header
+// for the given timestamp and body.
+func sign(signingSecret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	fmt.Fprintf(mac, "%s:%s:", signatureVersion, timestamp)
+	mac.Write(body)
+
+	return fmt.Sprintf("%s=%s", signatureVersion, hex.EncodeToString(mac.Sum(nil)))
+}
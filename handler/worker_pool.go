@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pivotal-golang/lager"
+)
+
+// workerPool runs jobs on a fixed number of goroutines, holding a bounded
+// number of jobs waiting for a free worker. A job which panics is logged, and
+// its worker goes on to the next job.
+type workerPool struct {
+	workers int
+	jobs    chan func()
+	logger  lager.Logger
+
+	startOnce sync.Once
+	inFlight  sync.WaitGroup
+
+	mutex   sync.Mutex
+	stopped bool
+}
+
+func newWorkerPool(workers int, queueSize int, logger lager.Logger) *workerPool {
+	return &workerPool{
+		workers: workers,
+		jobs:    make(chan func(), queueSize),
+		logger:  logger,
+	}
+}
+
+// submit queues the job to be run. It returns false if the pool has been
+// stopped or its queue is full.
+func (p *workerPool) submit(job func()) bool {
+	p.startOnce.Do(p.start)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.stopped {
+		return false
+	}
+
+	p.inFlight.Add(1)
+	select {
+	case p.jobs <- job:
+		return true
+	default:
+		p.inFlight.Done()
+		return false
+	}
+}
+
+// stop prevents further jobs from being submitted and waits for the queued
+// and running jobs to finish.
+func (p *workerPool) stop() {
+	p.mutex.Lock()
+	if !p.stopped {
+		p.stopped = true
+		close(p.jobs)
+	}
+	p.mutex.Unlock()
+
+	p.inFlight.Wait()
+}
+
+func (p *workerPool) start() {
+	for i := 0; i < p.workers; i++ {
+		go func() {
+			for job := range p.jobs {
+				p.run(job)
+			}
+		}()
+	}
+}
+
+func (p *workerPool) run(job func()) {
+	defer p.inFlight.Done()
+	defer func() {
+		if r := recover(); r != nil {
+			p.logger.Error("job-panicked", fmt.Errorf("%v", r))
+		}
+	}()
+
+	job()
+}
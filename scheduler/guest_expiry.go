@@ -0,0 +1,235 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/audit"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/expiry"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/slack"
+)
+
+const (
+	// automaticActor is who audit events are recorded as being by when
+	// nobody ran a command to cause them.
+	automaticActor = "goulash"
+
+	// guestExpiryWarningPeriod is how long before an account expires that
+	// the person and their inviter are warned.
+	guestExpiryWarningPeriod = 3 * 24 * time.Hour
+
+	expiryWarningAction = "expiry-warning"
+	expiryDateFormat    = "Monday, January 2, 2006"
+)
+
+// Recorder records an audit event, such as Handler.RecordAuditEvent does.
+type Recorder func(audit.Event)
+
+type guestExpiry struct {
+	config config.Config
+	api    slackapi.SlackAPI
+	store  expiry.Store
+	clock  clock.Clock
+	record Recorder
+}
+
+// NewGuestExpiry returns a Job which disables the accounts in the given Store
+// once they expire, and warns them and their inviters beforehand. Audit events
+// for what it does are given to record.
+func NewGuestExpiry(
+	config config.Config,
+	api slackapi.SlackAPI,
+	store expiry.Store,
+	clock clock.Clock,
+	record Recorder,
+) Job {
+	return &guestExpiry{
+		config: config,
+		api:    api,
+		store:  store,
+		clock:  clock,
+		record: record,
+	}
+}
+
+func (g guestExpiry) Name() string {
+	return "guest-expiry"
+}
+
+func (g guestExpiry) Run(logger lager.Logger) {
+	expirations, err := g.store.All()
+	if err != nil {
+		logger.Error("failed-to-list-expirations", err)
+		return
+	}
+
+	now := g.clock.Now()
+	for _, expiration := range expirations {
+		switch {
+		case !now.Before(expiration.ExpiresAt):
+			g.disable(expiration, logger)
+		case !expiration.Warned && expiration.ExpiresAt.Sub(now) <= guestExpiryWarningPeriod:
+			g.warn(expiration, logger)
+		}
+	}
+}
+
+// disable disables the expired account, forgetting the expiration unless it
+// failed in a way that may succeed when next tried.
+func (g guestExpiry) disable(expiration expiry.Expiration, logger lager.Logger) {
+	logger = logger.Session("disable", lager.Data{"emailAddress": expiration.EmailAddress})
+
+	disableUser := action.NewDisableUser([]string{expiration.EmailAddress}, automaticActor)
+	_, disableErr := disableUser.Do(g.config, g.api, g.clock, logger)
+
+	g.record(withRole(g.event(
+		expiration,
+		"disable-user",
+		fmt.Sprintf("%s as their account expired", disableUser.(action.AuditableAction).AuditMessage(g.api)),
+		disableErr,
+	), disableUser, g.api))
+
+	if disableErr != nil && !permanentDisableErr(disableErr) {
+		logger.Error("failed", disableErr)
+		return
+	}
+
+	if err := g.store.Remove(expiration.EmailAddress); err != nil {
+		logger.Error("failed-to-remove-expiration", err)
+		return
+	}
+
+	if disableErr == nil {
+		g.sendDirectMessage("@"+expiration.InviterName, fmt.Sprintf(
+			"The account of %s, whom you invited to '%s', has expired and been disabled.",
+			invitee(expiration),
+			expiration.ChannelName,
+		), logger)
+	}
+
+	logger.Info("succeeded")
+}
+
+// warn tells the person and their inviter that the account is about to
+// expire. It does so only once, even if either message cannot be sent.
+func (g guestExpiry) warn(expiration expiry.Expiration, logger lager.Logger) {
+	logger = logger.Session("warn", lager.Data{"emailAddress": expiration.EmailAddress})
+
+	expiresOn := expiration.ExpiresAt.UTC().Format(expiryDateFormat)
+
+	g.sendDirectMessage(expiration.EmailAddress, fmt.Sprintf(
+		"Your account will be disabled on %s. If you still need it, please ask @%s, who invited you.",
+		expiresOn,
+		expiration.InviterName,
+	), logger)
+
+	g.sendDirectMessage("@"+expiration.InviterName, fmt.Sprintf(
+		"The account of %s, whom you invited to '%s', will be disabled on %s.",
+		invitee(expiration),
+		expiration.ChannelName,
+		expiresOn,
+	), logger)
+
+	expiration.Warned = true
+	err := g.store.Put(expiration)
+
+	g.record(g.event(
+		expiration,
+		expiryWarningAction,
+		fmt.Sprintf(
+			"@%s warned %s and @%s that the account expires on %s",
+			automaticActor,
+			expiration.EmailAddress,
+			expiration.InviterName,
+			expiresOn,
+		),
+		err,
+	))
+
+	if err != nil {
+		logger.Error("failed-to-record-warning", err)
+		return
+	}
+
+	logger.Info("succeeded")
+}
+
+func (g guestExpiry) sendDirectMessage(searchVal string, text string, logger lager.Logger) {
+	user, err := action.FindUser(searchVal, g.api, false)
+	if err != nil {
+		logger.Error("failed-to-find-user", err, lager.Data{"user": searchVal})
+		return
+	}
+
+	_, _, dmID, err := g.api.OpenIMChannel(user.ID)
+	if err != nil {
+		logger.Error("failed-to-open-direct-message", err, lager.Data{"user": searchVal})
+		return
+	}
+
+	postMessageParams := slack.NewPostMessageParameters()
+	postMessageParams.AsUser = true
+
+	if _, _, err = g.api.PostMessage(dmID, text, postMessageParams); err != nil {
+		logger.Error("failed-to-send-direct-message", err, lager.Data{"user": searchVal})
+	}
+}
+
+func (g guestExpiry) event(
+	expiration expiry.Expiration,
+	actionName string,
+	message string,
+	err error,
+) audit.Event {
+	event := audit.Event{
+		Time:        g.clock.Now().UTC(),
+		Actor:       automaticActor,
+		ActorID:     g.config.SlackUserID(),
+		Action:      actionName,
+		Target:      expiration.EmailAddress,
+		ChannelID:   expiration.ChannelID,
+		ChannelName: expiration.ChannelName,
+		Message:     message,
+		Outcome:     audit.OutcomeSucceeded,
+	}
+
+	if err != nil {
+		event.Outcome = audit.OutcomeFailed
+		event.Error = err.Error()
+	}
+
+	return event
+}
+
+// withRole returns the event with the role that the user disabled by the
+// disable user action had, so that enable-user can restore it.
+func withRole(event audit.Event, disableUser action.Action, api slackapi.SlackAPI) audit.Event {
+	for _, entry := range disableUser.(action.MultiAuditableAction).AuditEntries(api) {
+		event.TargetRole = entry.TargetRole
+		event.TargetChannelID = entry.TargetChannelID
+	}
+
+	return event
+}
+
+// permanentDisableErr returns true if disabling the account failed in a way
+// that trying again will not fix, as there is no such user or they are a full
+// member.
+func permanentDisableErr(err error) bool {
+	return action.IsUserNotFoundErr(err) ||
+		err == action.NewFullUserCannotBeErr("disabled")
+}
+
+func invitee(expiration expiry.Expiration) string {
+	return fmt.Sprintf(
+		"%s %s (%s)",
+		expiration.FirstName,
+		expiration.LastName,
+		expiration.EmailAddress,
+	)
+}
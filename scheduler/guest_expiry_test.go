@@ -0,0 +1,269 @@
+package scheduler_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/audit"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/expiry"
+	"github.com/pivotalservices/goulash/expiry/expiryfakes"
+	"github.com/pivotalservices/goulash/scheduler"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GuestExpiry", func() {
+	var (
+		job          scheduler.Job
+		c            config.Config
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeStore    *expiryfakes.FakeStore
+		fakeClock    *fakeclock.FakeClock
+		logger       lager.Logger
+		events       []audit.Event
+		expiration   expiry.Expiration
+	)
+
+	BeforeEach(func() {
+		c = config.NewLocalConfig(config.LocalConfigOptions{
+			SlackAuthToken:    "slack-auth-token",
+			SlackSlashCommand: "/slack-slash-command",
+			SlackTeamName:     "slack-team-name",
+			SlackUserID:       "slack-user-id",
+			AuditLogChannelID: "audit-log-channel-id",
+			GuestExpiryPath:   "guest-expiry-path",
+		})
+
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		users := []slack.User{
+			{
+				ID:           "guest-id",
+				Name:         "guest",
+				IsRestricted: true,
+				Profile:      slack.UserProfile{Email: "guest@example.com"},
+			},
+			{
+				ID:      "inviter-id",
+				Name:    "inviter",
+				Profile: slack.UserProfile{Email: "inviter@example.com"},
+			},
+		}
+		fakeSlackAPI.GetUsersPageReturns(users, "", nil)
+		fakeSlackAPI.GetUserInfoStub = func(userID string) (*slack.User, error) {
+			for _, user := range users {
+				if user.ID == userID {
+					return &user, nil
+				}
+			}
+
+			return nil, errors.New("user_not_found")
+		}
+		fakeSlackAPI.OpenIMChannelStub = func(userID string) (bool, bool, string, error) {
+			return false, false, "dm-" + userID, nil
+		}
+
+		fakeClock = fakeclock.NewFakeClock(time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC))
+		fakeStore = &expiryfakes.FakeStore{}
+		logger = lager.NewLogger("testlogger")
+
+		events = nil
+		record := func(event audit.Event) {
+			events = append(events, event)
+		}
+
+		expiration = expiry.Expiration{
+			EmailAddress: "guest@example.com",
+			FirstName:    "Tom",
+			LastName:     "Smith",
+			InviterName:  "inviter",
+			ChannelID:    "channel-id",
+			ChannelName:  "channel-name",
+		}
+
+		job = scheduler.NewGuestExpiry(c, fakeSlackAPI, fakeStore, fakeClock, record)
+	})
+
+	Describe("Run", func() {
+		It("does nothing when the expirations cannot be listed", func() {
+			fakeStore.AllReturns(nil, errors.New("all-err"))
+
+			job.Run(logger)
+
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+			Ω(events).Should(BeEmpty())
+		})
+
+		It("does nothing for accounts which expire after the warning period", func() {
+			expiration.ExpiresAt = fakeClock.Now().Add(4 * 24 * time.Hour)
+			fakeStore.AllReturns([]expiry.Expiration{expiration}, nil)
+
+			job.Run(logger)
+
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+			Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(0))
+			Ω(fakeStore.PutCallCount()).Should(Equal(0))
+			Ω(events).Should(BeEmpty())
+		})
+
+		Context("when an account expires within the warning period", func() {
+			BeforeEach(func() {
+				expiration.ExpiresAt = fakeClock.Now().Add(2 * 24 * time.Hour)
+				fakeStore.AllReturns([]expiry.Expiration{expiration}, nil)
+			})
+
+			It("warns the person and their inviter", func() {
+				job.Run(logger)
+
+				Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(2))
+
+				channelID, text, params := fakeSlackAPI.PostMessageArgsForCall(0)
+				Ω(channelID).Should(Equal("dm-guest-id"))
+				Ω(text).Should(Equal("Your account will be disabled on Tuesday, May 3, 2016. If you still need it, please ask @inviter, who invited you."))
+				Ω(params.AsUser).Should(BeTrue())
+
+				channelID, text, _ = fakeSlackAPI.PostMessageArgsForCall(1)
+				Ω(channelID).Should(Equal("dm-inviter-id"))
+				Ω(text).Should(Equal("The account of Tom Smith (guest@example.com), whom you invited to 'channel-name', will be disabled on Tuesday, May 3, 2016."))
+
+				Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+			})
+
+			It("records that they were warned", func() {
+				job.Run(logger)
+
+				Ω(fakeStore.PutCallCount()).Should(Equal(1))
+
+				warned := expiration
+				warned.Warned = true
+				Ω(fakeStore.PutArgsForCall(0)).Should(Equal(warned))
+			})
+
+			It("records an audit event", func() {
+				job.Run(logger)
+
+				Ω(events).Should(Equal([]audit.Event{{
+					Time:        fakeClock.Now().UTC(),
+					Actor:       "goulash",
+					ActorID:     "slack-user-id",
+					Action:      "expiry-warning",
+					Target:      "guest@example.com",
+					ChannelID:   "channel-id",
+					ChannelName: "channel-name",
+					Message:     "@goulash warned guest@example.com and @inviter that the account expires on Tuesday, May 3, 2016",
+					Outcome:     audit.OutcomeSucceeded,
+				}}))
+			})
+
+			It("still records that they were warned when a message cannot be sent", func() {
+				fakeSlackAPI.PostMessageReturns("", "", errors.New("post-message-err"))
+
+				job.Run(logger)
+
+				Ω(fakeStore.PutCallCount()).Should(Equal(1))
+				Ω(fakeStore.PutArgsForCall(0).Warned).Should(BeTrue())
+			})
+
+			It("does not warn them again", func() {
+				expiration.Warned = true
+				fakeStore.AllReturns([]expiry.Expiration{expiration}, nil)
+
+				job.Run(logger)
+
+				Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(0))
+				Ω(fakeStore.PutCallCount()).Should(Equal(0))
+			})
+		})
+
+		Context("when an account has expired", func() {
+			BeforeEach(func() {
+				expiration.ExpiresAt = fakeClock.Now().Add(-time.Minute)
+				expiration.Warned = true
+				fakeStore.AllReturns([]expiry.Expiration{expiration}, nil)
+			})
+
+			It("disables the account", func() {
+				job.Run(logger)
+
+				Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(1))
+
+				teamName, userID := fakeSlackAPI.DisableUserArgsForCall(0)
+				Ω(teamName).Should(Equal("slack-team-name"))
+				Ω(userID).Should(Equal("guest-id"))
+			})
+
+			It("forgets the expiration", func() {
+				job.Run(logger)
+
+				Ω(fakeStore.RemoveCallCount()).Should(Equal(1))
+				Ω(fakeStore.RemoveArgsForCall(0)).Should(Equal("guest@example.com"))
+			})
+
+			It("tells the inviter", func() {
+				job.Run(logger)
+
+				Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(1))
+
+				channelID, text, _ := fakeSlackAPI.PostMessageArgsForCall(0)
+				Ω(channelID).Should(Equal("dm-inviter-id"))
+				Ω(text).Should(Equal("The account of Tom Smith (guest@example.com), whom you invited to 'channel-name', has expired and been disabled."))
+			})
+
+			It("records an audit event", func() {
+				job.Run(logger)
+
+				Ω(events).Should(Equal([]audit.Event{{
+					Time:        fakeClock.Now().UTC(),
+					Actor:       "goulash",
+					ActorID:     "slack-user-id",
+					Action:      "disable-user",
+					Target:      "guest@example.com",
+					ChannelID:   "channel-id",
+					ChannelName: "channel-name",
+					Message:     "@goulash disabled user guest@example.com as their account expired",
+					Outcome:     audit.OutcomeSucceeded,
+					TargetRole:  audit.RoleRestricted,
+				}}))
+			})
+
+			Context("when disabling the account fails", func() {
+				BeforeEach(func() {
+					fakeSlackAPI.DisableUserReturns(errors.New("disable-user-err"))
+				})
+
+				It("keeps the expiration so that it is tried again", func() {
+					job.Run(logger)
+
+					Ω(fakeStore.RemoveCallCount()).Should(Equal(0))
+					Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(0))
+				})
+
+				It("records a failed audit event", func() {
+					job.Run(logger)
+
+					Ω(events).Should(HaveLen(1))
+					Ω(events[0].Outcome).Should(Equal(audit.OutcomeFailed))
+					Ω(events[0].Error).Should(Equal("disable-user-err"))
+				})
+			})
+
+			It("forgets the expiration without telling the inviter when there is no such user", func() {
+				fakeSlackAPI.GetUsersPageReturns([]slack.User{}, "", nil)
+
+				job.Run(logger)
+
+				Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+				Ω(fakeStore.RemoveCallCount()).Should(Equal(1))
+				Ω(fakeSlackAPI.PostMessageCallCount()).Should(Equal(0))
+
+				Ω(events).Should(HaveLen(1))
+				Ω(events[0].Outcome).Should(Equal(audit.OutcomeFailed))
+			})
+		})
+	})
+})
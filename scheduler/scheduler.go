@@ -0,0 +1,87 @@
+// Package scheduler runs Jobs in the background at a regular interval.
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+)
+
+//go:generate counterfeiter . Job
+
+// Job is work which is done repeatedly by a Scheduler.
+type Job interface {
+	Name() string
+	Run(logger lager.Logger)
+}
+
+// Scheduler runs its Jobs once when started and then once every interval
+// until stopped.
+type Scheduler struct {
+	interval time.Duration
+	clock    clock.Clock
+	logger   lager.Logger
+	jobs     []Job
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New returns a new Scheduler for the given Jobs.
+func New(
+	interval time.Duration,
+	clock clock.Clock,
+	logger lager.Logger,
+	jobs ...Job,
+) *Scheduler {
+	return &Scheduler{
+		interval: interval,
+		clock:    clock,
+		logger:   logger.Session("scheduler"),
+		jobs:     jobs,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start runs the Jobs in the background.
+func (s *Scheduler) Start() {
+	go s.loop()
+}
+
+// Stop stops the Scheduler and waits for any Jobs which are running to
+// finish.
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+func (s *Scheduler) loop() {
+	defer close(s.done)
+
+	ticker := s.clock.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.runJobs()
+
+	for {
+		select {
+		case <-ticker.C():
+			s.runJobs()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *Scheduler) runJobs() {
+	for _, job := range s.jobs {
+		logger := s.logger.Session(job.Name())
+		logger.Info("starting")
+		job.Run(logger)
+		logger.Info("finished")
+	}
+}
@@ -0,0 +1,89 @@
+package scheduler_test
+
+import (
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/scheduler"
+	"github.com/pivotalservices/goulash/scheduler/schedulerfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scheduler", func() {
+	var (
+		s         *scheduler.Scheduler
+		fakeJob   *schedulerfakes.FakeJob
+		fakeClock *fakeclock.FakeClock
+	)
+
+	BeforeEach(func() {
+		fakeJob = &schedulerfakes.FakeJob{}
+		fakeJob.NameReturns("fake-job")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+
+		s = scheduler.New(time.Hour, fakeClock, lager.NewLogger("testlogger"), fakeJob)
+	})
+
+	It("runs its jobs as soon as it is started", func() {
+		s.Start()
+		defer s.Stop()
+
+		Eventually(fakeJob.RunCallCount).Should(Equal(1))
+	})
+
+	It("runs its jobs again every interval", func() {
+		s.Start()
+		defer s.Stop()
+
+		Eventually(fakeJob.RunCallCount).Should(Equal(1))
+
+		fakeClock.WaitForWatcherAndIncrement(time.Hour)
+		Eventually(fakeJob.RunCallCount).Should(Equal(2))
+
+		fakeClock.WaitForWatcherAndIncrement(time.Hour)
+		Eventually(fakeJob.RunCallCount).Should(Equal(3))
+	})
+
+	It("does not run its jobs before the interval has passed", func() {
+		s.Start()
+		defer s.Stop()
+
+		Eventually(fakeJob.RunCallCount).Should(Equal(1))
+
+		fakeClock.WaitForWatcherAndIncrement(59 * time.Minute)
+		Consistently(fakeJob.RunCallCount).Should(Equal(1))
+	})
+
+	It("does not run its jobs once stopped", func() {
+		s.Start()
+		Eventually(fakeJob.RunCallCount).Should(Equal(1))
+
+		s.Stop()
+
+		fakeClock.Increment(time.Hour)
+		Consistently(fakeJob.RunCallCount).Should(Equal(1))
+	})
+
+	It("waits for a running job to finish when stopped", func() {
+		release := make(chan struct{})
+		fakeJob.RunStub = func(lager.Logger) {
+			<-release
+		}
+
+		s.Start()
+		Eventually(fakeJob.RunCallCount).Should(Equal(1))
+
+		stopped := make(chan struct{})
+		go func() {
+			s.Stop()
+			close(stopped)
+		}()
+
+		Consistently(stopped).ShouldNot(BeClosed())
+		close(release)
+		Eventually(stopped).Should(BeClosed())
+	})
+})
@@ -0,0 +1,72 @@
+// This file was generated by counterfeiter
+package schedulerfakes
+
+import (
+	"sync"
+
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/scheduler"
+)
+
+type FakeJob struct {
+	NameStub        func() string
+	nameMutex       sync.RWMutex
+	nameArgsForCall []struct{}
+	nameReturns     struct {
+		result1 string
+	}
+	RunStub        func(logger lager.Logger)
+	runMutex       sync.RWMutex
+	runArgsForCall []struct {
+		logger lager.Logger
+	}
+}
+
+func (fake *FakeJob) Name() string {
+	fake.nameMutex.Lock()
+	fake.nameArgsForCall = append(fake.nameArgsForCall, struct{}{})
+	fake.nameMutex.Unlock()
+	if fake.NameStub != nil {
+		return fake.NameStub()
+	} else {
+		return fake.nameReturns.result1
+	}
+}
+
+func (fake *FakeJob) NameCallCount() int {
+	fake.nameMutex.RLock()
+	defer fake.nameMutex.RUnlock()
+	return len(fake.nameArgsForCall)
+}
+
+func (fake *FakeJob) NameReturns(result1 string) {
+	fake.NameStub = nil
+	fake.nameReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeJob) Run(logger lager.Logger) {
+	fake.runMutex.Lock()
+	fake.runArgsForCall = append(fake.runArgsForCall, struct {
+		logger lager.Logger
+	}{logger})
+	fake.runMutex.Unlock()
+	if fake.RunStub != nil {
+		fake.RunStub(logger)
+	}
+}
+
+func (fake *FakeJob) RunCallCount() int {
+	fake.runMutex.RLock()
+	defer fake.runMutex.RUnlock()
+	return len(fake.runArgsForCall)
+}
+
+func (fake *FakeJob) RunArgsForCall(i int) lager.Logger {
+	fake.runMutex.RLock()
+	defer fake.runMutex.RUnlock()
+	return fake.runArgsForCall[i].logger
+}
+
+var _ scheduler.Job = new(FakeJob)
@@ -0,0 +1,141 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/action"
+	"github.com/pivotalservices/goulash/audit"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/slackapi"
+)
+
+const (
+	// staleGuestReapInterval is how often stale guests are looked for, as
+	// doing so asks Slack about every guest.
+	staleGuestReapInterval = 24 * time.Hour
+
+	staleGuestAction = "stale-guest"
+)
+
+type staleGuestReaper struct {
+	config config.Config
+	api    slackapi.SlackAPI
+	clock  clock.Clock
+	record Recorder
+
+	lastRun time.Time
+}
+
+// NewStaleGuestReaper returns a Job which, once a day, looks for Restricted
+// Accounts and Single-Channel Guests who have not been active for the
+// configured time. It disables them, or in dry-run mode only flags them,
+// giving audit events for what it does to record.
+func NewStaleGuestReaper(
+	config config.Config,
+	api slackapi.SlackAPI,
+	clock clock.Clock,
+	record Recorder,
+) Job {
+	return &staleGuestReaper{
+		config: config,
+		api:    api,
+		clock:  clock,
+		record: record,
+	}
+}
+
+func (r *staleGuestReaper) Name() string {
+	return "stale-guest-reaper"
+}
+
+func (r *staleGuestReaper) Run(logger lager.Logger) {
+	now := r.clock.Now()
+	if !r.lastRun.IsZero() && now.Sub(r.lastRun) < staleGuestReapInterval {
+		return
+	}
+	r.lastRun = now
+
+	staleGuests, err := action.FindStaleGuests(r.api, r.config.StaleGuestInactivity(), now, logger)
+	if err != nil {
+		logger.Error("failed-to-find-stale-guests", err)
+		return
+	}
+
+	for _, staleGuest := range staleGuests {
+		if r.config.StaleGuestReaper() == config.StaleGuestReaperDisable {
+			r.disable(staleGuest, logger)
+		} else {
+			r.flag(staleGuest, logger)
+		}
+	}
+}
+
+func (r *staleGuestReaper) disable(staleGuest action.StaleGuest, logger lager.Logger) {
+	logger = logger.Session("disable", lager.Data{"user": staleGuest.SearchVal()})
+
+	disableUser := action.NewDisableUser([]string{staleGuest.SearchVal()}, automaticActor)
+	_, err := disableUser.Do(r.config, r.api, r.clock, logger)
+
+	r.record(withRole(r.event(
+		staleGuest,
+		"disable-user",
+		fmt.Sprintf(
+			"%s as %s",
+			disableUser.(action.AuditableAction).AuditMessage(r.api),
+			staleGuest.Inactivity(),
+		),
+		err,
+	), disableUser, r.api))
+
+	if err != nil {
+		logger.Error("failed", err)
+		return
+	}
+
+	logger.Info("succeeded")
+}
+
+func (r *staleGuestReaper) flag(staleGuest action.StaleGuest, logger lager.Logger) {
+	event := r.event(
+		staleGuest,
+		staleGuestAction,
+		fmt.Sprintf(
+			"@%s would have disabled user %s as %s (dry run)",
+			automaticActor,
+			staleGuest.SearchVal(),
+			staleGuest.Inactivity(),
+		),
+		nil,
+	)
+	event.DryRun = true
+	r.record(event)
+
+	logger.Info("flagged", lager.Data{"user": staleGuest.SearchVal()})
+}
+
+func (r *staleGuestReaper) event(
+	staleGuest action.StaleGuest,
+	actionName string,
+	message string,
+	err error,
+) audit.Event {
+	event := audit.Event{
+		Time:    r.clock.Now().UTC(),
+		Actor:   automaticActor,
+		ActorID: r.config.SlackUserID(),
+		Action:  actionName,
+		Target:  staleGuest.User.ID,
+		Message: message,
+		Outcome: audit.OutcomeSucceeded,
+	}
+
+	if err != nil {
+		event.Outcome = audit.OutcomeFailed
+		event.Error = err.Error()
+	}
+
+	return event
+}
@@ -0,0 +1,182 @@
+package scheduler_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/audit"
+	"github.com/pivotalservices/goulash/config"
+	"github.com/pivotalservices/goulash/scheduler"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StaleGuestReaper", func() {
+	var (
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeClock    *fakeclock.FakeClock
+		logger       lager.Logger
+		events       []audit.Event
+		record       scheduler.Recorder
+	)
+
+	newConfig := func(reaper string) config.Config {
+		return config.NewLocalConfig(config.LocalConfigOptions{
+			SlackAuthToken:       "slack-auth-token",
+			SlackSlashCommand:    "/slack-slash-command",
+			SlackTeamName:        "slack-team-name",
+			SlackUserID:          "slack-user-id",
+			AuditLogChannelID:    "audit-log-channel-id",
+			StaleGuestReaper:     reaper,
+			StaleGuestInactivity: 60 * 24 * time.Hour,
+		})
+	}
+
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		users := []slack.User{
+			{
+				ID:           "guest-id",
+				Name:         "guest",
+				IsRestricted: true,
+				Profile:      slack.UserProfile{Email: "guest@example.com"},
+			},
+			{
+				ID:      "member-id",
+				Name:    "member",
+				Profile: slack.UserProfile{Email: "member@example.com"},
+			},
+		}
+		fakeSlackAPI.GetUsersPageReturns(users, "", nil)
+		fakeSlackAPI.GetUserInfoStub = func(userID string) (*slack.User, error) {
+			for _, user := range users {
+				if user.ID == userID {
+					return &user, nil
+				}
+			}
+
+			return nil, errors.New("user_not_found")
+		}
+		fakeSlackAPI.GetLastActivitiesReturns(map[string]time.Time{
+			"guest-id":  time.Date(2016, 1, 15, 0, 0, 0, 0, time.UTC),
+			"member-id": time.Date(2016, 1, 15, 0, 0, 0, 0, time.UTC),
+		}, nil)
+
+		fakeClock = fakeclock.NewFakeClock(time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC))
+		logger = lager.NewLogger("testlogger")
+
+		events = nil
+		record = func(event audit.Event) {
+			events = append(events, event)
+		}
+	})
+
+	Context("in dry-run mode", func() {
+		var job scheduler.Job
+
+		BeforeEach(func() {
+			job = scheduler.NewStaleGuestReaper(newConfig(config.StaleGuestReaperDryRun), fakeSlackAPI, fakeClock, record)
+		})
+
+		It("flags stale guests without disabling them", func() {
+			job.Run(logger)
+
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+
+			Ω(events).Should(HaveLen(1))
+			Ω(events[0]).Should(Equal(audit.Event{
+				Time:    fakeClock.Now().UTC(),
+				Actor:   "goulash",
+				ActorID: "slack-user-id",
+				Action:  "stale-guest",
+				Target:  "guest-id",
+				Message: "@goulash would have disabled user guest@example.com as they have not been active since 2016-01-15 (dry run)",
+				Outcome: audit.OutcomeSucceeded,
+				DryRun:  true,
+			}))
+		})
+
+		It("flags stale guests with no recorded activity", func() {
+			fakeSlackAPI.GetLastActivitiesReturns(map[string]time.Time{}, nil)
+
+			job.Run(logger)
+
+			Ω(events).Should(HaveLen(1))
+			Ω(events[0].Message).Should(Equal("@goulash would have disabled user guest@example.com as no activity of theirs is recorded (dry run)"))
+			Ω(events[0].DryRun).Should(BeTrue())
+		})
+	})
+
+	Context("in disable mode", func() {
+		var job scheduler.Job
+
+		BeforeEach(func() {
+			job = scheduler.NewStaleGuestReaper(newConfig(config.StaleGuestReaperDisable), fakeSlackAPI, fakeClock, record)
+		})
+
+		It("disables stale guests and never full members", func() {
+			job.Run(logger)
+
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(1))
+			teamName, userID := fakeSlackAPI.DisableUserArgsForCall(0)
+			Ω(teamName).Should(Equal("slack-team-name"))
+			Ω(userID).Should(Equal("guest-id"))
+		})
+
+		It("records that they were disabled", func() {
+			job.Run(logger)
+
+			Ω(events).Should(HaveLen(1))
+			Ω(events[0].Action).Should(Equal("disable-user"))
+			Ω(events[0].Target).Should(Equal("guest-id"))
+			Ω(events[0].Message).Should(Equal("@goulash disabled user guest@example.com as they have not been active since 2016-01-15"))
+			Ω(events[0].Outcome).Should(Equal(audit.OutcomeSucceeded))
+		})
+
+		It("records a failure to disable them", func() {
+			fakeSlackAPI.DisableUserReturns(errors.New("disable-err"))
+
+			job.Run(logger)
+
+			Ω(events).Should(HaveLen(1))
+			Ω(events[0].Outcome).Should(Equal(audit.OutcomeFailed))
+			Ω(events[0].Error).Should(Equal("disable-err"))
+		})
+
+		It("does nothing when the access logs cannot be read", func() {
+			fakeSlackAPI.GetLastActivitiesReturns(nil, errors.New("paid_only"))
+
+			job.Run(logger)
+
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+			Ω(events).Should(BeEmpty())
+		})
+
+		It("does nothing when the users cannot be listed", func() {
+			fakeSlackAPI.GetUsersPageReturns(nil, "", errors.New("get-users-err"))
+
+			job.Run(logger)
+
+			Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+			Ω(events).Should(BeEmpty())
+		})
+
+		It("looks for stale guests only once a day", func() {
+			job.Run(logger)
+			Ω(fakeSlackAPI.GetLastActivitiesCallCount()).Should(Equal(1))
+
+			fakeClock.Increment(time.Hour)
+			job.Run(logger)
+			Ω(fakeSlackAPI.GetLastActivitiesCallCount()).Should(Equal(1))
+
+			fakeClock.Increment(23 * time.Hour)
+			job.Run(logger)
+			Ω(fakeSlackAPI.GetLastActivitiesCallCount()).Should(Equal(2))
+		})
+	})
+})
@@ -0,0 +1,369 @@
+package slackapi
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/slack"
+)
+
+// Directory is implemented by a SlackAPI which can look up users and
+// channels without fetching every one of them from Slack.
+type Directory interface {
+	UserByEmail(email string) (slack.User, bool, error)
+	UserByName(name string) (slack.User, bool, error)
+	ChannelByName(name string) (Conversation, bool, error)
+}
+
+type cache struct {
+	SlackAPI
+
+	ttl    time.Duration
+	clock  clock.Clock
+	logger lager.Logger
+
+	mutex         sync.Mutex
+	users         *userIndex
+	invalidations int
+	staleUsers    map[string]int
+	changes       int
+	conversations map[string]*conversationIndex
+
+	conversationInvalidations int
+}
+
+// userIndex holds the users fetched at once. It is not changed once made, so
+// it can be read without holding the cache's mutex.
+type userIndex struct {
+	fetchedAt time.Time
+	ids       []string
+	byID      map[string]slack.User
+	byEmail   map[string]string
+	byName    map[string]string
+}
+
+type conversationIndex struct {
+	fetchedAt     time.Time
+	conversations []Conversation
+	byName        map[string]int
+}
+
+// NewCache returns a SlackAPI which keeps the users and lists of
+// conversations fetched through api for the given ttl, indexing users by ID,
+// email and username and conversations by name. Users changed through the returned SlackAPI
+// are fetched again the next time they are needed, and inviting anyone causes
+// every user to be fetched again. Adding anyone to a channel, or making them a
+// Single-Channel Guest, causes the conversations to be fetched again.
+// GetUserInfo is never cached, so that it can be relied on to say who a user
+// is now.
+func NewCache(
+	api SlackAPI,
+	ttl time.Duration,
+	clock clock.Clock,
+	logger lager.Logger,
+) SlackAPI {
+	return &cache{
+		SlackAPI:      api,
+		ttl:           ttl,
+		clock:         clock,
+		logger:        logger.Session("cache"),
+		staleUsers:    map[string]int{},
+		conversations: map[string]*conversationIndex{},
+	}
+}
+
+// GetUsersPage returns every user as a single page, as they are all kept
+// together.
+func (c *cache) GetUsersPage(cursor string) ([]slack.User, string, error) {
+	if cursor != "" {
+		return c.SlackAPI.GetUsersPage(cursor)
+	}
+
+	users, err := c.userIndex()
+	if err != nil {
+		return nil, "", err
+	}
+
+	result := make([]slack.User, 0, len(users.ids))
+	for _, id := range users.ids {
+		result = append(result, users.byID[id])
+	}
+
+	return result, "", nil
+}
+
+func (c *cache) UserByEmail(email string) (slack.User, bool, error) {
+	users, err := c.userIndex()
+	if err != nil {
+		return slack.User{}, false, err
+	}
+
+	user, ok := users.byID[users.byEmail[email]]
+	return user, ok, nil
+}
+
+func (c *cache) UserByName(name string) (slack.User, bool, error) {
+	users, err := c.userIndex()
+	if err != nil {
+		return slack.User{}, false, err
+	}
+
+	user, ok := users.byID[users.byName[name]]
+	return user, ok, nil
+}
+
+func (c *cache) GetConversations(types []string, excludeArchived bool) ([]Conversation, error) {
+	conversations, err := c.conversationIndex(types, excludeArchived)
+	if err != nil {
+		return nil, err
+	}
+
+	return conversations.conversations, nil
+}
+
+// ChannelByName returns the unarchived public channel with the given name.
+func (c *cache) ChannelByName(name string) (Conversation, bool, error) {
+	excludeArchived := true
+	conversations, err := c.conversationIndex([]string{PublicChannel}, excludeArchived)
+	if err != nil {
+		return Conversation{}, false, err
+	}
+
+	i, ok := conversations.byName[name]
+	if !ok {
+		return Conversation{}, false, nil
+	}
+
+	return conversations.conversations[i], true, nil
+}
+
+func (c *cache) InviteGuest(teamName string, channelID string, firstName string, lastName string, emailAddress string) error {
+	defer c.invalidateUsers()
+	return c.SlackAPI.InviteGuest(teamName, channelID, firstName, lastName, emailAddress)
+}
+
+func (c *cache) InviteRestricted(teamName, channelID, firstName, lastName, emailAddress string) error {
+	defer c.invalidateUsers()
+	return c.SlackAPI.InviteRestricted(teamName, channelID, firstName, lastName, emailAddress)
+}
+
+func (c *cache) DisableUser(teamName string, user string) error {
+	defer c.invalidateUser(user)
+	return c.SlackAPI.DisableUser(teamName, user)
+}
+
+func (c *cache) EnableUser(teamName string, user string) error {
+	defer c.invalidateUser(user)
+	return c.SlackAPI.EnableUser(teamName, user)
+}
+
+func (c *cache) SetUltraRestricted(teamName string, user string, channel string) error {
+	defer c.invalidateConversations()
+	defer c.invalidateUser(user)
+	return c.SlackAPI.SetUltraRestricted(teamName, user, channel)
+}
+
+func (c *cache) SetRestricted(teamName string, user string) error {
+	defer c.invalidateUser(user)
+	return c.SlackAPI.SetRestricted(teamName, user)
+}
+
+func (c *cache) InviteToConversation(conversationID string, userID string) error {
+	defer c.invalidateConversations()
+	return c.SlackAPI.InviteToConversation(conversationID, userID)
+}
+
+func (c *cache) invalidateConversations() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.conversations = map[string]*conversationIndex{}
+	c.conversationInvalidations++
+}
+
+func (c *cache) invalidateUsers() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.users = nil
+	c.invalidations++
+}
+
+func (c *cache) invalidateUser(userID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.changes++
+	c.staleUsers[userID] = c.changes
+}
+
+// userIndex returns the indexed users, fetching them all if they have expired
+// and fetching any individual users which have been changed since. Slack is
+// called without the mutex held, so that a slow request does not hold up
+// others, and the new index then replaces the old one unless the users were
+// invalidated meanwhile.
+func (c *cache) userIndex() (*userIndex, error) {
+	c.mutex.Lock()
+	users := c.users
+	invalidations := c.invalidations
+	c.mutex.Unlock()
+
+	if users == nil || c.expired(users.fetchedAt) {
+		fetched, err := AllUsers(c.SlackAPI)
+		if err != nil {
+			return nil, err
+		}
+
+		users = newUserIndex(fetched, c.clock.Now())
+		c.logger.Info("fetched-users", lager.Data{"count": len(fetched)})
+
+		c.mutex.Lock()
+		if c.invalidations == invalidations {
+			c.users = users
+		}
+		c.mutex.Unlock()
+	}
+
+	c.mutex.Lock()
+	stale := make(map[string]int, len(c.staleUsers))
+	for userID, change := range c.staleUsers {
+		stale[userID] = change
+	}
+	c.mutex.Unlock()
+
+	if len(stale) == 0 {
+		return users, nil
+	}
+
+	var refetched []slack.User
+	for userID := range stale {
+		user, err := c.SlackAPI.GetUserInfo(userID)
+		if err != nil {
+			return nil, err
+		}
+
+		refetched = append(refetched, *user)
+		c.logger.Info("refetched-user", lager.Data{"userID": userID})
+	}
+
+	updated := users.with(refetched)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.users == users {
+		c.users = updated
+		for userID, change := range stale {
+			if c.staleUsers[userID] == change {
+				delete(c.staleUsers, userID)
+			}
+		}
+	}
+
+	return updated, nil
+}
+
+// conversationIndex returns the indexed conversations of the given types,
+// fetching them if they have expired. Slack is called without the mutex held,
+// and the new index is kept unless the conversations were invalidated
+// meanwhile.
+func (c *cache) conversationIndex(types []string, excludeArchived bool) (*conversationIndex, error) {
+	key := fmt.Sprintf("%s/%t", strings.Join(types, ","), excludeArchived)
+
+	c.mutex.Lock()
+	conversations := c.conversations[key]
+	invalidations := c.conversationInvalidations
+	c.mutex.Unlock()
+
+	if conversations == nil || c.expired(conversations.fetchedAt) {
+		fetched, err := c.SlackAPI.GetConversations(types, excludeArchived)
+		if err != nil {
+			return nil, err
+		}
+
+		conversations = &conversationIndex{
+			fetchedAt:     c.clock.Now(),
+			conversations: fetched,
+			byName:        map[string]int{},
+		}
+		for i, conversation := range fetched {
+			conversations.byName[conversation.Name] = i
+		}
+
+		c.mutex.Lock()
+		if c.conversationInvalidations == invalidations {
+			c.conversations[key] = conversations
+		}
+		c.mutex.Unlock()
+
+		c.logger.Info("fetched-conversations", lager.Data{"types": types, "count": len(fetched)})
+	}
+
+	return conversations, nil
+}
+
+func (c *cache) expired(fetchedAt time.Time) bool {
+	return c.clock.Since(fetchedAt) >= c.ttl
+}
+
+func newUserIndex(users []slack.User, fetchedAt time.Time) *userIndex {
+	index := &userIndex{
+		fetchedAt: fetchedAt,
+		byID:      make(map[string]slack.User, len(users)),
+		byEmail:   make(map[string]string, len(users)),
+		byName:    make(map[string]string, len(users)),
+	}
+	for _, user := range users {
+		index.ids = append(index.ids, user.ID)
+		index.put(user)
+	}
+
+	return index
+}
+
+// with returns a copy of the index with the given users replacing the ones
+// with the same IDs.
+func (u *userIndex) with(users []slack.User) *userIndex {
+	index := &userIndex{
+		fetchedAt: u.fetchedAt,
+		ids:       u.ids,
+		byID:      make(map[string]slack.User, len(u.byID)),
+		byEmail:   make(map[string]string, len(u.byEmail)),
+		byName:    make(map[string]string, len(u.byName)),
+	}
+	for id, user := range u.byID {
+		index.byID[id] = user
+	}
+	for email, id := range u.byEmail {
+		index.byEmail[email] = id
+	}
+	for name, id := range u.byName {
+		index.byName[name] = id
+	}
+
+	for _, user := range users {
+		if _, ok := index.byID[user.ID]; !ok {
+			index.ids = append(index.ids[:len(index.ids):len(index.ids)], user.ID)
+		}
+		index.put(user)
+	}
+
+	return index
+}
+
+func (u *userIndex) put(user slack.User) {
+	if previous, ok := u.byID[user.ID]; ok {
+		delete(u.byEmail, previous.Profile.Email)
+		delete(u.byName, "@"+previous.Name)
+	}
+
+	u.byID[user.ID] = user
+	if user.Profile.Email != "" {
+		u.byEmail[user.Profile.Email] = user.ID
+	}
+	u.byName["@"+user.Name] = user.ID
+}
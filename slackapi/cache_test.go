@@ -0,0 +1,319 @@
+package slackapi_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cache", func() {
+	var (
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeClock    *fakeclock.FakeClock
+		cache        slackapi.SlackAPI
+		directory    slackapi.Directory
+		ttl          time.Duration
+	)
+
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		ttl = 5 * time.Minute
+
+		cache = slackapi.NewCache(fakeSlackAPI, ttl, fakeClock, lager.NewLogger("testlogger"))
+		directory = cache.(slackapi.Directory)
+
+		fakeSlackAPI.GetUsersPageReturns([]slack.User{
+			{
+				ID:   "U1234",
+				Name: "tsmith",
+				Profile: slack.UserProfile{
+					Email: "tsmith@example.com",
+				},
+			},
+			{
+				ID:   "U5678",
+				Name: "jdoe",
+				Profile: slack.UserProfile{
+					Email: "jdoe@example.com",
+				},
+			},
+		}, "", nil)
+
+		fakeSlackAPI.GetConversationsReturns([]slackapi.Conversation{
+			{ID: "C1234", Name: "general"},
+			{ID: "C5678", Name: "random"},
+		}, nil)
+	})
+
+	Describe("users", func() {
+		It("looks users up by email, username and ID from a single fetch", func() {
+			user, found, err := directory.UserByEmail("jdoe@example.com")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(found).Should(BeTrue())
+			Ω(user.ID).Should(Equal("U5678"))
+
+			user, found, err = directory.UserByName("@tsmith")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(found).Should(BeTrue())
+			Ω(user.ID).Should(Equal("U1234"))
+
+			users, err := slackapi.AllUsers(cache)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(users).Should(HaveLen(2))
+
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(1))
+			Ω(fakeSlackAPI.GetUserInfoCallCount()).Should(Equal(0))
+		})
+
+		It("always looks a user up by ID in Slack, so that they are as they are now", func() {
+			_, err := slackapi.AllUsers(cache)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			fakeSlackAPI.GetUserInfoReturns(&slack.User{ID: "U5678", Name: "jdoe", IsUltraRestricted: true}, nil)
+
+			for i := 0; i < 2; i++ {
+				info, err := cache.GetUserInfo("U5678")
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(info.IsUltraRestricted).Should(BeTrue())
+			}
+
+			Ω(fakeSlackAPI.GetUserInfoCallCount()).Should(Equal(2))
+		})
+
+		It("fetches every page of users", func() {
+			fakeSlackAPI.GetUsersPageStub = func(cursor string) ([]slack.User, string, error) {
+				if cursor == "" {
+					return []slack.User{{ID: "U1234", Name: "tsmith"}}, "page-2", nil
+				}
+				return []slack.User{{ID: "U5678", Name: "jdoe"}}, "", nil
+			}
+
+			user, found, err := directory.UserByName("@jdoe")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(found).Should(BeTrue())
+			Ω(user.ID).Should(Equal("U5678"))
+
+			users, nextCursor, err := cache.GetUsersPage("")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(users).Should(HaveLen(2))
+			Ω(nextCursor).Should(BeEmpty())
+
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(2))
+		})
+
+		It("reports users which are not found", func() {
+			_, found, err := directory.UserByEmail("nobody@example.com")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(found).Should(BeFalse())
+		})
+
+		It("fetches the users again once the ttl has passed", func() {
+			_, err := slackapi.AllUsers(cache)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			fakeClock.Increment(ttl - time.Second)
+			_, err = slackapi.AllUsers(cache)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(1))
+
+			fakeClock.Increment(time.Second)
+			_, err = slackapi.AllUsers(cache)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(2))
+		})
+
+		It("returns an error when the users cannot be fetched", func() {
+			fakeSlackAPI.GetUsersPageReturns(nil, "", errors.New("slack is down"))
+
+			_, _, err := directory.UserByEmail("jdoe@example.com")
+			Ω(err).Should(MatchError("slack is down"))
+		})
+
+		Context("when a user is changed", func() {
+			BeforeEach(func() {
+				_, err := slackapi.AllUsers(cache)
+				Ω(err).ShouldNot(HaveOccurred())
+
+				fakeSlackAPI.GetUserInfoReturns(&slack.User{
+					ID:           "U5678",
+					Name:         "jdoe",
+					IsRestricted: true,
+					Profile:      slack.UserProfile{Email: "jdoe@example.com"},
+				}, nil)
+			})
+
+			It("fetches only that user again after they are disabled", func() {
+				Ω(cache.DisableUser("team-name", "U5678")).Should(Succeed())
+				Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(1))
+
+				user, _, err := directory.UserByEmail("jdoe@example.com")
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(user.IsRestricted).Should(BeTrue())
+
+				Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(1))
+				Ω(fakeSlackAPI.GetUserInfoCallCount()).Should(Equal(1))
+				Ω(fakeSlackAPI.GetUserInfoArgsForCall(0)).Should(Equal("U5678"))
+			})
+
+			It("forgets their old username once they are fetched again", func() {
+				fakeSlackAPI.GetUserInfoReturns(&slack.User{ID: "U5678", Name: "jsmith"}, nil)
+
+				Ω(cache.SetRestricted("team-name", "U5678")).Should(Succeed())
+
+				user, found, err := directory.UserByName("@jsmith")
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(found).Should(BeTrue())
+				Ω(user.ID).Should(Equal("U5678"))
+
+				_, found, err = directory.UserByName("@jdoe")
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(found).Should(BeFalse())
+			})
+
+			It("fetches only that user again after they are enabled", func() {
+				Ω(cache.EnableUser("team-name", "U5678")).Should(Succeed())
+				Ω(fakeSlackAPI.EnableUserCallCount()).Should(Equal(1))
+
+				_, err := slackapi.AllUsers(cache)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(fakeSlackAPI.GetUserInfoCallCount()).Should(Equal(1))
+			})
+
+			It("fetches only that user again after they are restricted", func() {
+				Ω(cache.SetRestricted("team-name", "U5678")).Should(Succeed())
+
+				_, err := slackapi.AllUsers(cache)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(fakeSlackAPI.GetUserInfoCallCount()).Should(Equal(1))
+			})
+
+			It("fetches only that user again after they are made a guest", func() {
+				Ω(cache.SetUltraRestricted("team-name", "U5678", "C1234")).Should(Succeed())
+
+				_, err := slackapi.AllUsers(cache)
+				Ω(err).ShouldNot(HaveOccurred())
+				Ω(fakeSlackAPI.GetUserInfoCallCount()).Should(Equal(1))
+			})
+		})
+
+		It("does not hold up other requests while fetching the users", func() {
+			fetching := make(chan struct{})
+			release := make(chan struct{})
+			fakeSlackAPI.GetUsersPageStub = func(string) ([]slack.User, string, error) {
+				close(fetching)
+				<-release
+				return []slack.User{{ID: "U1234", Name: "tsmith"}}, "", nil
+			}
+
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				_, _, err := directory.UserByName("@tsmith")
+				Ω(err).ShouldNot(HaveOccurred())
+				close(done)
+			}()
+
+			Eventually(fetching).Should(BeClosed())
+
+			_, found, err := directory.ChannelByName("general")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(found).Should(BeTrue())
+
+			close(release)
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("fetches every user again after someone is invited", func() {
+			_, err := slackapi.AllUsers(cache)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(cache.InviteGuest("team-name", "C1234", "Jane", "Doe", "jane@example.com")).Should(Succeed())
+			_, err = slackapi.AllUsers(cache)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(2))
+
+			Ω(cache.InviteRestricted("team-name", "C1234", "Jane", "Doe", "jane@example.com")).Should(Succeed())
+			_, err = slackapi.AllUsers(cache)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(3))
+		})
+	})
+
+	Describe("channels", func() {
+		It("looks channels up by name from a single fetch", func() {
+			channel, found, err := directory.ChannelByName("random")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(found).Should(BeTrue())
+			Ω(channel.Name).Should(Equal("random"))
+
+			_, found, err = directory.ChannelByName("missing")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(found).Should(BeFalse())
+
+			Ω(fakeSlackAPI.GetConversationsCallCount()).Should(Equal(1))
+			types, excludeArchived := fakeSlackAPI.GetConversationsArgsForCall(0)
+			Ω(types).Should(Equal([]string{slackapi.PublicChannel}))
+			Ω(excludeArchived).Should(BeTrue())
+		})
+
+		It("fetches the channels again once the ttl has passed", func() {
+			_, err := cache.GetConversations([]string{slackapi.PublicChannel}, true)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			fakeClock.Increment(ttl)
+			_, err = cache.GetConversations([]string{slackapi.PublicChannel}, true)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeSlackAPI.GetConversationsCallCount()).Should(Equal(2))
+		})
+
+		It("keeps each list of conversations for the ttl", func() {
+			_, err := cache.GetConversations([]string{slackapi.PrivateChannel}, true)
+			Ω(err).ShouldNot(HaveOccurred())
+			_, err = cache.GetConversations([]string{slackapi.PrivateChannel}, true)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeSlackAPI.GetConversationsCallCount()).Should(Equal(1))
+
+			_, err = cache.GetConversations([]string{slackapi.PrivateChannel}, false)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeSlackAPI.GetConversationsCallCount()).Should(Equal(2))
+
+			fakeClock.Increment(ttl)
+			_, err = cache.GetConversations([]string{slackapi.PrivateChannel}, true)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeSlackAPI.GetConversationsCallCount()).Should(Equal(3))
+		})
+
+		It("fetches the conversations again after someone is added to a channel", func() {
+			_, err := cache.GetConversations([]string{slackapi.PrivateChannel}, true)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(cache.InviteToConversation("C1234", "U5678")).Should(Succeed())
+			Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(1))
+
+			_, err = cache.GetConversations([]string{slackapi.PrivateChannel}, true)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeSlackAPI.GetConversationsCallCount()).Should(Equal(2))
+		})
+
+		It("fetches the conversations again after someone is made a guest", func() {
+			_, err := cache.GetConversations([]string{slackapi.PrivateChannel}, true)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			fakeSlackAPI.GetUserInfoReturns(&slack.User{ID: "U5678", IsUltraRestricted: true}, nil)
+			Ω(cache.SetUltraRestricted("team-name", "U5678", "C1234")).Should(Succeed())
+
+			_, err = cache.GetConversations([]string{slackapi.PrivateChannel}, true)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fakeSlackAPI.GetConversationsCallCount()).Should(Equal(2))
+		})
+	})
+})
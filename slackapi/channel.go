@@ -2,13 +2,17 @@ package slackapi
 
 type channel struct {
 	rawName string
-	name    string
 	id      string
+
+	lookedUp     bool
+	conversation Conversation
+	found        bool
 }
 
 //go:generate counterfeiter . Channel
 
-// Channel represents a Channel or Private Group in Slack.
+// Channel represents a public or private channel, or a direct message, in
+// Slack.
 type Channel interface {
 	Name(SlackAPI) string
 	Visible(SlackAPI) bool
@@ -23,39 +27,43 @@ func NewChannel(rawName string, id string) Channel {
 	}
 }
 
-// Name will attempt to retrieve a Private Group's real name from Slack,
-// falling back to the name given by Slack otherwise. In the case of Private
-// Groups, this is 'privategroup'
+// Name returns the name given by Slack, unless Slack only said the channel is
+// private, in which case the name is looked up with conversations.info. In
+// case that fails, or in the case of a direct message, it returns the name
+// given by Slack, such as 'privategroup' or 'directmessage'.
 func (c *channel) Name(api SlackAPI) string {
-	if c.name != "" {
-		return c.name
-	}
-
-	if c.rawName != PrivateGroupName {
+	if c.rawName != "" && c.rawName != PrivateGroupName {
 		return c.rawName
 	}
 
-	excludeArchived := true
-	groups, _ := api.GetGroups(excludeArchived)
-
-	for _, group := range groups {
-		if group.ID == c.id {
-			c.name = group.Name
-			return c.name
-		}
+	if conversation, found := c.lookUp(api); found && conversation.Name != "" {
+		return conversation.Name
 	}
 
-	c.name = c.rawName
-	return c.name
+	return c.rawName
 }
 
 // Visible returns true if the account associated with the configured
 // SLACK_AUTH_TOKEN is a member of the channel, and false if not.
 func (c *channel) Visible(api SlackAPI) bool {
-	return c.Name(api) != PrivateGroupName
+	conversation, found := c.lookUp(api)
+	return found && conversation.IsMember
 }
 
 // ID returns the channel's ID
 func (c *channel) ID() string {
 	return c.id
 }
+
+// lookUp returns the conversation with the channel's ID, only asking Slack
+// for it the first time.
+func (c *channel) lookUp(api SlackAPI) (Conversation, bool) {
+	if !c.lookedUp {
+		conversation, err := api.GetConversationInfo(c.id)
+		c.lookedUp = true
+		c.conversation = conversation
+		c.found = err == nil
+	}
+
+	return c.conversation, c.found
+}
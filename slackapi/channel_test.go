@@ -1,7 +1,7 @@
 package slackapi_test
 
 import (
-	"github.com/pivotalservices/slack"
+	"errors"
 
 	"github.com/pivotalservices/goulash/slackapi"
 	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
@@ -11,52 +11,45 @@ import (
 )
 
 var _ = Describe("Channel", func() {
-	Describe("Name", func() {
-		var fakeSlackAPI *slackapifakes.FakeSlackAPI
-		var channel slackapi.Channel
+	var fakeSlackAPI *slackapifakes.FakeSlackAPI
+	var channel slackapi.Channel
 
-		BeforeEach(func() {
-			fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
-		})
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+	})
 
-		Describe("when the group's name is slackapi.PrivateGroupName", func() {
+	Describe("Name", func() {
+		Describe("when the channel's name is slackapi.PrivateGroupName", func() {
 			BeforeEach(func() {
-				channel = slackapi.NewChannel(slackapi.PrivateGroupName, "C1234")
+				channel = slackapi.NewChannel(slackapi.PrivateGroupName, "G1234")
 			})
 
-			It("tries to find the group's name in Slack, excluding archived groups", func() {
+			It("looks up the channel in Slack", func() {
 				channel.Name(fakeSlackAPI)
-				Ω(fakeSlackAPI.GetGroupsCallCount()).Should(Equal(1))
-
-				excludeArchived := fakeSlackAPI.GetGroupsArgsForCall(0)
-				Ω(excludeArchived).Should(BeTrue())
+				Ω(fakeSlackAPI.GetConversationInfoCallCount()).Should(Equal(1))
+				Ω(fakeSlackAPI.GetConversationInfoArgsForCall(0)).Should(Equal("G1234"))
 			})
 
 			It("only hits the Slack API once", func() {
 				channel.Name(fakeSlackAPI)
+				channel.Visible(fakeSlackAPI)
 				channel.Name(fakeSlackAPI)
-				Ω(fakeSlackAPI.GetGroupsCallCount()).Should(Equal(1))
+				Ω(fakeSlackAPI.GetConversationInfoCallCount()).Should(Equal(1))
 			})
 
-			Describe("when the group is found in Slack", func() {
+			Describe("when the channel is found in Slack", func() {
 				BeforeEach(func() {
-					group := slack.Group{}
-					group.Name = "channel-name"
-					group.ID = "C1234"
-					fakeSlackAPI.GetGroupsReturns([]slack.Group{group}, nil)
+					fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{ID: "G1234", Name: "channel-name", IsPrivate: true}, nil)
 				})
 
-				It("returns the name associated with the found group", func() {
+				It("returns the name of the channel found", func() {
 					Ω(channel.Name(fakeSlackAPI)).Should(Equal("channel-name"))
 				})
 			})
 
-			Describe("when the group's name is not found in Slack", func() {
+			Describe("when the channel is not found in Slack", func() {
 				BeforeEach(func() {
-					group := slack.Group{}
-					group.Name = "other-channel-name"
-					group.ID = "C9999"
-					fakeSlackAPI.GetGroupsReturns([]slack.Group{group}, nil)
+					fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{}, errors.New("channel_not_found"))
 				})
 
 				It("returns slackapi.PrivateGroupName", func() {
@@ -65,7 +58,7 @@ var _ = Describe("Channel", func() {
 			})
 		})
 
-		Describe("when the group's name is not slackapi.PrivateGroupName", func() {
+		Describe("when the channel's name is not slackapi.PrivateGroupName", func() {
 			BeforeEach(func() {
 				channel = slackapi.NewChannel("channel-name", "C1234")
 			})
@@ -74,10 +67,31 @@ var _ = Describe("Channel", func() {
 				Ω(channel.Name(fakeSlackAPI)).Should(Equal("channel-name"))
 			})
 
-			It("does not call Slack to find the group's name", func() {
+			It("does not call Slack to find the channel's name", func() {
 				channel.Name(fakeSlackAPI)
-				Ω(fakeSlackAPI.GetGroupsCallCount()).Should(Equal(0))
+				Ω(fakeSlackAPI.GetConversationInfoCallCount()).Should(Equal(0))
 			})
 		})
 	})
+
+	Describe("Visible", func() {
+		BeforeEach(func() {
+			channel = slackapi.NewChannel("channel-name", "C1234")
+		})
+
+		It("is true when the configured account is a member of the channel", func() {
+			fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{ID: "C1234", IsMember: true}, nil)
+			Ω(channel.Visible(fakeSlackAPI)).Should(BeTrue())
+		})
+
+		It("is false when the configured account is not a member of the channel", func() {
+			fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{ID: "C1234"}, nil)
+			Ω(channel.Visible(fakeSlackAPI)).Should(BeFalse())
+		})
+
+		It("is false when the channel cannot be looked up", func() {
+			fakeSlackAPI.GetConversationInfoReturns(slackapi.Conversation{}, errors.New("channel_not_found"))
+			Ω(channel.Visible(fakeSlackAPI)).Should(BeFalse())
+		})
+	})
 })
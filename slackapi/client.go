@@ -0,0 +1,450 @@
+package slackapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pivotalservices/slack"
+)
+
+// DefaultAPIURL is the base URL of Slack's Web API.
+const DefaultAPIURL = "https://slack.com/api/"
+
+// requestTimeout is how long a request made directly to the Web API may take,
+// so that one Slack never answers cannot hold up a command forever.
+const requestTimeout = 30 * time.Second
+
+type client struct {
+	*slack.Slack
+
+	token      string
+	apiURL     string
+	httpClient *http.Client
+
+	// makeRequest makes each request to the Web API, given its method's name,
+	// by calling request. See NewRetrying.
+	makeRequest func(method string, request func() error) error
+}
+
+// New returns a SlackAPI backed by Slack's Web API at apiURL, authenticating
+// with the given token. Methods not provided by slack.Slack are implemented
+// by calling the Web API directly.
+func New(token string, apiURL string) SlackAPI {
+	return &client{
+		Slack:       slack.New(token),
+		token:       token,
+		apiURL:      strings.TrimSuffix(apiURL, "/") + "/",
+		httpClient:  &http.Client{Timeout: requestTimeout},
+		makeRequest: requestOnce,
+	}
+}
+
+// requestOnce makes a request to the Web API once.
+func requestOnce(method string, request func() error) error {
+	return request()
+}
+
+// makingRequestsWith returns a copy of the client which makes each of its
+// requests to the Web API with makeRequest.
+func (c *client) makingRequestsWith(makeRequest func(method string, request func() error) error) *client {
+	withMakeRequest := *c
+	withMakeRequest.makeRequest = makeRequest
+
+	return &withMakeRequest
+}
+
+// pageSize is how many results to ask for with each request to a method
+// which returns them a page at a time.
+const pageSize = "200"
+
+// slackResponse is a response from the Web API, which says whether the
+// request succeeded.
+type slackResponse interface {
+	err() error
+}
+
+type response struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+func (r response) err() error {
+	if r.OK {
+		return nil
+	}
+
+	return Error{Code: r.Error}
+}
+
+// pagedResponse is a response from a method which returns its results a page
+// at a time. See https://api.slack.com/docs/pagination for more information.
+type pagedResponse struct {
+	response
+	ResponseMetadata struct {
+		NextCursor string `json:"next_cursor"`
+	} `json:"response_metadata"`
+}
+
+// nextCursor returns the cursor with which to ask for the next page, or ""
+// if this is the last one.
+func (r pagedResponse) nextCursor() string {
+	return r.ResponseMetadata.NextCursor
+}
+
+// PostMessage posts a message as slack.Slack does, returning Slack's errors as
+// Errors.
+func (c *client) PostMessage(channelID string, text string, params slack.PostMessageParameters) (string, string, error) {
+	var channel, timestamp string
+	err := c.makeRequest("chat.postMessage", func() (err error) {
+		channel, timestamp, err = c.Slack.PostMessage(channelID, text, params)
+		return newError(err)
+	})
+
+	return channel, timestamp, err
+}
+
+// InviteGuest invites a Single-Channel Guest as slack.Slack does, returning
+// Slack's errors as Errors.
+func (c *client) InviteGuest(teamName string, channelID string, firstName string, lastName string, emailAddress string) error {
+	return c.makeRequest("users.admin.invite", func() error {
+		return newError(c.Slack.InviteGuest(teamName, channelID, firstName, lastName, emailAddress))
+	})
+}
+
+// InviteRestricted invites a Restricted Account as slack.Slack does, returning
+// Slack's errors as Errors.
+func (c *client) InviteRestricted(teamName, channelID, firstName, lastName, emailAddress string) error {
+	return c.makeRequest("users.admin.invite", func() error {
+		return newError(c.Slack.InviteRestricted(teamName, channelID, firstName, lastName, emailAddress))
+	})
+}
+
+// DisableUser disables an account as slack.Slack does, returning Slack's
+// errors as Errors.
+func (c *client) DisableUser(teamName string, user string) error {
+	return c.makeRequest("users.admin.setInactive", func() error {
+		return newError(c.Slack.DisableUser(teamName, user))
+	})
+}
+
+// SetUltraRestricted makes an account a Single-Channel Guest as slack.Slack
+// does, returning Slack's errors as Errors.
+func (c *client) SetUltraRestricted(teamName string, user string, channel string) error {
+	return c.makeRequest("users.admin.setUltraRestricted", func() error {
+		return newError(c.Slack.SetUltraRestricted(teamName, user, channel))
+	})
+}
+
+// SetRestricted makes an account a Restricted Account as slack.Slack does,
+// returning Slack's errors as Errors.
+func (c *client) SetRestricted(teamName string, user string) error {
+	return c.makeRequest("users.admin.setRestricted", func() error {
+		return newError(c.Slack.SetRestricted(teamName, user))
+	})
+}
+
+// OpenIMChannel opens a direct message channel as slack.Slack does, returning
+// Slack's errors as Errors.
+func (c *client) OpenIMChannel(userID string) (bool, bool, string, error) {
+	var noOp, alreadyOpen bool
+	var channelID string
+	err := c.makeRequest("im.open", func() (err error) {
+		noOp, alreadyOpen, channelID, err = c.Slack.OpenIMChannel(userID)
+		return newError(err)
+	})
+
+	return noOp, alreadyOpen, channelID, err
+}
+
+// GetUserInfo looks up a user as slack.Slack does, returning Slack's errors as
+// Errors.
+func (c *client) GetUserInfo(userID string) (*slack.User, error) {
+	var user *slack.User
+	err := c.makeRequest("users.info", func() (err error) {
+		user, err = c.Slack.GetUserInfo(userID)
+		return newError(err)
+	})
+
+	return user, err
+}
+
+// GetUsersPage returns the page of users starting at the given cursor, or
+// the first page if it is empty, and the cursor of the next page, which is
+// empty after the last one.
+func (c *client) GetUsersPage(cursor string) ([]slack.User, string, error) {
+	values := url.Values{"limit": {pageSize}}
+	if cursor != "" {
+		values.Set("cursor", cursor)
+	}
+
+	var resp struct {
+		pagedResponse
+		Members []slack.User `json:"members"`
+	}
+
+	if err := c.post("users.list", values, &resp); err != nil {
+		return nil, "", err
+	}
+
+	return resp.Members, resp.nextCursor(), nil
+}
+
+// GetUserGroupMembers returns the IDs of the users in the given user group.
+func (c *client) GetUserGroupMembers(userGroupID string) ([]string, error) {
+	var resp struct {
+		response
+		Users []string `json:"users"`
+	}
+
+	if err := c.post("usergroups.users.list", url.Values{"usergroup": {userGroupID}}, &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Users, nil
+}
+
+// accessLogsPageSize is how many logins to ask team.accessLogs for with each
+// request, the most it allows.
+const accessLogsPageSize = "1000"
+
+// GetLastActivities returns when each user in the team's access logs was last
+// active, by their ID, fetching every page of the logs. Users who have not
+// been active since the logs began are not in it. Reading the logs requires
+// the admin scope and a paid plan.
+func (c *client) GetLastActivities() (map[string]time.Time, error) {
+	values := url.Values{"count": {accessLogsPageSize}}
+
+	lastActivities := map[string]time.Time{}
+	for page := 1; ; page++ {
+		var resp struct {
+			response
+			Logins []struct {
+				UserID   string `json:"user_id"`
+				DateLast int64  `json:"date_last"`
+			} `json:"logins"`
+			Paging struct {
+				Page  int `json:"page"`
+				Pages int `json:"pages"`
+			} `json:"paging"`
+		}
+
+		values.Set("page", strconv.Itoa(page))
+		if err := c.post("team.accessLogs", values, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, login := range resp.Logins {
+			lastActive := time.Unix(login.DateLast, 0)
+			if lastActive.After(lastActivities[login.UserID]) {
+				lastActivities[login.UserID] = lastActive
+			}
+		}
+
+		if page >= resp.Paging.Pages {
+			return lastActivities, nil
+		}
+	}
+}
+
+// EnableUser reactivates the given disabled account as a Restricted Account.
+// Slack's admin API has no method which only reactivates an account, but
+// giving one a role does so.
+func (c *client) EnableUser(teamName string, user string) error {
+	var resp response
+	return c.post("users.admin.setRestricted", url.Values{
+		"user":       {user},
+		"set_active": {"true"},
+	}, &resp)
+}
+
+// GetConversations returns the conversations of the given types, fetching
+// every page of them.
+func (c *client) GetConversations(types []string, excludeArchived bool) ([]Conversation, error) {
+	return c.conversations("conversations.list", url.Values{
+		"types":            {strings.Join(types, ",")},
+		"exclude_archived": {strconv.FormatBool(excludeArchived)},
+	})
+}
+
+// GetUserConversations returns the conversations of the given types which the
+// given user is a member of, fetching every page of them. Private channels are
+// only those the user the token belongs to is also a member of.
+func (c *client) GetUserConversations(userID string, types []string, excludeArchived bool) ([]Conversation, error) {
+	return c.conversations("users.conversations", url.Values{
+		"user":             {userID},
+		"types":            {strings.Join(types, ",")},
+		"exclude_archived": {strconv.FormatBool(excludeArchived)},
+	})
+}
+
+// conversations calls the given method which lists conversations, fetching
+// every page of them.
+func (c *client) conversations(method string, values url.Values) ([]Conversation, error) {
+	values.Set("limit", pageSize)
+
+	var conversations []Conversation
+	for {
+		var resp struct {
+			pagedResponse
+			Channels []Conversation `json:"channels"`
+		}
+
+		if err := c.post(method, values, &resp); err != nil {
+			return nil, err
+		}
+
+		conversations = append(conversations, resp.Channels...)
+
+		if resp.nextCursor() == "" {
+			return conversations, nil
+		}
+		values.Set("cursor", resp.nextCursor())
+	}
+}
+
+// GetConversationInfo returns the conversation with the given ID.
+func (c *client) GetConversationInfo(conversationID string) (Conversation, error) {
+	var resp struct {
+		response
+		Channel Conversation `json:"channel"`
+	}
+
+	if err := c.post("conversations.info", url.Values{"channel": {conversationID}}, &resp); err != nil {
+		return Conversation{}, err
+	}
+
+	return resp.Channel, nil
+}
+
+// GetConversationMembers returns the IDs of the members of the given
+// conversation, fetching every page of them.
+func (c *client) GetConversationMembers(conversationID string) ([]string, error) {
+	values := url.Values{
+		"channel": {conversationID},
+		"limit":   {pageSize},
+	}
+
+	var members []string
+	for {
+		var resp struct {
+			pagedResponse
+			Members []string `json:"members"`
+		}
+
+		if err := c.post("conversations.members", values, &resp); err != nil {
+			return nil, err
+		}
+
+		members = append(members, resp.Members...)
+
+		if resp.nextCursor() == "" {
+			return members, nil
+		}
+		values.Set("cursor", resp.nextCursor())
+	}
+}
+
+// InviteToConversation adds the given user to the given public or private
+// channel.
+func (c *client) InviteToConversation(conversationID string, userID string) error {
+	var resp response
+	return c.post("conversations.invite", url.Values{
+		"channel": {conversationID},
+		"users":   {userID},
+	}, &resp)
+}
+
+// UploadFile shares a file with the given name and text content in the given
+// channel.
+func (c *client) UploadFile(channelID string, filename string, content string) error {
+	var resp response
+	return c.post("files.upload", url.Values{
+		"channels": {channelID},
+		"filename": {filename},
+		"content":  {content},
+	}, &resp)
+}
+
+// SendMessage posts the given Message, including its Blocks, to the given
+// channel as the authenticated user, returning the timestamp identifying it.
+func (c *client) SendMessage(channelID string, message Message) (string, error) {
+	values, err := messageValues(channelID, message)
+	if err != nil {
+		return "", err
+	}
+	values.Set("as_user", "true")
+
+	var resp struct {
+		response
+		Timestamp string `json:"ts"`
+	}
+
+	if err = c.post("chat.postMessage", values, &resp); err != nil {
+		return "", err
+	}
+
+	return resp.Timestamp, nil
+}
+
+// UpdateMessage replaces the message with the given timestamp in the given
+// channel with the given Message.
+func (c *client) UpdateMessage(channelID string, timestamp string, message Message) error {
+	values, err := messageValues(channelID, message)
+	if err != nil {
+		return err
+	}
+	values.Set("ts", timestamp)
+
+	var resp response
+	return c.post("chat.update", values, &resp)
+}
+
+func messageValues(channelID string, message Message) (url.Values, error) {
+	values := url.Values{
+		"channel": {channelID},
+		"text":    {message.Text},
+	}
+
+	if len(message.Blocks) > 0 {
+		blocks, err := json.Marshal(message.Blocks)
+		if err != nil {
+			return nil, err
+		}
+		values.Set("blocks", string(blocks))
+	}
+
+	return values, nil
+}
+
+// post calls the named method, decoding its response into result and
+// returning its error, if any. Each call is a request of its own, so a method
+// returning results a page at a time is called once for each page.
+func (c *client) post(method string, values url.Values, result slackResponse) error {
+	values.Set("token", c.token)
+
+	return c.makeRequest(method, func() error {
+		resp, err := c.httpClient.PostForm(c.apiURL+method, values)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			return Error{Code: ErrorRateLimited, RetryAfter: time.Duration(retryAfter) * time.Second}
+		case resp.StatusCode >= http.StatusInternalServerError:
+			return Error{Code: ErrorServiceUnavailable}
+		}
+
+		if err = json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return err
+		}
+
+		return result.err()
+	})
+}
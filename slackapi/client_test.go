@@ -0,0 +1,419 @@
+package slackapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		server        *httptest.Server
+		requestsMutex sync.Mutex
+		requests      []*http.Request
+		body          string
+		pages         []string
+		status        int
+		statuses      []int
+		api           slackapi.SlackAPI
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		pages = nil
+		status = http.StatusOK
+		statuses = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Ω(r.ParseForm()).Should(Succeed())
+
+			requestsMutex.Lock()
+			defer requestsMutex.Unlock()
+
+			requests = append(requests, r)
+
+			responseStatus := status
+			if len(statuses) > 0 {
+				responseStatus = statuses[0]
+				statuses = statuses[1:]
+			}
+
+			if responseStatus != http.StatusOK {
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(responseStatus)
+				return
+			}
+			if len(pages) > 0 {
+				w.Write([]byte(pages[0]))
+				pages = pages[1:]
+				return
+			}
+			w.Write([]byte(body))
+		}))
+
+		api = slackapi.New("slack-auth-token", server.URL)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("GetUserGroupMembers", func() {
+		It("calls usergroups.users.list", func() {
+			body = `{"ok":true,"users":["U1234","U5678"]}`
+
+			members, err := api.GetUserGroupMembers("S1234")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(members).Should(Equal([]string{"U1234", "U5678"}))
+
+			Ω(requests).Should(HaveLen(1))
+			Ω(requests[0].URL.Path).Should(Equal("/usergroups.users.list"))
+			Ω(requests[0].PostForm.Get("usergroup")).Should(Equal("S1234"))
+			Ω(requests[0].PostForm.Get("token")).Should(Equal("slack-auth-token"))
+		})
+
+		It("returns Slack's error", func() {
+			body = `{"ok":false,"error":"missing_scope"}`
+
+			_, err := api.GetUserGroupMembers("S1234")
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(Equal("missing_scope"))
+			Ω(slackapi.ErrorCode(err)).Should(Equal(slackapi.ErrorMissingScope))
+		})
+
+		It("returns being rate limited as an error", func() {
+			status = http.StatusTooManyRequests
+			body = ``
+
+			_, err := api.GetUserGroupMembers("S1234")
+			Ω(slackapi.ErrorCode(err)).Should(Equal(slackapi.ErrorRateLimited))
+		})
+	})
+
+	Describe("GetLastActivities", func() {
+		It("calls team.accessLogs for every page, keeping each user's latest login", func() {
+			pages = []string{
+				`{"ok":true,"logins":[{"user_id":"U1234","date_first":1410000000,"date_last":1420070400},{"user_id":"U5678","date_first":1410000000,"date_last":1410000000}],"paging":{"count":2,"total":3,"page":1,"pages":2}}`,
+				`{"ok":true,"logins":[{"user_id":"U1234","date_first":1400000000,"date_last":1410000000}],"paging":{"count":2,"total":3,"page":2,"pages":2}}`,
+			}
+
+			lastActivities, err := api.GetLastActivities()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(lastActivities).Should(HaveLen(2))
+			Ω(lastActivities["U1234"].Equal(time.Unix(1420070400, 0))).Should(BeTrue())
+			Ω(lastActivities["U5678"].Equal(time.Unix(1410000000, 0))).Should(BeTrue())
+
+			Ω(requests).Should(HaveLen(2))
+			Ω(requests[0].URL.Path).Should(Equal("/team.accessLogs"))
+			Ω(requests[0].PostForm.Get("page")).Should(Equal("1"))
+			Ω(requests[1].PostForm.Get("page")).Should(Equal("2"))
+		})
+
+		It("returns Slack's error", func() {
+			body = `{"ok":false,"error":"paid_only"}`
+
+			_, err := api.GetLastActivities()
+			Ω(err).Should(MatchError("paid_only"))
+		})
+	})
+
+	Describe("EnableUser", func() {
+		It("calls users.admin.setRestricted", func() {
+			body = `{"ok":true}`
+
+			err := api.EnableUser("team-name", "U1234")
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(requests).Should(HaveLen(1))
+			Ω(requests[0].URL.Path).Should(Equal("/users.admin.setRestricted"))
+			Ω(requests[0].PostForm.Get("user")).Should(Equal("U1234"))
+			Ω(requests[0].PostForm.Get("set_active")).Should(Equal("true"))
+		})
+
+		It("returns Slack's error", func() {
+			body = `{"ok":false,"error":"user_not_found"}`
+
+			err := api.EnableUser("team-name", "U1234")
+			Ω(err).Should(MatchError("user_not_found"))
+		})
+	})
+
+	Describe("GetUsersPage", func() {
+		It("calls users.list for the page at the given cursor", func() {
+			body = `{"ok":true,"members":[{"id":"U1234","name":"tsmith","is_restricted":true}],"response_metadata":{"next_cursor":"dXNlcjpVMDYxTkZUVDI="}}`
+
+			users, nextCursor, err := api.GetUsersPage("dXNlcjpVMDY0UTVKSjA=")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(users).Should(Equal([]slack.User{{ID: "U1234", Name: "tsmith", IsRestricted: true}}))
+			Ω(nextCursor).Should(Equal("dXNlcjpVMDYxTkZUVDI="))
+
+			Ω(requests).Should(HaveLen(1))
+			Ω(requests[0].URL.Path).Should(Equal("/users.list"))
+			Ω(requests[0].PostForm.Get("cursor")).Should(Equal("dXNlcjpVMDY0UTVKSjA="))
+			Ω(requests[0].PostForm.Get("limit")).Should(Equal("200"))
+		})
+
+		It("returns Slack's error", func() {
+			body = `{"ok":false,"error":"invalid_cursor"}`
+
+			_, _, err := api.GetUsersPage("")
+			Ω(err).Should(MatchError("invalid_cursor"))
+		})
+	})
+
+	Describe("GetConversations", func() {
+		It("calls conversations.list for every page", func() {
+			pages = []string{
+				`{"ok":true,"channels":[{"id":"C1234","name":"general","is_channel":true,"is_member":true}],"response_metadata":{"next_cursor":"dGVhbTpDMDYxRkE1UEI="}}`,
+				`{"ok":true,"channels":[{"id":"G1234","name":"secret","is_channel":true,"is_private":true}],"response_metadata":{"next_cursor":""}}`,
+			}
+
+			conversations, err := api.GetConversations([]string{slackapi.PublicChannel, slackapi.PrivateChannel}, true)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(conversations).Should(Equal([]slackapi.Conversation{
+				{ID: "C1234", Name: "general", IsChannel: true, IsMember: true},
+				{ID: "G1234", Name: "secret", IsChannel: true, IsPrivate: true},
+			}))
+
+			Ω(requests).Should(HaveLen(2))
+			Ω(requests[0].URL.Path).Should(Equal("/conversations.list"))
+			Ω(requests[0].PostForm.Get("types")).Should(Equal("public_channel,private_channel"))
+			Ω(requests[0].PostForm.Get("exclude_archived")).Should(Equal("true"))
+			Ω(requests[0].PostForm.Get("cursor")).Should(BeEmpty())
+			Ω(requests[1].PostForm.Get("cursor")).Should(Equal("dGVhbTpDMDYxRkE1UEI="))
+		})
+
+		It("returns Slack's error", func() {
+			body = `{"ok":false,"error":"invalid_cursor"}`
+
+			_, err := api.GetConversations([]string{slackapi.PublicChannel}, true)
+			Ω(err).Should(MatchError("invalid_cursor"))
+		})
+	})
+
+	Describe("GetConversationInfo", func() {
+		It("calls conversations.info", func() {
+			body = `{"ok":true,"channel":{"id":"D1234","is_im":true,"user":"U1234"}}`
+
+			conversation, err := api.GetConversationInfo("D1234")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(conversation).Should(Equal(slackapi.Conversation{ID: "D1234", IsIM: true, User: "U1234"}))
+
+			Ω(requests).Should(HaveLen(1))
+			Ω(requests[0].URL.Path).Should(Equal("/conversations.info"))
+			Ω(requests[0].PostForm.Get("channel")).Should(Equal("D1234"))
+		})
+
+		It("returns Slack's error", func() {
+			body = `{"ok":false,"error":"channel_not_found"}`
+
+			_, err := api.GetConversationInfo("C1234")
+			Ω(slackapi.ErrorCode(err)).Should(Equal(slackapi.ErrorChannelNotFound))
+		})
+	})
+
+	Describe("GetConversationMembers", func() {
+		It("calls conversations.members for every page", func() {
+			pages = []string{
+				`{"ok":true,"members":["U1234","U5678"],"response_metadata":{"next_cursor":"e3VzZXJfaWQ6IFUwNjY5MDFRRDY1fQ=="}}`,
+				`{"ok":true,"members":["U9999"],"response_metadata":{"next_cursor":""}}`,
+			}
+
+			members, err := api.GetConversationMembers("C1234")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(members).Should(Equal([]string{"U1234", "U5678", "U9999"}))
+
+			Ω(requests).Should(HaveLen(2))
+			Ω(requests[0].URL.Path).Should(Equal("/conversations.members"))
+			Ω(requests[0].PostForm.Get("channel")).Should(Equal("C1234"))
+			Ω(requests[1].PostForm.Get("cursor")).Should(Equal("e3VzZXJfaWQ6IFUwNjY5MDFRRDY1fQ=="))
+		})
+	})
+
+	Describe("GetUserConversations", func() {
+		It("calls users.conversations for every page", func() {
+			pages = []string{
+				`{"ok":true,"channels":[{"id":"C1234","name":"general","is_channel":true}],"response_metadata":{"next_cursor":"dGVhbTpDMDYxRkE1UEI="}}`,
+				`{"ok":true,"channels":[{"id":"G1234","name":"secret","is_channel":true,"is_private":true}],"response_metadata":{"next_cursor":""}}`,
+			}
+
+			conversations, err := api.GetUserConversations("U1234", []string{slackapi.PublicChannel, slackapi.PrivateChannel}, true)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(conversations).Should(Equal([]slackapi.Conversation{
+				{ID: "C1234", Name: "general", IsChannel: true},
+				{ID: "G1234", Name: "secret", IsChannel: true, IsPrivate: true},
+			}))
+
+			Ω(requests).Should(HaveLen(2))
+			Ω(requests[0].URL.Path).Should(Equal("/users.conversations"))
+			Ω(requests[0].PostForm.Get("user")).Should(Equal("U1234"))
+			Ω(requests[0].PostForm.Get("types")).Should(Equal("public_channel,private_channel"))
+			Ω(requests[0].PostForm.Get("exclude_archived")).Should(Equal("true"))
+			Ω(requests[1].PostForm.Get("cursor")).Should(Equal("dGVhbTpDMDYxRkE1UEI="))
+		})
+	})
+
+	Describe("InviteToConversation", func() {
+		It("calls conversations.invite", func() {
+			body = `{"ok":true}`
+
+			err := api.InviteToConversation("G1234", "U1234")
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(requests).Should(HaveLen(1))
+			Ω(requests[0].URL.Path).Should(Equal("/conversations.invite"))
+			Ω(requests[0].PostForm.Get("channel")).Should(Equal("G1234"))
+			Ω(requests[0].PostForm.Get("users")).Should(Equal("U1234"))
+		})
+
+		It("returns Slack's error", func() {
+			body = `{"ok":false,"error":"already_in_channel"}`
+
+			err := api.InviteToConversation("C1234", "U1234")
+			Ω(slackapi.ErrorCode(err)).Should(Equal(slackapi.ErrorAlreadyInChannel))
+		})
+	})
+
+	Describe("UploadFile", func() {
+		It("calls files.upload with the content", func() {
+			body = `{"ok":true,"file":{"id":"F1234"}}`
+
+			err := api.UploadFile("D1234", "guests.csv", "email\n")
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(requests).Should(HaveLen(1))
+			Ω(requests[0].URL.Path).Should(Equal("/files.upload"))
+			Ω(requests[0].PostForm.Get("channels")).Should(Equal("D1234"))
+			Ω(requests[0].PostForm.Get("filename")).Should(Equal("guests.csv"))
+			Ω(requests[0].PostForm.Get("content")).Should(Equal("email\n"))
+		})
+
+		It("returns Slack's error", func() {
+			body = `{"ok":false,"error":"invalid_channel"}`
+
+			err := api.UploadFile("D1234", "guests.csv", "email\n")
+			Ω(err).Should(MatchError("invalid_channel"))
+		})
+	})
+
+	Describe("SendMessage", func() {
+		It("calls chat.postMessage with the message's blocks", func() {
+			body = `{"ok":true,"ts":"1462104000.000100"}`
+
+			message := slackapi.Message{
+				Text:   "text",
+				Blocks: []slackapi.Block{slackapi.NewSectionBlock("section")},
+			}
+
+			timestamp, err := api.SendMessage("C1234", message)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(timestamp).Should(Equal("1462104000.000100"))
+
+			Ω(requests).Should(HaveLen(1))
+			Ω(requests[0].URL.Path).Should(Equal("/chat.postMessage"))
+			Ω(requests[0].PostForm.Get("channel")).Should(Equal("C1234"))
+			Ω(requests[0].PostForm.Get("text")).Should(Equal("text"))
+			Ω(requests[0].PostForm.Get("as_user")).Should(Equal("true"))
+			Ω(requests[0].PostForm.Get("blocks")).Should(MatchJSON(`[{"type":"section","text":{"type":"mrkdwn","text":"section"}}]`))
+		})
+
+		It("returns Slack's error", func() {
+			body = `{"ok":false,"error":"channel_not_found"}`
+
+			_, err := api.SendMessage("C1234", slackapi.NewTextMessage("text"))
+			Ω(err).Should(MatchError("channel_not_found"))
+		})
+	})
+
+	Describe("UpdateMessage", func() {
+		It("calls chat.update", func() {
+			body = `{"ok":true}`
+
+			err := api.UpdateMessage("C1234", "1462104000.000100", slackapi.NewTextMessage("text"))
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(requests).Should(HaveLen(1))
+			Ω(requests[0].URL.Path).Should(Equal("/chat.update"))
+			Ω(requests[0].PostForm.Get("channel")).Should(Equal("C1234"))
+			Ω(requests[0].PostForm.Get("ts")).Should(Equal("1462104000.000100"))
+			Ω(requests[0].PostForm.Get("text")).Should(Equal("text"))
+		})
+	})
+
+	Describe("when retrying", func() {
+		var fakeClock *fakeclock.FakeClock
+
+		BeforeEach(func() {
+			fakeClock = fakeclock.NewFakeClock(time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC))
+			api = slackapi.NewRetrying(api, fakeClock, lager.NewLogger("testlogger"))
+
+			pages = []string{
+				`{"ok":true,"members":["U1234"],"response_metadata":{"next_cursor":"page-2"}}`,
+				`{"ok":true,"members":["U5678"],"response_metadata":{"next_cursor":""}}`,
+			}
+		})
+
+		requestCount := func() int {
+			requestsMutex.Lock()
+			defer requestsMutex.Unlock()
+
+			return len(requests)
+		}
+
+		getMembers := func() (chan struct{}, *[]string, *error) {
+			var members []string
+			var err error
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				members, err = api.GetConversationMembers("C1234")
+				close(done)
+			}()
+
+			return done, &members, &err
+		}
+
+		It("spaces out the requests for each page", func() {
+			done, members, err := getMembers()
+
+			Eventually(requestCount).Should(Equal(1))
+			Consistently(requestCount).Should(Equal(1))
+
+			fakeClock.WaitForWatcherAndIncrement(600 * time.Millisecond)
+			Eventually(done).Should(BeClosed())
+			Ω(*err).ShouldNot(HaveOccurred())
+			Ω(*members).Should(Equal([]string{"U1234", "U5678"}))
+			Ω(requestCount()).Should(Equal(2))
+		})
+
+		It("fetches only the page which was rate limited again, once Slack says to", func() {
+			statuses = []int{http.StatusOK, http.StatusTooManyRequests}
+
+			done, members, err := getMembers()
+
+			Eventually(requestCount).Should(Equal(1))
+			fakeClock.WaitForWatcherAndIncrement(600 * time.Millisecond)
+			Eventually(requestCount).Should(Equal(2))
+
+			fakeClock.WaitForWatcherAndIncrement(29 * time.Second)
+			Consistently(requestCount).Should(Equal(2))
+
+			fakeClock.Increment(time.Second)
+			Eventually(done).Should(BeClosed())
+			Ω(*err).ShouldNot(HaveOccurred())
+			Ω(*members).Should(Equal([]string{"U1234", "U5678"}))
+
+			Ω(requests).Should(HaveLen(3))
+			Ω(requests[1].PostForm.Get("cursor")).Should(Equal("page-2"))
+			Ω(requests[2].PostForm.Get("cursor")).Should(Equal("page-2"))
+		})
+	})
+})
@@ -0,0 +1,30 @@
+package slackapi
+
+// The types of conversation which GetConversations can list.
+const (
+	PublicChannel      = "public_channel"
+	PrivateChannel     = "private_channel"
+	DirectMessage      = "im"
+	GroupDirectMessage = "mpim"
+)
+
+// Conversation is a public or private channel, including those shared with
+// other teams, or a direct message in Slack. See
+// https://api.slack.com/types/conversation for more information.
+type Conversation struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	IsChannel  bool   `json:"is_channel"`
+	IsIM       bool   `json:"is_im"`
+	IsMpIM     bool   `json:"is_mpim"`
+	IsPrivate  bool   `json:"is_private"`
+	IsArchived bool   `json:"is_archived"`
+	IsShared   bool   `json:"is_shared"`
+
+	// IsMember is true if the account associated with the configured
+	// SLACK_AUTH_TOKEN is a member of the conversation.
+	IsMember bool `json:"is_member"`
+
+	// User is the ID of the other person in a direct message.
+	User string `json:"user"`
+}
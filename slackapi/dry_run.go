@@ -0,0 +1,172 @@
+package slackapi
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotalservices/slack"
+)
+
+// DryRun is a SlackAPI which looks things up in Slack but changes nothing,
+// instead describing each change it was asked to make.
+type DryRun interface {
+	SlackAPI
+
+	// Describe adds a change made outside Slack which was not made either.
+	Describe(change string)
+
+	// Changes returns what would have been changed, in order.
+	Changes() []string
+}
+
+// dryRun implements every SlackAPI method itself rather than embedding the
+// SlackAPI it looks things up through, so that a method added to SlackAPI
+// cannot reach Slack until it is decided here whether it changes anything.
+type dryRun struct {
+	api SlackAPI
+
+	mutex   sync.Mutex
+	changes []string
+}
+
+// NewDryRun returns a DryRun which looks things up through api.
+func NewDryRun(api SlackAPI) DryRun {
+	return &dryRun{api: api}
+}
+
+func (d *dryRun) Describe(change string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.changes = append(d.changes, change)
+}
+
+func (d *dryRun) Changes() []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return append([]string(nil), d.changes...)
+}
+
+func (d *dryRun) PostMessage(channelID string, text string, params slack.PostMessageParameters) (string, string, error) {
+	d.Describe(fmt.Sprintf("post a message in %s", channelRef(channelID)))
+	return channelID, "", nil
+}
+
+func (d *dryRun) InviteToConversation(conversationID string, userID string) error {
+	d.Describe(fmt.Sprintf("add <@%s> to %s", userID, channelRef(conversationID)))
+	return nil
+}
+
+func (d *dryRun) SendMessage(channelID string, message Message) (string, error) {
+	d.Describe(fmt.Sprintf("post a message in %s", channelRef(channelID)))
+	return "", nil
+}
+
+func (d *dryRun) UpdateMessage(channelID string, timestamp string, message Message) error {
+	d.Describe(fmt.Sprintf("update a message in %s", channelRef(channelID)))
+	return nil
+}
+
+func (d *dryRun) InviteGuest(teamName string, channelID string, firstName string, lastName string, emailAddress string) error {
+	d.Describe(fmt.Sprintf(
+		"invite %s as a single-channel guest in %s",
+		invitee(firstName, lastName, emailAddress),
+		channelRef(channelID),
+	))
+	return nil
+}
+
+func (d *dryRun) InviteRestricted(teamName, channelID, firstName, lastName, emailAddress string) error {
+	d.Describe(fmt.Sprintf(
+		"invite %s as a restricted account in %s",
+		invitee(firstName, lastName, emailAddress),
+		channelRef(channelID),
+	))
+	return nil
+}
+
+func (d *dryRun) DisableUser(teamName string, user string) error {
+	d.Describe(fmt.Sprintf("disable <@%s>", user))
+	return nil
+}
+
+func (d *dryRun) EnableUser(teamName string, user string) error {
+	d.Describe(fmt.Sprintf("enable <@%s> as a restricted account", user))
+	return nil
+}
+
+func (d *dryRun) SetUltraRestricted(teamName string, user string, channel string) error {
+	d.Describe(fmt.Sprintf("make <@%s> a single-channel guest in %s", user, channelRef(channel)))
+	return nil
+}
+
+func (d *dryRun) SetRestricted(teamName string, user string) error {
+	d.Describe(fmt.Sprintf("make <@%s> a restricted account", user))
+	return nil
+}
+
+func (d *dryRun) UploadFile(channelID string, filename string, content string) error {
+	d.Describe(fmt.Sprintf("upload %s to %s", filename, channelRef(channelID)))
+	return nil
+}
+
+func (d *dryRun) GetConversations(types []string, excludeArchived bool) ([]Conversation, error) {
+	return d.api.GetConversations(types, excludeArchived)
+}
+
+func (d *dryRun) GetConversationInfo(conversationID string) (Conversation, error) {
+	return d.api.GetConversationInfo(conversationID)
+}
+
+func (d *dryRun) GetConversationMembers(conversationID string) ([]string, error) {
+	return d.api.GetConversationMembers(conversationID)
+}
+
+func (d *dryRun) GetUserConversations(userID string, types []string, excludeArchived bool) ([]Conversation, error) {
+	return d.api.GetUserConversations(userID, types, excludeArchived)
+}
+
+// OpenIMChannel opens the direct message channel, as doing so changes nothing
+// anyone can see until a message is sent in it.
+func (d *dryRun) OpenIMChannel(userID string) (bool, bool, string, error) {
+	return d.api.OpenIMChannel(userID)
+}
+
+func (d *dryRun) GetUserInfo(userID string) (*slack.User, error) {
+	return d.api.GetUserInfo(userID)
+}
+
+func (d *dryRun) GetUsersPage(cursor string) ([]slack.User, string, error) {
+	return d.api.GetUsersPage(cursor)
+}
+
+func (d *dryRun) GetLastActivities() (map[string]time.Time, error) {
+	return d.api.GetLastActivities()
+}
+
+func (d *dryRun) GetUserGroupMembers(userGroupID string) ([]string, error) {
+	return d.api.GetUserGroupMembers(userGroupID)
+}
+
+// channelRef returns how Slack is asked to show the channels with the given
+// comma-separated IDs.
+func channelRef(channelIDs string) string {
+	var refs []string
+	for _, id := range strings.Split(channelIDs, ",") {
+		refs = append(refs, fmt.Sprintf("<#%s>", id))
+	}
+
+	return strings.Join(refs, ", ")
+}
+
+func invitee(firstName string, lastName string, emailAddress string) string {
+	name := strings.TrimSpace(firstName + " " + lastName)
+	if name == "" {
+		return emailAddress
+	}
+
+	return fmt.Sprintf("%s (%s)", name, emailAddress)
+}
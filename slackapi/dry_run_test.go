@@ -0,0 +1,77 @@
+package slackapi_test
+
+import (
+	"errors"
+
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DryRun", func() {
+	var (
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		dryRun       slackapi.DryRun
+	)
+
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		dryRun = slackapi.NewDryRun(fakeSlackAPI)
+	})
+
+	It("looks things up through the given SlackAPI", func() {
+		fakeSlackAPI.GetUsersPageReturns([]slack.User{{ID: "U1234"}}, "", nil)
+		fakeSlackAPI.GetConversationsReturns(nil, errors.New("get-conversations-err"))
+
+		users, err := slackapi.AllUsers(dryRun)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(users).Should(Equal([]slack.User{{ID: "U1234"}}))
+
+		_, err = dryRun.GetConversations([]string{slackapi.PrivateChannel}, true)
+		Ω(err).Should(MatchError("get-conversations-err"))
+
+		_, err = dryRun.GetUserConversations("U1234", []string{slackapi.PublicChannel}, true)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(fakeSlackAPI.GetUserConversationsCallCount()).Should(Equal(1))
+
+		_, err = dryRun.GetLastActivities()
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(fakeSlackAPI.GetLastActivitiesCallCount()).Should(Equal(1))
+	})
+
+	It("describes changes instead of making them", func() {
+		Ω(dryRun.DisableUser("team-name", "U1234")).Should(Succeed())
+		Ω(dryRun.SetUltraRestricted("team-name", "U1234", "C1234")).Should(Succeed())
+		Ω(dryRun.InviteRestricted("team-name", "C1234,G5678", "Tom", "Smith", "tsmith@example.com")).Should(Succeed())
+		Ω(dryRun.InviteGuest("team-name", "C1234", "", "", "jdoe@example.com")).Should(Succeed())
+		Ω(dryRun.InviteToConversation("G5678", "U1234")).Should(Succeed())
+		_, err := dryRun.SendMessage("D1234", slackapi.NewTextMessage("hello"))
+		Ω(err).ShouldNot(HaveOccurred())
+
+		dryRun.Describe("record something")
+
+		Ω(dryRun.Changes()).Should(Equal([]string{
+			"disable <@U1234>",
+			"make <@U1234> a single-channel guest in <#C1234>",
+			"invite Tom Smith (tsmith@example.com) as a restricted account in <#C1234>, <#G5678>",
+			"invite jdoe@example.com as a single-channel guest in <#C1234>",
+			"add <@U1234> to <#G5678>",
+			"post a message in <#D1234>",
+			"record something",
+		}))
+
+		Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(0))
+		Ω(fakeSlackAPI.SetUltraRestrictedCallCount()).Should(Equal(0))
+		Ω(fakeSlackAPI.InviteRestrictedCallCount()).Should(Equal(0))
+		Ω(fakeSlackAPI.InviteGuestCallCount()).Should(Equal(0))
+		Ω(fakeSlackAPI.InviteToConversationCallCount()).Should(Equal(0))
+		Ω(fakeSlackAPI.SendMessageCallCount()).Should(Equal(0))
+	})
+
+	It("has no changes when nothing is changed", func() {
+		Ω(dryRun.Changes()).Should(BeEmpty())
+	})
+})
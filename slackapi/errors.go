@@ -0,0 +1,102 @@
+package slackapi
+
+import (
+	"strings"
+	"time"
+)
+
+// The codes of the errors Slack's Web API returns which Goulash handles. See
+// https://api.slack.com/web#evaluating_responses for more information.
+const (
+	ErrorAlreadyInvited      = "already_invited"
+	ErrorAlreadyInTeam       = "already_in_team"
+	ErrorAlreadyInChannel    = "already_in_channel"
+	ErrorUserDisabled        = "user_disabled"
+	ErrorRateLimited         = "ratelimited"
+	ErrorServiceUnavailable  = "service_unavailable"
+	ErrorInternalError       = "internal_error"
+	ErrorRequestTimeout      = "request_timeout"
+	ErrorNotAuthed           = "not_authed"
+	ErrorInvalidAuth         = "invalid_auth"
+	ErrorAccountInactive     = "account_inactive"
+	ErrorTokenRevoked        = "token_revoked"
+	ErrorMissingScope        = "missing_scope"
+	ErrorNotAllowedTokenType = "not_allowed_token_type"
+	ErrorInvalidEmail        = "invalid_email"
+	ErrorRestrictedAction    = "restricted_action"
+	ErrorIsArchived          = "is_archived"
+	ErrorNotInChannel        = "not_in_channel"
+	ErrorUserNotFound        = "user_not_found"
+	ErrorChannelNotFound     = "channel_not_found"
+)
+
+// Error is an error returned by Slack's Web API, identified by its code, such
+// as ErrorRateLimited.
+type Error struct {
+	Code string
+
+	// RetryAfter is how long Slack asked to be left before trying again, if
+	// it said, when rate limited.
+	RetryAfter time.Duration
+
+	// message is the error as reported by github.com/pivotalservices/slack,
+	// if it came from there.
+	message string
+}
+
+func (e Error) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+
+	return e.Code
+}
+
+// ErrorCode returns the code of err if it is an Error, and otherwise "".
+func ErrorCode(err error) string {
+	if slackErr, ok := err.(Error); ok {
+		return slackErr.Code
+	}
+
+	return ""
+}
+
+// newError returns err as an Error when it is one of Slack's error codes, or
+// ends with one after a colon as some of the errors returned by
+// github.com/pivotalservices/slack do, and otherwise returns err unchanged.
+func newError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(Error); ok {
+		return err
+	}
+
+	message := err.Error()
+	code := message
+	if i := strings.LastIndex(message, ": "); i >= 0 {
+		code = message[i+len(": "):]
+	}
+	if !isErrorCode(code) {
+		return err
+	}
+
+	return Error{Code: code, message: message}
+}
+
+// isErrorCode returns true if s looks like one of Slack's error codes, which
+// are lower case words joined by underscores.
+func isErrorCode(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && r != '_' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,213 @@
+package slackapi
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pivotalservices/slack"
+)
+
+const (
+	// ResponseTypeEphemeral is the response type of a message shown only to
+	// the user who ran the command.
+	ResponseTypeEphemeral = "ephemeral"
+
+	// ResponseTypeInChannel is the response type of a message shown to
+	// everyone in the channel the command was run in.
+	ResponseTypeInChannel = "in_channel"
+
+	// ButtonStylePrimary and ButtonStyleDanger are the styles of a Button
+	// which confirms or destroys something.
+	ButtonStylePrimary = "primary"
+	ButtonStyleDanger  = "danger"
+
+	errorColor = "danger"
+	markdown   = "mrkdwn"
+	plainText  = "plain_text"
+
+	// maxSectionTextLength is the longest text Slack accepts in a section
+	// Block.
+	maxSectionTextLength = 3000
+
+	// MaxBlocks is the most Blocks Slack accepts in a message.
+	MaxBlocks = 50
+)
+
+// Message is a message shown in Slack in response to a command. See
+// https://api.slack.com/reference/messaging/payload for more information.
+type Message struct {
+	ResponseType string             `json:"response_type,omitempty"`
+	Text         string             `json:"text"`
+	Attachments  []slack.Attachment `json:"attachments,omitempty"`
+	Blocks       []Block            `json:"blocks,omitempty"`
+}
+
+// Block is a Block Kit layout block. See
+// https://api.slack.com/reference/block-kit/blocks for more information.
+type Block struct {
+	Type     string        `json:"type"`
+	BlockID  string        `json:"block_id,omitempty"`
+	Text     *TextObject   `json:"text,omitempty"`
+	Fields   []TextObject  `json:"fields,omitempty"`
+	Elements []interface{} `json:"elements,omitempty"`
+}
+
+// TextObject is the text of a Block or one of its elements.
+type TextObject struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Button is an interactive element of an actions Block. When it is clicked,
+// Slack sends its ActionID and Value to the interactivity request URL.
+type Button struct {
+	Type     string     `json:"type"`
+	Text     TextObject `json:"text"`
+	ActionID string     `json:"action_id"`
+	Value    string     `json:"value,omitempty"`
+	Style    string     `json:"style,omitempty"`
+}
+
+// NewTextMessage returns a Message consisting only of the given text.
+func NewTextMessage(text string) Message {
+	return Message{Text: text}
+}
+
+// NewErrorMessage returns a Message which shows the given text as an error.
+func NewErrorMessage(text string) Message {
+	return Message{
+		Attachments: []slack.Attachment{
+			{
+				Color:      errorColor,
+				Fallback:   text,
+				Text:       text,
+				MarkdownIn: []string{"text"},
+			},
+		},
+	}
+}
+
+// NewSectionBlock returns a section Block showing the given text, with the
+// given fields laid out in columns beneath it.
+func NewSectionBlock(text string, fields ...string) Block {
+	block := Block{Type: "section", Text: newMarkdown(text)}
+	for _, field := range fields {
+		block.Fields = append(block.Fields, *newMarkdown(field))
+	}
+
+	return block
+}
+
+// NewSectionBlocks returns as few section Blocks as are needed to show the
+// given lines, one per line, within Slack's limit on the length of each. A
+// line too long for a Block of its own is split across several. There may be
+// more Blocks than Slack accepts in a message; see Fit.
+func NewSectionBlocks(lines []string) []Block {
+	var blocks []Block
+	var text string
+	for _, line := range splitLongLines(lines) {
+		if text != "" && len(text)+len(line)+1 > maxSectionTextLength {
+			blocks = append(blocks, NewSectionBlock(text))
+			text = ""
+		}
+
+		if text != "" {
+			text += "\n"
+		}
+		text += line
+	}
+
+	if text != "" {
+		blocks = append(blocks, NewSectionBlock(text))
+	}
+
+	return blocks
+}
+
+// splitLongLines returns the lines with any longer than a section Block
+// accepts split into several, between characters.
+func splitLongLines(lines []string) []string {
+	var split []string
+	for _, line := range lines {
+		for len(line) > maxSectionTextLength {
+			end := maxSectionTextLength
+			for !utf8.RuneStart(line[end]) {
+				end--
+			}
+
+			split = append(split, line[:end])
+			line = line[end:]
+		}
+
+		split = append(split, line)
+	}
+
+	return split
+}
+
+// NewContextBlock returns a context Block showing the given texts in small
+// type.
+func NewContextBlock(texts ...string) Block {
+	block := Block{Type: "context"}
+	for _, text := range texts {
+		block.Elements = append(block.Elements, newMarkdown(text))
+	}
+
+	return block
+}
+
+// NewActionsBlock returns an actions Block showing the given Buttons.
+func NewActionsBlock(blockID string, buttons ...Button) Block {
+	block := Block{Type: "actions", BlockID: blockID}
+	for _, button := range buttons {
+		block.Elements = append(block.Elements, button)
+	}
+
+	return block
+}
+
+// NewButton returns a Button with the given label, which sends the given
+// action ID and value when clicked.
+func NewButton(text string, actionID string, value string, style string) Button {
+	return Button{
+		Type:     "button",
+		Text:     TextObject{Type: plainText, Text: text},
+		ActionID: actionID,
+		Value:    value,
+		Style:    style,
+	}
+}
+
+// NewDividerBlock returns a divider Block.
+func NewDividerBlock() Block {
+	return Block{Type: "divider"}
+}
+
+// Fit returns the message without its Blocks if it has more than Slack
+// accepts, so that it is shown as its Text instead.
+func (m Message) Fit() Message {
+	if len(m.Blocks) > MaxBlocks {
+		m.Blocks = nil
+	}
+
+	return m
+}
+
+// String returns the text of the message and of its attachments, one per
+// line.
+func (m Message) String() string {
+	var lines []string
+	if m.Text != "" {
+		lines = append(lines, m.Text)
+	}
+
+	for _, attachment := range m.Attachments {
+		lines = append(lines, attachment.Text)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func newMarkdown(text string) *TextObject {
+	return &TextObject{Type: markdown, Text: text}
+}
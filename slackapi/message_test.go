@@ -0,0 +1,144 @@
+package slackapi_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pivotalservices/goulash/slackapi"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Message", func() {
+	It("serializes as a Slack message payload", func() {
+		message := slackapi.Message{
+			ResponseType: slackapi.ResponseTypeInChannel,
+			Text:         "Tom Smith",
+			Blocks: []slackapi.Block{
+				slackapi.NewSectionBlock("*Tom Smith*", "*Email*\nuser@example.com"),
+				slackapi.NewDividerBlock(),
+				slackapi.NewContextBlock("Requested by @tsmith"),
+			},
+		}
+
+		body, err := json.Marshal(message)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(body).Should(MatchJSON(`{
+			"response_type": "in_channel",
+			"text": "Tom Smith",
+			"blocks": [
+				{
+					"type": "section",
+					"text": {"type": "mrkdwn", "text": "*Tom Smith*"},
+					"fields": [{"type": "mrkdwn", "text": "*Email*\nuser@example.com"}]
+				},
+				{"type": "divider"},
+				{
+					"type": "context",
+					"elements": [{"type": "mrkdwn", "text": "Requested by @tsmith"}]
+				}
+			]
+		}`))
+	})
+
+	It("serializes buttons in an actions block", func() {
+		message := slackapi.Message{
+			Text: "Approve?",
+			Blocks: []slackapi.Block{
+				slackapi.NewActionsBlock(
+					"access-request-1",
+					slackapi.NewButton("Approve", "approve", "1", slackapi.ButtonStylePrimary),
+					slackapi.NewButton("Deny", "deny", "1", slackapi.ButtonStyleDanger),
+				),
+			},
+		}
+
+		body, err := json.Marshal(message)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(body).Should(MatchJSON(`{
+			"text": "Approve?",
+			"blocks": [
+				{
+					"type": "actions",
+					"block_id": "access-request-1",
+					"elements": [
+						{
+							"type": "button",
+							"text": {"type": "plain_text", "text": "Approve"},
+							"action_id": "approve",
+							"value": "1",
+							"style": "primary"
+						},
+						{
+							"type": "button",
+							"text": {"type": "plain_text", "text": "Deny"},
+							"action_id": "deny",
+							"value": "1",
+							"style": "danger"
+						}
+					]
+				}
+			]
+		}`))
+	})
+
+	Describe("NewErrorMessage", func() {
+		It("shows the text in a danger attachment", func() {
+			message := slackapi.NewErrorMessage("Something went wrong.")
+
+			Ω(message.Text).Should(BeEmpty())
+			Ω(message.Attachments).Should(HaveLen(1))
+			Ω(message.Attachments[0].Color).Should(Equal("danger"))
+			Ω(message.Attachments[0].Text).Should(Equal("Something went wrong."))
+			Ω(message.Attachments[0].Fallback).Should(Equal("Something went wrong."))
+		})
+	})
+
+	Describe("NewSectionBlocks", func() {
+		It("shows the lines in as few sections as Slack allows", func() {
+			long := strings.Repeat("x", 2000)
+
+			blocks := slackapi.NewSectionBlocks([]string{"one", "two", long, long})
+
+			Ω(blocks).Should(Equal([]slackapi.Block{
+				slackapi.NewSectionBlock("one\ntwo\n" + long),
+				slackapi.NewSectionBlock(long),
+			}))
+		})
+
+		It("splits a line too long for a section of its own between characters", func() {
+			long := strings.Repeat("x", 2999) + "é" + strings.Repeat("y", 10)
+
+			blocks := slackapi.NewSectionBlocks([]string{long})
+
+			Ω(blocks).Should(Equal([]slackapi.Block{
+				slackapi.NewSectionBlock(strings.Repeat("x", 2999)),
+				slackapi.NewSectionBlock("é" + strings.Repeat("y", 10)),
+			}))
+		})
+	})
+
+	Describe("Fit", func() {
+		It("leaves a message with no more blocks than Slack allows as it is", func() {
+			message := slackapi.Message{Text: "text", Blocks: make([]slackapi.Block, slackapi.MaxBlocks)}
+
+			Ω(message.Fit()).Should(Equal(message))
+		})
+
+		It("shows a message with more blocks than Slack allows as its text", func() {
+			message := slackapi.Message{Text: "text", Blocks: make([]slackapi.Block, slackapi.MaxBlocks+1)}
+
+			Ω(message.Fit()).Should(Equal(slackapi.Message{Text: "text"}))
+		})
+	})
+
+	Describe("String", func() {
+		It("returns the text of the message and its attachments", func() {
+			message := slackapi.NewErrorMessage("Something went wrong.")
+			message.Text = "Failed."
+
+			Ω(message.String()).Should(Equal("Failed.\nSomething went wrong."))
+		})
+	})
+})
@@ -0,0 +1,367 @@
+package slackapi
+
+import (
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/slack"
+)
+
+const (
+	// maxAttempts is how many times a request is made before its error is
+	// returned.
+	maxAttempts = 4
+
+	// initialBackoff is about how long to wait before retrying a request the
+	// first time. It doubles with each attempt after that.
+	initialBackoff = time.Second
+)
+
+// Slack's rate limit tiers, as the number of requests it allows each minute.
+// See https://api.slack.com/docs/rate-limits for more information.
+const (
+	tier2 = 20
+	tier3 = 50
+	tier4 = 100
+
+	// postMessageLimit is the one message a second Slack allows to be posted
+	// in each channel, applied across all of them.
+	postMessageLimit = 60
+)
+
+// rateLimits are the tiers of the Web API methods used, by method name.
+var rateLimits = map[string]int{
+	"chat.postMessage":               postMessageLimit,
+	"chat.update":                    tier3,
+	"conversations.info":             tier3,
+	"conversations.invite":           tier3,
+	"conversations.list":             tier2,
+	"conversations.members":          tier4,
+	"files.upload":                   tier2,
+	"im.open":                        tier3,
+	"team.accessLogs":                tier2,
+	"users.admin.invite":             tier2,
+	"users.admin.setInactive":        tier2,
+	"users.admin.setRestricted":      tier2,
+	"users.admin.setUltraRestricted": tier2,
+	"users.info":                     tier4,
+	"users.list":                     tier2,
+	"users.conversations":            tier3,
+	"usergroups.users.list":          tier2,
+}
+
+// idempotentMethods are the Web API methods used which only look things up,
+// or, like im.open, do nothing more when repeated, so may be called again
+// after failing for reasons which may pass. Methods which change things are
+// not, as they may have taken effect.
+var idempotentMethods = map[string]bool{
+	"conversations.info":    true,
+	"conversations.list":    true,
+	"conversations.members": true,
+	"im.open":               true,
+	"team.accessLogs":       true,
+	"users.conversations":   true,
+	"users.info":            true,
+	"users.list":            true,
+	"usergroups.users.list": true,
+}
+
+type retrying struct {
+	api    SlackAPI
+	clock  clock.Clock
+	logger lager.Logger
+
+	mutex sync.Mutex
+	next  map[string]time.Time
+}
+
+// NewRetrying returns a SlackAPI which spaces out its requests to each Web API
+// method to stay within Slack's rate limits, and makes them again when Slack
+// says it is rate limiting them, after as long as Slack asks. Requests which
+// only look things up are also made again when they fail for reasons which
+// may pass, waiting longer each time. Requests which change things are not,
+// as they may have taken effect.
+//
+// When api is one returned by New, each of its HTTP requests is spaced out
+// and made again on its own, so that every page of a method returning results
+// a page at a time counts towards the method's rate limit, and only the page
+// which failed is fetched again. Otherwise each call to api is treated as one
+// request.
+func NewRetrying(
+	api SlackAPI,
+	clock clock.Clock,
+	logger lager.Logger,
+) SlackAPI {
+	r := &retrying{
+		api:    api,
+		clock:  clock,
+		logger: logger.Session("retrying"),
+		next:   map[string]time.Time{},
+	}
+
+	if c, ok := api.(*client); ok {
+		return c.makingRequestsWith(r.do)
+	}
+
+	return r
+}
+
+func (r *retrying) PostMessage(channelID string, text string, params slack.PostMessageParameters) (string, string, error) {
+	var channel, timestamp string
+	err := r.do("chat.postMessage", func() (err error) {
+		channel, timestamp, err = r.api.PostMessage(channelID, text, params)
+		return err
+	})
+
+	return channel, timestamp, err
+}
+
+func (r *retrying) SendMessage(channelID string, message Message) (string, error) {
+	var timestamp string
+	err := r.do("chat.postMessage", func() (err error) {
+		timestamp, err = r.api.SendMessage(channelID, message)
+		return err
+	})
+
+	return timestamp, err
+}
+
+func (r *retrying) UpdateMessage(channelID string, timestamp string, message Message) error {
+	return r.do("chat.update", func() error {
+		return r.api.UpdateMessage(channelID, timestamp, message)
+	})
+}
+
+func (r *retrying) InviteGuest(teamName string, channelID string, firstName string, lastName string, emailAddress string) error {
+	return r.do("users.admin.invite", func() error {
+		return r.api.InviteGuest(teamName, channelID, firstName, lastName, emailAddress)
+	})
+}
+
+func (r *retrying) InviteRestricted(teamName, channelID, firstName, lastName, emailAddress string) error {
+	return r.do("users.admin.invite", func() error {
+		return r.api.InviteRestricted(teamName, channelID, firstName, lastName, emailAddress)
+	})
+}
+
+func (r *retrying) DisableUser(teamName string, user string) error {
+	return r.do("users.admin.setInactive", func() error {
+		return r.api.DisableUser(teamName, user)
+	})
+}
+
+func (r *retrying) EnableUser(teamName string, user string) error {
+	return r.do("users.admin.setRestricted", func() error {
+		return r.api.EnableUser(teamName, user)
+	})
+}
+
+func (r *retrying) SetUltraRestricted(teamName string, user string, channel string) error {
+	return r.do("users.admin.setUltraRestricted", func() error {
+		return r.api.SetUltraRestricted(teamName, user, channel)
+	})
+}
+
+func (r *retrying) SetRestricted(teamName string, user string) error {
+	return r.do("users.admin.setRestricted", func() error {
+		return r.api.SetRestricted(teamName, user)
+	})
+}
+
+func (r *retrying) UploadFile(channelID string, filename string, content string) error {
+	return r.do("files.upload", func() error {
+		return r.api.UploadFile(channelID, filename, content)
+	})
+}
+
+func (r *retrying) GetConversations(types []string, excludeArchived bool) ([]Conversation, error) {
+	var conversations []Conversation
+	err := r.do("conversations.list", func() (err error) {
+		conversations, err = r.api.GetConversations(types, excludeArchived)
+		return err
+	})
+
+	return conversations, err
+}
+
+func (r *retrying) GetConversationInfo(conversationID string) (Conversation, error) {
+	var conversation Conversation
+	err := r.do("conversations.info", func() (err error) {
+		conversation, err = r.api.GetConversationInfo(conversationID)
+		return err
+	})
+
+	return conversation, err
+}
+
+func (r *retrying) GetConversationMembers(conversationID string) ([]string, error) {
+	var members []string
+	err := r.do("conversations.members", func() (err error) {
+		members, err = r.api.GetConversationMembers(conversationID)
+		return err
+	})
+
+	return members, err
+}
+
+func (r *retrying) GetUserConversations(userID string, types []string, excludeArchived bool) ([]Conversation, error) {
+	var conversations []Conversation
+	err := r.do("users.conversations", func() (err error) {
+		conversations, err = r.api.GetUserConversations(userID, types, excludeArchived)
+		return err
+	})
+
+	return conversations, err
+}
+
+func (r *retrying) InviteToConversation(conversationID string, userID string) error {
+	return r.do("conversations.invite", func() error {
+		return r.api.InviteToConversation(conversationID, userID)
+	})
+}
+
+func (r *retrying) OpenIMChannel(userID string) (bool, bool, string, error) {
+	var noOp, alreadyOpen bool
+	var channelID string
+	err := r.do("im.open", func() (err error) {
+		noOp, alreadyOpen, channelID, err = r.api.OpenIMChannel(userID)
+		return err
+	})
+
+	return noOp, alreadyOpen, channelID, err
+}
+
+func (r *retrying) GetUserInfo(userID string) (*slack.User, error) {
+	var user *slack.User
+	err := r.do("users.info", func() (err error) {
+		user, err = r.api.GetUserInfo(userID)
+		return err
+	})
+
+	return user, err
+}
+
+func (r *retrying) GetUsersPage(cursor string) ([]slack.User, string, error) {
+	var users []slack.User
+	var nextCursor string
+	err := r.do("users.list", func() (err error) {
+		users, nextCursor, err = r.api.GetUsersPage(cursor)
+		return err
+	})
+
+	return users, nextCursor, err
+}
+
+func (r *retrying) GetLastActivities() (map[string]time.Time, error) {
+	var lastActivities map[string]time.Time
+	err := r.do("team.accessLogs", func() (err error) {
+		lastActivities, err = r.api.GetLastActivities()
+		return err
+	})
+
+	return lastActivities, err
+}
+
+func (r *retrying) GetUserGroupMembers(userGroupID string) ([]string, error) {
+	var members []string
+	err := r.do("usergroups.users.list", func() (err error) {
+		members, err = r.api.GetUserGroupMembers(userGroupID)
+		return err
+	})
+
+	return members, err
+}
+
+// do makes a request to the named method, waiting for its rate limit and
+// making it again as long as it fails in a way which allows that.
+func (r *retrying) do(method string, request func() error) error {
+	for attempt := 1; ; attempt++ {
+		r.wait(method)
+
+		err := request()
+		if err == nil {
+			return nil
+		}
+
+		delay, retry := r.delay(err, idempotentMethods[method], attempt)
+		if !retry {
+			return err
+		}
+
+		r.logger.Info("retrying", lager.Data{
+			"method":  method,
+			"attempt": attempt,
+			"delay":   delay.String(),
+			"error":   err.Error(),
+		})
+
+		r.clock.Sleep(delay)
+	}
+}
+
+// wait waits until the named method can be called again without exceeding
+// its rate limit, and reserves the call.
+func (r *retrying) wait(method string) {
+	limit, ok := rateLimits[method]
+	if !ok {
+		limit = tier2
+	}
+
+	r.mutex.Lock()
+	now := r.clock.Now()
+	next := r.next[method]
+	if next.Before(now) {
+		next = now
+	}
+	r.next[method] = next.Add(time.Minute / time.Duration(limit))
+	r.mutex.Unlock()
+
+	if delay := next.Sub(now); delay > 0 {
+		r.clock.Sleep(delay)
+	}
+}
+
+// delay returns how long to wait before making a request again after the
+// given attempt failed with err, or false if it should not be made again.
+func (r *retrying) delay(err error, idempotent bool, attempt int) (time.Duration, bool) {
+	if attempt >= maxAttempts {
+		return 0, false
+	}
+
+	if slackErr, ok := err.(Error); ok && slackErr.Code == ErrorRateLimited {
+		if slackErr.RetryAfter > 0 {
+			return slackErr.RetryAfter, true
+		}
+
+		return backoff(attempt), true
+	}
+
+	if idempotent && transient(err) {
+		return backoff(attempt), true
+	}
+
+	return 0, false
+}
+
+// backoff returns a random time between half and all of initialBackoff,
+// doubled for each attempt after the first.
+func backoff(attempt int) time.Duration {
+	max := initialBackoff << uint(attempt-1)
+	return max/2 + time.Duration(rand.Int63n(int64(max/2)))
+}
+
+// transient returns true if err may not happen again, such as Slack being
+// briefly unavailable or the network failing.
+func transient(err error) bool {
+	switch ErrorCode(err) {
+	case ErrorServiceUnavailable, ErrorInternalError, ErrorRequestTimeout:
+		return true
+	}
+
+	_, ok := err.(net.Error)
+	return ok
+}
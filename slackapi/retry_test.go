@@ -0,0 +1,160 @@
+package slackapi_test
+
+import (
+	"time"
+
+	"github.com/pivotal-golang/clock/fakeclock"
+	"github.com/pivotal-golang/lager"
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Retrying", func() {
+	var (
+		fakeSlackAPI *slackapifakes.FakeSlackAPI
+		fakeClock    *fakeclock.FakeClock
+		api          slackapi.SlackAPI
+	)
+
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeClock = fakeclock.NewFakeClock(time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC))
+		api = slackapi.NewRetrying(fakeSlackAPI, fakeClock, lager.NewLogger("testlogger"))
+	})
+
+	// waitFor lets time pass until done is closed.
+	waitFor := func(done chan struct{}) {
+		Eventually(func() bool {
+			select {
+			case <-done:
+				return true
+			default:
+				fakeClock.Increment(time.Second)
+				return false
+			}
+		}).Should(BeTrue())
+	}
+
+	It("makes requests through the given SlackAPI", func() {
+		fakeSlackAPI.GetUsersPageReturns([]slack.User{{ID: "U1234"}}, "", nil)
+		fakeSlackAPI.DisableUserReturns(nil)
+
+		users, _, err := api.GetUsersPage("")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(users).Should(Equal([]slack.User{{ID: "U1234"}}))
+
+		Ω(api.DisableUser("team-name", "U1234")).Should(Succeed())
+		Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(1))
+	})
+
+	It("spaces out requests to the same method", func() {
+		_, err := api.GetUserInfo("U1234")
+		Ω(err).ShouldNot(HaveOccurred())
+
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			api.GetUserInfo("U5678")
+			close(done)
+		}()
+
+		Consistently(fakeSlackAPI.GetUserInfoCallCount).Should(Equal(1))
+
+		fakeClock.WaitForWatcherAndIncrement(600 * time.Millisecond)
+		Eventually(done).Should(BeClosed())
+		Ω(fakeSlackAPI.GetUserInfoCallCount()).Should(Equal(2))
+	})
+
+	It("does not space out requests to different methods", func() {
+		api.GetUserInfo("U1234")
+		api.GetUsersPage("")
+		api.DisableUser("team-name", "U1234")
+
+		Ω(fakeSlackAPI.GetUserInfoCallCount()).Should(Equal(1))
+		Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(1))
+		Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(1))
+	})
+
+	It("makes a rate limited request again once Slack says to", func() {
+		fakeSlackAPI.DisableUserStub = func(string, string) error {
+			if fakeSlackAPI.DisableUserCallCount() == 1 {
+				return slackapi.Error{Code: slackapi.ErrorRateLimited, RetryAfter: 30 * time.Second}
+			}
+			return nil
+		}
+
+		var err error
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			err = api.DisableUser("team-name", "U1234")
+			close(done)
+		}()
+
+		fakeClock.WaitForWatcherAndIncrement(29 * time.Second)
+		Consistently(fakeSlackAPI.DisableUserCallCount).Should(Equal(1))
+
+		fakeClock.Increment(time.Second)
+		Eventually(done).Should(BeClosed())
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(2))
+	})
+
+	It("makes a request which only looks things up again when Slack is unavailable", func() {
+		fakeSlackAPI.GetUsersPageStub = func(string) ([]slack.User, string, error) {
+			if fakeSlackAPI.GetUsersPageCallCount() == 1 {
+				return nil, "", slackapi.Error{Code: slackapi.ErrorServiceUnavailable}
+			}
+			return []slack.User{{ID: "U1234"}}, "", nil
+		}
+
+		var users []slack.User
+		var err error
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			users, _, err = api.GetUsersPage("")
+			close(done)
+		}()
+
+		waitFor(done)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(users).Should(Equal([]slack.User{{ID: "U1234"}}))
+	})
+
+	It("gives up on a request after a few attempts", func() {
+		fakeSlackAPI.GetUsersPageReturns(nil, "", slackapi.Error{Code: slackapi.ErrorServiceUnavailable})
+
+		var err error
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			_, _, err = api.GetUsersPage("")
+			close(done)
+		}()
+
+		waitFor(done)
+		Ω(err).Should(MatchError("service_unavailable"))
+		Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(4))
+	})
+
+	It("does not make a request which changes things again when Slack is unavailable", func() {
+		fakeSlackAPI.DisableUserReturns(slackapi.Error{Code: slackapi.ErrorServiceUnavailable})
+
+		err := api.DisableUser("team-name", "U1234")
+		Ω(err).Should(MatchError("service_unavailable"))
+		Ω(fakeSlackAPI.DisableUserCallCount()).Should(Equal(1))
+	})
+
+	It("does not make a request again when it failed for another reason", func() {
+		fakeSlackAPI.GetUsersPageReturns(nil, "", slackapi.Error{Code: slackapi.ErrorMissingScope})
+
+		_, _, err := api.GetUsersPage("")
+		Ω(err).Should(MatchError("missing_scope"))
+		Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(1))
+	})
+})
@@ -1,6 +1,10 @@
 package slackapi
 
-import "github.com/pivotalservices/slack"
+import (
+	"time"
+
+	"github.com/pivotalservices/slack"
+)
 
 const (
 	// PrivateGroupName holds the name Slack provides for a Slash Command sent
@@ -14,27 +18,42 @@ const (
 
 //go:generate counterfeiter . SlackAPI
 
-// SlackAPI defines the set of methods we expect to call on slack.Slack. This
-// allows us to fake it for testing purposes.
+// SlackAPI defines the set of methods we expect to call on Slack's Web API.
+// This allows us to fake it for testing purposes.
 type SlackAPI interface {
-	// channel
+	// chat
 	PostMessage(channelID string, text string, params slack.PostMessageParameters) (channel string, timestamp string, err error)
-	GetChannels(excludeArchived bool) ([]slack.Channel, error)
+	SendMessage(channelID string, message Message) (timestamp string, err error)
+	UpdateMessage(channelID string, timestamp string, message Message) error
 
 	// admin
 	InviteGuest(teamName string, channelID string, firstName string, lastName string, emailAddress string) error
 	InviteRestricted(teamName, channelID, firstName, lastName, emailAddress string) error
 	DisableUser(teamName string, user string) error
+	EnableUser(teamName string, user string) error
 	SetUltraRestricted(teamName string, user string, channel string) error
 	SetRestricted(teamName string, user string) error
 
-	// groups
-	GetGroups(excludeArchived bool) ([]slack.Group, error)
+	// files
+	UploadFile(channelID string, filename string, content string) error
+
+	// conversations
+	GetConversations(types []string, excludeArchived bool) ([]Conversation, error)
+	GetConversationInfo(conversationID string) (Conversation, error)
+	GetConversationMembers(conversationID string) ([]string, error)
+	InviteToConversation(conversationID string, userID string) error
+	GetUserConversations(userID string, types []string, excludeArchived bool) ([]Conversation, error)
 
 	// im
 	OpenIMChannel(userID string) (bool, bool, string, error)
 
 	// users
 	GetUserInfo(userID string) (*slack.User, error)
-	GetUsers() ([]slack.User, error)
+	GetUsersPage(cursor string) (users []slack.User, nextCursor string, err error)
+
+	// team
+	GetLastActivities() (map[string]time.Time, error)
+
+	// usergroups
+	GetUserGroupMembers(userGroupID string) ([]string, error)
 }
@@ -3,6 +3,7 @@ package slackapifakes
 
 import (
 	"sync"
+	"time"
 
 	"github.com/pivotalservices/goulash/slackapi"
 	"github.com/pivotalservices/slack"
@@ -21,15 +22,26 @@ type FakeSlackAPI struct {
 		result2 string
 		result3 error
 	}
-	GetChannelsStub        func(excludeArchived bool) ([]slack.Channel, error)
-	getChannelsMutex       sync.RWMutex
-	getChannelsArgsForCall []struct {
-		excludeArchived bool
+	SendMessageStub        func(channelID string, message slackapi.Message) (timestamp string, err error)
+	sendMessageMutex       sync.RWMutex
+	sendMessageArgsForCall []struct {
+		channelID string
+		message   slackapi.Message
 	}
-	getChannelsReturns struct {
-		result1 []slack.Channel
+	sendMessageReturns struct {
+		result1 string
 		result2 error
 	}
+	UpdateMessageStub        func(channelID string, timestamp string, message slackapi.Message) error
+	updateMessageMutex       sync.RWMutex
+	updateMessageArgsForCall []struct {
+		channelID string
+		timestamp string
+		message   slackapi.Message
+	}
+	updateMessageReturns struct {
+		result1 error
+	}
 	InviteGuestStub        func(teamName string, channelID string, firstName string, lastName string, emailAddress string) error
 	inviteGuestMutex       sync.RWMutex
 	inviteGuestArgsForCall []struct {
@@ -63,6 +75,15 @@ type FakeSlackAPI struct {
 	disableUserReturns struct {
 		result1 error
 	}
+	EnableUserStub        func(teamName string, user string) error
+	enableUserMutex       sync.RWMutex
+	enableUserArgsForCall []struct {
+		teamName string
+		user     string
+	}
+	enableUserReturns struct {
+		result1 error
+	}
 	SetUltraRestrictedStub        func(teamName string, user string, channel string) error
 	setUltraRestrictedMutex       sync.RWMutex
 	setUltraRestrictedArgsForCall []struct {
@@ -82,13 +103,62 @@ type FakeSlackAPI struct {
 	setRestrictedReturns struct {
 		result1 error
 	}
-	GetGroupsStub        func(excludeArchived bool) ([]slack.Group, error)
-	getGroupsMutex       sync.RWMutex
-	getGroupsArgsForCall []struct {
+	UploadFileStub        func(channelID string, filename string, content string) error
+	uploadFileMutex       sync.RWMutex
+	uploadFileArgsForCall []struct {
+		channelID string
+		filename  string
+		content   string
+	}
+	uploadFileReturns struct {
+		result1 error
+	}
+	GetConversationsStub        func(types []string, excludeArchived bool) ([]slackapi.Conversation, error)
+	getConversationsMutex       sync.RWMutex
+	getConversationsArgsForCall []struct {
+		types           []string
 		excludeArchived bool
 	}
-	getGroupsReturns struct {
-		result1 []slack.Group
+	getConversationsReturns struct {
+		result1 []slackapi.Conversation
+		result2 error
+	}
+	GetConversationInfoStub        func(conversationID string) (slackapi.Conversation, error)
+	getConversationInfoMutex       sync.RWMutex
+	getConversationInfoArgsForCall []struct {
+		conversationID string
+	}
+	getConversationInfoReturns struct {
+		result1 slackapi.Conversation
+		result2 error
+	}
+	GetConversationMembersStub        func(conversationID string) ([]string, error)
+	getConversationMembersMutex       sync.RWMutex
+	getConversationMembersArgsForCall []struct {
+		conversationID string
+	}
+	getConversationMembersReturns struct {
+		result1 []string
+		result2 error
+	}
+	InviteToConversationStub        func(conversationID string, userID string) error
+	inviteToConversationMutex       sync.RWMutex
+	inviteToConversationArgsForCall []struct {
+		conversationID string
+		userID         string
+	}
+	inviteToConversationReturns struct {
+		result1 error
+	}
+	GetUserConversationsStub        func(userID string, types []string, excludeArchived bool) ([]slackapi.Conversation, error)
+	getUserConversationsMutex       sync.RWMutex
+	getUserConversationsArgsForCall []struct {
+		userID          string
+		types           []string
+		excludeArchived bool
+	}
+	getUserConversationsReturns struct {
+		result1 []slackapi.Conversation
 		result2 error
 	}
 	OpenIMChannelStub        func(userID string) (bool, bool, string, error)
@@ -111,11 +181,30 @@ type FakeSlackAPI struct {
 		result1 *slack.User
 		result2 error
 	}
-	GetUsersStub        func() ([]slack.User, error)
-	getUsersMutex       sync.RWMutex
-	getUsersArgsForCall []struct{}
-	getUsersReturns     struct {
+	GetUsersPageStub        func(cursor string) (users []slack.User, nextCursor string, err error)
+	getUsersPageMutex       sync.RWMutex
+	getUsersPageArgsForCall []struct {
+		cursor string
+	}
+	getUsersPageReturns struct {
 		result1 []slack.User
+		result2 string
+		result3 error
+	}
+	GetLastActivitiesStub        func() (map[string]time.Time, error)
+	getLastActivitiesMutex       sync.RWMutex
+	getLastActivitiesArgsForCall []struct{}
+	getLastActivitiesReturns     struct {
+		result1 map[string]time.Time
+		result2 error
+	}
+	GetUserGroupMembersStub        func(userGroupID string) ([]string, error)
+	getUserGroupMembersMutex       sync.RWMutex
+	getUserGroupMembersArgsForCall []struct {
+		userGroupID string
+	}
+	getUserGroupMembersReturns struct {
+		result1 []string
 		result2 error
 	}
 }
@@ -156,39 +245,74 @@ func (fake *FakeSlackAPI) PostMessageReturns(result1 string, result2 string, res
 	}{result1, result2, result3}
 }
 
-func (fake *FakeSlackAPI) GetChannels(excludeArchived bool) ([]slack.Channel, error) {
-	fake.getChannelsMutex.Lock()
-	fake.getChannelsArgsForCall = append(fake.getChannelsArgsForCall, struct {
-		excludeArchived bool
-	}{excludeArchived})
-	fake.getChannelsMutex.Unlock()
-	if fake.GetChannelsStub != nil {
-		return fake.GetChannelsStub(excludeArchived)
+func (fake *FakeSlackAPI) SendMessage(channelID string, message slackapi.Message) (timestamp string, err error) {
+	fake.sendMessageMutex.Lock()
+	fake.sendMessageArgsForCall = append(fake.sendMessageArgsForCall, struct {
+		channelID string
+		message   slackapi.Message
+	}{channelID, message})
+	fake.sendMessageMutex.Unlock()
+	if fake.SendMessageStub != nil {
+		return fake.SendMessageStub(channelID, message)
 	} else {
-		return fake.getChannelsReturns.result1, fake.getChannelsReturns.result2
+		return fake.sendMessageReturns.result1, fake.sendMessageReturns.result2
 	}
 }
 
-func (fake *FakeSlackAPI) GetChannelsCallCount() int {
-	fake.getChannelsMutex.RLock()
-	defer fake.getChannelsMutex.RUnlock()
-	return len(fake.getChannelsArgsForCall)
+func (fake *FakeSlackAPI) SendMessageCallCount() int {
+	fake.sendMessageMutex.RLock()
+	defer fake.sendMessageMutex.RUnlock()
+	return len(fake.sendMessageArgsForCall)
 }
 
-func (fake *FakeSlackAPI) GetChannelsArgsForCall(i int) bool {
-	fake.getChannelsMutex.RLock()
-	defer fake.getChannelsMutex.RUnlock()
-	return fake.getChannelsArgsForCall[i].excludeArchived
+func (fake *FakeSlackAPI) SendMessageArgsForCall(i int) (string, slackapi.Message) {
+	fake.sendMessageMutex.RLock()
+	defer fake.sendMessageMutex.RUnlock()
+	return fake.sendMessageArgsForCall[i].channelID, fake.sendMessageArgsForCall[i].message
 }
 
-func (fake *FakeSlackAPI) GetChannelsReturns(result1 []slack.Channel, result2 error) {
-	fake.GetChannelsStub = nil
-	fake.getChannelsReturns = struct {
-		result1 []slack.Channel
+func (fake *FakeSlackAPI) SendMessageReturns(result1 string, result2 error) {
+	fake.SendMessageStub = nil
+	fake.sendMessageReturns = struct {
+		result1 string
 		result2 error
 	}{result1, result2}
 }
 
+func (fake *FakeSlackAPI) UpdateMessage(channelID string, timestamp string, message slackapi.Message) error {
+	fake.updateMessageMutex.Lock()
+	fake.updateMessageArgsForCall = append(fake.updateMessageArgsForCall, struct {
+		channelID string
+		timestamp string
+		message   slackapi.Message
+	}{channelID, timestamp, message})
+	fake.updateMessageMutex.Unlock()
+	if fake.UpdateMessageStub != nil {
+		return fake.UpdateMessageStub(channelID, timestamp, message)
+	} else {
+		return fake.updateMessageReturns.result1
+	}
+}
+
+func (fake *FakeSlackAPI) UpdateMessageCallCount() int {
+	fake.updateMessageMutex.RLock()
+	defer fake.updateMessageMutex.RUnlock()
+	return len(fake.updateMessageArgsForCall)
+}
+
+func (fake *FakeSlackAPI) UpdateMessageArgsForCall(i int) (string, string, slackapi.Message) {
+	fake.updateMessageMutex.RLock()
+	defer fake.updateMessageMutex.RUnlock()
+	return fake.updateMessageArgsForCall[i].channelID, fake.updateMessageArgsForCall[i].timestamp, fake.updateMessageArgsForCall[i].message
+}
+
+func (fake *FakeSlackAPI) UpdateMessageReturns(result1 error) {
+	fake.UpdateMessageStub = nil
+	fake.updateMessageReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeSlackAPI) InviteGuest(teamName string, channelID string, firstName string, lastName string, emailAddress string) error {
 	fake.inviteGuestMutex.Lock()
 	fake.inviteGuestArgsForCall = append(fake.inviteGuestArgsForCall, struct {
@@ -294,6 +418,39 @@ func (fake *FakeSlackAPI) DisableUserReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeSlackAPI) EnableUser(teamName string, user string) error {
+	fake.enableUserMutex.Lock()
+	fake.enableUserArgsForCall = append(fake.enableUserArgsForCall, struct {
+		teamName string
+		user     string
+	}{teamName, user})
+	fake.enableUserMutex.Unlock()
+	if fake.EnableUserStub != nil {
+		return fake.EnableUserStub(teamName, user)
+	} else {
+		return fake.enableUserReturns.result1
+	}
+}
+
+func (fake *FakeSlackAPI) EnableUserCallCount() int {
+	fake.enableUserMutex.RLock()
+	defer fake.enableUserMutex.RUnlock()
+	return len(fake.enableUserArgsForCall)
+}
+
+func (fake *FakeSlackAPI) EnableUserArgsForCall(i int) (string, string) {
+	fake.enableUserMutex.RLock()
+	defer fake.enableUserMutex.RUnlock()
+	return fake.enableUserArgsForCall[i].teamName, fake.enableUserArgsForCall[i].user
+}
+
+func (fake *FakeSlackAPI) EnableUserReturns(result1 error) {
+	fake.EnableUserStub = nil
+	fake.enableUserReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeSlackAPI) SetUltraRestricted(teamName string, user string, channel string) error {
 	fake.setUltraRestrictedMutex.Lock()
 	fake.setUltraRestrictedArgsForCall = append(fake.setUltraRestrictedArgsForCall, struct {
@@ -361,35 +518,204 @@ func (fake *FakeSlackAPI) SetRestrictedReturns(result1 error) {
 	}{result1}
 }
 
-func (fake *FakeSlackAPI) GetGroups(excludeArchived bool) ([]slack.Group, error) {
-	fake.getGroupsMutex.Lock()
-	fake.getGroupsArgsForCall = append(fake.getGroupsArgsForCall, struct {
+func (fake *FakeSlackAPI) UploadFile(channelID string, filename string, content string) error {
+	fake.uploadFileMutex.Lock()
+	fake.uploadFileArgsForCall = append(fake.uploadFileArgsForCall, struct {
+		channelID string
+		filename  string
+		content   string
+	}{channelID, filename, content})
+	fake.uploadFileMutex.Unlock()
+	if fake.UploadFileStub != nil {
+		return fake.UploadFileStub(channelID, filename, content)
+	} else {
+		return fake.uploadFileReturns.result1
+	}
+}
+
+func (fake *FakeSlackAPI) UploadFileCallCount() int {
+	fake.uploadFileMutex.RLock()
+	defer fake.uploadFileMutex.RUnlock()
+	return len(fake.uploadFileArgsForCall)
+}
+
+func (fake *FakeSlackAPI) UploadFileArgsForCall(i int) (string, string, string) {
+	fake.uploadFileMutex.RLock()
+	defer fake.uploadFileMutex.RUnlock()
+	return fake.uploadFileArgsForCall[i].channelID, fake.uploadFileArgsForCall[i].filename, fake.uploadFileArgsForCall[i].content
+}
+
+func (fake *FakeSlackAPI) UploadFileReturns(result1 error) {
+	fake.UploadFileStub = nil
+	fake.uploadFileReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSlackAPI) GetConversations(types []string, excludeArchived bool) ([]slackapi.Conversation, error) {
+	fake.getConversationsMutex.Lock()
+	fake.getConversationsArgsForCall = append(fake.getConversationsArgsForCall, struct {
+		types           []string
 		excludeArchived bool
-	}{excludeArchived})
-	fake.getGroupsMutex.Unlock()
-	if fake.GetGroupsStub != nil {
-		return fake.GetGroupsStub(excludeArchived)
+	}{types, excludeArchived})
+	fake.getConversationsMutex.Unlock()
+	if fake.GetConversationsStub != nil {
+		return fake.GetConversationsStub(types, excludeArchived)
+	} else {
+		return fake.getConversationsReturns.result1, fake.getConversationsReturns.result2
+	}
+}
+
+func (fake *FakeSlackAPI) GetConversationsCallCount() int {
+	fake.getConversationsMutex.RLock()
+	defer fake.getConversationsMutex.RUnlock()
+	return len(fake.getConversationsArgsForCall)
+}
+
+func (fake *FakeSlackAPI) GetConversationsArgsForCall(i int) ([]string, bool) {
+	fake.getConversationsMutex.RLock()
+	defer fake.getConversationsMutex.RUnlock()
+	return fake.getConversationsArgsForCall[i].types, fake.getConversationsArgsForCall[i].excludeArchived
+}
+
+func (fake *FakeSlackAPI) GetConversationsReturns(result1 []slackapi.Conversation, result2 error) {
+	fake.GetConversationsStub = nil
+	fake.getConversationsReturns = struct {
+		result1 []slackapi.Conversation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSlackAPI) GetConversationInfo(conversationID string) (slackapi.Conversation, error) {
+	fake.getConversationInfoMutex.Lock()
+	fake.getConversationInfoArgsForCall = append(fake.getConversationInfoArgsForCall, struct {
+		conversationID string
+	}{conversationID})
+	fake.getConversationInfoMutex.Unlock()
+	if fake.GetConversationInfoStub != nil {
+		return fake.GetConversationInfoStub(conversationID)
 	} else {
-		return fake.getGroupsReturns.result1, fake.getGroupsReturns.result2
+		return fake.getConversationInfoReturns.result1, fake.getConversationInfoReturns.result2
 	}
 }
 
-func (fake *FakeSlackAPI) GetGroupsCallCount() int {
-	fake.getGroupsMutex.RLock()
-	defer fake.getGroupsMutex.RUnlock()
-	return len(fake.getGroupsArgsForCall)
+func (fake *FakeSlackAPI) GetConversationInfoCallCount() int {
+	fake.getConversationInfoMutex.RLock()
+	defer fake.getConversationInfoMutex.RUnlock()
+	return len(fake.getConversationInfoArgsForCall)
 }
 
-func (fake *FakeSlackAPI) GetGroupsArgsForCall(i int) bool {
-	fake.getGroupsMutex.RLock()
-	defer fake.getGroupsMutex.RUnlock()
-	return fake.getGroupsArgsForCall[i].excludeArchived
+func (fake *FakeSlackAPI) GetConversationInfoArgsForCall(i int) string {
+	fake.getConversationInfoMutex.RLock()
+	defer fake.getConversationInfoMutex.RUnlock()
+	return fake.getConversationInfoArgsForCall[i].conversationID
 }
 
-func (fake *FakeSlackAPI) GetGroupsReturns(result1 []slack.Group, result2 error) {
-	fake.GetGroupsStub = nil
-	fake.getGroupsReturns = struct {
-		result1 []slack.Group
+func (fake *FakeSlackAPI) GetConversationInfoReturns(result1 slackapi.Conversation, result2 error) {
+	fake.GetConversationInfoStub = nil
+	fake.getConversationInfoReturns = struct {
+		result1 slackapi.Conversation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSlackAPI) GetConversationMembers(conversationID string) ([]string, error) {
+	fake.getConversationMembersMutex.Lock()
+	fake.getConversationMembersArgsForCall = append(fake.getConversationMembersArgsForCall, struct {
+		conversationID string
+	}{conversationID})
+	fake.getConversationMembersMutex.Unlock()
+	if fake.GetConversationMembersStub != nil {
+		return fake.GetConversationMembersStub(conversationID)
+	} else {
+		return fake.getConversationMembersReturns.result1, fake.getConversationMembersReturns.result2
+	}
+}
+
+func (fake *FakeSlackAPI) GetConversationMembersCallCount() int {
+	fake.getConversationMembersMutex.RLock()
+	defer fake.getConversationMembersMutex.RUnlock()
+	return len(fake.getConversationMembersArgsForCall)
+}
+
+func (fake *FakeSlackAPI) GetConversationMembersArgsForCall(i int) string {
+	fake.getConversationMembersMutex.RLock()
+	defer fake.getConversationMembersMutex.RUnlock()
+	return fake.getConversationMembersArgsForCall[i].conversationID
+}
+
+func (fake *FakeSlackAPI) GetConversationMembersReturns(result1 []string, result2 error) {
+	fake.GetConversationMembersStub = nil
+	fake.getConversationMembersReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSlackAPI) InviteToConversation(conversationID string, userID string) error {
+	fake.inviteToConversationMutex.Lock()
+	fake.inviteToConversationArgsForCall = append(fake.inviteToConversationArgsForCall, struct {
+		conversationID string
+		userID         string
+	}{conversationID, userID})
+	fake.inviteToConversationMutex.Unlock()
+	if fake.InviteToConversationStub != nil {
+		return fake.InviteToConversationStub(conversationID, userID)
+	} else {
+		return fake.inviteToConversationReturns.result1
+	}
+}
+
+func (fake *FakeSlackAPI) InviteToConversationCallCount() int {
+	fake.inviteToConversationMutex.RLock()
+	defer fake.inviteToConversationMutex.RUnlock()
+	return len(fake.inviteToConversationArgsForCall)
+}
+
+func (fake *FakeSlackAPI) InviteToConversationArgsForCall(i int) (string, string) {
+	fake.inviteToConversationMutex.RLock()
+	defer fake.inviteToConversationMutex.RUnlock()
+	return fake.inviteToConversationArgsForCall[i].conversationID, fake.inviteToConversationArgsForCall[i].userID
+}
+
+func (fake *FakeSlackAPI) InviteToConversationReturns(result1 error) {
+	fake.InviteToConversationStub = nil
+	fake.inviteToConversationReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSlackAPI) GetUserConversations(userID string, types []string, excludeArchived bool) ([]slackapi.Conversation, error) {
+	fake.getUserConversationsMutex.Lock()
+	fake.getUserConversationsArgsForCall = append(fake.getUserConversationsArgsForCall, struct {
+		userID          string
+		types           []string
+		excludeArchived bool
+	}{userID, types, excludeArchived})
+	fake.getUserConversationsMutex.Unlock()
+	if fake.GetUserConversationsStub != nil {
+		return fake.GetUserConversationsStub(userID, types, excludeArchived)
+	} else {
+		return fake.getUserConversationsReturns.result1, fake.getUserConversationsReturns.result2
+	}
+}
+
+func (fake *FakeSlackAPI) GetUserConversationsCallCount() int {
+	fake.getUserConversationsMutex.RLock()
+	defer fake.getUserConversationsMutex.RUnlock()
+	return len(fake.getUserConversationsArgsForCall)
+}
+
+func (fake *FakeSlackAPI) GetUserConversationsArgsForCall(i int) (string, []string, bool) {
+	fake.getUserConversationsMutex.RLock()
+	defer fake.getUserConversationsMutex.RUnlock()
+	return fake.getUserConversationsArgsForCall[i].userID, fake.getUserConversationsArgsForCall[i].types, fake.getUserConversationsArgsForCall[i].excludeArchived
+}
+
+func (fake *FakeSlackAPI) GetUserConversationsReturns(result1 []slackapi.Conversation, result2 error) {
+	fake.GetUserConversationsStub = nil
+	fake.getUserConversationsReturns = struct {
+		result1 []slackapi.Conversation
 		result2 error
 	}{result1, result2}
 }
@@ -462,27 +788,94 @@ func (fake *FakeSlackAPI) GetUserInfoReturns(result1 *slack.User, result2 error)
 	}{result1, result2}
 }
 
-func (fake *FakeSlackAPI) GetUsers() ([]slack.User, error) {
-	fake.getUsersMutex.Lock()
-	fake.getUsersArgsForCall = append(fake.getUsersArgsForCall, struct{}{})
-	fake.getUsersMutex.Unlock()
-	if fake.GetUsersStub != nil {
-		return fake.GetUsersStub()
+func (fake *FakeSlackAPI) GetUsersPage(cursor string) (users []slack.User, nextCursor string, err error) {
+	fake.getUsersPageMutex.Lock()
+	fake.getUsersPageArgsForCall = append(fake.getUsersPageArgsForCall, struct {
+		cursor string
+	}{cursor})
+	fake.getUsersPageMutex.Unlock()
+	if fake.GetUsersPageStub != nil {
+		return fake.GetUsersPageStub(cursor)
 	} else {
-		return fake.getUsersReturns.result1, fake.getUsersReturns.result2
+		return fake.getUsersPageReturns.result1, fake.getUsersPageReturns.result2, fake.getUsersPageReturns.result3
 	}
 }
 
-func (fake *FakeSlackAPI) GetUsersCallCount() int {
-	fake.getUsersMutex.RLock()
-	defer fake.getUsersMutex.RUnlock()
-	return len(fake.getUsersArgsForCall)
+func (fake *FakeSlackAPI) GetUsersPageCallCount() int {
+	fake.getUsersPageMutex.RLock()
+	defer fake.getUsersPageMutex.RUnlock()
+	return len(fake.getUsersPageArgsForCall)
+}
+
+func (fake *FakeSlackAPI) GetUsersPageArgsForCall(i int) string {
+	fake.getUsersPageMutex.RLock()
+	defer fake.getUsersPageMutex.RUnlock()
+	return fake.getUsersPageArgsForCall[i].cursor
 }
 
-func (fake *FakeSlackAPI) GetUsersReturns(result1 []slack.User, result2 error) {
-	fake.GetUsersStub = nil
-	fake.getUsersReturns = struct {
+func (fake *FakeSlackAPI) GetUsersPageReturns(result1 []slack.User, result2 string, result3 error) {
+	fake.GetUsersPageStub = nil
+	fake.getUsersPageReturns = struct {
 		result1 []slack.User
+		result2 string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeSlackAPI) GetLastActivities() (map[string]time.Time, error) {
+	fake.getLastActivitiesMutex.Lock()
+	fake.getLastActivitiesArgsForCall = append(fake.getLastActivitiesArgsForCall, struct{}{})
+	fake.getLastActivitiesMutex.Unlock()
+	if fake.GetLastActivitiesStub != nil {
+		return fake.GetLastActivitiesStub()
+	} else {
+		return fake.getLastActivitiesReturns.result1, fake.getLastActivitiesReturns.result2
+	}
+}
+
+func (fake *FakeSlackAPI) GetLastActivitiesCallCount() int {
+	fake.getLastActivitiesMutex.RLock()
+	defer fake.getLastActivitiesMutex.RUnlock()
+	return len(fake.getLastActivitiesArgsForCall)
+}
+
+func (fake *FakeSlackAPI) GetLastActivitiesReturns(result1 map[string]time.Time, result2 error) {
+	fake.GetLastActivitiesStub = nil
+	fake.getLastActivitiesReturns = struct {
+		result1 map[string]time.Time
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSlackAPI) GetUserGroupMembers(userGroupID string) ([]string, error) {
+	fake.getUserGroupMembersMutex.Lock()
+	fake.getUserGroupMembersArgsForCall = append(fake.getUserGroupMembersArgsForCall, struct {
+		userGroupID string
+	}{userGroupID})
+	fake.getUserGroupMembersMutex.Unlock()
+	if fake.GetUserGroupMembersStub != nil {
+		return fake.GetUserGroupMembersStub(userGroupID)
+	} else {
+		return fake.getUserGroupMembersReturns.result1, fake.getUserGroupMembersReturns.result2
+	}
+}
+
+func (fake *FakeSlackAPI) GetUserGroupMembersCallCount() int {
+	fake.getUserGroupMembersMutex.RLock()
+	defer fake.getUserGroupMembersMutex.RUnlock()
+	return len(fake.getUserGroupMembersArgsForCall)
+}
+
+func (fake *FakeSlackAPI) GetUserGroupMembersArgsForCall(i int) string {
+	fake.getUserGroupMembersMutex.RLock()
+	defer fake.getUserGroupMembersMutex.RUnlock()
+	return fake.getUserGroupMembersArgsForCall[i].userGroupID
+}
+
+func (fake *FakeSlackAPI) GetUserGroupMembersReturns(result1 []string, result2 error) {
+	fake.GetUserGroupMembersStub = nil
+	fake.getUserGroupMembersReturns = struct {
+		result1 []string
 		result2 error
 	}{result1, result2}
 }
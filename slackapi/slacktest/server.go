@@ -0,0 +1,337 @@
+// Package slacktest provides a fake of Slack's Web API, serving a workspace
+// held in memory, so that Goulash can be tested and tried out end to end
+// without Slack.
+package slacktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/slack"
+)
+
+// defaultPageSize is the most results a method returns at once unless the
+// request asks for fewer.
+const defaultPageSize = 100
+
+// Server is a fake of Slack's Web API for a single workspace. It knows the
+// workspace's users, public and private channels, direct messages and user
+// groups, and records the messages, files and invitations sent to it.
+// Requests are authenticated with the token given to NewServer, and act as
+// the user it belongs to.
+type Server struct {
+	server *httptest.Server
+	token  string
+	userID string
+
+	mutex         sync.Mutex
+	pageSize      int
+	users         []slack.User
+	conversations []*conversation
+	userGroups    map[string][]string
+	lastActivity  map[string]time.Time
+	messages      []Message
+	files         []File
+	invites       []Invite
+	responses     []slackapi.Message
+	requests      []string
+	sequence      int
+}
+
+type conversation struct {
+	slackapi.Conversation
+	members []string
+}
+
+// Message is a message posted to a channel or direct message.
+type Message struct {
+	slackapi.Message
+
+	Channel   string
+	Timestamp string
+	User      string
+}
+
+// File is a file shared in a channel or direct message.
+type File struct {
+	Channel  string
+	Filename string
+	Content  string
+}
+
+// Invite is an invitation to join the workspace.
+type Invite struct {
+	Email           string
+	FirstName       string
+	LastName        string
+	Channels        []string
+	Restricted      bool
+	UltraRestricted bool
+}
+
+// NewServer starts a Server for a workspace in which the given token belongs
+// to the user with the given ID. The user must be added with AddUser, and
+// must be an admin or owner for the admin methods to succeed.
+func NewServer(token string, userID string) *Server {
+	s := &Server{
+		token:        token,
+		userID:       userID,
+		pageSize:     defaultPageSize,
+		userGroups:   map[string][]string{},
+		lastActivity: map[string]time.Time{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/", s.serveMethod)
+	mux.HandleFunc("/response", s.serveResponse)
+	s.server = httptest.NewServer(mux)
+
+	return s
+}
+
+// URL returns the base URL of the Server's Web API, to be given to
+// slackapi.New.
+func (s *Server) URL() string {
+	return s.server.URL + "/api/"
+}
+
+// ResponseURL returns a URL to give as a Slash Command's response_url, to
+// which responses are recorded.
+func (s *Server) ResponseURL() string {
+	return s.server.URL + "/response"
+}
+
+// Close shuts the Server down.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Transport returns an http.RoundTripper which sends requests for slack.com,
+// including a team's own subdomain, to the Server, and any others on as
+// http.DefaultTransport would. github.com/pivotalservices/slack always calls
+// slack.com, so http.DefaultTransport must be set to it for requests made
+// through that package to reach the Server.
+func (s *Server) Transport() http.RoundTripper {
+	return &transport{
+		host: strings.TrimPrefix(s.server.URL, "http://"),
+		next: http.DefaultTransport,
+	}
+}
+
+// SetPageSize sets the most results a method returns at once, so that
+// paging through them can be tested with only a few.
+func (s *Server) SetPageSize(pageSize int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pageSize = pageSize
+}
+
+// AddUser adds the given user to the workspace. Their role is given by
+// IsAdmin, IsOwner, IsRestricted and IsUltraRestricted, and they are disabled
+// if Deleted is true.
+func (s *Server) AddUser(user slack.User) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.users = append(s.users, user)
+}
+
+// AddConversation adds the given public or private channel, direct message
+// or group direct message to the workspace, with the given members. Whether
+// the user the token belongs to is a member is worked out from members.
+func (s *Server) AddConversation(c slackapi.Conversation, members ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.conversations = append(s.conversations, &conversation{
+		Conversation: c,
+		members:      members,
+	})
+}
+
+// AddUserGroup adds a user group with the given members to the workspace.
+func (s *Server) AddUserGroup(userGroupID string, members ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.userGroups[userGroupID] = members
+}
+
+// SetLastActivity sets when the given user was last active.
+func (s *Server) SetLastActivity(userID string, lastActivity time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.lastActivity[userID] = lastActivity
+}
+
+// User returns the user with the given ID as they now are.
+func (s *Server) User(userID string) (slack.User, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if user := s.user(userID); user != nil {
+		return *user, true
+	}
+
+	return slack.User{}, false
+}
+
+// Members returns the IDs of the members of the given conversation.
+func (s *Server) Members(conversationID string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if c := s.conversation(conversationID); c != nil {
+		return append([]string{}, c.members...)
+	}
+
+	return nil
+}
+
+// Messages returns the messages posted to the given conversation, oldest
+// first.
+func (s *Server) Messages(conversationID string) []Message {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var messages []Message
+	for _, message := range s.messages {
+		if message.Channel == conversationID {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages
+}
+
+// DirectMessages returns the messages sent to the given user in their direct
+// message with the user the token belongs to.
+func (s *Server) DirectMessages(userID string) []Message {
+	s.mutex.Lock()
+	var id string
+	for _, c := range s.conversations {
+		if c.IsIM && c.User == userID {
+			id = c.ID
+		}
+	}
+	s.mutex.Unlock()
+
+	if id == "" {
+		return nil
+	}
+
+	return s.Messages(id)
+}
+
+// Files returns the files shared, oldest first.
+func (s *Server) Files() []File {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]File{}, s.files...)
+}
+
+// Invites returns the invitations sent, oldest first.
+func (s *Server) Invites() []Invite {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]Invite{}, s.invites...)
+}
+
+// Responses returns the messages posted to ResponseURL, oldest first.
+func (s *Server) Responses() []slackapi.Message {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]slackapi.Message{}, s.responses...)
+}
+
+// Requests returns the names of the Web API methods called, in order.
+func (s *Server) Requests() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]string{}, s.requests...)
+}
+
+func (s *Server) serveResponse(w http.ResponseWriter, r *http.Request) {
+	var message slackapi.Message
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	s.responses = append(s.responses, message)
+	s.mutex.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// user returns the user with the given ID, or nil if there is none. The
+// mutex must be held.
+func (s *Server) user(userID string) *slack.User {
+	for i := range s.users {
+		if s.users[i].ID == userID {
+			return &s.users[i]
+		}
+	}
+
+	return nil
+}
+
+// conversation returns the conversation with the given ID, or nil if there
+// is none. The mutex must be held.
+func (s *Server) conversation(conversationID string) *conversation {
+	for _, c := range s.conversations {
+		if c.ID == conversationID {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// next returns a new number, from which IDs and timestamps are made. The
+// mutex must be held.
+func (s *Server) next() int {
+	s.sequence++
+	return s.sequence
+}
+
+// timestamp returns a new timestamp identifying a message. The mutex must be
+// held.
+func (s *Server) timestamp() string {
+	return fmt.Sprintf("%d.%06d", time.Now().Unix(), s.next())
+}
+
+type transport struct {
+	host string
+	next http.RoundTripper
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	host := r.URL.Host
+	if host != "slack.com" && !strings.HasSuffix(host, ".slack.com") {
+		return t.next.RoundTrip(r)
+	}
+
+	redirectedURL := *r.URL
+	redirectedURL.Scheme = "http"
+	redirectedURL.Host = t.host
+
+	redirected := *r
+	redirected.URL = &redirectedURL
+	redirected.Host = t.host
+
+	return t.next.RoundTrip(&redirected)
+}
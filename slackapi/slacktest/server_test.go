@@ -0,0 +1,245 @@
+package slacktest_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slacktest"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Server", func() {
+	var (
+		server            *slacktest.Server
+		originalTransport http.RoundTripper
+		api               slackapi.SlackAPI
+	)
+
+	BeforeEach(func() {
+		server = slacktest.NewServer("xoxp-token", "U0BOT")
+		server.AddUser(slack.User{ID: "U0BOT", Name: "goulash", IsAdmin: true})
+		server.AddUser(slack.User{ID: "U1", Name: "full", Profile: slack.UserProfile{Email: "full@example.com"}})
+		server.AddUser(slack.User{ID: "U2", Name: "restricted", IsRestricted: true})
+		server.AddUser(slack.User{ID: "U3", Name: "guest", IsUltraRestricted: true})
+		server.AddConversation(slackapi.Conversation{ID: "C1", Name: "general", IsChannel: true}, "U0BOT", "U1", "U2")
+		server.AddConversation(slackapi.Conversation{ID: "C2", Name: "outside", IsChannel: true}, "U1")
+		server.AddConversation(slackapi.Conversation{ID: "G1", Name: "secret", IsPrivate: true}, "U0BOT", "U3")
+		server.AddConversation(slackapi.Conversation{ID: "G2", Name: "hidden", IsPrivate: true}, "U1")
+
+		originalTransport = http.DefaultTransport
+		http.DefaultTransport = server.Transport()
+
+		api = slackapi.New("xoxp-token", server.URL())
+	})
+
+	AfterEach(func() {
+		http.DefaultTransport = originalTransport
+		server.Close()
+	})
+
+	It("rejects requests with the wrong token", func() {
+		_, err := slackapi.New("xoxp-wrong", server.URL()).GetUserInfo("U1")
+		Ω(slackapi.ErrorCode(err)).Should(Equal(slackapi.ErrorInvalidAuth))
+	})
+
+	Describe("users", func() {
+		It("looks up users", func() {
+			user, err := api.GetUserInfo("U2")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(user.Name).Should(Equal("restricted"))
+			Ω(user.IsRestricted).Should(BeTrue())
+
+			_, err = api.GetUserInfo("U9")
+			Ω(slackapi.ErrorCode(err)).Should(Equal(slackapi.ErrorUserNotFound))
+		})
+
+		It("returns users a page at a time", func() {
+			server.SetPageSize(3)
+
+			users, err := slackapi.AllUsers(api)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(users).Should(HaveLen(4))
+			Ω(users[3].ID).Should(Equal("U3"))
+
+			Ω(server.Requests()).Should(Equal([]string{"users.list", "users.list"}))
+		})
+
+		It("returns when users were last active", func() {
+			lastActive := time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC)
+			server.SetLastActivity("U1", lastActive)
+
+			server.SetLastActivity("U2", lastActive.Add(time.Hour))
+			server.SetPageSize(1)
+
+			lastActivities, err := api.GetLastActivities()
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(lastActivities).Should(HaveLen(2))
+			Ω(lastActivities["U1"].Equal(lastActive)).Should(BeTrue())
+			Ω(server.Requests()).Should(Equal([]string{"team.accessLogs", "team.accessLogs"}))
+		})
+
+		It("returns the members of user groups", func() {
+			server.AddUserGroup("S1", "U1", "U2")
+
+			members, err := api.GetUserGroupMembers("S1")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(members).Should(Equal([]string{"U1", "U2"}))
+		})
+	})
+
+	Describe("conversations", func() {
+		It("lists the channels the user the token belongs to can see", func() {
+			conversations, err := api.GetConversations([]string{slackapi.PublicChannel, slackapi.PrivateChannel}, true)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(conversations).Should(HaveLen(3))
+			Ω(conversations[0].ID).Should(Equal("C1"))
+			Ω(conversations[0].IsMember).Should(BeTrue())
+			Ω(conversations[1].ID).Should(Equal("C2"))
+			Ω(conversations[1].IsMember).Should(BeFalse())
+			Ω(conversations[2].ID).Should(Equal("G1"))
+		})
+
+		It("does not show private channels to non-members", func() {
+			_, err := api.GetConversationInfo("G2")
+			Ω(slackapi.ErrorCode(err)).Should(Equal(slackapi.ErrorChannelNotFound))
+		})
+
+		It("returns the members of channels", func() {
+			server.SetPageSize(2)
+
+			members, err := api.GetConversationMembers("C1")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(members).Should(Equal([]string{"U0BOT", "U1", "U2"}))
+		})
+
+		It("lists the channels a user is in which the user the token belongs to can see", func() {
+			conversations, err := api.GetUserConversations("U1", []string{slackapi.PublicChannel, slackapi.PrivateChannel}, true)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(conversations).Should(HaveLen(2))
+			Ω(conversations[0].ID).Should(Equal("C1"))
+			Ω(conversations[1].ID).Should(Equal("C2"))
+
+			_, err = api.GetUserConversations("U9", []string{slackapi.PublicChannel}, true)
+			Ω(slackapi.ErrorCode(err)).Should(Equal(slackapi.ErrorUserNotFound))
+		})
+
+		It("invites users to channels", func() {
+			Ω(api.InviteToConversation("G1", "U1")).Should(Succeed())
+			Ω(server.Members("G1")).Should(ContainElement("U1"))
+
+			err := api.InviteToConversation("G1", "U1")
+			Ω(slackapi.ErrorCode(err)).Should(Equal(slackapi.ErrorAlreadyInChannel))
+
+			err = api.InviteToConversation("C2", "U2")
+			Ω(slackapi.ErrorCode(err)).Should(Equal(slackapi.ErrorNotInChannel))
+		})
+
+		It("does not invite Single-Channel Guests to a second channel", func() {
+			err := api.InviteToConversation("C1", "U3")
+			Ω(err).Should(MatchError("ura_max_channels"))
+			Ω(server.Members("C1")).ShouldNot(ContainElement("U3"))
+		})
+	})
+
+	Describe("messages", func() {
+		It("records messages sent and updated", func() {
+			timestamp, err := api.SendMessage("C1", slackapi.Message{Text: "hello"})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			Ω(api.UpdateMessage("C1", timestamp, slackapi.Message{Text: "goodbye"})).Should(Succeed())
+
+			messages := server.Messages("C1")
+			Ω(messages).Should(HaveLen(1))
+			Ω(messages[0].Text).Should(Equal("goodbye"))
+			Ω(messages[0].Timestamp).Should(Equal(timestamp))
+			Ω(messages[0].User).Should(Equal("U0BOT"))
+		})
+
+		It("does not post to channels the user the token belongs to is not in", func() {
+			_, err := api.SendMessage("C2", slackapi.Message{Text: "hello"})
+			Ω(slackapi.ErrorCode(err)).Should(Equal(slackapi.ErrorNotInChannel))
+		})
+
+		It("records direct messages", func() {
+			_, alreadyOpen, channelID, err := api.OpenIMChannel("U1")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(alreadyOpen).Should(BeFalse())
+
+			_, err = api.SendMessage(channelID, slackapi.Message{Text: "hello"})
+			Ω(err).ShouldNot(HaveOccurred())
+
+			_, alreadyOpen, reopenedID, err := api.OpenIMChannel("U1")
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(alreadyOpen).Should(BeTrue())
+			Ω(reopenedID).Should(Equal(channelID))
+
+			messages := server.DirectMessages("U1")
+			Ω(messages).Should(HaveLen(1))
+			Ω(messages[0].Text).Should(Equal("hello"))
+		})
+
+		It("records files uploaded", func() {
+			Ω(api.UploadFile("C1", "users.csv", "U1,full")).Should(Succeed())
+			Ω(server.Files()).Should(Equal([]slacktest.File{
+				{Channel: "C1", Filename: "users.csv", Content: "U1,full"},
+			}))
+		})
+	})
+
+	Describe("admin methods", func() {
+		It("records invitations", func() {
+			Ω(api.InviteGuest("team-name", "C1", "Tom", "Thumb", "tom@example.com")).Should(Succeed())
+			Ω(api.InviteRestricted("team-name", "C1", "Ann", "Bee", "ann@example.com")).Should(Succeed())
+
+			Ω(server.Invites()).Should(Equal([]slacktest.Invite{
+				{Email: "tom@example.com", FirstName: "Tom", LastName: "Thumb", Channels: []string{"C1"}, UltraRestricted: true},
+				{Email: "ann@example.com", FirstName: "Ann", LastName: "Bee", Channels: []string{"C1"}, Restricted: true},
+			}))
+
+			err := api.InviteGuest("team-name", "C1", "Tom", "Thumb", "tom@example.com")
+			Ω(slackapi.ErrorCode(err)).Should(Equal(slackapi.ErrorAlreadyInvited))
+
+			err = api.InviteGuest("team-name", "C1", "Full", "User", "full@example.com")
+			Ω(slackapi.ErrorCode(err)).Should(Equal(slackapi.ErrorAlreadyInTeam))
+		})
+
+		It("disables and enables users", func() {
+			Ω(api.DisableUser("team-name", "U1")).Should(Succeed())
+			user, _ := server.User("U1")
+			Ω(user.Deleted).Should(BeTrue())
+
+			Ω(api.EnableUser("team-name", "U1")).Should(Succeed())
+			user, _ = server.User("U1")
+			Ω(user.Deleted).Should(BeFalse())
+			Ω(user.IsRestricted).Should(BeTrue())
+		})
+
+		It("leaves Single-Channel Guests in only their channel", func() {
+			Ω(api.SetUltraRestricted("team-name", "U2", "G1")).Should(Succeed())
+
+			user, _ := server.User("U2")
+			Ω(user.IsUltraRestricted).Should(BeTrue())
+			Ω(user.IsRestricted).Should(BeFalse())
+			Ω(server.Members("C1")).ShouldNot(ContainElement("U2"))
+			Ω(server.Members("G1")).Should(ContainElement("U2"))
+		})
+
+		It("only lets admins and owners call them", func() {
+			other := slacktest.NewServer("xoxp-member", "U1")
+			defer other.Close()
+			other.AddUser(slack.User{ID: "U1"})
+			other.AddUser(slack.User{ID: "U2"})
+			http.DefaultTransport = other.Transport()
+
+			err := slackapi.New("xoxp-member", other.URL()).DisableUser("team-name", "U2")
+			Ω(slackapi.ErrorCode(err)).Should(Equal("not_authorized"))
+
+			user, _ := other.User("U2")
+			Ω(user.Deleted).Should(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package slacktest_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSlacktest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Slacktest Suite")
+}
@@ -0,0 +1,645 @@
+package slacktest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/slack"
+)
+
+// method handles a request to a Web API method, returning the fields to
+// respond with alongside "ok", or the code of the error to respond with. The
+// mutex is held.
+type method func(s *Server, r *http.Request) (map[string]interface{}, string)
+
+// methods are the Web API methods served, by name. See
+// https://api.slack.com/methods for more information.
+var methods = map[string]method{
+	"chat.postMessage":               (*Server).postMessage,
+	"chat.update":                    (*Server).updateMessage,
+	"conversations.info":             (*Server).conversationInfo,
+	"conversations.invite":           (*Server).inviteToConversation,
+	"conversations.list":             (*Server).listConversations,
+	"conversations.members":          (*Server).conversationMembers,
+	"files.upload":                   (*Server).uploadFile,
+	"im.open":                        (*Server).openIM,
+	"team.accessLogs":                (*Server).accessLogs,
+	"usergroups.users.list":          (*Server).userGroupMembers,
+	"users.conversations":            (*Server).userConversations,
+	"users.admin.invite":             (*Server).invite,
+	"users.admin.setInactive":        (*Server).setInactive,
+	"users.admin.setRestricted":      (*Server).setRestricted,
+	"users.admin.setUltraRestricted": (*Server).setUltraRestricted,
+	"users.info":                     (*Server).userInfo,
+	"users.list":                     (*Server).listUsers,
+}
+
+// adminMethods may only be called by admins and owners.
+var adminMethods = map[string]bool{
+	"team.accessLogs":                true,
+	"users.admin.invite":             true,
+	"users.admin.setInactive":        true,
+	"users.admin.setRestricted":      true,
+	"users.admin.setUltraRestricted": true,
+}
+
+func (s *Server) serveMethod(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/api/")
+
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	s.requests = append(s.requests, name)
+	result, code := s.call(name, r)
+	s.mutex.Unlock()
+
+	body := map[string]interface{}{}
+	for key, value := range result {
+		body[key] = value
+	}
+	body["ok"] = code == ""
+	if code != "" {
+		body["error"] = code
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+// call authenticates the request and calls the named method. The mutex must
+// be held.
+func (s *Server) call(name string, r *http.Request) (map[string]interface{}, string) {
+	m, ok := methods[name]
+	if !ok {
+		return nil, "unknown_method"
+	}
+
+	switch token := r.Form.Get("token"); {
+	case token == "":
+		return nil, slackapi.ErrorNotAuthed
+	case token != s.token:
+		return nil, slackapi.ErrorInvalidAuth
+	}
+
+	caller := s.user(s.userID)
+	if caller == nil || caller.Deleted {
+		return nil, slackapi.ErrorAccountInactive
+	}
+
+	if adminMethods[name] && !caller.IsAdmin && !caller.IsOwner {
+		return nil, "not_authorized"
+	}
+
+	return m(s, r)
+}
+
+func (s *Server) postMessage(r *http.Request) (map[string]interface{}, string) {
+	c := s.conversation(r.Form.Get("channel"))
+	if c == nil || !s.visible(c) {
+		return nil, slackapi.ErrorChannelNotFound
+	}
+
+	if c.IsArchived {
+		return nil, slackapi.ErrorIsArchived
+	}
+
+	if !c.IsIM && !s.isMember(c) {
+		return nil, slackapi.ErrorNotInChannel
+	}
+
+	message, code := formMessage(r)
+	if code != "" {
+		return nil, code
+	}
+
+	posted := Message{
+		Message:   message,
+		Channel:   c.ID,
+		Timestamp: s.timestamp(),
+		User:      s.userID,
+	}
+	s.messages = append(s.messages, posted)
+
+	return map[string]interface{}{
+		"channel": posted.Channel,
+		"ts":      posted.Timestamp,
+	}, ""
+}
+
+func (s *Server) updateMessage(r *http.Request) (map[string]interface{}, string) {
+	channelID, timestamp := r.Form.Get("channel"), r.Form.Get("ts")
+
+	for i := range s.messages {
+		if s.messages[i].Channel != channelID || s.messages[i].Timestamp != timestamp {
+			continue
+		}
+
+		message, code := formMessage(r)
+		if code != "" {
+			return nil, code
+		}
+
+		s.messages[i].Message = message
+
+		return map[string]interface{}{
+			"channel": channelID,
+			"ts":      timestamp,
+		}, ""
+	}
+
+	return nil, "message_not_found"
+}
+
+func (s *Server) conversationInfo(r *http.Request) (map[string]interface{}, string) {
+	c := s.conversation(r.Form.Get("channel"))
+	if c == nil || !s.visible(c) {
+		return nil, slackapi.ErrorChannelNotFound
+	}
+
+	return map[string]interface{}{"channel": s.view(c)}, ""
+}
+
+func (s *Server) inviteToConversation(r *http.Request) (map[string]interface{}, string) {
+	c := s.conversation(r.Form.Get("channel"))
+	if c == nil || !s.visible(c) {
+		return nil, slackapi.ErrorChannelNotFound
+	}
+
+	switch {
+	case c.IsArchived:
+		return nil, slackapi.ErrorIsArchived
+	case c.IsIM || c.IsMpIM:
+		return nil, "method_not_supported_for_channel_type"
+	case !s.isMember(c):
+		return nil, slackapi.ErrorNotInChannel
+	}
+
+	for _, userID := range strings.Split(r.Form.Get("users"), ",") {
+		user := s.user(userID)
+		switch {
+		case user == nil, user.Deleted:
+			return nil, slackapi.ErrorUserNotFound
+		case contains(c.members, userID):
+			return nil, slackapi.ErrorAlreadyInChannel
+		case user.IsUltraRestricted && len(s.memberships(userID)) > 0:
+			return nil, "ura_max_channels"
+		}
+
+		c.members = append(c.members, userID)
+	}
+
+	return map[string]interface{}{"channel": s.view(c)}, ""
+}
+
+func (s *Server) listConversations(r *http.Request) (map[string]interface{}, string) {
+	return s.conversationsPage(r, func(c *conversation) bool { return true })
+}
+
+func (s *Server) userConversations(r *http.Request) (map[string]interface{}, string) {
+	userID := r.Form.Get("user")
+	if userID == "" {
+		userID = s.userID
+	}
+	if s.user(userID) == nil {
+		return nil, slackapi.ErrorUserNotFound
+	}
+
+	return s.conversationsPage(r, func(c *conversation) bool { return contains(c.members, userID) })
+}
+
+// conversationsPage returns a page of the conversations of the request's
+// types which the user the token belongs to can see and which are included.
+func (s *Server) conversationsPage(r *http.Request, include func(c *conversation) bool) (map[string]interface{}, string) {
+	types := map[string]bool{}
+	for _, t := range strings.Split(r.Form.Get("types"), ",") {
+		if t != "" {
+			types[t] = true
+		}
+	}
+	if len(types) == 0 {
+		types[slackapi.PublicChannel] = true
+	}
+
+	excludeArchived, _ := strconv.ParseBool(r.Form.Get("exclude_archived"))
+
+	var conversations []slackapi.Conversation
+	for _, c := range s.conversations {
+		if !types[conversationType(c)] || !s.visible(c) || (excludeArchived && c.IsArchived) || !include(c) {
+			continue
+		}
+
+		conversations = append(conversations, s.view(c))
+	}
+
+	start, end, nextCursor, code := s.page(r, len(conversations))
+	if code != "" {
+		return nil, code
+	}
+
+	return map[string]interface{}{
+		"channels":          nonNil(conversations[start:end]),
+		"response_metadata": map[string]string{"next_cursor": nextCursor},
+	}, ""
+}
+
+func (s *Server) conversationMembers(r *http.Request) (map[string]interface{}, string) {
+	c := s.conversation(r.Form.Get("channel"))
+	if c == nil || !s.visible(c) {
+		return nil, slackapi.ErrorChannelNotFound
+	}
+
+	start, end, nextCursor, code := s.page(r, len(c.members))
+	if code != "" {
+		return nil, code
+	}
+
+	return map[string]interface{}{
+		"members":           append([]string{}, c.members[start:end]...),
+		"response_metadata": map[string]string{"next_cursor": nextCursor},
+	}, ""
+}
+
+func (s *Server) uploadFile(r *http.Request) (map[string]interface{}, string) {
+	var channelIDs []string
+	for _, channelID := range strings.Split(r.Form.Get("channels"), ",") {
+		if channelID == "" {
+			continue
+		}
+
+		if c := s.conversation(channelID); c == nil || !s.visible(c) {
+			return nil, slackapi.ErrorChannelNotFound
+		}
+
+		channelIDs = append(channelIDs, channelID)
+	}
+
+	id := fmt.Sprintf("F%08d", s.next())
+	for _, channelID := range channelIDs {
+		s.files = append(s.files, File{
+			Channel:  channelID,
+			Filename: r.Form.Get("filename"),
+			Content:  r.Form.Get("content"),
+		})
+	}
+
+	return map[string]interface{}{
+		"file": map[string]string{"id": id, "name": r.Form.Get("filename")},
+	}, ""
+}
+
+func (s *Server) openIM(r *http.Request) (map[string]interface{}, string) {
+	userID := r.Form.Get("user")
+
+	user := s.user(userID)
+	if user == nil || user.Deleted {
+		return nil, slackapi.ErrorUserNotFound
+	}
+
+	for _, c := range s.conversations {
+		if c.IsIM && c.User == userID {
+			return map[string]interface{}{
+				"no_op":        true,
+				"already_open": true,
+				"channel":      map[string]string{"id": c.ID},
+			}, ""
+		}
+	}
+
+	c := &conversation{
+		Conversation: slackapi.Conversation{
+			ID:   fmt.Sprintf("D%08d", s.next()),
+			IsIM: true,
+			User: userID,
+		},
+		members: []string{s.userID, userID},
+	}
+	s.conversations = append(s.conversations, c)
+
+	return map[string]interface{}{
+		"channel": map[string]string{"id": c.ID},
+	}, ""
+}
+
+func (s *Server) userGroupMembers(r *http.Request) (map[string]interface{}, string) {
+	members, ok := s.userGroups[r.Form.Get("usergroup")]
+	if !ok {
+		return nil, "no_such_subteam"
+	}
+
+	return map[string]interface{}{"users": append([]string{}, members...)}, ""
+}
+
+func (s *Server) invite(r *http.Request) (map[string]interface{}, string) {
+	email := r.Form.Get("email")
+	if !strings.Contains(email, "@") {
+		return nil, slackapi.ErrorInvalidEmail
+	}
+
+	for _, user := range s.users {
+		if strings.EqualFold(user.Profile.Email, email) {
+			return nil, slackapi.ErrorAlreadyInTeam
+		}
+	}
+
+	for _, invite := range s.invites {
+		if strings.EqualFold(invite.Email, email) {
+			return nil, slackapi.ErrorAlreadyInvited
+		}
+	}
+
+	var channels []string
+	for _, channelID := range strings.Split(r.Form.Get("channels"), ",") {
+		if channelID == "" {
+			continue
+		}
+
+		if c := s.conversation(channelID); c == nil || !s.visible(c) {
+			return nil, slackapi.ErrorChannelNotFound
+		}
+
+		channels = append(channels, channelID)
+	}
+
+	invite := Invite{
+		Email:           email,
+		FirstName:       r.Form.Get("first_name"),
+		LastName:        r.Form.Get("last_name"),
+		Channels:        channels,
+		Restricted:      r.Form.Get("restricted") == "1",
+		UltraRestricted: r.Form.Get("ultra_restricted") == "1",
+	}
+
+	if invite.UltraRestricted && len(channels) != 1 {
+		return nil, "ura_max_channels"
+	}
+
+	s.invites = append(s.invites, invite)
+
+	return nil, ""
+}
+
+func (s *Server) setInactive(r *http.Request) (map[string]interface{}, string) {
+	user := s.user(r.Form.Get("user"))
+	switch {
+	case user == nil:
+		return nil, slackapi.ErrorUserNotFound
+	case user.IsOwner:
+		return nil, "cant_disable_owner"
+	}
+
+	user.Deleted = true
+
+	return nil, ""
+}
+
+func (s *Server) setRestricted(r *http.Request) (map[string]interface{}, string) {
+	user := s.user(r.Form.Get("user"))
+	if user == nil {
+		return nil, slackapi.ErrorUserNotFound
+	}
+
+	if code := s.setRole(user, r); code != "" {
+		return nil, code
+	}
+
+	user.IsRestricted = true
+	user.IsUltraRestricted = false
+
+	return nil, ""
+}
+
+func (s *Server) setUltraRestricted(r *http.Request) (map[string]interface{}, string) {
+	user := s.user(r.Form.Get("user"))
+	if user == nil {
+		return nil, slackapi.ErrorUserNotFound
+	}
+
+	channelID := r.Form.Get("channel")
+	c := s.conversation(channelID)
+	if c == nil || !s.visible(c) {
+		return nil, slackapi.ErrorChannelNotFound
+	}
+
+	if code := s.setRole(user, r); code != "" {
+		return nil, code
+	}
+
+	// A Single-Channel Guest is only in the one channel.
+	for _, other := range s.conversations {
+		if other != c && !other.IsIM {
+			other.members = remove(other.members, user.ID)
+		}
+	}
+	if !contains(c.members, user.ID) {
+		c.members = append(c.members, user.ID)
+	}
+
+	user.IsRestricted = false
+	user.IsUltraRestricted = true
+
+	return nil, ""
+}
+
+// setRole checks that the user's role can be changed, reactivating them if
+// they are disabled and the request sets them active.
+func (s *Server) setRole(user *slack.User, r *http.Request) string {
+	if user.IsAdmin || user.IsOwner {
+		return slackapi.ErrorRestrictedAction
+	}
+
+	if user.Deleted {
+		if setActive, _ := strconv.ParseBool(r.Form.Get("set_active")); !setActive {
+			return slackapi.ErrorUserDisabled
+		}
+		user.Deleted = false
+	}
+
+	return ""
+}
+
+// accessLogs returns a login for each user with a last activity, a page at a
+// time by page number.
+func (s *Server) accessLogs(r *http.Request) (map[string]interface{}, string) {
+	var userIDs []string
+	for userID := range s.lastActivity {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+
+	page := 1
+	if requested, err := strconv.Atoi(r.Form.Get("page")); err == nil && requested > 0 {
+		page = requested
+	}
+
+	pages := (len(userIDs) + s.pageSize - 1) / s.pageSize
+	if pages == 0 {
+		pages = 1
+	}
+
+	logins := []map[string]interface{}{}
+	for i := (page - 1) * s.pageSize; i < len(userIDs) && i < page*s.pageSize; i++ {
+		logins = append(logins, map[string]interface{}{
+			"user_id":   userIDs[i],
+			"date_last": s.lastActivity[userIDs[i]].Unix(),
+		})
+	}
+
+	return map[string]interface{}{
+		"logins": logins,
+		"paging": map[string]int{"page": page, "pages": pages, "total": len(userIDs)},
+	}, ""
+}
+
+func (s *Server) userInfo(r *http.Request) (map[string]interface{}, string) {
+	user := s.user(r.Form.Get("user"))
+	if user == nil {
+		return nil, slackapi.ErrorUserNotFound
+	}
+
+	return map[string]interface{}{"user": *user}, ""
+}
+
+func (s *Server) listUsers(r *http.Request) (map[string]interface{}, string) {
+	start, end, nextCursor, code := s.page(r, len(s.users))
+	if code != "" {
+		return nil, code
+	}
+
+	return map[string]interface{}{
+		"members":           append([]slack.User{}, s.users[start:end]...),
+		"response_metadata": map[string]string{"next_cursor": nextCursor},
+	}, ""
+}
+
+// page returns the range of the total results to return for the request's
+// cursor and limit, and the cursor of the next page, or "" if there is none.
+func (s *Server) page(r *http.Request, total int) (int, int, string, string) {
+	start := 0
+	if cursor := r.Form.Get("cursor"); cursor != "" {
+		decoded, err := base64.StdEncoding.DecodeString(cursor)
+		if err == nil {
+			start, err = strconv.Atoi(strings.TrimPrefix(string(decoded), "offset:"))
+		}
+		if err != nil || start < 0 || start > total {
+			return 0, 0, "", "invalid_cursor"
+		}
+	}
+
+	limit := s.pageSize
+	if requested, err := strconv.Atoi(r.Form.Get("limit")); err == nil && requested > 0 && requested < limit {
+		limit = requested
+	}
+
+	end := start + limit
+	if end >= total {
+		return start, total, "", ""
+	}
+
+	return start, end, base64.StdEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(end))), ""
+}
+
+// visible returns true if the user the token belongs to can see the
+// conversation: any public channel, and the private channels and direct
+// messages they are in.
+func (s *Server) visible(c *conversation) bool {
+	return conversationType(c) == slackapi.PublicChannel || s.isMember(c)
+}
+
+func (s *Server) isMember(c *conversation) bool {
+	return contains(c.members, s.userID)
+}
+
+// view returns the conversation as the user the token belongs to sees it.
+func (s *Server) view(c *conversation) slackapi.Conversation {
+	conversation := c.Conversation
+	conversation.IsMember = s.isMember(c)
+	return conversation
+}
+
+// memberships returns the channels the given user is a member of, not
+// counting direct messages.
+func (s *Server) memberships(userID string) []*conversation {
+	var memberships []*conversation
+	for _, c := range s.conversations {
+		if !c.IsIM && !c.IsMpIM && contains(c.members, userID) {
+			memberships = append(memberships, c)
+		}
+	}
+
+	return memberships
+}
+
+func conversationType(c *conversation) string {
+	switch {
+	case c.IsIM:
+		return slackapi.DirectMessage
+	case c.IsMpIM:
+		return slackapi.GroupDirectMessage
+	case c.IsPrivate:
+		return slackapi.PrivateChannel
+	default:
+		return slackapi.PublicChannel
+	}
+}
+
+// formMessage returns the message given by the request's text, attachments
+// and blocks.
+func formMessage(r *http.Request) (slackapi.Message, string) {
+	message := slackapi.Message{Text: r.Form.Get("text")}
+
+	if attachments := r.Form.Get("attachments"); attachments != "" {
+		if err := json.Unmarshal([]byte(attachments), &message.Attachments); err != nil {
+			return slackapi.Message{}, "invalid_attachments"
+		}
+	}
+
+	if blocks := r.Form.Get("blocks"); blocks != "" {
+		if err := json.Unmarshal([]byte(blocks), &message.Blocks); err != nil {
+			return slackapi.Message{}, "invalid_blocks"
+		}
+	}
+
+	if message.Text == "" && len(message.Attachments) == 0 && len(message.Blocks) == 0 {
+		return slackapi.Message{}, "no_text"
+	}
+
+	return message, ""
+}
+
+func nonNil(conversations []slackapi.Conversation) []slackapi.Conversation {
+	if conversations == nil {
+		return []slackapi.Conversation{}
+	}
+
+	return conversations
+}
+
+func contains(ids []string, id string) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func remove(ids []string, id string) []string {
+	var remaining []string
+	for _, candidate := range ids {
+		if candidate != id {
+			remaining = append(remaining, candidate)
+		}
+	}
+
+	return remaining
+}
@@ -0,0 +1,41 @@
+package slackapi
+
+import "github.com/pivotalservices/slack"
+
+// EachUser calls fn with each user in the team, fetching them a page at a
+// time, until fn returns false or there are no more users. Pages after the
+// one in which fn returns false are not fetched.
+func EachUser(api SlackAPI, fn func(slack.User) bool) error {
+	cursor := ""
+	for {
+		users, nextCursor, err := api.GetUsersPage(cursor)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			if !fn(user) {
+				return nil
+			}
+		}
+
+		if nextCursor == "" {
+			return nil
+		}
+		cursor = nextCursor
+	}
+}
+
+// AllUsers returns every user in the team, fetching every page of them.
+func AllUsers(api SlackAPI) ([]slack.User, error) {
+	var users []slack.User
+	err := EachUser(api, func(user slack.User) bool {
+		users = append(users, user)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
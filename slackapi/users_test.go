@@ -0,0 +1,73 @@
+package slackapi_test
+
+import (
+	"errors"
+
+	"github.com/pivotalservices/goulash/slackapi"
+	"github.com/pivotalservices/goulash/slackapi/slackapifakes"
+	"github.com/pivotalservices/slack"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Users", func() {
+	var fakeSlackAPI *slackapifakes.FakeSlackAPI
+
+	BeforeEach(func() {
+		fakeSlackAPI = &slackapifakes.FakeSlackAPI{}
+		fakeSlackAPI.GetUsersPageStub = func(cursor string) ([]slack.User, string, error) {
+			switch cursor {
+			case "":
+				return []slack.User{{ID: "U1"}, {ID: "U2"}}, "page-2", nil
+			case "page-2":
+				return []slack.User{{ID: "U3"}}, "page-3", nil
+			default:
+				return []slack.User{{ID: "U4"}}, "", nil
+			}
+		}
+	})
+
+	Describe("EachUser", func() {
+		It("goes through every page of users", func() {
+			var ids []string
+			err := slackapi.EachUser(fakeSlackAPI, func(user slack.User) bool {
+				ids = append(ids, user.ID)
+				return true
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(ids).Should(Equal([]string{"U1", "U2", "U3", "U4"}))
+
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(3))
+			Ω(fakeSlackAPI.GetUsersPageArgsForCall(1)).Should(Equal("page-2"))
+			Ω(fakeSlackAPI.GetUsersPageArgsForCall(2)).Should(Equal("page-3"))
+		})
+
+		It("stops without fetching more pages once told to", func() {
+			var ids []string
+			err := slackapi.EachUser(fakeSlackAPI, func(user slack.User) bool {
+				ids = append(ids, user.ID)
+				return user.ID != "U3"
+			})
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(ids).Should(Equal([]string{"U1", "U2", "U3"}))
+
+			Ω(fakeSlackAPI.GetUsersPageCallCount()).Should(Equal(2))
+		})
+
+		It("returns an error when a page cannot be fetched", func() {
+			fakeSlackAPI.GetUsersPageReturns(nil, "", errors.New("slack is down"))
+
+			err := slackapi.EachUser(fakeSlackAPI, func(slack.User) bool { return true })
+			Ω(err).Should(MatchError("slack is down"))
+		})
+	})
+
+	Describe("AllUsers", func() {
+		It("returns the users from every page", func() {
+			users, err := slackapi.AllUsers(fakeSlackAPI)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(users).Should(Equal([]slack.User{{ID: "U1"}, {ID: "U2"}, {ID: "U3"}, {ID: "U4"}}))
+		})
+	})
+})